@@ -11,6 +11,7 @@ import (
 	"github.com/LorenzoCampos/bolsillo-claro/internal/config"
 	"github.com/LorenzoCampos/bolsillo-claro/internal/database"
 	"github.com/LorenzoCampos/bolsillo-claro/internal/server"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/attachments"
 	"github.com/LorenzoCampos/bolsillo-claro/pkg/scheduler"
 )
 
@@ -35,11 +36,27 @@ func main() {
 	// Esto garantiza que siempre cerremos el pool de conexiones
 	defer db.Close()
 
-	// Paso 3: Crear el servidor HTTP (ahora le pasamos también la DB)
-	srv := server.New(cfg, db)
+	// Paso 2.5: Storage de adjuntos (filesystem local o S3 compatible según STORAGE_BACKEND)
+	store, err := attachments.New(attachments.Config{
+		Backend:           cfg.StorageBackend,
+		LocalPath:         cfg.StoragePath,
+		S3Endpoint:        cfg.S3Endpoint,
+		S3Region:          cfg.S3Region,
+		S3Bucket:          cfg.S3Bucket,
+		S3AccessKeyID:     cfg.S3AccessKeyID,
+		S3SecretAccessKey: cfg.S3SecretAccessKey,
+		S3ForcePathStyle:  cfg.S3ForcePathStyle,
+	})
+	if err != nil {
+		log.Fatalf("❌ Error configurando el storage de adjuntos: %v", err)
+	}
+	fmt.Printf("✅ Storage de adjuntos: %s\n", store.Name())
+
+	// Paso 3: Crear el servidor HTTP (ahora le pasamos también la DB y el storage)
+	srv := server.New(cfg, db, store)
 	fmt.Println("✅ Servidor HTTP creado")
 
-	// Paso 3.5: Iniciar CRON scheduler para gastos e ingresos recurrentes
+	// Paso 3.5: Iniciar CRON scheduler para gastos e ingresos recurrentes y aportes a metas
 	c := cron.New()
 	
 	// Ejecutar generación diaria a las 00:01 (1 minuto después de medianoche)
@@ -56,6 +73,19 @@ func main() {
 		if err != nil {
 			log.Printf("❌ Error en generación de ingresos recurrentes: %v", err)
 		}
+
+		// Después de generar los ingresos del día, para que los aportes por porcentaje los incluyan
+		fmt.Println("🎯 Ejecutando aportes automáticos a metas de ahorro...")
+		err = scheduler.ExecuteSavingsContributionRules(db.Pool)
+		if err != nil {
+			log.Printf("❌ Error en aportes automáticos a metas: %v", err)
+		}
+
+		fmt.Println("📎 Eliminando archivos de adjuntos borrados...")
+		err = scheduler.PurgeDeletedAttachments(db.Pool, store)
+		if err != nil {
+			log.Printf("❌ Error eliminando archivos de adjuntos: %v", err)
+		}
 	})
 	
 	// Iniciar CRON
@@ -75,6 +105,12 @@ func main() {
 		if err != nil {
 			log.Printf("❌ Error en generación inicial de ingresos: %v", err)
 		}
+
+		fmt.Println("🎯 Ejecutando aportes automáticos a metas (catchup)...")
+		err = scheduler.ExecuteSavingsContributionRules(db.Pool)
+		if err != nil {
+			log.Printf("❌ Error en aportes automáticos iniciales: %v", err)
+		}
 	}()
 
 	// Paso 4: Setup de graceful shutdown
@@ -16,6 +16,21 @@ type Config struct {
 	JWTSecret        string // Clave secreta para firmar tokens JWT
 	JWTAccessExpiry  string // Duración del access token (ej: "15m")
 	JWTRefreshExpiry string // Duración del refresh token (ej: "7d")
+	PriceFixtures    string // JSON con cotizaciones para el proveedor local de precios (vacío = las embebidas)
+
+	// Storage de adjuntos (comprobantes): local o s3
+	StorageBackend    string // "local" (default) o "s3"
+	StoragePath       string // Carpeta del storage local (ej: "./uploads")
+	S3Endpoint        string // Vacío = AWS; para MinIO u otro compatible: "http://localhost:9000"
+	S3Region          string
+	S3Bucket          string
+	S3AccessKeyID     string
+	S3SecretAccessKey string
+	S3ForcePathStyle  bool // true para MinIO y la mayoría de los compatibles
+
+	// OCR de comprobantes (POST /api/receipts/scan)
+	OCRProvider  string // "tesseract" (default) o "fake" (texto de ejemplo, para desarrollo)
+	OCRLanguages string // Idiomas de tesseract (ej: "spa+eng")
 }
 
 // Load carga las variables de entorno desde el archivo .env
@@ -36,6 +51,19 @@ func Load() (*Config, error) {
 		JWTSecret:        getEnv("JWT_SECRET", ""),
 		JWTAccessExpiry:  getEnv("JWT_ACCESS_EXPIRY", "15m"),
 		JWTRefreshExpiry: getEnv("JWT_REFRESH_EXPIRY", "7d"),
+		PriceFixtures:    getEnv("PRICE_FIXTURES_PATH", ""),
+
+		StorageBackend:    getEnv("STORAGE_BACKEND", "local"),
+		StoragePath:       getEnv("STORAGE_PATH", "./uploads"),
+		S3Endpoint:        getEnv("S3_ENDPOINT", ""),
+		S3Region:          getEnv("S3_REGION", "us-east-1"),
+		S3Bucket:          getEnv("S3_BUCKET", ""),
+		S3AccessKeyID:     getEnv("S3_ACCESS_KEY_ID", ""),
+		S3SecretAccessKey: getEnv("S3_SECRET_ACCESS_KEY", ""),
+		S3ForcePathStyle:  getEnv("S3_FORCE_PATH_STYLE", "false") == "true",
+
+		OCRProvider:  getEnv("OCR_PROVIDER", "tesseract"),
+		OCRLanguages: getEnv("OCR_LANGUAGES", "spa+eng"),
 	}
 
 	// Validar que las variables críticas existan
@@ -13,16 +13,18 @@ import (
 
 // AddMemberRequest representa la request para agregar un miembro
 type AddMemberRequest struct {
-	Name  string `json:"name" binding:"required"`
-	Email string `json:"email"`
+	Name             string   `json:"name" binding:"required"`
+	Email            string   `json:"email"`
+	MonthlyAllowance *float64 `json:"monthlyAllowance" binding:"omitempty,gt=0"` // En la moneda de la cuenta
 }
 
 // AddMemberResponse representa la response al agregar un miembro
 type AddMemberResponse struct {
-	ID       string `json:"id"`
-	Name     string `json:"name"`
-	Email    string `json:"email"`
-	IsActive bool   `json:"isActive"`
+	ID               string   `json:"id"`
+	Name             string   `json:"name"`
+	Email            string   `json:"email"`
+	IsActive         bool     `json:"isActive"`
+	MonthlyAllowance *float64 `json:"monthlyAllowance"`
 }
 
 // AddMember maneja POST /api/accounts/:id/members
@@ -131,9 +133,9 @@ func (h *Handler) AddMember(c *gin.Context) {
 	// Insertar nuevo miembro
 	memberID := uuid.New()
 	insertMemberQuery := `
-		INSERT INTO family_members (id, account_id, name, email, is_active, created_at)
-		VALUES ($1, $2, $3, $4, true, NOW())
-		RETURNING id, name, email, is_active
+		INSERT INTO family_members (id, account_id, name, email, monthly_allowance, is_active, created_at)
+		VALUES ($1, $2, $3, $4, $5, true, NOW())
+		RETURNING id, name, email, is_active, monthly_allowance
 	`
 
 	var member AddMemberResponse
@@ -144,7 +146,8 @@ func (h *Handler) AddMember(c *gin.Context) {
 		accountID,
 		req.Name,
 		req.Email,
-	).Scan(&member.ID, &member.Name, &member.Email, &member.IsActive)
+		req.MonthlyAllowance,
+	).Scan(&member.ID, &member.Name, &member.Email, &member.IsActive, &member.MonthlyAllowance)
 
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -22,8 +22,9 @@ type CreateAccountRequest struct {
 
 // MemberInput representa un miembro familiar en la request
 type MemberInput struct {
-	Name  string `json:"name" binding:"required"`
-	Email string `json:"email"`
+	Name             string   `json:"name" binding:"required"`
+	Email            string   `json:"email"`
+	MonthlyAllowance *float64 `json:"monthlyAllowance" binding:"omitempty,gt=0"` // En la moneda de la cuenta
 }
 
 // AccountResponse representa la cuenta creada
@@ -38,9 +39,10 @@ type AccountResponse struct {
 
 // MemberResponse representa un miembro en la response
 type MemberResponse struct {
-	ID    string `json:"id"`
-	Name  string `json:"name"`
-	Email string `json:"email,omitempty"`
+	ID               string   `json:"id"`
+	Name             string   `json:"name"`
+	Email            string   `json:"email,omitempty"`
+	MonthlyAllowance *float64 `json:"monthlyAllowance,omitempty"`
 }
 
 // Handler encapsula las dependencias
@@ -99,6 +101,16 @@ func (h *Handler) CreateAccount(c *gin.Context) {
 		return
 	}
 
+	// Validar asignaciones mensuales (el binding no recorre los miembros)
+	for _, member := range req.Members {
+		if member.MonthlyAllowance != nil && *member.MonthlyAllowance <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "La asignación mensual de un miembro debe ser mayor a 0",
+			})
+			return
+		}
+	}
+
 	ctx := c.Request.Context()
 
 	// Generar ID para la cuenta
@@ -161,8 +173,8 @@ func (h *Handler) CreateAccount(c *gin.Context) {
 		for _, member := range req.Members {
 			memberID := uuid.New()
 			insertMemberQuery := `
-				INSERT INTO family_members (id, account_id, name, email, is_active, created_at)
-				VALUES ($1, $2, $3, $4, true, NOW())
+				INSERT INTO family_members (id, account_id, name, email, monthly_allowance, is_active, created_at)
+				VALUES ($1, $2, $3, $4, $5, true, NOW())
 			`
 
 			_, err = tx.Exec(
@@ -172,6 +184,7 @@ func (h *Handler) CreateAccount(c *gin.Context) {
 				accountID,
 				strings.TrimSpace(member.Name),
 				strings.TrimSpace(member.Email),
+				member.MonthlyAllowance,
 			)
 
 			if err != nil {
@@ -183,9 +196,10 @@ func (h *Handler) CreateAccount(c *gin.Context) {
 			}
 
 			members = append(members, MemberResponse{
-				ID:    memberID.String(),
-				Name:  member.Name,
-				Email: member.Email,
+				ID:               memberID.String(),
+				Name:             member.Name,
+				Email:            member.Email,
+				MonthlyAllowance: member.MonthlyAllowance,
 			})
 		}
 	}
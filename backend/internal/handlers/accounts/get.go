@@ -10,10 +10,11 @@ import (
 
 // FamilyMemberDetail representa un miembro de la familia
 type FamilyMemberDetail struct {
-	ID       string `json:"id"`
-	Name     string `json:"name"`
-	Email    string `json:"email"`
-	IsActive bool   `json:"isActive"`
+	ID               string   `json:"id"`
+	Name             string   `json:"name"`
+	Email            string   `json:"email"`
+	IsActive         bool     `json:"isActive"`
+	MonthlyAllowance *float64 `json:"monthlyAllowance"`
 }
 
 // AccountDetail representa el detalle completo de una cuenta
@@ -92,7 +93,8 @@ func (h *Handler) GetAccount(c *gin.Context) {
 				id,
 				name,
 				email,
-				is_active
+				is_active,
+				monthly_allowance
 			FROM family_members
 			WHERE account_id = $1 AND is_active = true
 			ORDER BY created_at ASC
@@ -111,7 +113,7 @@ func (h *Handler) GetAccount(c *gin.Context) {
 		members := []FamilyMemberDetail{}
 		for rows.Next() {
 			var member FamilyMemberDetail
-			err := rows.Scan(&member.ID, &member.Name, &member.Email, &member.IsActive)
+			err := rows.Scan(&member.ID, &member.Name, &member.Email, &member.IsActive, &member.MonthlyAllowance)
 			if err != nil {
 				c.JSON(http.StatusInternalServerError, gin.H{
 					"error":   "Error leyendo miembros",
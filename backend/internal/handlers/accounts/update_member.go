@@ -12,20 +12,22 @@ import (
 
 // UpdateMemberRequest representa la request para actualizar un miembro
 type UpdateMemberRequest struct {
-	Name  *string `json:"name,omitempty"`
-	Email *string `json:"email,omitempty"`
+	Name             *string  `json:"name,omitempty"`
+	Email            *string  `json:"email,omitempty"`
+	MonthlyAllowance *float64 `json:"monthlyAllowance,omitempty" binding:"omitempty,gte=0"` // 0 quita la asignación
 }
 
 // UpdateMemberResponse representa la response al actualizar un miembro
 type UpdateMemberResponse struct {
-	ID       string `json:"id"`
-	Name     string `json:"name"`
-	Email    string `json:"email"`
-	IsActive bool   `json:"isActive"`
+	ID               string   `json:"id"`
+	Name             string   `json:"name"`
+	Email            string   `json:"email"`
+	IsActive         bool     `json:"isActive"`
+	MonthlyAllowance *float64 `json:"monthlyAllowance"`
 }
 
 // UpdateMember maneja PUT /api/accounts/:id/members/:member_id
-// Actualiza nombre y/o email de un miembro existente
+// Actualiza nombre, email y/o asignación mensual de un miembro existente
 func (h *Handler) UpdateMember(c *gin.Context) {
 	// Extraer user_id del contexto
 	userID, ok := middleware.GetUserID(c)
@@ -58,9 +60,9 @@ func (h *Handler) UpdateMember(c *gin.Context) {
 	}
 
 	// Validar que al menos un campo esté presente
-	if req.Name == nil && req.Email == nil {
+	if req.Name == nil && req.Email == nil && req.MonthlyAllowance == nil {
 		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Debe proporcionar al menos un campo para actualizar (name o email)",
+			"error": "Debe proporcionar al menos un campo para actualizar (name, email o monthlyAllowance)",
 		})
 		return
 	}
@@ -191,6 +193,16 @@ func (h *Handler) UpdateMember(c *gin.Context) {
 		needsComma = true
 	}
 
+	if req.MonthlyAllowance != nil {
+		if needsComma {
+			query += ","
+		}
+		argPosition++
+		query += " monthly_allowance = NULLIF($" + string(rune(argPosition+'0')) + "::NUMERIC, 0)"
+		args = append(args, *req.MonthlyAllowance)
+		needsComma = true
+	}
+
 	// Agregar condiciones WHERE
 	argPosition++
 	query += " WHERE id = $" + string(rune(argPosition+'0'))
@@ -200,7 +212,7 @@ func (h *Handler) UpdateMember(c *gin.Context) {
 	query += " AND account_id = $" + string(rune(argPosition+'0'))
 	args = append(args, accountID)
 
-	query += " RETURNING id, name, email, is_active"
+	query += " RETURNING id, name, email, is_active, monthly_allowance"
 
 	// Ejecutar UPDATE
 	var member UpdateMemberResponse
@@ -209,6 +221,7 @@ func (h *Handler) UpdateMember(c *gin.Context) {
 		&member.Name,
 		&member.Email,
 		&member.IsActive,
+		&member.MonthlyAllowance,
 	)
 
 	if err != nil {
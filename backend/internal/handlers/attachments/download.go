@@ -0,0 +1,126 @@
+package attachments
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/LorenzoCampos/bolsillo-claro/internal/middleware"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/attachments"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// contentDisposition encodes the original name per RFC 5987 (it may have accents or spaces)
+func contentDisposition(disposition, filename string) string {
+	return disposition + "; filename*=UTF-8''" + strings.ReplaceAll(url.QueryEscape(filename), "+", "%20")
+}
+
+// DownloadAttachment handles GET /api/attachments/:id/download?download=true
+// Serves the file inline (to preview images and PDFs); download=true forces "save as"
+func DownloadAttachment(db *pgxpool.Pool, store attachments.Storage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountID, exists := c.Get("account_id")
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "account_id not found in context"})
+			return
+		}
+
+		var backend, key, filename, contentType string
+		var size int64
+		err := db.QueryRow(c.Request.Context(), `
+			SELECT storage_backend, storage_key, filename, content_type, size_bytes
+			FROM attachments
+			WHERE id = $1 AND account_id = $2
+		`, c.Param("id"), accountID).Scan(&backend, &key, &filename, &contentType, &size)
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "attachment not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch attachment: " + err.Error()})
+			return
+		}
+		if backend != store.Name() {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "attachment is in the " + backend + " storage, which is not the one configured"})
+			return
+		}
+
+		body, err := store.Get(c.Request.Context(), key)
+		if err == attachments.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "attachment file not found in storage"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to read attachment: " + err.Error()})
+			return
+		}
+		defer body.Close()
+
+		disposition := "inline"
+		if c.Query("download") == "true" {
+			disposition = "attachment"
+		}
+
+		c.DataFromReader(http.StatusOK, size, contentType, body, map[string]string{
+			"Content-Disposition":    contentDisposition(disposition, filename),
+			"X-Content-Type-Options": "nosniff",
+			"Cache-Control":          "private, no-cache",
+		})
+	}
+}
+
+// DeleteAttachment handles DELETE /api/attachments/:id
+// The file is removed right away; if the storage fails it stays queued for the scheduler
+func DeleteAttachment(db *pgxpool.Pool, store attachments.Storage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountID, exists := c.Get("account_id")
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "account_id not found in context"})
+			return
+		}
+		userID, _ := middleware.GetUserID(c)
+		attachmentID := c.Param("id")
+
+		// The trigger queues the file in attachment_deletions
+		var backend, key string
+		err := db.QueryRow(c.Request.Context(), `
+			DELETE FROM attachments
+			WHERE id = $1 AND account_id = $2
+			RETURNING storage_backend, storage_key
+		`, attachmentID, accountID).Scan(&backend, &key)
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "attachment not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete attachment: " + err.Error()})
+			return
+		}
+
+		if backend == store.Name() {
+			if err := store.Delete(c.Request.Context(), key); err != nil {
+				logger.Error("attachment.file_delete_failed", "No se pudo borrar el archivo, queda en la cola", map[string]interface{}{
+					"attachment_id": attachmentID,
+					"error":         err.Error(),
+				})
+			} else {
+				db.Exec(c.Request.Context(), `DELETE FROM attachment_deletions WHERE storage_backend = $1 AND storage_key = $2`, backend, key)
+			}
+		}
+
+		logger.Info("attachment.deleted", "Adjunto eliminado", map[string]interface{}{
+			"attachment_id": attachmentID,
+			"account_id":    accountID,
+			"user_id":       userID,
+			"ip":            c.ClientIP(),
+		})
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "attachment deleted successfully",
+			"id":      attachmentID,
+		})
+	}
+}
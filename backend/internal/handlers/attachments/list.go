@@ -0,0 +1,72 @@
+package attachments
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ListExpenseAttachments handles GET /api/expenses/:id/attachments
+func ListExpenseAttachments(db *pgxpool.Pool) gin.HandlerFunc {
+	return list(db, expenseMovement)
+}
+
+// ListIncomeAttachments handles GET /api/incomes/:id/attachments
+func ListIncomeAttachments(db *pgxpool.Pool) gin.HandlerFunc {
+	return list(db, incomeMovement)
+}
+
+func list(db *pgxpool.Pool, m movement) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountID, exists := c.Get("account_id")
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "account_id not found in context"})
+			return
+		}
+		movementID := c.Param("id")
+
+		var found bool
+		err := db.QueryRow(c.Request.Context(), `SELECT true FROM `+m.table+` WHERE id = $1 AND account_id = $2`, movementID, accountID).Scan(&found)
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": m.name + " not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch " + m.name + ": " + err.Error()})
+			return
+		}
+
+		rows, err := db.Query(c.Request.Context(), `
+			SELECT `+attachmentColumns+`
+			FROM attachments
+			WHERE `+m.column+` = $1 AND account_id = $2
+			ORDER BY created_at ASC
+		`, movementID, accountID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch attachments: " + err.Error()})
+			return
+		}
+		defer rows.Close()
+
+		result := []AttachmentResponse{}
+		for rows.Next() {
+			attachment, err := scanAttachment(rows)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to parse attachment: " + err.Error()})
+				return
+			}
+			result = append(result, *attachment)
+		}
+		if err := rows.Err(); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error reading attachments"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"attachments": result,
+			"count":       len(result),
+		})
+	}
+}
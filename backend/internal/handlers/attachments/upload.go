@@ -0,0 +1,135 @@
+package attachments
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/LorenzoCampos/bolsillo-claro/internal/middleware"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/attachments"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type AttachmentResponse struct {
+	ID          string  `json:"id"`
+	ExpenseID   *string `json:"expense_id,omitempty"`
+	IncomeID    *string `json:"income_id,omitempty"`
+	Filename    string  `json:"filename"`
+	ContentType string  `json:"content_type"`
+	SizeBytes   int64   `json:"size_bytes"`
+	SHA256      string  `json:"sha256"`
+	UploadedBy  *string `json:"uploaded_by"`
+	CreatedAt   string  `json:"created_at"`
+	DownloadURL string  `json:"download_url"`
+}
+
+// movement is what the attachments hang from: an expense or an income
+type movement struct {
+	table  string // expenses, incomes
+	column string // Column in attachments: expense_id, income_id
+	name   string // For errors and logs
+}
+
+var (
+	expenseMovement = movement{table: "expenses", column: "expense_id", name: "expense"}
+	incomeMovement  = movement{table: "incomes", column: "income_id", name: "income"}
+)
+
+const attachmentColumns = `id, expense_id, income_id, filename, content_type, size_bytes, sha256, uploaded_by, created_at`
+
+func scanAttachment(row pgx.Row) (*AttachmentResponse, error) {
+	var a AttachmentResponse
+	var createdAt time.Time
+
+	err := row.Scan(&a.ID, &a.ExpenseID, &a.IncomeID, &a.Filename, &a.ContentType, &a.SizeBytes, &a.SHA256, &a.UploadedBy, &createdAt)
+	if err != nil {
+		return nil, err
+	}
+
+	a.CreatedAt = createdAt.Format(time.RFC3339)
+	a.DownloadURL = "/api/attachments/" + a.ID + "/download"
+	return &a, nil
+}
+
+// UploadExpenseAttachment handles POST /api/expenses/:id/attachments (multipart, field "file")
+func UploadExpenseAttachment(db *pgxpool.Pool, store attachments.Storage) gin.HandlerFunc {
+	return upload(db, store, expenseMovement)
+}
+
+// UploadIncomeAttachment handles POST /api/incomes/:id/attachments (multipart, field "file")
+func UploadIncomeAttachment(db *pgxpool.Pool, store attachments.Storage) gin.HandlerFunc {
+	return upload(db, store, incomeMovement)
+}
+
+func upload(db *pgxpool.Pool, store attachments.Storage, m movement) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountID, exists := c.Get("account_id")
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "account_id not found in context"})
+			return
+		}
+		userID, _ := middleware.GetUserID(c)
+		movementID := c.Param("id")
+
+		// The movement must belong to the account and have room for one more attachment
+		var count int
+		err := db.QueryRow(c.Request.Context(), `
+			SELECT (SELECT COUNT(*) FROM attachments a WHERE a.`+m.column+` = m.id)
+			FROM `+m.table+` m
+			WHERE m.id = $1 AND m.account_id = $2
+		`, movementID, accountID).Scan(&count)
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": m.name + " not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch " + m.name + ": " + err.Error()})
+			return
+		}
+		if count >= attachments.MaxPerMovement {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "an " + m.name + " can have at most " + strconv.Itoa(attachments.MaxPerMovement) + " attachments"})
+			return
+		}
+
+		file, err := attachments.FromRequest(c.Writer, c.Request)
+		if err != nil {
+			c.JSON(attachments.HTTPStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		key := attachments.Key(accountID.(string), file.ContentType)
+		if err := store.Put(c.Request.Context(), key, file.Data, file.ContentType); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to store file: " + err.Error()})
+			return
+		}
+
+		attachment, err := scanAttachment(db.QueryRow(c.Request.Context(), `
+			INSERT INTO attachments (account_id, `+m.column+`, storage_backend, storage_key, filename, content_type, size_bytes, sha256, uploaded_by)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			RETURNING `+attachmentColumns,
+			accountID, movementID, store.Name(), key, file.Filename,
+			file.ContentType, len(file.Data), file.SHA256, userID,
+		))
+		if err != nil {
+			store.Delete(c.Request.Context(), key) // Best effort: without the row nobody can reach the file
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save attachment: " + err.Error()})
+			return
+		}
+
+		logger.Info("attachment.uploaded", "Adjunto subido", map[string]interface{}{
+			"attachment_id": attachment.ID,
+			m.column:        movementID,
+			"content_type":  attachment.ContentType,
+			"size_bytes":    attachment.SizeBytes,
+			"storage":       store.Name(),
+			"account_id":    accountID,
+			"user_id":       userID,
+			"ip":            c.ClientIP(),
+		})
+
+		c.JSON(http.StatusCreated, attachment)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/categories"
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -13,6 +14,7 @@ type IncomeCategoryResponse struct {
 	ID        string  `json:"id"`
 	AccountID *string `json:"account_id,omitempty"`
 	Name      string  `json:"name"`
+	ParentID  *string `json:"parent_id,omitempty"` // NULL si es de primer nivel
 	Icon      *string `json:"icon,omitempty"`
 	Color     *string `json:"color,omitempty"`
 	IsSystem  bool    `json:"is_system"`
@@ -20,15 +22,17 @@ type IncomeCategoryResponse struct {
 }
 
 type CreateIncomeCategoryRequest struct {
-	Name  string  `json:"name" binding:"required"`
-	Icon  *string `json:"icon"`
-	Color *string `json:"color"`
+	Name     string  `json:"name" binding:"required"`
+	ParentID *string `json:"parent_id"` // Opcional: categoría de sistema o de la cuenta
+	Icon     *string `json:"icon"`
+	Color    *string `json:"color"`
 }
 
 type UpdateIncomeCategoryRequest struct {
-	Name  *string `json:"name"`
-	Icon  *string `json:"icon"`
-	Color *string `json:"color"`
+	Name     *string `json:"name"`
+	ParentID *string `json:"parent_id"` // "" para pasarla a primer nivel
+	Icon     *string `json:"icon"`
+	Color    *string `json:"color"`
 }
 
 func ListIncomeCategories(db *pgxpool.Pool) gin.HandlerFunc {
@@ -40,7 +44,7 @@ func ListIncomeCategories(db *pgxpool.Pool) gin.HandlerFunc {
 		}
 
 		query := `
-			SELECT id, account_id, name, icon, color, is_system, created_at
+			SELECT id, account_id, name, parent_id, icon, color, is_system, created_at
 			FROM income_categories
 			WHERE account_id IS NULL OR account_id = $1
 			ORDER BY is_system DESC, name ASC
@@ -63,6 +67,7 @@ func ListIncomeCategories(db *pgxpool.Pool) gin.HandlerFunc {
 				&cat.ID,
 				&accountIDPtr,
 				&cat.Name,
+				&cat.ParentID,
 				&cat.Icon,
 				&cat.Color,
 				&cat.IsSystem,
@@ -104,20 +109,34 @@ func CreateIncomeCategory(db *pgxpool.Pool) gin.HandlerFunc {
 			return
 		}
 
+		// The parent has to be visible to the account (system or custom)
+		if req.ParentID != nil {
+			tree, err := categories.LoadTree(c.Request.Context(), db, categories.IncomeTable, accountID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load categories: " + err.Error()})
+				return
+			}
+			if err := tree.ValidateParent("", *req.ParentID); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+		}
+
 		var cat IncomeCategoryResponse
 		var accountIDPtr *string
 		var createdAt time.Time
 
 		query := `
-			INSERT INTO income_categories (account_id, name, icon, color, is_system)
-			VALUES ($1, $2, $3, $4, FALSE)
-			RETURNING id, account_id, name, icon, color, is_system, created_at
+			INSERT INTO income_categories (account_id, name, icon, color, is_system, parent_id)
+			VALUES ($1, $2, $3, $4, FALSE, $5)
+			RETURNING id, account_id, name, parent_id, icon, color, is_system, created_at
 		`
 
-		err := db.QueryRow(c.Request.Context(), query, accountID, req.Name, req.Icon, req.Color).Scan(
+		err := db.QueryRow(c.Request.Context(), query, accountID, req.Name, req.Icon, req.Color, req.ParentID).Scan(
 			&cat.ID,
 			&accountIDPtr,
 			&cat.Name,
+			&cat.ParentID,
 			&cat.Icon,
 			&cat.Color,
 			&cat.IsSystem,
@@ -186,24 +205,39 @@ func UpdateIncomeCategory(db *pgxpool.Pool) gin.HandlerFunc {
 			return
 		}
 
+		// Moving it under another category: the new parent can't be itself or one of its subcategories
+		if req.ParentID != nil && *req.ParentID != "" {
+			tree, err := categories.LoadTree(c.Request.Context(), db, categories.IncomeTable, accountID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load categories: " + err.Error()})
+				return
+			}
+			if err := tree.ValidateParent(categoryID, *req.ParentID); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+		}
+
 		updateQuery := `
 			UPDATE income_categories SET
 				name = COALESCE($1, name),
 				icon = COALESCE($2, icon),
 				color = COALESCE($3, color),
+				parent_id = CASE WHEN $5::TEXT IS NULL THEN parent_id ELSE NULLIF($5, '')::UUID END,
 				updated_at = CURRENT_TIMESTAMP
 			WHERE id = $4
-			RETURNING id, account_id, name, icon, color, is_system, created_at
+			RETURNING id, account_id, name, parent_id, icon, color, is_system, created_at
 		`
 
 		var cat IncomeCategoryResponse
 		var accountIDPtr *string
 		var createdAt time.Time
 
-		err = db.QueryRow(c.Request.Context(), updateQuery, req.Name, req.Icon, req.Color, categoryID).Scan(
+		err = db.QueryRow(c.Request.Context(), updateQuery, req.Name, req.Icon, req.Color, categoryID, req.ParentID).Scan(
 			&cat.ID,
 			&accountIDPtr,
 			&cat.Name,
+			&cat.ParentID,
 			&cat.Icon,
 			&cat.Color,
 			&cat.IsSystem,
@@ -256,6 +290,12 @@ func DeleteIncomeCategory(db *pgxpool.Pool) gin.HandlerFunc {
 			return
 		}
 
+		// With reassign_to, everything using the category moves there instead of blocking the delete
+		if reassignTo := c.Query("reassign_to"); reassignTo != "" {
+			mergeCategory(c, db, incomeCategoryKind, categoryID, reassignTo, accountID)
+			return
+		}
+
 		var incomeCount int
 		countQuery := `SELECT COUNT(*) FROM incomes WHERE category_id = $1`
 		err = db.QueryRow(c.Request.Context(), countQuery, categoryID).Scan(&incomeCount)
@@ -273,6 +313,22 @@ func DeleteIncomeCategory(db *pgxpool.Pool) gin.HandlerFunc {
 			return
 		}
 
+		// Subcategories have to be moved or deleted first
+		var subcategoryCount int
+		err = db.QueryRow(c.Request.Context(), `SELECT COUNT(*) FROM income_categories WHERE parent_id = $1`, categoryID).Scan(&subcategoryCount)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check subcategories"})
+			return
+		}
+
+		if subcategoryCount > 0 {
+			c.JSON(http.StatusConflict, gin.H{
+				"error":             "cannot delete category with subcategories",
+				"subcategory_count": subcategoryCount,
+			})
+			return
+		}
+
 		deleteQuery := `DELETE FROM income_categories WHERE id = $1`
 		_, err = db.Exec(c.Request.Context(), deleteQuery, categoryID)
 
@@ -0,0 +1,189 @@
+package categories
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/LorenzoCampos/bolsillo-claro/internal/middleware"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/categories"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// MergeCategoryRequest es el body de POST /api/{expense,income}-categories/:id/merge
+type MergeCategoryRequest struct {
+	TargetCategoryID string `json:"target_category_id" binding:"required"`
+}
+
+// categoryRef es una columna que apunta a una categoría
+type categoryRef struct {
+	Key    string // Nombre en la respuesta (ej: expenses)
+	Table  string
+	Column string
+}
+
+// categoryKind describe una tabla de categorías y todo lo que la referencia
+type categoryKind struct {
+	Table string
+	Refs  []categoryRef
+}
+
+var expenseCategoryKind = categoryKind{
+	Table: categories.ExpenseTable,
+	Refs: []categoryRef{
+		{Key: "expenses", Table: "expenses", Column: "category_id"},
+		{Key: "recurring_expenses", Table: "recurring_expenses", Column: "category_id"},
+		{Key: "recurring_expense_versions", Table: "recurring_expense_versions", Column: "category_id"},
+		{Key: "installments", Table: "installment_purchases", Column: "category_id"},
+		{Key: "debts", Table: "debts", Column: "expense_category_id"},
+		{Key: "rules", Table: "categorization_rules", Column: "set_expense_category_id"},
+	},
+}
+
+var incomeCategoryKind = categoryKind{
+	Table: categories.IncomeTable,
+	Refs: []categoryRef{
+		{Key: "incomes", Table: "incomes", Column: "category_id"},
+		{Key: "recurring_incomes", Table: "recurring_incomes", Column: "category_id"},
+		{Key: "debts", Table: "debts", Column: "income_category_id"},
+		{Key: "rules", Table: "categorization_rules", Column: "set_income_category_id"},
+	},
+}
+
+// checkCustomCategory verifica que la categoría exista, sea custom y pertenezca a la cuenta
+// Devuelve el status y el error a responder (status 0 = OK)
+func checkCustomCategory(ctx context.Context, db *pgxpool.Pool, table, categoryID string, accountID interface{}, action string) (int, string) {
+	var isSystem bool
+	var categoryAccountID *string
+	err := db.QueryRow(ctx, `SELECT is_system, account_id FROM `+table+` WHERE id = $1`, categoryID).Scan(&isSystem, &categoryAccountID)
+	if err != nil {
+		return http.StatusNotFound, "category not found"
+	}
+	if isSystem {
+		return http.StatusForbidden, "cannot " + action + " system categories"
+	}
+	if categoryAccountID == nil || *categoryAccountID != accountID.(string) {
+		return http.StatusForbidden, "category does not belong to this account"
+	}
+	return 0, ""
+}
+
+// mergeCategory mueve todo lo que usa sourceID (movimientos, templates, cuotas, deudas y
+// subcategorías) a targetID y borra sourceID, en una sola transacción. Escribe la respuesta
+func mergeCategory(c *gin.Context, db *pgxpool.Pool, kind categoryKind, sourceID, targetID string, accountID interface{}) {
+	ctx := c.Request.Context()
+
+	tree, err := categories.LoadTree(ctx, db, kind.Table, accountID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load categories: " + err.Error()})
+		return
+	}
+	if _, ok := tree[targetID]; !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "target category does not exist or does not belong to this account"})
+		return
+	}
+	if targetID == sourceID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "target category must be different from the source"})
+		return
+	}
+	// The source's subcategories move under the target, so the target can't be one of them
+	for _, ancestor := range tree.Ancestors(targetID) {
+		if ancestor == sourceID {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "target category can't be a subcategory of the source"})
+			return
+		}
+	}
+
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to start transaction"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	moved := gin.H{}
+	for _, ref := range kind.Refs {
+		result, err := tx.Exec(ctx,
+			`UPDATE `+ref.Table+` SET `+ref.Column+` = $1 WHERE `+ref.Column+` = $2`,
+			targetID, sourceID,
+		)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to reassign " + ref.Key + ": " + err.Error()})
+			return
+		}
+		moved[ref.Key] = result.RowsAffected()
+	}
+
+	result, err := tx.Exec(ctx, `UPDATE `+kind.Table+` SET parent_id = $1 WHERE parent_id = $2`, targetID, sourceID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to move subcategories: " + err.Error()})
+		return
+	}
+	moved["subcategories"] = result.RowsAffected()
+
+	if _, err := tx.Exec(ctx, `DELETE FROM `+kind.Table+` WHERE id = $1`, sourceID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete category: " + err.Error()})
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to commit transaction"})
+		return
+	}
+
+	userID, _ := middleware.GetUserID(c)
+	logger.Info("category.merged", "Categoría fusionada", map[string]interface{}{
+		"table":      kind.Table,
+		"source_id":  sourceID,
+		"target_id":  targetID,
+		"moved":      moved,
+		"account_id": accountID,
+		"user_id":    userID,
+		"ip":         c.ClientIP(),
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "category merged successfully",
+		"id":        sourceID,
+		"target_id": targetID,
+		"moved":     moved,
+	})
+}
+
+// MergeExpenseCategory pasa todos los gastos, templates recurrentes, compras en cuotas,
+// deudas, reglas y subcategorías de una categoría custom a otra, y borra la original
+func MergeExpenseCategory(db *pgxpool.Pool) gin.HandlerFunc {
+	return mergeHandler(db, expenseCategoryKind)
+}
+
+// MergeIncomeCategory pasa todos los ingresos, templates recurrentes, deudas, reglas y
+// subcategorías de una categoría custom a otra, y borra la original
+func MergeIncomeCategory(db *pgxpool.Pool) gin.HandlerFunc {
+	return mergeHandler(db, incomeCategoryKind)
+}
+
+func mergeHandler(db *pgxpool.Pool, kind categoryKind) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountID, exists := c.Get("account_id")
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "account_id not found in context"})
+			return
+		}
+
+		categoryID := c.Param("id")
+
+		var req MergeCategoryRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if status, msg := checkCustomCategory(c.Request.Context(), db, kind.Table, categoryID, accountID, "merge"); status != 0 {
+			c.JSON(status, gin.H{"error": msg})
+			return
+		}
+
+		mergeCategory(c, db, kind, categoryID, req.TargetCategoryID, accountID)
+	}
+}
@@ -0,0 +1,70 @@
+package dashboard
+
+import (
+	"sort"
+
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/categories"
+)
+
+// groupByCategory regroups the per-category totals following the category tree
+// - rollup = false, parentID = nil: as assigned (each subcategory on its own)
+// - rollup = true, parentID = nil: subcategories add up into their top-level category
+// - parentID != nil: only that category's subtree, added up into its direct subcategories
+// (what was assigned to the parent itself shows up as the parent)
+func groupByCategory(flat []CategoryExpense, tree categories.Tree, rollup bool, parentID *string) []CategoryExpense {
+	result := []CategoryExpense{}
+	if !rollup && parentID == nil {
+		result = append(result, flat...)
+	} else {
+		grouped := map[string]*CategoryExpense{}
+		var order []string
+		for _, cat := range flat {
+			if cat.CategoryID == nil {
+				// Uncategorized expenses only make sense at the top level
+				if parentID == nil {
+					result = append(result, cat)
+				}
+				continue
+			}
+
+			key, ok := tree.RollupTo(*cat.CategoryID, parentID)
+			if !ok {
+				continue
+			}
+			if group, exists := grouped[key]; exists {
+				group.Total += cat.Total
+				continue
+			}
+
+			group := cat
+			if node, exists := tree[key]; exists {
+				id := node.ID
+				name := node.Name
+				group.CategoryID = &id
+				group.CategoryName = &name
+				group.CategoryIcon = node.Icon
+				group.CategoryColor = node.Color
+			}
+			grouped[key] = &group
+			order = append(order, key)
+		}
+		for _, key := range order {
+			result = append(result, *grouped[key])
+		}
+	}
+
+	for i := range result {
+		if result[i].CategoryID == nil {
+			continue
+		}
+		if node, exists := tree[*result[i].CategoryID]; exists {
+			result[i].ParentID = node.ParentID
+			result[i].HasChildren = tree.HasChildren(node.ID)
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Total > result[j].Total
+	})
+	return result
+}
@@ -0,0 +1,77 @@
+package dashboard
+
+import (
+	"context"
+
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/splits"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// memberShares is the member's share of each split expense of the month, as parallel
+// arrays so the summary queries can join them with UNNEST
+type memberShares struct {
+	expenseIDs     []string
+	amounts        []float64 // In the expense currency
+	primaryAmounts []float64 // In the account currency
+}
+
+// loadMemberShares computes the member's share of the split expenses they're part of,
+// with splits.Amounts like GET /expenses/:id/split and the members report
+func loadMemberShares(ctx context.Context, db *pgxpool.Pool, accountID interface{}, month, expenseDate, memberID string) (*memberShares, error) {
+	rows, err := db.Query(ctx, `
+		SELECT e.id, e.split_type, e.amount, e.amount_in_primary_currency,
+		       s.family_member_id, s.percentage, s.amount
+		FROM expenses e
+		JOIN expense_splits s ON s.expense_id = e.id
+		WHERE e.account_id = $1 AND e.split_type IS NOT NULL AND NOT e.is_draft
+		  AND TO_CHAR(`+expenseDate+`, 'YYYY-MM') = $2
+		  AND EXISTS (SELECT 1 FROM expense_splits m WHERE m.expense_id = e.id AND m.family_member_id::TEXT = $3)
+		ORDER BY e.id, s.created_at, s.family_member_id
+	`, accountID, month, memberID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	type splitExpense struct {
+		splitType     string
+		amount        float64
+		primaryAmount float64
+		shares        []splits.Share
+	}
+	expenses := map[string]*splitExpense{}
+	var order []string
+	for rows.Next() {
+		var id string
+		var e splitExpense
+		var share splits.Share
+		if err := rows.Scan(&id, &e.splitType, &e.amount, &e.primaryAmount, &share.MemberID, &share.Percentage, &share.Amount); err != nil {
+			return nil, err
+		}
+		if _, ok := expenses[id]; !ok {
+			expenses[id] = &e
+			order = append(order, id)
+		}
+		expenses[id].shares = append(expenses[id].shares, share)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	result := &memberShares{expenseIDs: []string{}, amounts: []float64{}, primaryAmounts: []float64{}}
+	for _, id := range order {
+		e := expenses[id]
+		amounts := splits.Amounts(e.splitType, e.amount, e.shares)
+		primaryAmounts := splits.Amounts(e.splitType, e.primaryAmount, e.shares)
+		for i, share := range e.shares {
+			if share.MemberID != memberID {
+				continue
+			}
+			result.expenseIDs = append(result.expenseIDs, id)
+			result.amounts = append(result.amounts, amounts[i])
+			result.primaryAmounts = append(result.primaryAmounts, primaryAmounts[i])
+		}
+	}
+
+	return result, nil
+}
@@ -1,9 +1,14 @@
 package dashboard
 
 import (
+	"math"
 	"net/http"
+	"sort"
 	"time"
 
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/categories"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/debts"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/savings"
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
@@ -14,6 +19,8 @@ type CategoryExpense struct {
 	CategoryName  *string `json:"category_name,omitempty"`
 	CategoryIcon  *string `json:"category_icon,omitempty"`
 	CategoryColor *string `json:"category_color,omitempty"`
+	ParentID      *string `json:"parent_id,omitempty"`
+	HasChildren   bool    `json:"has_children"` // Can be drilled down with category_parent_id
 	Total         float64 `json:"total"`
 	Percentage    float64 `json:"percentage"`
 }
@@ -42,17 +49,36 @@ type RecentTransaction struct {
 	CreatedAt               string  `json:"created_at"`
 }
 
+// OverdueDebtPayment represents a debt installment past its due date and not fully paid
+type OverdueDebtPayment struct {
+	DebtID            string  `json:"debt_id"`
+	Counterparty      string  `json:"counterparty"`
+	Direction         string  `json:"direction"` // borrowed (we owe it) or lent (they owe us)
+	InstallmentNumber int     `json:"installment_number"`
+	DueDate           string  `json:"due_date"`
+	AmountDue         float64 `json:"amount_due"` // What's left to pay of the installment
+	Currency          string  `json:"currency"`
+	DaysOverdue       int     `json:"days_overdue"`
+}
+
 // DashboardSummaryResponse represents the complete dashboard summary
 type DashboardSummaryResponse struct {
-	Period               string              `json:"period"` // YYYY-MM format
-	PrimaryCurrency      string              `json:"primary_currency"`
-	TotalIncome          float64             `json:"total_income"`
-	TotalExpenses        float64             `json:"total_expenses"`
-	TotalAssignedToGoals float64             `json:"total_assigned_to_goals"` // Always 0 for now
-	AvailableBalance     float64             `json:"available_balance"`
-	ExpensesByCategory   []CategoryExpense   `json:"expenses_by_category"`
-	TopExpenses          []TopExpense        `json:"top_expenses"`
-	RecentTransactions   []RecentTransaction `json:"recent_transactions"`
+	Period               string               `json:"period"` // YYYY-MM format
+	Basis                string               `json:"basis"`  // accrual (purchase date) or cash (card statement due date)
+	PrimaryCurrency      string               `json:"primary_currency"`
+	FamilyMemberID       *string              `json:"family_member_id,omitempty"`   // Only the member's incomes/expenses
+	CategoryView         string               `json:"category_view"`                // flat or rollup
+	CategoryParentID     *string              `json:"category_parent_id,omitempty"` // Drill down: expenses_by_category only has this category's subtree
+	TotalIncome          float64              `json:"total_income"`
+	TotalExpenses        float64              `json:"total_expenses"`
+	TotalAssignedToGoals float64              `json:"total_assigned_to_goals"` // Goal deposits - withdrawals of the month, in primary currency
+	UnconvertedGoalTxns  int                  `json:"unconverted_goal_transactions,omitempty"`
+	AvailableBalance     float64              `json:"available_balance"`
+	ExpensesByCategory   []CategoryExpense    `json:"expenses_by_category"`
+	TopExpenses          []TopExpense         `json:"top_expenses"`
+	RecentTransactions   []RecentTransaction  `json:"recent_transactions"`
+	OverdueDebtPayments  []OverdueDebtPayment `json:"overdue_debt_payments"` // As of today, regardless of the month
+	PendingDrafts        int                  `json:"pending_drafts"`        // Expenses from scanned receipts waiting to be confirmed (not counted above)
 }
 
 // GetSummary handles GET /api/dashboard/summary
@@ -70,12 +96,38 @@ func GetSummary(db *pgxpool.Pool) gin.HandlerFunc {
 		month := c.DefaultQuery("month", time.Now().Format("2006-01"))
 
 		// Validate month format (YYYY-MM)
-		_, err := time.Parse("2006-01", month)
+		monthStart, err := time.Parse("2006-01", month)
 		if err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid month format, use YYYY-MM"})
 			return
 		}
 
+		// basis=accrual (default): expenses count in the month of their date
+		// basis=cash: card expenses count in the month their statement is due
+		basis := c.DefaultQuery("basis", "accrual")
+		expenseDate := "e.date"
+		switch basis {
+		case "accrual":
+		case "cash":
+			expenseDate = "COALESCE(e.statement_due_date, e.date)"
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "basis must be accrual or cash"})
+			return
+		}
+
+		// category_view=flat (default): expenses_by_category as assigned
+		// category_view=rollup: subcategories add up into their top-level category
+		// category_parent_id: drill down into one category, split by its direct subcategories
+		categoryView := c.DefaultQuery("category_view", "flat")
+		if categoryView != "flat" && categoryView != "rollup" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "category_view must be flat or rollup"})
+			return
+		}
+		var categoryParentID *string
+		if parentID := c.Query("category_parent_id"); parentID != "" {
+			categoryParentID = &parentID
+		}
+
 		ctx := c.Request.Context()
 
 		// Get primary currency of the account
@@ -86,17 +138,54 @@ func GetSummary(db *pgxpool.Pool) gin.HandlerFunc {
 			return
 		}
 
+		// family_member_id (optional): only incomes/expenses assigned to that member
+		// Split expenses count with the member's share, same amounts as GET /expenses/:id/split
+		// Deactivated members are accepted so past months can still be filtered
+		args := []interface{}{accountID, month}
+		expenseArgs := args
+		expenseMember, incomeMember, expenseShares := "", "", ""
+		expenseAmount, expensePrimary := "e.amount", "e.amount_in_primary_currency"
+		var familyMemberID *string
+		if memberID := c.Query("family_member_id"); memberID != "" {
+			var found bool
+			err := db.QueryRow(ctx,
+				`SELECT EXISTS(SELECT 1 FROM family_members WHERE id::TEXT = $1 AND account_id = $2)`,
+				memberID, accountID,
+			).Scan(&found)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to validate family member"})
+				return
+			}
+			if !found {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "family_member_id does not belong to this account"})
+				return
+			}
+			shares, err := loadMemberShares(ctx, db, accountID, month, expenseDate, memberID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get split expenses of the member"})
+				return
+			}
+			familyMemberID = &memberID
+			args = append(args, memberID)
+			expenseArgs = append(args, shares.expenseIDs, shares.amounts, shares.primaryAmounts)
+			expenseShares = " LEFT JOIN UNNEST($4::TEXT[], $5::FLOAT8[], $6::FLOAT8[]) AS ms(expense_id, amount, amount_in_primary_currency) ON ms.expense_id = e.id::TEXT"
+			expenseAmount = "COALESCE(ms.amount, e.amount)"
+			expensePrimary = "COALESCE(ms.amount_in_primary_currency, e.amount_in_primary_currency)"
+			expenseMember = " AND (e.split_type IS NULL AND e.family_member_id = $3 OR ms.expense_id IS NOT NULL)"
+			incomeMember = " AND i.family_member_id = $3"
+		}
+
 		// ============================================================================
 		// 1. CALCULATE TOTAL INCOME (sum of amount_in_primary_currency)
 		// ============================================================================
 		var totalIncome float64
 		incomeQuery := `
-			SELECT COALESCE(SUM(amount_in_primary_currency), 0)
-			FROM incomes
-			WHERE account_id = $1
-			  AND TO_CHAR(date, 'YYYY-MM') = $2
+			SELECT COALESCE(SUM(i.amount_in_primary_currency), 0)
+			FROM incomes i
+			WHERE i.account_id = $1
+			  AND TO_CHAR(i.date, 'YYYY-MM') = $2` + incomeMember + `
 		`
-		err = db.QueryRow(ctx, incomeQuery, accountID, month).Scan(&totalIncome)
+		err = db.QueryRow(ctx, incomeQuery, args...).Scan(&totalIncome)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to calculate total income"})
 			return
@@ -107,12 +196,12 @@ func GetSummary(db *pgxpool.Pool) gin.HandlerFunc {
 		// ============================================================================
 		var totalExpenses float64
 		expensesQuery := `
-			SELECT COALESCE(SUM(amount_in_primary_currency), 0)
-			FROM expenses
-			WHERE account_id = $1
-			  AND TO_CHAR(date, 'YYYY-MM') = $2
+			SELECT COALESCE(SUM(` + expensePrimary + `), 0)
+			FROM expenses e` + expenseShares + `
+			WHERE e.account_id = $1 AND NOT e.is_draft
+			  AND TO_CHAR(` + expenseDate + `, 'YYYY-MM') = $2` + expenseMember + `
 		`
-		err = db.QueryRow(ctx, expensesQuery, accountID, month).Scan(&totalExpenses)
+		err = db.QueryRow(ctx, expensesQuery, expenseArgs...).Scan(&totalExpenses)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to calculate total expenses"})
 			return
@@ -127,24 +216,24 @@ func GetSummary(db *pgxpool.Pool) gin.HandlerFunc {
 				ec.name as category_name,
 				ec.icon as category_icon,
 				ec.color as category_color,
-				SUM(e.amount_in_primary_currency) as total
-			FROM expenses e
+				SUM(` + expensePrimary + `) as total
+			FROM expenses e` + expenseShares + `
 			LEFT JOIN expense_categories ec ON e.category_id = ec.id
-			WHERE e.account_id = $1
-			  AND TO_CHAR(e.date, 'YYYY-MM') = $2
+			WHERE e.account_id = $1 AND NOT e.is_draft
+			  AND TO_CHAR(` + expenseDate + `, 'YYYY-MM') = $2` + expenseMember + `
 			GROUP BY e.category_id, ec.name, ec.icon, ec.color
-			HAVING SUM(e.amount_in_primary_currency) > 0
+			HAVING SUM(` + expensePrimary + `) > 0
 			ORDER BY total DESC
 		`
 
-		rows, err := db.Query(ctx, categoryQuery, accountID, month)
+		rows, err := db.Query(ctx, categoryQuery, expenseArgs...)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get expenses by category"})
 			return
 		}
 		defer rows.Close()
 
-		expensesByCategory := []CategoryExpense{}
+		flatByCategory := []CategoryExpense{}
 		for rows.Next() {
 			var cat CategoryExpense
 			err := rows.Scan(&cat.CategoryID, &cat.CategoryName, &cat.CategoryIcon, &cat.CategoryColor, &cat.Total)
@@ -152,15 +241,7 @@ func GetSummary(db *pgxpool.Pool) gin.HandlerFunc {
 				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to parse category expense"})
 				return
 			}
-
-			// Calculate percentage
-			if totalExpenses > 0 {
-				cat.Percentage = (cat.Total / totalExpenses) * 100
-			} else {
-				cat.Percentage = 0
-			}
-
-			expensesByCategory = append(expensesByCategory, cat)
+			flatByCategory = append(flatByCategory, cat)
 		}
 
 		if err := rows.Err(); err != nil {
@@ -168,6 +249,34 @@ func GetSummary(db *pgxpool.Pool) gin.HandlerFunc {
 			return
 		}
 
+		tree, err := categories.LoadTree(ctx, db, categories.ExpenseTable, accountID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load categories"})
+			return
+		}
+		if categoryParentID != nil {
+			if _, ok := tree[*categoryParentID]; !ok {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "category_parent_id does not exist or does not belong to this account"})
+				return
+			}
+		}
+
+		expensesByCategory := groupByCategory(flatByCategory, tree, categoryView == "rollup", categoryParentID)
+
+		// Percentages over the month's expenses (or over the parent's total when drilling down)
+		categoryBase := totalExpenses
+		if categoryParentID != nil {
+			categoryBase = 0
+			for _, cat := range expensesByCategory {
+				categoryBase += cat.Total
+			}
+		}
+		for i := range expensesByCategory {
+			if categoryBase > 0 {
+				expensesByCategory[i].Percentage = (expensesByCategory[i].Total / categoryBase) * 100
+			}
+		}
+
 		// ============================================================================
 		// 4. TOP 5 EXPENSES (ordered by amount_in_primary_currency)
 		// ============================================================================
@@ -175,20 +284,20 @@ func GetSummary(db *pgxpool.Pool) gin.HandlerFunc {
 			SELECT 
 				e.id,
 				e.description,
-				e.amount,
+				` + expenseAmount + `,
 				e.currency,
-				e.amount_in_primary_currency,
+				` + expensePrimary + `,
 				ec.name as category_name,
 				e.date::TEXT
-			FROM expenses e
+			FROM expenses e` + expenseShares + `
 			LEFT JOIN expense_categories ec ON e.category_id = ec.id
-			WHERE e.account_id = $1
-			  AND TO_CHAR(e.date, 'YYYY-MM') = $2
-			ORDER BY e.amount_in_primary_currency DESC
+			WHERE e.account_id = $1 AND NOT e.is_draft
+			  AND TO_CHAR(` + expenseDate + `, 'YYYY-MM') = $2` + expenseMember + `
+			ORDER BY ` + expensePrimary + ` DESC
 			LIMIT 5
 		`
 
-		rows, err = db.Query(ctx, topExpensesQuery, accountID, month)
+		rows, err = db.Query(ctx, topExpensesQuery, expenseArgs...)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get top expenses"})
 			return
@@ -221,16 +330,16 @@ func GetSummary(db *pgxpool.Pool) gin.HandlerFunc {
 					e.id,
 					'expense' as type,
 					e.description,
-					e.amount,
+					` + expenseAmount + `,
 					e.currency,
-					e.amount_in_primary_currency,
+					` + expensePrimary + `,
 					ec.name as category_name,
 					e.date::TEXT,
 					e.created_at::TEXT
-				FROM expenses e
+				FROM expenses e` + expenseShares + `
 				LEFT JOIN expense_categories ec ON e.category_id = ec.id
-				WHERE e.account_id = $1
-				  AND TO_CHAR(e.date, 'YYYY-MM') = $2
+				WHERE e.account_id = $1 AND NOT e.is_draft
+				  AND TO_CHAR(` + expenseDate + `, 'YYYY-MM') = $2` + expenseMember + `
 			)
 			UNION ALL
 			(
@@ -247,13 +356,13 @@ func GetSummary(db *pgxpool.Pool) gin.HandlerFunc {
 				FROM incomes i
 				LEFT JOIN income_categories ic ON i.category_id = ic.id
 				WHERE i.account_id = $1
-				  AND TO_CHAR(i.date, 'YYYY-MM') = $2
+				  AND TO_CHAR(i.date, 'YYYY-MM') = $2` + incomeMember + `
 			)
 			ORDER BY created_at DESC
 			LIMIT 10
 		`
 
-		rows, err = db.Query(ctx, recentTransactionsQuery, accountID, month)
+		rows, err = db.Query(ctx, recentTransactionsQuery, expenseArgs...)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get recent transactions"})
 			return
@@ -280,36 +389,98 @@ func GetSummary(db *pgxpool.Pool) gin.HandlerFunc {
 		// ============================================================================
 		// 6. CALCULATE TOTAL ASSIGNED TO SAVINGS GOALS
 		// ============================================================================
-		var totalAssignedToGoals float64
-		goalsQuery := `
-		SELECT COALESCE(SUM(current_amount), 0)
-		FROM savings_goals
-		WHERE account_id = $1 AND is_active = true
-	`
-		err = db.QueryRow(ctx, goalsQuery, accountID).Scan(&totalAssignedToGoals)
-		if err != nil {
-			// If there's an error, just set to 0 instead of failing the entire request
-			totalAssignedToGoals = 0
+		// Net of deposits and withdrawals dated in the month (same ledger as savings.AvailableBalance),
+		// so money moved into a goal reduces the available balance of that month
+		// Goals belong to the account, not to a member: with family_member_id they're left out
+		assigned := &savings.AssignedToGoals{}
+		if familyMemberID == nil {
+			monthEnd := monthStart.AddDate(0, 1, -1)
+			assigned, err = savings.NetAssigned(ctx, db, accountID, &monthStart, monthEnd)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to calculate total assigned to goals"})
+				return
+			}
 		}
+		totalAssignedToGoals := math.Round(assigned.Total*100) / 100
 
 		// ============================================================================
 		// 7. CALCULATE AVAILABLE BALANCE
 		// ============================================================================
 		availableBalance := totalIncome - totalExpenses - totalAssignedToGoals
 
+		// ============================================================================
+		// 8. OVERDUE DEBT PAYMENTS (as of today, not limited to the month)
+		// ============================================================================
+		today := time.Now().UTC().Truncate(24 * time.Hour)
+		accountDebts, err := debts.ListByAccount(ctx, db, accountID, nil)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get debts"})
+			return
+		}
+
+		overdueDebtPayments := []OverdueDebtPayment{}
+		for i := range accountDebts {
+			debt := &accountDebts[i]
+			if debt.WrittenOffAt != nil {
+				continue
+			}
+
+			ledger, err := debt.Ledger(ctx, db, today)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get debt payments"})
+				return
+			}
+
+			for _, entry := range ledger.Entries {
+				if entry.Status != debts.EntryOverdue {
+					continue
+				}
+				overdueDebtPayments = append(overdueDebtPayments, OverdueDebtPayment{
+					DebtID:            debt.ID,
+					Counterparty:      debt.Counterparty,
+					Direction:         debt.Direction,
+					InstallmentNumber: entry.Number,
+					DueDate:           entry.DueDate.Format("2006-01-02"),
+					AmountDue:         math.Round((entry.Amount-entry.Paid)*100) / 100,
+					Currency:          debt.Currency,
+					DaysOverdue:       int(today.Sub(entry.DueDate).Hours() / 24),
+				})
+			}
+		}
+		sort.SliceStable(overdueDebtPayments, func(i, j int) bool {
+			return overdueDebtPayments[i].DueDate < overdueDebtPayments[j].DueDate
+		})
+
+		// ============================================================================
+		// 9. PENDING RECEIPT DRAFTS (any month: they still need the user's review)
+		// ============================================================================
+		var pendingDrafts int
+		err = db.QueryRow(ctx, `SELECT COUNT(*) FROM expenses WHERE account_id = $1 AND is_draft`, accountID).Scan(&pendingDrafts)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to count pending drafts"})
+			return
+		}
+
 		// ============================================================================
 		// BUILD RESPONSE
 		// ============================================================================
 		response := DashboardSummaryResponse{
 			Period:               month,
+			Basis:                basis,
 			PrimaryCurrency:      primaryCurrency,
+			FamilyMemberID:       familyMemberID,
+			CategoryView:         categoryView,
+			CategoryParentID:     categoryParentID,
 			TotalIncome:          totalIncome,
 			TotalExpenses:        totalExpenses,
 			TotalAssignedToGoals: totalAssignedToGoals,
+			UnconvertedGoalTxns:  assigned.Unconverted,
 			AvailableBalance:     availableBalance,
 			ExpensesByCategory:   expensesByCategory,
 			TopExpenses:          topExpenses,
 			RecentTransactions:   recentTransactions,
+			OverdueDebtPayments:  overdueDebtPayments,
+			PendingDrafts:        pendingDrafts,
 		}
 
 		c.JSON(http.StatusOK, response)
@@ -0,0 +1,420 @@
+package debts
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/LorenzoCampos/bolsillo-claro/internal/database"
+	"github.com/LorenzoCampos/bolsillo-claro/internal/middleware"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/debts"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/logger"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/money"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/savings"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type CreateDebtRequest struct {
+	Counterparty      string   `json:"counterparty" binding:"required,max=100"`
+	Description       *string  `json:"description"`
+	Direction         string   `json:"direction" binding:"required,oneof=borrowed lent"`
+	Principal         float64  `json:"principal" binding:"required,gt=0"`
+	Currency          *string  `json:"currency" binding:"omitempty,oneof=ARS USD EUR"` // Defaults to the account currency
+	AmortizationType  string   `json:"amortization_type" binding:"required,oneof=french german interest_free"`
+	AnnualRate        *float64 `json:"annual_rate" binding:"omitempty,gte=0"` // TNA in %, required for french/german
+	InstallmentsCount int      `json:"installments_count" binding:"required,gte=1,lte=360"`
+	StartDate         *string  `json:"start_date"`                           // YYYY-MM-DD, defaults to today
+	FirstDueDate      *string  `json:"first_due_date"`                       // YYYY-MM-DD, defaults to one month after start_date
+	CategoryID        *string  `json:"category_id" binding:"omitempty,uuid"` // Category of the generated payments
+
+	// Record the disbursement as an income (borrowed) or expense (lent) dated start_date
+	RecordDisbursement bool     `json:"record_disbursement"`
+	ExchangeRate       *float64 `json:"exchange_rate" binding:"omitempty,gt=0"` // For the disbursement, if currency differs from the account
+}
+
+// NextDueResponse is the first installment not fully paid
+type NextDueResponse struct {
+	Number  int     `json:"number"`
+	DueDate string  `json:"due_date"`
+	Amount  float64 `json:"amount"` // What's left to pay of this installment
+	Overdue bool    `json:"overdue"`
+}
+
+type DebtResponse struct {
+	ID                  string           `json:"id"`
+	AccountID           string           `json:"account_id"`
+	Counterparty        string           `json:"counterparty"`
+	Description         *string          `json:"description,omitempty"`
+	Direction           string           `json:"direction"`
+	Principal           float64          `json:"principal"`
+	Currency            string           `json:"currency"`
+	AmortizationType    string           `json:"amortization_type"`
+	AnnualRate          float64          `json:"annual_rate"`
+	InstallmentsCount   int              `json:"installments_count"`
+	StartDate           string           `json:"start_date"`
+	FirstDueDate        string           `json:"first_due_date"`
+	CategoryID          *string          `json:"category_id,omitempty"`
+	Status              string           `json:"status"` // active | overdue | paid_off | written_off
+	TotalWithInterest   float64          `json:"total_with_interest"`
+	TotalPaid           float64          `json:"total_paid"`
+	PaidPrincipal       float64          `json:"paid_principal"`
+	PaidInterest        float64          `json:"paid_interest"`
+	OutstandingBalance  float64          `json:"outstanding_balance"` // Principal still owed
+	OutstandingTotal    float64          `json:"outstanding_total"`   // Pending installments (principal + interest)
+	OverdueAmount       float64          `json:"overdue_amount"`
+	OverdueInstallments int              `json:"overdue_installments"`
+	NextDue             *NextDueResponse `json:"next_due,omitempty"`
+	WrittenOffAt        *string          `json:"written_off_at,omitempty"`
+	CreatedAt           string           `json:"created_at"`
+	UpdatedAt           string           `json:"updated_at"`
+}
+
+type ScheduleEntryResponse struct {
+	Number             int     `json:"number"`
+	DueDate            string  `json:"due_date"`
+	Amount             float64 `json:"amount"`
+	Principal          float64 `json:"principal"`
+	Interest           float64 `json:"interest"`
+	RemainingPrincipal float64 `json:"remaining_principal"`
+	Paid               float64 `json:"paid"`
+	Status             string  `json:"status"` // paid | partial | pending | overdue
+}
+
+func newDebtResponse(d *debts.Debt, ledger *debts.Ledger, asOf time.Time) DebtResponse {
+	response := DebtResponse{
+		ID:                  d.ID,
+		AccountID:           d.AccountID,
+		Counterparty:        d.Counterparty,
+		Description:         d.Description,
+		Direction:           d.Direction,
+		Principal:           d.Principal,
+		Currency:            d.Currency,
+		AmortizationType:    d.AmortizationType,
+		AnnualRate:          d.AnnualRate,
+		InstallmentsCount:   d.InstallmentsCount,
+		StartDate:           d.StartDate.Format("2006-01-02"),
+		FirstDueDate:        d.FirstDueDate.Format("2006-01-02"),
+		CategoryID:          paymentCategoryID(d),
+		Status:              d.Status(ledger, asOf),
+		TotalWithInterest:   debts.Total(d.Schedule()),
+		TotalPaid:           ledger.TotalPaid,
+		PaidPrincipal:       ledger.PaidPrincipal,
+		PaidInterest:        ledger.PaidInterest,
+		OutstandingBalance:  ledger.OutstandingPrincipal,
+		OutstandingTotal:    ledger.OutstandingTotal,
+		OverdueAmount:       ledger.OverdueAmount,
+		OverdueInstallments: ledger.OverdueCount,
+		CreatedAt:           d.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:           d.UpdatedAt.Format(time.RFC3339),
+	}
+
+	if ledger.NextDue != nil {
+		response.NextDue = &NextDueResponse{
+			Number:  ledger.NextDue.Number,
+			DueDate: ledger.NextDue.DueDate.Format("2006-01-02"),
+			Amount:  money.Round(ledger.NextDue.Amount - ledger.NextDue.Paid),
+			Overdue: ledger.NextDue.Status == debts.EntryOverdue,
+		}
+	}
+	if d.WrittenOffAt != nil {
+		formatted := d.WrittenOffAt.Format("2006-01-02")
+		response.WrittenOffAt = &formatted
+	}
+
+	return response
+}
+
+func newScheduleResponse(ledger *debts.Ledger) []ScheduleEntryResponse {
+	schedule := make([]ScheduleEntryResponse, 0, len(ledger.Entries))
+	for _, e := range ledger.Entries {
+		schedule = append(schedule, ScheduleEntryResponse{
+			Number:             e.Number,
+			DueDate:            e.DueDate.Format("2006-01-02"),
+			Amount:             e.Amount,
+			Principal:          e.Principal,
+			Interest:           e.Interest,
+			RemainingPrincipal: e.RemainingPrincipal,
+			Paid:               e.Paid,
+			Status:             e.Status,
+		})
+	}
+	return schedule
+}
+
+// paymentCategoryID is the expense category (borrowed) or income category (lent) of the payments
+func paymentCategoryID(d *debts.Debt) *string {
+	if d.Direction == debts.DirectionLent {
+		return d.IncomeCategoryID
+	}
+	return d.ExpenseCategoryID
+}
+
+// categoryBelongsToAccount checks a system or account category in expense_categories / income_categories
+func categoryBelongsToAccount(ctx context.Context, q database.Querier, direction, categoryID string, accountID interface{}) (bool, error) {
+	table := "expense_categories"
+	if direction == debts.DirectionLent {
+		table = "income_categories"
+	}
+
+	var found bool
+	err := q.QueryRow(ctx,
+		`SELECT EXISTS(SELECT 1 FROM `+table+` WHERE id = $1 AND (account_id IS NULL OR account_id = $2))`,
+		categoryID, accountID,
+	).Scan(&found)
+	return found, err
+}
+
+// parsePastDate parses an optional YYYY-MM-DD date (default today) that can't be in the future
+func parsePastDate(value *string, field string) (time.Time, string) {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	if value == nil || *value == "" {
+		return today, ""
+	}
+
+	date, err := time.Parse("2006-01-02", *value)
+	if err != nil {
+		return time.Time{}, "invalid " + field + " format, use YYYY-MM-DD"
+	}
+	if date.After(today) {
+		return time.Time{}, field + " cannot be in the future"
+	}
+	return date, ""
+}
+
+// resolveExchangeRate follows the expenses multi-currency modes: same currency → 1,
+// amount_in_primary_currency or exchange_rate if given, otherwise the latest rate in exchange_rates
+// Returns a user-facing message when there's no way to convert
+func resolveExchangeRate(ctx context.Context, q database.Querier, currency, primaryCurrency string, amount float64, exchangeRate, amountInPrimary *float64, date time.Time) (float64, string, error) {
+	switch {
+	case currency == primaryCurrency:
+		return 1, "", nil
+	case amountInPrimary != nil:
+		return *amountInPrimary / amount, "", nil
+	case exchangeRate != nil:
+		return *exchangeRate, "", nil
+	}
+
+	rate, err := savings.LookupRate(ctx, q, currency, primaryCurrency, date.Format("2006-01-02"))
+	if err == savings.ErrRateNotFound {
+		return 0, "exchange rate not found, provide exchange_rate or amount_in_primary_currency", nil
+	}
+	if err != nil {
+		return 0, "", err
+	}
+	return rate, "", nil
+}
+
+// movement is an expense or income generated by a debt (payment or disbursement)
+type movement struct {
+	table          string // expenses | incomes
+	categoryID     *string
+	familyMemberID *string
+	description    string
+	amount         float64
+	currency       string
+	exchangeRate   float64
+	date           time.Time
+}
+
+// insertMovement creates the one-time expense/income linked to the debt
+func insertMovement(ctx context.Context, tx pgx.Tx, debtID string, accountID interface{}, m movement) (string, error) {
+	typeColumn := "expense_type"
+	if m.table == "incomes" {
+		typeColumn = "income_type"
+	}
+
+	var id string
+	err := tx.QueryRow(ctx, fmt.Sprintf(`
+		INSERT INTO %s (
+			account_id, family_member_id, category_id, description,
+			amount, currency, exchange_rate, amount_in_primary_currency,
+			%s, date, debt_id
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, 'one-time', $9, $10)
+		RETURNING id
+	`, m.table, typeColumn),
+		accountID, m.familyMemberID, m.categoryID, m.description,
+		m.amount, m.currency, m.exchangeRate, money.Round(m.amount*m.exchangeRate),
+		m.date, debtID,
+	).Scan(&id)
+	return id, err
+}
+
+// CreateDebt handles POST /api/debts
+// Creates the debt and returns it with its amortization schedule. With record_disbursement the
+// money received (borrowed) or handed over (lent) is also recorded as an income/expense
+func CreateDebt(db *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountID, exists := c.Get("account_id")
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "account_id not found in context"})
+			return
+		}
+
+		var req CreateDebtRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		annualRate := 0.0
+		if req.AnnualRate != nil {
+			annualRate = *req.AnnualRate
+		}
+		if req.AmortizationType == debts.AmortizationInterestFree && annualRate != 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "interest_free debts can't have annual_rate"})
+			return
+		}
+		if req.AmortizationType != debts.AmortizationInterestFree && req.AnnualRate == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "annual_rate is required for french and german amortization"})
+			return
+		}
+
+		startDate, msg := parsePastDate(req.StartDate, "start_date")
+		if msg != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+			return
+		}
+
+		firstDueDate := savings.AddPeriods(savings.FrequencyMonthly, startDate, startDate.Day(), 1)
+		if req.FirstDueDate != nil {
+			parsed, err := time.Parse("2006-01-02", *req.FirstDueDate)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid first_due_date format, use YYYY-MM-DD"})
+				return
+			}
+			if parsed.Before(startDate) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "first_due_date can't be before start_date"})
+				return
+			}
+			firstDueDate = parsed
+		}
+
+		ctx := c.Request.Context()
+
+		if req.CategoryID != nil {
+			found, err := categoryBelongsToAccount(ctx, db, req.Direction, *req.CategoryID, accountID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to validate category: " + err.Error()})
+				return
+			}
+			if !found {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "category not found for this account"})
+				return
+			}
+		}
+
+		var primaryCurrency string
+		if err := db.QueryRow(ctx, `SELECT currency FROM accounts WHERE id = $1`, accountID).Scan(&primaryCurrency); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get account currency"})
+			return
+		}
+
+		currency := primaryCurrency
+		if req.Currency != nil {
+			currency = *req.Currency
+		}
+
+		var disbursementRate float64
+		if req.RecordDisbursement {
+			rate, msg, err := resolveExchangeRate(ctx, db, currency, primaryCurrency, req.Principal, req.ExchangeRate, nil, startDate)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get exchange rate: " + err.Error()})
+				return
+			}
+			if msg != "" {
+				c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+				return
+			}
+			disbursementRate = rate
+		}
+
+		var expenseCategoryID, incomeCategoryID *string
+		if req.Direction == debts.DirectionLent {
+			incomeCategoryID = req.CategoryID
+		} else {
+			expenseCategoryID = req.CategoryID
+		}
+
+		tx, err := db.Begin(ctx)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to start transaction: " + err.Error()})
+			return
+		}
+		defer tx.Rollback(ctx)
+
+		var debtID string
+		err = tx.QueryRow(ctx, `
+			INSERT INTO debts (
+				account_id, counterparty, description, direction, principal, currency,
+				amortization_type, annual_rate, installments_count, start_date, first_due_date,
+				expense_category_id, income_category_id
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+			RETURNING id
+		`,
+			accountID, req.Counterparty, req.Description, req.Direction, req.Principal, currency,
+			req.AmortizationType, annualRate, req.InstallmentsCount, startDate, firstDueDate,
+			expenseCategoryID, incomeCategoryID,
+		).Scan(&debtID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create debt: " + err.Error()})
+			return
+		}
+
+		debt, err := debts.Find(ctx, tx, debtID, accountID, false)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch debt: " + err.Error()})
+			return
+		}
+
+		var disbursementID *string
+		if req.RecordDisbursement {
+			description := "Préstamo recibido de " + req.Counterparty
+			if req.Direction == debts.DirectionLent {
+				description = "Préstamo otorgado a " + req.Counterparty
+			}
+
+			id, err := insertMovement(ctx, tx, debtID, accountID, movement{
+				table:        debt.DisbursementTable(),
+				description:  description,
+				amount:       req.Principal,
+				currency:     currency,
+				exchangeRate: disbursementRate,
+				date:         startDate,
+			})
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to record disbursement: " + err.Error()})
+				return
+			}
+			disbursementID = &id
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to commit transaction: " + err.Error()})
+			return
+		}
+
+		today := time.Now().UTC().Truncate(24 * time.Hour)
+		ledger := debts.Allocate(debt.Schedule(), debt.Principal, nil, today)
+
+		userID, _ := middleware.GetUserID(c)
+		logger.Info("debt.created", "Deuda creada", map[string]interface{}{
+			"debt_id":           debtID,
+			"account_id":        accountID,
+			"user_id":           userID,
+			"direction":         req.Direction,
+			"principal":         req.Principal,
+			"currency":          currency,
+			"amortization_type": req.AmortizationType,
+			"ip":                c.ClientIP(),
+		})
+
+		c.JSON(http.StatusCreated, gin.H{
+			"debt":            newDebtResponse(debt, ledger, today),
+			"schedule":        newScheduleResponse(ledger),
+			"disbursement_id": disbursementID,
+		})
+	}
+}
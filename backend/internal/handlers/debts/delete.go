@@ -0,0 +1,79 @@
+package debts
+
+import (
+	"net/http"
+
+	"github.com/LorenzoCampos/bolsillo-claro/internal/middleware"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// DeleteDebt handles DELETE /api/debts/:id
+// The generated payments and disbursement stay as regular expenses/incomes (the money did move).
+// With ?delete_movements=true they're deleted too (for a debt loaded by mistake)
+func DeleteDebt(db *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountID, exists := c.Get("account_id")
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "account_id not found in context"})
+			return
+		}
+
+		debtID := c.Param("id")
+		deleteMovements := c.Query("delete_movements") == "true"
+		ctx := c.Request.Context()
+
+		tx, err := db.Begin(ctx)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to start transaction: " + err.Error()})
+			return
+		}
+		defer tx.Rollback(ctx)
+
+		var deletedMovements int64
+		if deleteMovements {
+			for _, table := range []string{"expenses", "incomes"} {
+				result, err := tx.Exec(ctx,
+					`DELETE FROM `+table+` WHERE debt_id = $1 AND account_id = $2`,
+					debtID, accountID,
+				)
+				if err != nil {
+					c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete movements: " + err.Error()})
+					return
+				}
+				deletedMovements += result.RowsAffected()
+			}
+		}
+
+		result, err := tx.Exec(ctx, `DELETE FROM debts WHERE id = $1 AND account_id = $2`, debtID, accountID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete debt: " + err.Error()})
+			return
+		}
+		if result.RowsAffected() == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "debt not found"})
+			return
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to commit transaction: " + err.Error()})
+			return
+		}
+
+		userID, _ := middleware.GetUserID(c)
+		logger.Info("debt.deleted", "Deuda eliminada", map[string]interface{}{
+			"debt_id":           debtID,
+			"account_id":        accountID,
+			"user_id":           userID,
+			"deleted_movements": deletedMovements,
+			"ip":                c.ClientIP(),
+		})
+
+		c.JSON(http.StatusOK, gin.H{
+			"message":           "debt deleted successfully",
+			"id":                debtID,
+			"deleted_movements": deletedMovements,
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package debts
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/debts"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PaymentResponse is a payment (expense) or collection (income) with its principal/interest split
+type PaymentResponse struct {
+	ID          string  `json:"id"` // expense_id (borrowed) or income_id (lent)
+	Type        string  `json:"type"`
+	Date        string  `json:"date"`
+	Amount      float64 `json:"amount"`
+	Description string  `json:"description"`
+	Principal   float64 `json:"principal"`
+	Interest    float64 `json:"interest"`
+}
+
+func newPaymentResponse(d *debts.Debt, p debts.Payment) PaymentResponse {
+	paymentType := "expense"
+	if d.Direction == debts.DirectionLent {
+		paymentType = "income"
+	}
+
+	return PaymentResponse{
+		ID:          p.ID,
+		Type:        paymentType,
+		Date:        p.Date.Format("2006-01-02"),
+		Amount:      p.Amount,
+		Description: p.Description,
+		Principal:   p.Principal,
+		Interest:    p.Interest,
+	}
+}
+
+// GetDebt handles GET /api/debts/:id
+// Returns the debt with its schedule (each installment with what was paid) and its payments
+func GetDebt(db *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountID, exists := c.Get("account_id")
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "account_id not found in context"})
+			return
+		}
+
+		debtID := c.Param("id")
+		ctx := c.Request.Context()
+		today := time.Now().UTC().Truncate(24 * time.Hour)
+
+		debt, err := debts.Find(ctx, db, debtID, accountID, false)
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "debt not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch debt: " + err.Error()})
+			return
+		}
+
+		ledger, err := debt.Ledger(ctx, db, today)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch payments: " + err.Error()})
+			return
+		}
+
+		payments := make([]PaymentResponse, 0, len(ledger.Payments))
+		for _, p := range ledger.Payments {
+			payments = append(payments, newPaymentResponse(debt, p))
+		}
+
+		var disbursementID *string
+		err = db.QueryRow(ctx,
+			`SELECT id FROM `+debt.DisbursementTable()+` WHERE debt_id = $1 ORDER BY created_at LIMIT 1`,
+			debtID,
+		).Scan(&disbursementID)
+		if err != nil && err != pgx.ErrNoRows {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch disbursement: " + err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"debt":            newDebtResponse(debt, ledger, today),
+			"schedule":        newScheduleResponse(ledger),
+			"payments":        payments,
+			"disbursement_id": disbursementID,
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package debts
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/debts"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/money"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// DebtTotals groups outstanding balances by direction and currency (not converted)
+type DebtTotals struct {
+	Direction          string  `json:"direction"`
+	Currency           string  `json:"currency"`
+	OutstandingBalance float64 `json:"outstanding_balance"`
+	OutstandingTotal   float64 `json:"outstanding_total"`
+	OverdueAmount      float64 `json:"overdue_amount"`
+	Count              int     `json:"count"`
+}
+
+// ListDebts handles GET /api/debts
+// Query params:
+//   - direction: borrowed | lent (optional)
+//   - status: active | overdue | paid_off | written_off | open (active + overdue) | all (default: all)
+func ListDebts(db *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountID, exists := c.Get("account_id")
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "account_id not found in context"})
+			return
+		}
+
+		direction := c.Query("direction")
+		if direction != "" && direction != debts.DirectionBorrowed && direction != debts.DirectionLent {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "direction must be borrowed or lent"})
+			return
+		}
+
+		status := c.DefaultQuery("status", "all")
+		switch status {
+		case debts.StatusActive, debts.StatusOverdue, debts.StatusPaidOff, debts.StatusWrittenOff, "open", "all":
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "status must be active, overdue, paid_off, written_off, open or all"})
+			return
+		}
+
+		ctx := c.Request.Context()
+		today := time.Now().UTC().Truncate(24 * time.Hour)
+
+		list, err := debts.ListByAccount(ctx, db, accountID, nil)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch debts: " + err.Error()})
+			return
+		}
+
+		result := []DebtResponse{}
+		totals := []DebtTotals{}
+		totalsIndex := map[string]int{}
+		for i := range list {
+			d := &list[i]
+			if direction != "" && d.Direction != direction {
+				continue
+			}
+
+			ledger, err := d.Ledger(ctx, db, today)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch payments: " + err.Error()})
+				return
+			}
+
+			debt := newDebtResponse(d, ledger, today)
+			open := debt.Status == debts.StatusActive || debt.Status == debts.StatusOverdue
+			if status != "all" && debt.Status != status && !(status == "open" && open) {
+				continue
+			}
+			result = append(result, debt)
+
+			// Las perdonadas y las pagadas no suman saldo pendiente
+			if !open {
+				continue
+			}
+			key := d.Direction + "|" + d.Currency
+			idx, ok := totalsIndex[key]
+			if !ok {
+				totals = append(totals, DebtTotals{Direction: d.Direction, Currency: d.Currency})
+				idx = len(totals) - 1
+				totalsIndex[key] = idx
+			}
+			totals[idx].OutstandingBalance = money.Round(totals[idx].OutstandingBalance + debt.OutstandingBalance)
+			totals[idx].OutstandingTotal = money.Round(totals[idx].OutstandingTotal + debt.OutstandingTotal)
+			totals[idx].OverdueAmount = money.Round(totals[idx].OverdueAmount + debt.OverdueAmount)
+			totals[idx].Count++
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"debts":  result,
+			"totals": totals,
+			"count":  len(result),
+		})
+	}
+}
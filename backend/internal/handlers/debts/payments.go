@@ -0,0 +1,257 @@
+package debts
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/LorenzoCampos/bolsillo-claro/internal/middleware"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/debts"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/logger"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/money"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type CreatePaymentRequest struct {
+	Amount         *float64 `json:"amount" binding:"omitempty,gt=0"` // Defaults to what's left of the next installment
+	Date           *string  `json:"date"`                            // YYYY-MM-DD, defaults to today
+	Description    *string  `json:"description"`
+	FamilyMemberID *string  `json:"family_member_id" binding:"omitempty,uuid"`
+
+	// Multi-currency (optional), same modes as expenses
+	ExchangeRate            *float64 `json:"exchange_rate" binding:"omitempty,gt=0"`
+	AmountInPrimaryCurrency *float64 `json:"amount_in_primary_currency" binding:"omitempty,gt=0"`
+}
+
+// CreatePayment handles POST /api/debts/:id/payments
+// Records the payment as an expense (borrowed) or the collection as an income (lent) linked to the
+// debt, and returns how it was allocated between interest and principal
+func CreatePayment(db *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountID, exists := c.Get("account_id")
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "account_id not found in context"})
+			return
+		}
+
+		debtID := c.Param("id")
+
+		// El body es opcional (sin body = pagar hoy lo que falta de la próxima cuota)
+		var req CreatePaymentRequest
+		if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		date, msg := parsePastDate(req.Date, "date")
+		if msg != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+			return
+		}
+
+		ctx := c.Request.Context()
+		today := time.Now().UTC().Truncate(24 * time.Hour)
+
+		if req.FamilyMemberID != nil {
+			var found bool
+			err := db.QueryRow(ctx,
+				`SELECT EXISTS(SELECT 1 FROM family_members WHERE id = $1 AND account_id = $2)`,
+				*req.FamilyMemberID, accountID,
+			).Scan(&found)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to validate family member: " + err.Error()})
+				return
+			}
+			if !found {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "family_member_id does not belong to this account"})
+				return
+			}
+		}
+
+		tx, err := db.Begin(ctx)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to start transaction: " + err.Error()})
+			return
+		}
+		defer tx.Rollback(ctx)
+
+		// FOR UPDATE: dos pagos simultáneos no pueden superar el saldo
+		debt, err := debts.Find(ctx, tx, debtID, accountID, true)
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "debt not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch debt: " + err.Error()})
+			return
+		}
+
+		if debt.WrittenOffAt != nil {
+			c.JSON(http.StatusConflict, gin.H{"error": "debt was written off"})
+			return
+		}
+		if date.Before(debt.StartDate) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "date can't be before the debt start_date"})
+			return
+		}
+
+		ledger, err := debt.Ledger(ctx, tx, today)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch payments: " + err.Error()})
+			return
+		}
+		if ledger.NextDue == nil {
+			c.JSON(http.StatusConflict, gin.H{"error": "debt is already paid off"})
+			return
+		}
+
+		amount := money.Round(ledger.NextDue.Amount - ledger.NextDue.Paid)
+		if req.Amount != nil {
+			amount = *req.Amount
+		}
+		if amount > ledger.OutstandingTotal {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":             debts.ErrPaymentExceedsBalance.Error(),
+				"outstanding_total": ledger.OutstandingTotal,
+			})
+			return
+		}
+
+		var primaryCurrency string
+		if err := tx.QueryRow(ctx, `SELECT currency FROM accounts WHERE id = $1`, accountID).Scan(&primaryCurrency); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get account currency"})
+			return
+		}
+
+		exchangeRate, msg, err := resolveExchangeRate(ctx, tx, debt.Currency, primaryCurrency, amount, req.ExchangeRate, req.AmountInPrimaryCurrency, date)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get exchange rate: " + err.Error()})
+			return
+		}
+		if msg != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+			return
+		}
+
+		description := fmt.Sprintf("Pago préstamo %s (cuota %d/%d)", debt.Counterparty, ledger.NextDue.Number, debt.InstallmentsCount)
+		if debt.Direction == debts.DirectionLent {
+			description = fmt.Sprintf("Cobro préstamo %s (cuota %d/%d)", debt.Counterparty, ledger.NextDue.Number, debt.InstallmentsCount)
+		}
+		if req.Description != nil && *req.Description != "" {
+			description = *req.Description
+		}
+
+		paymentID, err := insertMovement(ctx, tx, debtID, accountID, movement{
+			table:          debt.PaymentsTable(),
+			categoryID:     paymentCategoryID(debt),
+			familyMemberID: req.FamilyMemberID,
+			description:    description,
+			amount:         amount,
+			currency:       debt.Currency,
+			exchangeRate:   exchangeRate,
+			date:           date,
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to record payment: " + err.Error()})
+			return
+		}
+
+		ledger, err = debt.Ledger(ctx, tx, today)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch payments: " + err.Error()})
+			return
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to commit transaction: " + err.Error()})
+			return
+		}
+
+		var payment PaymentResponse
+		for _, p := range ledger.Payments {
+			if p.ID == paymentID {
+				payment = newPaymentResponse(debt, p)
+			}
+		}
+
+		userID, _ := middleware.GetUserID(c)
+		logger.Info("debt.payment.created", "Pago de deuda registrado", map[string]interface{}{
+			"debt_id":    debtID,
+			"payment_id": paymentID,
+			"account_id": accountID,
+			"user_id":    userID,
+			"amount":     amount,
+			"date":       date.Format("2006-01-02"),
+			"ip":         c.ClientIP(),
+		})
+
+		c.JSON(http.StatusCreated, gin.H{
+			"payment": payment,
+			"debt":    newDebtResponse(debt, ledger, today),
+		})
+	}
+}
+
+// DeletePayment handles DELETE /api/debts/:id/payments/:payment_id
+// Deletes the generated expense/income; the remaining payments are re-allocated
+func DeletePayment(db *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountID, exists := c.Get("account_id")
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "account_id not found in context"})
+			return
+		}
+
+		debtID := c.Param("id")
+		paymentID := c.Param("payment_id")
+		ctx := c.Request.Context()
+
+		debt, err := debts.Find(ctx, db, debtID, accountID, false)
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "debt not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch debt: " + err.Error()})
+			return
+		}
+
+		result, err := db.Exec(ctx,
+			`DELETE FROM `+debt.PaymentsTable()+` WHERE id = $1 AND debt_id = $2 AND account_id = $3`,
+			paymentID, debtID, accountID,
+		)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete payment: " + err.Error()})
+			return
+		}
+		if result.RowsAffected() == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "payment not found"})
+			return
+		}
+
+		today := time.Now().UTC().Truncate(24 * time.Hour)
+		ledger, err := debt.Ledger(ctx, db, today)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch payments: " + err.Error()})
+			return
+		}
+
+		userID, _ := middleware.GetUserID(c)
+		logger.Info("debt.payment.deleted", "Pago de deuda eliminado", map[string]interface{}{
+			"debt_id":    debtID,
+			"payment_id": paymentID,
+			"account_id": accountID,
+			"user_id":    userID,
+			"ip":         c.ClientIP(),
+		})
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "payment deleted successfully",
+			"id":      paymentID,
+			"debt":    newDebtResponse(debt, ledger, today),
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package debts
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/LorenzoCampos/bolsillo-claro/internal/middleware"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/debts"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type UpdateDebtRequest struct {
+	Counterparty *string `json:"counterparty" binding:"omitempty,max=100"`
+	Description  *string `json:"description"`
+	CategoryID   *string `json:"category_id"` // Empty string clears it
+	WrittenOff   *bool   `json:"written_off"` // true: forgiven / uncollectible, false: undo
+	WrittenOffAt *string `json:"written_off_at"`
+}
+
+// UpdateDebt handles PUT /api/debts/:id
+// The financial terms (principal, rate, amortization, installments) can't change: the payments
+// were allocated against that schedule. Delete and recreate the debt to change them
+func UpdateDebt(db *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountID, exists := c.Get("account_id")
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "account_id not found in context"})
+			return
+		}
+
+		debtID := c.Param("id")
+
+		var req UpdateDebtRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx := c.Request.Context()
+
+		current, err := debts.Find(ctx, db, debtID, accountID, false)
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "debt not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch debt: " + err.Error()})
+			return
+		}
+
+		expenseCategoryID, incomeCategoryID := current.ExpenseCategoryID, current.IncomeCategoryID
+		if req.CategoryID != nil {
+			var categoryID *string
+			if *req.CategoryID != "" {
+				found, err := categoryBelongsToAccount(ctx, db, current.Direction, *req.CategoryID, accountID)
+				if err != nil {
+					c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to validate category: " + err.Error()})
+					return
+				}
+				if !found {
+					c.JSON(http.StatusBadRequest, gin.H{"error": "category not found for this account"})
+					return
+				}
+				categoryID = req.CategoryID
+			}
+
+			if current.Direction == debts.DirectionLent {
+				incomeCategoryID = categoryID
+			} else {
+				expenseCategoryID = categoryID
+			}
+		}
+
+		writtenOffAt := current.WrittenOffAt
+		if req.WrittenOff != nil {
+			writtenOffAt = nil
+			if *req.WrittenOff {
+				date, msg := parsePastDate(req.WrittenOffAt, "written_off_at")
+				if msg != "" {
+					c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+					return
+				}
+				if date.Before(current.StartDate) {
+					c.JSON(http.StatusBadRequest, gin.H{"error": "written_off_at can't be before start_date"})
+					return
+				}
+				writtenOffAt = &date
+			}
+		}
+
+		_, err = db.Exec(ctx, `
+			UPDATE debts SET
+				counterparty = COALESCE($1, counterparty),
+				description = COALESCE($2, description),
+				expense_category_id = $3,
+				income_category_id = $4,
+				written_off_at = $5
+			WHERE id = $6 AND account_id = $7
+		`, req.Counterparty, req.Description, expenseCategoryID, incomeCategoryID, writtenOffAt, debtID, accountID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update debt: " + err.Error()})
+			return
+		}
+
+		debt, err := debts.Find(ctx, db, debtID, accountID, false)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch debt: " + err.Error()})
+			return
+		}
+
+		today := time.Now().UTC().Truncate(24 * time.Hour)
+		ledger, err := debt.Ledger(ctx, db, today)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch payments: " + err.Error()})
+			return
+		}
+
+		userID, _ := middleware.GetUserID(c)
+		logger.Info("debt.updated", "Deuda actualizada", map[string]interface{}{
+			"debt_id":    debtID,
+			"account_id": accountID,
+			"user_id":    userID,
+			"ip":         c.ClientIP(),
+		})
+
+		c.JSON(http.StatusOK, gin.H{"debt": newDebtResponse(debt, ledger, today)})
+	}
+}
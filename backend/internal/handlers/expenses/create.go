@@ -6,20 +6,27 @@ import (
 
 	"github.com/LorenzoCampos/bolsillo-claro/internal/middleware"
 	"github.com/LorenzoCampos/bolsillo-claro/pkg/logger"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/rules"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/statement"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/suggest"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/tags"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type CreateExpenseRequest struct {
-	FamilyMemberID *string `json:"family_member_id"` // Optional: for family accounts
-	CategoryID     *string `json:"category_id"`      // Optional: UUID of expense_categories
-	Description    string  `json:"description" binding:"required"`
-	Amount         float64 `json:"amount" binding:"required,gt=0"`
-	Currency       string  `json:"currency" binding:"required,oneof=ARS USD EUR"`
-	ExpenseType    *string `json:"expense_type" binding:"omitempty,oneof=one-time recurring"` // Optional: defaults to "one-time"
-	Date           string  `json:"date" binding:"required"`                                   // Format: YYYY-MM-DD
-	EndDate        *string `json:"end_date"`                                                  // Optional for recurring
+	FamilyMemberID  *string  `json:"family_member_id"` // Optional: for family accounts
+	CategoryID      *string  `json:"category_id"`      // Optional: UUID of expense_categories
+	Description     string   `json:"description" binding:"required"`
+	Amount          float64  `json:"amount" binding:"required,gt=0"`
+	Currency        string   `json:"currency" binding:"required,oneof=ARS USD EUR"`
+	ExpenseType     *string  `json:"expense_type" binding:"omitempty,oneof=one-time recurring"` // Optional: defaults to "one-time"
+	Date            string   `json:"date" binding:"required"`                                   // Format: YYYY-MM-DD
+	EndDate         *string  `json:"end_date"`                                                  // Optional for recurring
+	PaymentMethodID *string  `json:"payment_method_id"`                                         // Optional: UUID of payment_methods (credit cards get statement dates)
+	Tags            []string `json:"tags"`                                                      // Optional: free-form tags, created on the fly
+	AutoCategorize  bool     `json:"auto_categorize"`                                           // Optional: fill category_id from history when the suggestion is confident
 
 	// Multi-currency fields (Modo 3: Flexibilidad Total)
 	ExchangeRate            *float64 `json:"exchange_rate,omitempty"`              // Optional: tasa de conversión
@@ -27,20 +34,26 @@ type CreateExpenseRequest struct {
 }
 
 type ExpenseResponse struct {
-	ID                      string  `json:"id"`
-	AccountID               string  `json:"account_id"`
-	FamilyMemberID          *string `json:"family_member_id,omitempty"`
-	CategoryID              *string `json:"category_id,omitempty"`
-	CategoryName            *string `json:"category_name,omitempty"` // Incluimos el nombre para el frontend
-	Description             string  `json:"description"`
-	Amount                  float64 `json:"amount"`
-	Currency                string  `json:"currency"`
-	ExchangeRate            float64 `json:"exchange_rate"`              // Tasa de conversión (snapshot)
-	AmountInPrimaryCurrency float64 `json:"amount_in_primary_currency"` // Monto en moneda primaria
-	ExpenseType             string  `json:"expense_type"`
-	Date                    string  `json:"date"`
-	EndDate                 *string `json:"end_date,omitempty"`
-	CreatedAt               string  `json:"created_at"`
+	ID                      string   `json:"id"`
+	AccountID               string   `json:"account_id"`
+	FamilyMemberID          *string  `json:"family_member_id,omitempty"`
+	CategoryID              *string  `json:"category_id,omitempty"`
+	CategoryName            *string  `json:"category_name,omitempty"` // Incluimos el nombre para el frontend
+	Description             string   `json:"description"`
+	Amount                  float64  `json:"amount"`
+	Currency                string   `json:"currency"`
+	ExchangeRate            float64  `json:"exchange_rate"`              // Tasa de conversión (snapshot)
+	AmountInPrimaryCurrency float64  `json:"amount_in_primary_currency"` // Monto en moneda primaria
+	ExpenseType             string   `json:"expense_type"`
+	Date                    string   `json:"date"`
+	EndDate                 *string  `json:"end_date,omitempty"`
+	PaymentMethodID         *string  `json:"payment_method_id,omitempty"`
+	StatementClosingDate    *string  `json:"statement_closing_date,omitempty"` // Cierre del resumen (solo tarjeta de crédito)
+	StatementDueDate        *string  `json:"statement_due_date,omitempty"`     // Vencimiento: cuándo impacta en el flujo de caja
+	Tags                    []string `json:"tags"`
+	CategorySuggested       bool     `json:"category_suggested,omitempty"` // category_id came from the history (auto_categorize)
+	IsDraft                 bool     `json:"is_draft"`                     // Created from a scanned receipt, pending confirmation
+	CreatedAt               string   `json:"created_at"`
 }
 
 func CreateExpense(db *pgxpool.Pool) gin.HandlerFunc {
@@ -92,6 +105,44 @@ func CreateExpense(db *pgxpool.Pool) gin.HandlerFunc {
 			}
 		}
 
+		// Normalize tags ("Vacaciones 2026" → "vacaciones-2026")
+		tagNames, err := tags.NormalizeAll(req.Tags)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		// Auto-categorization rules only fill in what the user left empty (category, member) and add tags
+		movement := rules.Movement{
+			Description:     req.Description,
+			Amount:          req.Amount,
+			Currency:        req.Currency,
+			PaymentMethodID: req.PaymentMethodID,
+			CategoryID:      req.CategoryID,
+			FamilyMemberID:  req.FamilyMemberID,
+			Tags:            tagNames,
+		}
+		appliedRules, err := rules.Categorize(c.Request.Context(), db, accountID, rules.Expense, &movement)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to apply categorization rules: " + err.Error()})
+			return
+		}
+		req.CategoryID, req.FamilyMemberID, tagNames = movement.CategoryID, movement.FamilyMemberID, movement.Tags
+
+		// Still no category: optionally take the top suggestion from the account's history if it's confident
+		categorySuggested := false
+		if req.AutoCategorize && req.CategoryID == nil {
+			suggestions, err := suggest.Suggest(c.Request.Context(), db, accountID, req.Description)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to suggest category: " + err.Error()})
+				return
+			}
+			if len(suggestions) > 0 && suggestions[0].IsConfident() {
+				req.CategoryID = &suggestions[0].CategoryID
+				categorySuggested = true
+			}
+		}
+
 		// If family_member_id is provided, validate it belongs to this account
 		if req.FamilyMemberID != nil {
 			var exists bool
@@ -112,6 +163,20 @@ func CreateExpense(db *pgxpool.Pool) gin.HandlerFunc {
 			}
 		}
 
+		// If payment_method_id is provided, validate it and resolve the card statement it falls into
+		var statementClosingDate, statementDueDate *time.Time
+		if req.PaymentMethodID != nil {
+			statementClosingDate, statementDueDate, err = statement.ResolveDates(c.Request.Context(), db, *req.PaymentMethodID, accountID, expenseDate)
+			if err == statement.ErrPaymentMethodNotFound {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "payment_method_id does not belong to this account or is inactive"})
+				return
+			}
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to validate payment method"})
+				return
+			}
+		}
+
 		// ============================================================================
 		// MULTI-CURRENCY LOGIC - Modo 3: Flexibilidad Total
 		// ============================================================================
@@ -188,12 +253,14 @@ func CreateExpense(db *pgxpool.Pool) gin.HandlerFunc {
 			`INSERT INTO expenses (
 			account_id, family_member_id, category_id, description, 
 			amount, currency, exchange_rate, amount_in_primary_currency,
-			expense_type, date, end_date
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+			expense_type, date, end_date,
+			payment_method_id, statement_closing_date, statement_due_date
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
 		RETURNING id, created_at`,
 			accountID, req.FamilyMemberID, req.CategoryID, req.Description,
 			req.Amount, req.Currency, exchangeRate, amountInPrimaryCurrency,
 			expenseType, req.Date, req.EndDate,
+			req.PaymentMethodID, statementClosingDate, statementDueDate,
 		).Scan(&expenseID, &createdAt)
 
 		if err != nil {
@@ -201,6 +268,11 @@ func CreateExpense(db *pgxpool.Pool) gin.HandlerFunc {
 			return
 		}
 
+		if err := tags.Set(c.Request.Context(), db, tags.Expenses, expenseID.String(), accountID, tagNames); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save tags: " + err.Error()})
+			return
+		}
+
 		// Get category name if category_id was provided
 		var categoryName *string
 		if req.CategoryID != nil {
@@ -219,15 +291,17 @@ func CreateExpense(db *pgxpool.Pool) gin.HandlerFunc {
 
 		// Log de creación exitosa
 		logger.Info("expense.created", "Gasto creado", map[string]interface{}{
-			"expense_id":    expenseID.String(),
-			"account_id":    accountID,
-			"user_id":       userID,
-			"description":   req.Description,
-			"amount":        req.Amount,
-			"currency":      req.Currency,
-			"expense_type":  expenseType,
-			"exchange_rate": exchangeRate,
-			"ip":            c.ClientIP(),
+			"expense_id":         expenseID.String(),
+			"account_id":         accountID,
+			"user_id":            userID,
+			"description":        req.Description,
+			"amount":             req.Amount,
+			"currency":           req.Currency,
+			"expense_type":       expenseType,
+			"exchange_rate":      exchangeRate,
+			"applied_rules":      appliedRules,
+			"category_suggested": categorySuggested,
+			"ip":                 c.ClientIP(),
 		})
 
 		// Build response
@@ -245,9 +319,23 @@ func CreateExpense(db *pgxpool.Pool) gin.HandlerFunc {
 			ExpenseType:             expenseType,
 			Date:                    req.Date,
 			EndDate:                 req.EndDate,
+			PaymentMethodID:         req.PaymentMethodID,
+			StatementClosingDate:    formatDate(statementClosingDate),
+			StatementDueDate:        formatDate(statementDueDate),
+			Tags:                    tagNames,
+			CategorySuggested:       categorySuggested,
 			CreatedAt:               createdAt.Format(time.RFC3339),
 		}
 
 		c.JSON(http.StatusCreated, response)
 	}
 }
+
+// formatDate devuelve la fecha como YYYY-MM-DD, o nil si no hay fecha
+func formatDate(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	s := t.Format("2006-01-02")
+	return &s
+}
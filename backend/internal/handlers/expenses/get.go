@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/tags"
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -28,14 +29,15 @@ func GetExpense(db *pgxpool.Pool) gin.HandlerFunc {
 		// Query expense with category name
 		var expense ExpenseResponse
 		var familyMemberID, categoryID, categoryName *string
-		var date, endDate *time.Time
+		var date, endDate, statementClosingDate, statementDueDate *time.Time
 		var createdAt time.Time
 
 		query := `
 			SELECT e.id, e.account_id, e.family_member_id, e.category_id, 
 			       ec.name as category_name, e.description, 
 			       e.amount, e.currency, e.exchange_rate, e.amount_in_primary_currency,
-			       e.expense_type, e.date, e.end_date, e.created_at
+			       e.expense_type, e.date, e.end_date,
+			       e.payment_method_id, e.statement_closing_date, e.statement_due_date, e.is_draft, e.created_at
 			FROM expenses e
 			LEFT JOIN expense_categories ec ON e.category_id = ec.id
 			WHERE e.id = $1 AND e.account_id = $2
@@ -55,6 +57,10 @@ func GetExpense(db *pgxpool.Pool) gin.HandlerFunc {
 			&expense.ExpenseType,
 			&date,
 			&endDate,
+			&expense.PaymentMethodID,
+			&statementClosingDate,
+			&statementDueDate,
+			&expense.IsDraft,
 			&createdAt,
 		)
 
@@ -83,8 +89,18 @@ func GetExpense(db *pgxpool.Pool) gin.HandlerFunc {
 			expense.EndDate = &endDateStr
 		}
 
+		expense.StatementClosingDate = formatDate(statementClosingDate)
+		expense.StatementDueDate = formatDate(statementDueDate)
+
 		expense.CreatedAt = createdAt.Format(time.RFC3339)
 
+		expenseTags, err := tags.Load(c.Request.Context(), db, tags.Expenses, []string{expense.ID})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch tags: " + err.Error()})
+			return
+		}
+		expense.Tags = expenseTags[expense.ID]
+
 		c.JSON(http.StatusOK, expense)
 	}
 }
@@ -6,44 +6,50 @@ import (
 	"strings"
 	"time"
 
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/listing"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/tags"
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type ListExpensesQuery struct {
-	DateFrom       string `form:"date_from"`        // YYYY-MM-DD
-	DateTo         string `form:"date_to"`          // YYYY-MM-DD
-	ExpenseType    string `form:"expense_type"`     // one-time, recurring
-	CategoryID     string `form:"category_id"`      // Categoría exacta
-	FamilyMemberID string `form:"family_member_id"` // UUID
-	SortBy         string `form:"sort_by"`          // date, amount, created_at
-	Order          string `form:"order"`            // asc, desc
-	Page           int    `form:"page"`             // Página (default: 1)
-	Limit          int    `form:"limit"`            // Items por página (default: 20, max: 100)
+	listing.Params        // Filtros (comunes con GET /incomes y las vistas guardadas)
+	ExpenseType    string `form:"expense_type"` // one-time, recurring
+	SortBy         string `form:"sort_by"`      // date, amount, created_at, category, member
+	Order          string `form:"order"`        // asc, desc
+	Page           int    `form:"page"`         // Página (default: 1)
+	Limit          int    `form:"limit"`        // Items por página (default: 20, max: 100)
+	Cursor         string `form:"cursor"`       // next_cursor de la respuesta anterior (en lugar de page)
 }
 
 type ExpenseListItem struct {
-	ID                      string  `json:"id"`
-	FamilyMemberID          *string `json:"family_member_id,omitempty"`
-	CategoryID              *string `json:"category_id,omitempty"`
-	CategoryName            *string `json:"category_name,omitempty"`
-	Description             string  `json:"description"`
-	Amount                  float64 `json:"amount"`
-	Currency                string  `json:"currency"`
-	ExchangeRate            float64 `json:"exchange_rate"`
-	AmountInPrimaryCurrency float64 `json:"amount_in_primary_currency"`
-	ExpenseType             string  `json:"expense_type"`
-	Date                    string  `json:"date"`
-	EndDate                 *string `json:"end_date,omitempty"`
-	CreatedAt               string  `json:"created_at"`
+	ID                      string   `json:"id"`
+	FamilyMemberID          *string  `json:"family_member_id,omitempty"`
+	CategoryID              *string  `json:"category_id,omitempty"`
+	CategoryName            *string  `json:"category_name,omitempty"`
+	Description             string   `json:"description"`
+	Amount                  float64  `json:"amount"`
+	Currency                string   `json:"currency"`
+	ExchangeRate            float64  `json:"exchange_rate"`
+	AmountInPrimaryCurrency float64  `json:"amount_in_primary_currency"`
+	ExpenseType             string   `json:"expense_type"`
+	Date                    string   `json:"date"`
+	EndDate                 *string  `json:"end_date,omitempty"`
+	PaymentMethodID         *string  `json:"payment_method_id,omitempty"`
+	StatementClosingDate    *string  `json:"statement_closing_date,omitempty"`
+	StatementDueDate        *string  `json:"statement_due_date,omitempty"`
+	Tags                    []string `json:"tags"`
+	IsDraft                 bool     `json:"is_draft"`
+	CreatedAt               string   `json:"created_at"`
 }
 
 type ListExpensesResponse struct {
 	Expenses   []ExpenseListItem `json:"expenses"`
 	TotalCount int               `json:"total_count"`
-	Page       int               `json:"page"`
+	Page       int               `json:"page,omitempty"` // Omitido con cursor
 	Limit      int               `json:"limit"`
 	TotalPages int               `json:"total_pages"`
+	NextCursor *string           `json:"next_cursor"` // null en la última página
 }
 
 func ListExpenses(db *pgxpool.Pool) gin.HandlerFunc {
@@ -80,13 +86,8 @@ func ListExpenses(db *pgxpool.Pool) gin.HandlerFunc {
 		}
 
 		// Validate sort_by
-		validSortFields := map[string]bool{
-			"date":       true,
-			"amount":     true,
-			"created_at": true,
-		}
-		if !validSortFields[query.SortBy] {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid sort_by field"})
+		if !listing.ValidSort(query.SortBy) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": listing.ErrInvalidSortBy.Error()})
 			return
 		}
 
@@ -96,24 +97,22 @@ func ListExpenses(db *pgxpool.Pool) gin.HandlerFunc {
 			return
 		}
 
-		// Validate dates if provided
-		if query.DateFrom != "" {
-			if _, err := time.Parse("2006-01-02", query.DateFrom); err != nil {
-				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid date_from format, use YYYY-MM-DD"})
-				return
-			}
+		query.Params.Type = query.ExpenseType
+		filters, err := query.Params.Filters(listing.Expenses)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
 		}
-		if query.DateTo != "" {
-			if _, err := time.Parse("2006-01-02", query.DateTo); err != nil {
-				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid date_to format, use YYYY-MM-DD"})
+
+		// Keyset pagination: the cursor replaces page/OFFSET
+		var cursor *listing.Cursor
+		if query.Cursor != "" {
+			decoded, err := listing.DecodeCursor(query.Cursor, query.SortBy, query.Order)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 				return
 			}
-		}
-
-		// Validate expense_type if provided
-		if query.ExpenseType != "" && query.ExpenseType != "one-time" && query.ExpenseType != "recurring" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "expense_type must be one-time or recurring"})
-			return
+			cursor = &decoded
 		}
 
 		// Build WHERE clauses dynamically (with table alias e.)
@@ -121,42 +120,14 @@ func ListExpenses(db *pgxpool.Pool) gin.HandlerFunc {
 		args := []interface{}{accountID}
 		argIndex := 2
 
-		if query.DateFrom != "" {
-			whereClauses = append(whereClauses, "e.date >= $"+strconv.Itoa(argIndex))
-			args = append(args, query.DateFrom)
-			argIndex++
-		}
-
-		if query.DateTo != "" {
-			whereClauses = append(whereClauses, "e.date <= $"+strconv.Itoa(argIndex))
-			args = append(args, query.DateTo)
-			argIndex++
-		}
-
-		if query.ExpenseType != "" {
-			whereClauses = append(whereClauses, "e.expense_type = $"+strconv.Itoa(argIndex))
-			args = append(args, query.ExpenseType)
-			argIndex++
-		}
-
-		if query.CategoryID != "" {
-			whereClauses = append(whereClauses, "e.category_id = $"+strconv.Itoa(argIndex))
-			args = append(args, query.CategoryID)
-			argIndex++
-		}
-
-		if query.FamilyMemberID != "" {
-			whereClauses = append(whereClauses, "e.family_member_id = $"+strconv.Itoa(argIndex))
-			args = append(args, query.FamilyMemberID)
-			argIndex++
-		}
+		whereClauses, args, argIndex = filters.Where(listing.Expenses, whereClauses, args, argIndex)
 
 		whereClause := strings.Join(whereClauses, " AND ")
 
 		// Get total count
 		var totalCount int
 		countQuery := "SELECT COUNT(*) FROM expenses e WHERE " + whereClause
-		err := db.QueryRow(c.Request.Context(), countQuery, args...).Scan(&totalCount)
+		err = db.QueryRow(c.Request.Context(), countQuery, args...).Scan(&totalCount)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to count expenses"})
 			return
@@ -165,19 +136,29 @@ func ListExpenses(db *pgxpool.Pool) gin.HandlerFunc {
 		// Calculate pagination
 		totalPages := (totalCount + query.Limit - 1) / query.Limit
 		offset := (query.Page - 1) * query.Limit
+		if cursor != nil {
+			offset = 0
+			query.Page = 0
+			whereClauses, args, argIndex = listing.Expenses.After(*cursor, whereClauses, args, argIndex)
+			whereClause = strings.Join(whereClauses, " AND ")
+		}
 
 		// Build main query with JOIN to get category name
 		mainQuery := `
 			SELECT e.id, e.family_member_id, e.category_id, ec.name as category_name,
 			       e.description, e.amount, e.currency, e.exchange_rate, e.amount_in_primary_currency,
-			       e.expense_type, e.date, e.end_date, e.created_at
+			       e.expense_type, e.date, e.end_date,
+			       e.payment_method_id, e.statement_closing_date, e.statement_due_date, e.is_draft, e.created_at,
+			       ` + listing.Expenses.SortExpr(query.SortBy) + `::TEXT AS sort_value
 			FROM expenses e
 			LEFT JOIN expense_categories ec ON e.category_id = ec.id
+			LEFT JOIN family_members fm ON e.family_member_id = fm.id
 			WHERE ` + whereClause + `
-			ORDER BY e.` + query.SortBy + ` ` + strings.ToUpper(query.Order) + `
+			ORDER BY ` + listing.Expenses.OrderBy(query.SortBy, query.Order) + `
 			LIMIT $` + strconv.Itoa(argIndex) + ` OFFSET $` + strconv.Itoa(argIndex+1)
 
-		args = append(args, query.Limit, offset)
+		// One extra row tells if there is a next page
+		args = append(args, query.Limit+1, offset)
 
 		// Execute query
 		rows, err := db.Query(c.Request.Context(), mainQuery, args...)
@@ -189,11 +170,13 @@ func ListExpenses(db *pgxpool.Pool) gin.HandlerFunc {
 
 		// Parse results
 		expenses := []ExpenseListItem{}
+		sortValues := []string{}
 		for rows.Next() {
 			var expense ExpenseListItem
 			var familyMemberID, categoryID, categoryName *string
-			var date, endDate *time.Time
+			var date, endDate, statementClosingDate, statementDueDate *time.Time
 			var createdAt time.Time
+			var sortValue string
 
 			err := rows.Scan(
 				&expense.ID,
@@ -208,7 +191,12 @@ func ListExpenses(db *pgxpool.Pool) gin.HandlerFunc {
 				&expense.ExpenseType,
 				&date,
 				&endDate,
+				&expense.PaymentMethodID,
+				&statementClosingDate,
+				&statementDueDate,
+				&expense.IsDraft,
 				&createdAt,
+				&sortValue,
 			)
 			if err != nil {
 				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to parse expense: " + err.Error()})
@@ -229,9 +217,13 @@ func ListExpenses(db *pgxpool.Pool) gin.HandlerFunc {
 				expense.EndDate = &endDateStr
 			}
 
+			expense.StatementClosingDate = formatDate(statementClosingDate)
+			expense.StatementDueDate = formatDate(statementDueDate)
+
 			expense.CreatedAt = createdAt.Format(time.RFC3339)
 
 			expenses = append(expenses, expense)
+			sortValues = append(sortValues, sortValue)
 		}
 
 		// Check for errors during iteration
@@ -240,6 +232,28 @@ func ListExpenses(db *pgxpool.Pool) gin.HandlerFunc {
 			return
 		}
 
+		var nextCursor *string
+		if len(expenses) > query.Limit {
+			expenses = expenses[:query.Limit]
+			last := listing.Cursor{SortBy: query.SortBy, Order: query.Order, Value: sortValues[query.Limit-1], ID: expenses[query.Limit-1].ID}
+			encoded := last.Encode()
+			nextCursor = &encoded
+		}
+
+		// Tags of the page in a single query
+		expenseIDs := make([]string, len(expenses))
+		for i, expense := range expenses {
+			expenseIDs[i] = expense.ID
+		}
+		expenseTags, err := tags.Load(c.Request.Context(), db, tags.Expenses, expenseIDs)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch tags: " + err.Error()})
+			return
+		}
+		for i := range expenses {
+			expenses[i].Tags = expenseTags[expenses[i].ID]
+		}
+
 		// Build response
 		response := ListExpensesResponse{
 			Expenses:   expenses,
@@ -247,6 +261,7 @@ func ListExpenses(db *pgxpool.Pool) gin.HandlerFunc {
 			Page:       query.Page,
 			Limit:      query.Limit,
 			TotalPages: totalPages,
+			NextCursor: nextCursor,
 		}
 
 		c.JSON(http.StatusOK, response)
@@ -0,0 +1,93 @@
+package expenses
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/categories"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/suggest"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// CategorySuggestion is a category ranked from the account's own expense history
+type CategorySuggestion struct {
+	CategoryID     string  `json:"category_id"`
+	CategoryName   string  `json:"category_name"`
+	CategoryIcon   *string `json:"category_icon,omitempty"`
+	CategoryColor  *string `json:"category_color,omitempty"`
+	Score          float64 `json:"score"`           // Similarity × recency weight, summed over similar expenses
+	Confidence     float64 `json:"confidence"`      // Share of the total score (0-1)
+	Matches        int     `json:"matches"`         // Similar past expenses with this category
+	HighConfidence bool    `json:"high_confidence"` // Enough to auto-fill (see auto_categorize in POST /expenses)
+}
+
+// SuggestCategory handles GET /api/expenses/suggest-category?description=&limit=
+// Ranks categories by how similar past descriptions are, weighting recent expenses more
+func SuggestCategory(db *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountID, exists := c.Get("account_id")
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "account_id not found in context"})
+			return
+		}
+
+		description := c.Query("description")
+		if strings.TrimSpace(description) == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "description is required"})
+			return
+		}
+
+		limit := 5
+		if limitStr := c.Query("limit"); limitStr != "" {
+			parsed, err := strconv.Atoi(limitStr)
+			if err != nil || parsed < 1 || parsed > 10 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 10"})
+				return
+			}
+			limit = parsed
+		}
+
+		ranked, err := suggest.Suggest(c.Request.Context(), db, accountID, description)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load expense history: " + err.Error()})
+			return
+		}
+
+		tree, err := categories.LoadTree(c.Request.Context(), db, categories.ExpenseTable, accountID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load categories: " + err.Error()})
+			return
+		}
+
+		suggestions := []CategorySuggestion{}
+		for _, s := range ranked {
+			if len(suggestions) == limit {
+				break
+			}
+			node, ok := tree[s.CategoryID]
+			if !ok {
+				continue
+			}
+			suggestions = append(suggestions, CategorySuggestion{
+				CategoryID:     s.CategoryID,
+				CategoryName:   node.Name,
+				CategoryIcon:   node.Icon,
+				CategoryColor:  node.Color,
+				Score:          math.Round(s.Score*1000) / 1000,
+				Confidence:     math.Round(s.Confidence*1000) / 1000,
+				Matches:        s.Matches,
+				HighConfidence: s.IsConfident(),
+			})
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"description": description,
+			"tokens":      suggest.Tokenize(description),
+			"suggestions": suggestions,
+			"count":       len(suggestions),
+		})
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/statement"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/tags"
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -19,6 +21,12 @@ type UpdateExpenseRequest struct {
 	Date           *string  `json:"date"`     // Format: YYYY-MM-DD
 	EndDate        *string  `json:"end_date"` // Format: YYYY-MM-DD
 
+	// Payment method (optional): empty string removes it
+	PaymentMethodID *string `json:"payment_method_id"`
+
+	// Tags (optional): replaces all the tags, [] removes them
+	Tags *[]string `json:"tags"`
+
 	// Multi-currency fields (Modo 3)
 	ExchangeRate            *float64 `json:"exchange_rate,omitempty"`
 	AmountInPrimaryCurrency *float64 `json:"amount_in_primary_currency,omitempty"`
@@ -50,11 +58,13 @@ func UpdateExpense(db *pgxpool.Pool) gin.HandlerFunc {
 		var existingExpenseType, existingCurrency string
 		var existingAmount, existingExchangeRate, existingAmountInPrimaryCurrency float64
 		var existingDate string
-		checkQuery := `SELECT expense_type, amount, currency, exchange_rate, amount_in_primary_currency, date::TEXT 
+		var existingPaymentMethodID *string
+		checkQuery := `SELECT expense_type, amount, currency, exchange_rate, amount_in_primary_currency, date::TEXT, payment_method_id
 	               FROM expenses WHERE id = $1 AND account_id = $2`
 		err := db.QueryRow(c.Request.Context(), checkQuery, expenseID, accountID).Scan(
 			&existingExpenseType, &existingAmount, &existingCurrency,
-			&existingExchangeRate, &existingAmountInPrimaryCurrency, &existingDate)
+			&existingExchangeRate, &existingAmountInPrimaryCurrency, &existingDate,
+			&existingPaymentMethodID)
 
 		if err == pgx.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{"error": "expense not found or does not belong to this account"})
@@ -125,6 +135,16 @@ func UpdateExpense(db *pgxpool.Pool) gin.HandlerFunc {
 			}
 		}
 
+		// Normalize tags if provided
+		var tagNames []string
+		if req.Tags != nil {
+			tagNames, err = tags.NormalizeAll(*req.Tags)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+		}
+
 		// If family_member_id is provided, validate it belongs to this account
 		if req.FamilyMemberID != nil && *req.FamilyMemberID != "" {
 			var memberExists bool
@@ -145,6 +165,37 @@ func UpdateExpense(db *pgxpool.Pool) gin.HandlerFunc {
 			}
 		}
 
+		// Statement dates depend on the payment method and the date: recalculate if either changed
+		statementChanged := req.PaymentMethodID != nil || (req.Date != nil && existingPaymentMethodID != nil)
+		var finalPaymentMethodID *string
+		var statementClosingDate, statementDueDate *time.Time
+		if statementChanged {
+			finalPaymentMethodID = existingPaymentMethodID
+			if req.PaymentMethodID != nil {
+				finalPaymentMethodID = req.PaymentMethodID
+				if *req.PaymentMethodID == "" {
+					finalPaymentMethodID = nil
+				}
+			}
+
+			if finalPaymentMethodID != nil {
+				statementDate, _ := time.Parse("2006-01-02", existingDate)
+				if req.Date != nil {
+					statementDate = expenseDate
+				}
+
+				statementClosingDate, statementDueDate, err = statement.ResolveDates(c.Request.Context(), db, *finalPaymentMethodID, accountID, statementDate)
+				if err == statement.ErrPaymentMethodNotFound {
+					c.JSON(http.StatusBadRequest, gin.H{"error": "payment_method_id does not belong to this account or is inactive"})
+					return
+				}
+				if err != nil {
+					c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to validate payment method"})
+					return
+				}
+			}
+		}
+
 		// ============================================================================
 		// MULTI-CURRENCY RECALCULATION - Modo 3
 		// ============================================================================
@@ -253,11 +304,15 @@ func UpdateExpense(db *pgxpool.Pool) gin.HandlerFunc {
 			END,
 			exchange_rate = COALESCE($11, exchange_rate),
 			amount_in_primary_currency = COALESCE($12, amount_in_primary_currency),
+			payment_method_id = CASE WHEN $13 THEN $14::uuid ELSE payment_method_id END,
+			statement_closing_date = CASE WHEN $13 THEN $15::date ELSE statement_closing_date END,
+			statement_due_date = CASE WHEN $13 THEN $16::date ELSE statement_due_date END,
 			updated_at = CURRENT_TIMESTAMP
 		WHERE id = $9 AND account_id = $10
 		RETURNING id, account_id, family_member_id, category_id, description, 
 		          amount, currency, exchange_rate, amount_in_primary_currency,
-		          expense_type, date, end_date, created_at
+		          expense_type, date, end_date,
+		          payment_method_id, statement_closing_date, statement_due_date, is_draft, created_at
 	`
 
 		// Handle end_date special case: empty string means clear it
@@ -273,7 +328,7 @@ func UpdateExpense(db *pgxpool.Pool) gin.HandlerFunc {
 
 		var expense ExpenseResponse
 		var familyMemberID, categoryID *string
-		var date, endDate *time.Time
+		var date, endDate, updatedClosingDate, updatedDueDate *time.Time
 		var createdAt time.Time
 
 		err = db.QueryRow(c.Request.Context(), updateQuery,
@@ -281,6 +336,7 @@ func UpdateExpense(db *pgxpool.Pool) gin.HandlerFunc {
 			req.Amount, req.Currency, req.ExpenseType, req.Date,
 			endDateParam, expenseID, accountID,
 			finalExchangeRate, finalAmountInPrimaryCurrency,
+			statementChanged, finalPaymentMethodID, statementClosingDate, statementDueDate,
 		).Scan(
 			&expense.ID,
 			&expense.AccountID,
@@ -294,6 +350,10 @@ func UpdateExpense(db *pgxpool.Pool) gin.HandlerFunc {
 			&expense.ExpenseType,
 			&date,
 			&endDate,
+			&expense.PaymentMethodID,
+			&updatedClosingDate,
+			&updatedDueDate,
+			&expense.IsDraft,
 			&createdAt,
 		)
 
@@ -302,6 +362,13 @@ func UpdateExpense(db *pgxpool.Pool) gin.HandlerFunc {
 			return
 		}
 
+		if req.Tags != nil {
+			if err := tags.Set(c.Request.Context(), db, tags.Expenses, expense.ID, accountID, tagNames); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save tags: " + err.Error()})
+				return
+			}
+		}
+
 		// Get category name if category_id exists
 		var categoryName *string
 		if categoryID != nil {
@@ -330,8 +397,18 @@ func UpdateExpense(db *pgxpool.Pool) gin.HandlerFunc {
 			expense.EndDate = &endDateStr
 		}
 
+		expense.StatementClosingDate = formatDate(updatedClosingDate)
+		expense.StatementDueDate = formatDate(updatedDueDate)
+
 		expense.CreatedAt = createdAt.Format(time.RFC3339)
 
+		expenseTags, err := tags.Load(c.Request.Context(), db, tags.Expenses, []string{expense.ID})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch tags: " + err.Error()})
+			return
+		}
+		expense.Tags = expenseTags[expense.ID]
+
 		c.JSON(http.StatusOK, expense)
 	}
 }
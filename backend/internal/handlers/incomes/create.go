@@ -6,20 +6,24 @@ import (
 
 	"github.com/LorenzoCampos/bolsillo-claro/internal/middleware"
 	"github.com/LorenzoCampos/bolsillo-claro/pkg/logger"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/rules"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/tags"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type CreateIncomeRequest struct {
-	FamilyMemberID *string `json:"family_member_id"` // Optional: for family accounts
-	CategoryID     *string `json:"category_id"`      // Optional
-	Description    string  `json:"description" binding:"required"`
-	Amount         float64 `json:"amount" binding:"required,gt=0"`
-	Currency       string  `json:"currency" binding:"required,oneof=ARS USD EUR"`
-	IncomeType     *string `json:"income_type" binding:"omitempty,oneof=one-time recurring"` // Optional: defaults to "one-time"
-	Date           string  `json:"date" binding:"required"`                                  // Format: YYYY-MM-DD
-	EndDate        *string `json:"end_date"`                                                 // Optional: for recurring
+	FamilyMemberID  *string  `json:"family_member_id"` // Optional: for family accounts
+	CategoryID      *string  `json:"category_id"`      // Optional
+	Description     string   `json:"description" binding:"required"`
+	Amount          float64  `json:"amount" binding:"required,gt=0"`
+	Currency        string   `json:"currency" binding:"required,oneof=ARS USD EUR"`
+	IncomeType      *string  `json:"income_type" binding:"omitempty,oneof=one-time recurring"` // Optional: defaults to "one-time"
+	Date            string   `json:"date" binding:"required"`                                  // Format: YYYY-MM-DD
+	EndDate         *string  `json:"end_date"`                                                 // Optional: for recurring
+	PaymentMethodID *string  `json:"payment_method_id"`                                        // Optional: wallet/payment method where the money came in
+	Tags            []string `json:"tags"`                                                     // Optional: free-form tags, created on the fly
 
 	// Multi-currency fields (Modo 3: Flexibilidad Total)
 	ExchangeRate            *float64 `json:"exchange_rate,omitempty"`              // Optional: tasa de conversión
@@ -27,20 +31,22 @@ type CreateIncomeRequest struct {
 }
 
 type IncomeResponse struct {
-	ID                      string  `json:"id"`
-	AccountID               string  `json:"account_id"`
-	FamilyMemberID          *string `json:"family_member_id,omitempty"`
-	CategoryID              *string `json:"category_id,omitempty"`
-	CategoryName            *string `json:"category_name,omitempty"`
-	Description             string  `json:"description"`
-	Amount                  float64 `json:"amount"`
-	Currency                string  `json:"currency"`
-	ExchangeRate            float64 `json:"exchange_rate"`              // Tasa de conversión (snapshot)
-	AmountInPrimaryCurrency float64 `json:"amount_in_primary_currency"` // Monto en moneda primaria
-	IncomeType              string  `json:"income_type"`
-	Date                    string  `json:"date"`
-	EndDate                 *string `json:"end_date,omitempty"`
-	CreatedAt               string  `json:"created_at"`
+	ID                      string   `json:"id"`
+	AccountID               string   `json:"account_id"`
+	FamilyMemberID          *string  `json:"family_member_id,omitempty"`
+	CategoryID              *string  `json:"category_id,omitempty"`
+	CategoryName            *string  `json:"category_name,omitempty"`
+	Description             string   `json:"description"`
+	Amount                  float64  `json:"amount"`
+	Currency                string   `json:"currency"`
+	ExchangeRate            float64  `json:"exchange_rate"`              // Tasa de conversión (snapshot)
+	AmountInPrimaryCurrency float64  `json:"amount_in_primary_currency"` // Monto en moneda primaria
+	IncomeType              string   `json:"income_type"`
+	Date                    string   `json:"date"`
+	EndDate                 *string  `json:"end_date,omitempty"`
+	PaymentMethodID         *string  `json:"payment_method_id,omitempty"`
+	Tags                    []string `json:"tags"`
+	CreatedAt               string   `json:"created_at"`
 }
 
 func CreateIncome(db *pgxpool.Pool) gin.HandlerFunc {
@@ -92,6 +98,30 @@ func CreateIncome(db *pgxpool.Pool) gin.HandlerFunc {
 			}
 		}
 
+		// Normalize tags ("Vacaciones 2026" → "vacaciones-2026")
+		tagNames, err := tags.NormalizeAll(req.Tags)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		// Auto-categorization rules only fill in what the user left empty (category, member) and add tags
+		movement := rules.Movement{
+			Description:     req.Description,
+			Amount:          req.Amount,
+			Currency:        req.Currency,
+			PaymentMethodID: req.PaymentMethodID,
+			CategoryID:      req.CategoryID,
+			FamilyMemberID:  req.FamilyMemberID,
+			Tags:            tagNames,
+		}
+		appliedRules, err := rules.Categorize(c.Request.Context(), db, accountID, rules.Income, &movement)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to apply categorization rules: " + err.Error()})
+			return
+		}
+		req.CategoryID, req.FamilyMemberID, tagNames = movement.CategoryID, movement.FamilyMemberID, movement.Tags
+
 		// If family_member_id is provided, validate it belongs to this account
 		if req.FamilyMemberID != nil {
 			var exists bool
@@ -111,6 +141,25 @@ func CreateIncome(db *pgxpool.Pool) gin.HandlerFunc {
 				return
 			}
 		}
+		// If payment_method_id is provided, validate it belongs to this account and is active
+		if req.PaymentMethodID != nil {
+			var paymentMethodExists bool
+			err := db.QueryRow(c.Request.Context(),
+				`SELECT EXISTS(
+				SELECT 1 FROM payment_methods
+				WHERE id = $1 AND account_id = $2 AND is_active = true
+			)`,
+				req.PaymentMethodID, accountID,
+			).Scan(&paymentMethodExists)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to validate payment method"})
+				return
+			}
+			if !paymentMethodExists {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "payment_method_id does not belong to this account or is inactive"})
+				return
+			}
+		}
 
 		// ============================================================================
 		// MULTI-CURRENCY LOGIC - Modo 3: Flexibilidad Total
@@ -188,12 +237,12 @@ func CreateIncome(db *pgxpool.Pool) gin.HandlerFunc {
 			`INSERT INTO incomes (
 			account_id, family_member_id, category_id, description, 
 			amount, currency, exchange_rate, amount_in_primary_currency,
-			income_type, date, end_date
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+			income_type, date, end_date, payment_method_id
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 		RETURNING id, created_at`,
 			accountID, req.FamilyMemberID, req.CategoryID, req.Description,
 			req.Amount, req.Currency, exchangeRate, amountInPrimaryCurrency,
-			incomeType, req.Date, req.EndDate,
+			incomeType, req.Date, req.EndDate, req.PaymentMethodID,
 		).Scan(&incomeID, &createdAt)
 
 		if err != nil {
@@ -201,6 +250,11 @@ func CreateIncome(db *pgxpool.Pool) gin.HandlerFunc {
 			return
 		}
 
+		if err := tags.Set(c.Request.Context(), db, tags.Incomes, incomeID.String(), accountID, tagNames); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save tags: " + err.Error()})
+			return
+		}
+
 		// Get category name if category_id was provided
 		var categoryName *string
 		if req.CategoryID != nil {
@@ -219,14 +273,15 @@ func CreateIncome(db *pgxpool.Pool) gin.HandlerFunc {
 
 		// Log de creación exitosa
 		logger.Info("income.created", "Ingreso creado", map[string]interface{}{
-			"income_id":   incomeID.String(),
-			"account_id":  accountID,
-			"user_id":     userID,
-			"description": req.Description,
-			"amount":      req.Amount,
-			"currency":    req.Currency,
-			"income_type": incomeType,
-			"ip":          c.ClientIP(),
+			"income_id":     incomeID.String(),
+			"account_id":    accountID,
+			"user_id":       userID,
+			"description":   req.Description,
+			"amount":        req.Amount,
+			"currency":      req.Currency,
+			"income_type":   incomeType,
+			"applied_rules": appliedRules,
+			"ip":            c.ClientIP(),
 		})
 
 		// Build response
@@ -244,6 +299,8 @@ func CreateIncome(db *pgxpool.Pool) gin.HandlerFunc {
 			IncomeType:              incomeType,
 			Date:                    req.Date,
 			EndDate:                 req.EndDate,
+			PaymentMethodID:         req.PaymentMethodID,
+			Tags:                    tagNames,
 			CreatedAt:               createdAt.Format(time.RFC3339),
 		}
 
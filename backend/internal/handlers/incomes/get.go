@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/tags"
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -34,7 +35,7 @@ func GetIncome(db *pgxpool.Pool) gin.HandlerFunc {
 		query := `
 			SELECT i.id, i.account_id, i.family_member_id, i.category_id, ic.name as category_name, i.description, 
 			       i.amount, i.currency, i.exchange_rate, i.amount_in_primary_currency,
-			       i.income_type, i.date, i.end_date, i.created_at
+			       i.income_type, i.date, i.end_date, i.payment_method_id, i.created_at
 			FROM incomes i
 			LEFT JOIN income_categories ic ON i.category_id = ic.id
 			WHERE i.id = $1 AND i.account_id = $2
@@ -54,6 +55,7 @@ func GetIncome(db *pgxpool.Pool) gin.HandlerFunc {
 			&income.IncomeType,
 			&date,
 			&endDate,
+			&income.PaymentMethodID,
 			&createdAt,
 		)
 
@@ -84,6 +86,13 @@ func GetIncome(db *pgxpool.Pool) gin.HandlerFunc {
 
 		income.CreatedAt = createdAt.Format(time.RFC3339)
 
+		incomeTags, err := tags.Load(c.Request.Context(), db, tags.Incomes, []string{income.ID})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch tags: " + err.Error()})
+			return
+		}
+		income.Tags = incomeTags[income.ID]
+
 		c.JSON(http.StatusOK, income)
 	}
 }
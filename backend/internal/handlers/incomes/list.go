@@ -6,44 +6,47 @@ import (
 	"strings"
 	"time"
 
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/listing"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/tags"
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type ListIncomesQuery struct {
-	DateFrom       string `form:"date_from"`        // YYYY-MM-DD
-	DateTo         string `form:"date_to"`          // YYYY-MM-DD
-	IncomeType     string `form:"income_type"`      // one-time, recurring
-	CategoryID     string `form:"category_id"`      // Categoría exacta
-	FamilyMemberID string `form:"family_member_id"` // UUID
-	SortBy         string `form:"sort_by"`          // date, amount, created_at
-	Order          string `form:"order"`            // asc, desc
-	Page           int    `form:"page"`             // Página (default: 1)
-	Limit          int    `form:"limit"`            // Items por página (default: 20, max: 100)
+	listing.Params        // Filtros (comunes con GET /expenses y las vistas guardadas)
+	IncomeType     string `form:"income_type"` // one-time, recurring
+	SortBy         string `form:"sort_by"`     // date, amount, created_at, category, member
+	Order          string `form:"order"`       // asc, desc
+	Page           int    `form:"page"`        // Página (default: 1)
+	Limit          int    `form:"limit"`       // Items por página (default: 20, max: 100)
+	Cursor         string `form:"cursor"`      // next_cursor de la respuesta anterior (en lugar de page)
 }
 
 type IncomeListItem struct {
-	ID                      string  `json:"id"`
-	FamilyMemberID          *string `json:"family_member_id,omitempty"`
-	CategoryID              *string `json:"category_id,omitempty"`
-	CategoryName            *string `json:"category_name,omitempty"`
-	Description             string  `json:"description"`
-	Amount                  float64 `json:"amount"`
-	Currency                string  `json:"currency"`
-	ExchangeRate            float64 `json:"exchange_rate"`
-	AmountInPrimaryCurrency float64 `json:"amount_in_primary_currency"`
-	IncomeType              string  `json:"income_type"`
-	Date                    string  `json:"date"`
-	EndDate                 *string `json:"end_date,omitempty"`
-	CreatedAt               string  `json:"created_at"`
+	ID                      string   `json:"id"`
+	FamilyMemberID          *string  `json:"family_member_id,omitempty"`
+	CategoryID              *string  `json:"category_id,omitempty"`
+	CategoryName            *string  `json:"category_name,omitempty"`
+	Description             string   `json:"description"`
+	Amount                  float64  `json:"amount"`
+	Currency                string   `json:"currency"`
+	ExchangeRate            float64  `json:"exchange_rate"`
+	AmountInPrimaryCurrency float64  `json:"amount_in_primary_currency"`
+	IncomeType              string   `json:"income_type"`
+	Date                    string   `json:"date"`
+	EndDate                 *string  `json:"end_date,omitempty"`
+	PaymentMethodID         *string  `json:"payment_method_id,omitempty"`
+	Tags                    []string `json:"tags"`
+	CreatedAt               string   `json:"created_at"`
 }
 
 type ListIncomesResponse struct {
 	Incomes    []IncomeListItem `json:"incomes"`
 	TotalCount int              `json:"total_count"`
-	Page       int              `json:"page"`
+	Page       int              `json:"page,omitempty"` // Omitido con cursor
 	Limit      int              `json:"limit"`
 	TotalPages int              `json:"total_pages"`
+	NextCursor *string          `json:"next_cursor"` // null en la última página
 }
 
 func ListIncomes(db *pgxpool.Pool) gin.HandlerFunc {
@@ -80,13 +83,8 @@ func ListIncomes(db *pgxpool.Pool) gin.HandlerFunc {
 		}
 
 		// Validate sort_by
-		validSortFields := map[string]bool{
-			"date":       true,
-			"amount":     true,
-			"created_at": true,
-		}
-		if !validSortFields[query.SortBy] {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid sort_by field"})
+		if !listing.ValidSort(query.SortBy) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": listing.ErrInvalidSortBy.Error()})
 			return
 		}
 
@@ -96,24 +94,22 @@ func ListIncomes(db *pgxpool.Pool) gin.HandlerFunc {
 			return
 		}
 
-		// Validate dates if provided
-		if query.DateFrom != "" {
-			if _, err := time.Parse("2006-01-02", query.DateFrom); err != nil {
-				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid date_from format, use YYYY-MM-DD"})
-				return
-			}
+		query.Params.Type = query.IncomeType
+		filters, err := query.Params.Filters(listing.Incomes)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
 		}
-		if query.DateTo != "" {
-			if _, err := time.Parse("2006-01-02", query.DateTo); err != nil {
-				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid date_to format, use YYYY-MM-DD"})
+
+		// Keyset pagination: the cursor replaces page/OFFSET
+		var cursor *listing.Cursor
+		if query.Cursor != "" {
+			decoded, err := listing.DecodeCursor(query.Cursor, query.SortBy, query.Order)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 				return
 			}
-		}
-
-		// Validate income_type if provided
-		if query.IncomeType != "" && query.IncomeType != "one-time" && query.IncomeType != "recurring" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "income_type must be one-time or recurring"})
-			return
+			cursor = &decoded
 		}
 
 		// Build WHERE clauses dynamically
@@ -121,42 +117,14 @@ func ListIncomes(db *pgxpool.Pool) gin.HandlerFunc {
 		args := []interface{}{accountID}
 		argIndex := 2
 
-		if query.DateFrom != "" {
-			whereClauses = append(whereClauses, "i.date >= $"+strconv.Itoa(argIndex))
-			args = append(args, query.DateFrom)
-			argIndex++
-		}
-
-		if query.DateTo != "" {
-			whereClauses = append(whereClauses, "i.date <= $"+strconv.Itoa(argIndex))
-			args = append(args, query.DateTo)
-			argIndex++
-		}
-
-		if query.IncomeType != "" {
-			whereClauses = append(whereClauses, "i.income_type = $"+strconv.Itoa(argIndex))
-			args = append(args, query.IncomeType)
-			argIndex++
-		}
-
-		if query.CategoryID != "" {
-			whereClauses = append(whereClauses, "i.category_id = $"+strconv.Itoa(argIndex))
-			args = append(args, query.CategoryID)
-			argIndex++
-		}
-
-		if query.FamilyMemberID != "" {
-			whereClauses = append(whereClauses, "i.family_member_id = $"+strconv.Itoa(argIndex))
-			args = append(args, query.FamilyMemberID)
-			argIndex++
-		}
+		whereClauses, args, argIndex = filters.Where(listing.Incomes, whereClauses, args, argIndex)
 
 		whereClause := strings.Join(whereClauses, " AND ")
 
 		// Get total count
 		var totalCount int
 		countQuery := "SELECT COUNT(*) FROM incomes i WHERE " + whereClause
-		err := db.QueryRow(c.Request.Context(), countQuery, args...).Scan(&totalCount)
+		err = db.QueryRow(c.Request.Context(), countQuery, args...).Scan(&totalCount)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to count incomes"})
 			return
@@ -165,19 +133,28 @@ func ListIncomes(db *pgxpool.Pool) gin.HandlerFunc {
 		// Calculate pagination
 		totalPages := (totalCount + query.Limit - 1) / query.Limit
 		offset := (query.Page - 1) * query.Limit
+		if cursor != nil {
+			offset = 0
+			query.Page = 0
+			whereClauses, args, argIndex = listing.Incomes.After(*cursor, whereClauses, args, argIndex)
+			whereClause = strings.Join(whereClauses, " AND ")
+		}
 
 		// Build main query with JOIN to get category name
 		mainQuery := `
 			SELECT i.id, i.family_member_id, i.category_id, ic.name as category_name,
 			       i.description, i.amount, i.currency, i.exchange_rate, i.amount_in_primary_currency,
-			       i.income_type, i.date, i.end_date, i.created_at
+			       i.income_type, i.date, i.end_date, i.payment_method_id, i.created_at,
+			       ` + listing.Incomes.SortExpr(query.SortBy) + `::TEXT AS sort_value
 			FROM incomes i
 			LEFT JOIN income_categories ic ON i.category_id = ic.id
+			LEFT JOIN family_members fm ON i.family_member_id = fm.id
 			WHERE ` + whereClause + `
-			ORDER BY i.` + query.SortBy + ` ` + strings.ToUpper(query.Order) + `
+			ORDER BY ` + listing.Incomes.OrderBy(query.SortBy, query.Order) + `
 			LIMIT $` + strconv.Itoa(argIndex) + ` OFFSET $` + strconv.Itoa(argIndex+1)
 
-		args = append(args, query.Limit, offset)
+		// One extra row tells if there is a next page
+		args = append(args, query.Limit+1, offset)
 
 		// Execute query
 		rows, err := db.Query(c.Request.Context(), mainQuery, args...)
@@ -189,11 +166,13 @@ func ListIncomes(db *pgxpool.Pool) gin.HandlerFunc {
 
 		// Parse results
 		incomes := []IncomeListItem{}
+		sortValues := []string{}
 		for rows.Next() {
 			var income IncomeListItem
 			var familyMemberID, categoryID, categoryName *string
 			var date, endDate *time.Time
 			var createdAt time.Time
+			var sortValue string
 
 			err := rows.Scan(
 				&income.ID,
@@ -208,7 +187,9 @@ func ListIncomes(db *pgxpool.Pool) gin.HandlerFunc {
 				&income.IncomeType,
 				&date,
 				&endDate,
+				&income.PaymentMethodID,
 				&createdAt,
+				&sortValue,
 			)
 			if err != nil {
 				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to parse income: " + err.Error()})
@@ -232,6 +213,7 @@ func ListIncomes(db *pgxpool.Pool) gin.HandlerFunc {
 			income.CreatedAt = createdAt.Format(time.RFC3339)
 
 			incomes = append(incomes, income)
+			sortValues = append(sortValues, sortValue)
 		}
 
 		// Check for errors during iteration
@@ -240,6 +222,28 @@ func ListIncomes(db *pgxpool.Pool) gin.HandlerFunc {
 			return
 		}
 
+		var nextCursor *string
+		if len(incomes) > query.Limit {
+			incomes = incomes[:query.Limit]
+			last := listing.Cursor{SortBy: query.SortBy, Order: query.Order, Value: sortValues[query.Limit-1], ID: incomes[query.Limit-1].ID}
+			encoded := last.Encode()
+			nextCursor = &encoded
+		}
+
+		// Tags of the page in a single query
+		incomeIDs := make([]string, len(incomes))
+		for i, income := range incomes {
+			incomeIDs[i] = income.ID
+		}
+		incomeTags, err := tags.Load(c.Request.Context(), db, tags.Incomes, incomeIDs)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch tags: " + err.Error()})
+			return
+		}
+		for i := range incomes {
+			incomes[i].Tags = incomeTags[incomes[i].ID]
+		}
+
 		// Build response
 		response := ListIncomesResponse{
 			Incomes:    incomes,
@@ -247,6 +251,7 @@ func ListIncomes(db *pgxpool.Pool) gin.HandlerFunc {
 			Page:       query.Page,
 			Limit:      query.Limit,
 			TotalPages: totalPages,
+			NextCursor: nextCursor,
 		}
 
 		c.JSON(http.StatusOK, response)
@@ -9,6 +9,7 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/LorenzoCampos/bolsillo-claro/internal/middleware"
 	"github.com/LorenzoCampos/bolsillo-claro/pkg/logger"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/tags"
 )
 
 type UpdateIncomeRequest struct {
@@ -21,6 +22,12 @@ type UpdateIncomeRequest struct {
 	Date           *string  `json:"date"`     // Format: YYYY-MM-DD
 	EndDate        *string  `json:"end_date"` // Format: YYYY-MM-DD
 
+	// Payment method (optional): empty string removes it
+	PaymentMethodID *string `json:"payment_method_id"`
+
+	// Tags (optional): replaces all the tags, [] removes them
+	Tags *[]string `json:"tags"`
+
 	// Multi-currency fields (Modo 3)
 	ExchangeRate            *float64 `json:"exchange_rate,omitempty"`
 	AmountInPrimaryCurrency *float64 `json:"amount_in_primary_currency,omitempty"`
@@ -127,6 +134,16 @@ func UpdateIncome(db *pgxpool.Pool) gin.HandlerFunc {
 			}
 		}
 
+		// Normalize tags if provided
+		var tagNames []string
+		if req.Tags != nil {
+			tagNames, err = tags.NormalizeAll(*req.Tags)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+		}
+
 		// If family_member_id is provided, validate it belongs to this account
 		if req.FamilyMemberID != nil && *req.FamilyMemberID != "" {
 			var memberExists bool
@@ -146,6 +163,25 @@ func UpdateIncome(db *pgxpool.Pool) gin.HandlerFunc {
 				return
 			}
 		}
+		// If payment_method_id is provided, validate it belongs to this account and is active
+		if req.PaymentMethodID != nil && *req.PaymentMethodID != "" {
+			var paymentMethodExists bool
+			err := db.QueryRow(c.Request.Context(),
+				`SELECT EXISTS(
+				SELECT 1 FROM payment_methods
+				WHERE id = $1 AND account_id = $2 AND is_active = true
+			)`,
+				req.PaymentMethodID, accountID,
+			).Scan(&paymentMethodExists)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to validate payment method"})
+				return
+			}
+			if !paymentMethodExists {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "payment_method_id does not belong to this account or is inactive"})
+				return
+			}
+		}
 
 		// ============================================================================
 		// MULTI-CURRENCY RECALCULATION - Modo 3
@@ -244,11 +280,16 @@ func UpdateIncome(db *pgxpool.Pool) gin.HandlerFunc {
 				END,
 				exchange_rate = COALESCE($11, exchange_rate),
 				amount_in_primary_currency = COALESCE($12, amount_in_primary_currency),
+				payment_method_id = CASE
+					WHEN $13::text = '' THEN NULL
+					WHEN $13::text IS NOT NULL THEN $13::uuid
+					ELSE payment_method_id
+				END,
 				updated_at = CURRENT_TIMESTAMP
 			WHERE id = $9 AND account_id = $10
 			RETURNING id, account_id, family_member_id, category_id, description, 
 			          amount, currency, exchange_rate, amount_in_primary_currency,
-			          income_type, date, end_date, created_at
+			          income_type, date, end_date, payment_method_id, created_at
 		`
 
 		// Handle end_date special case: empty string means clear it
@@ -271,7 +312,7 @@ func UpdateIncome(db *pgxpool.Pool) gin.HandlerFunc {
 			req.FamilyMemberID, req.CategoryID, req.Description,
 			req.Amount, req.Currency, req.IncomeType, req.Date,
 			endDateParam, incomeID, accountID,
-			finalExchangeRate, finalAmountInPrimaryCurrency,
+			finalExchangeRate, finalAmountInPrimaryCurrency, req.PaymentMethodID,
 		).Scan(
 			&income.ID,
 			&income.AccountID,
@@ -285,6 +326,7 @@ func UpdateIncome(db *pgxpool.Pool) gin.HandlerFunc {
 			&income.IncomeType,
 			&date,
 			&endDate,
+			&income.PaymentMethodID,
 			&createdAt,
 		)
 
@@ -293,6 +335,13 @@ func UpdateIncome(db *pgxpool.Pool) gin.HandlerFunc {
 			return
 		}
 
+		if req.Tags != nil {
+			if err := tags.Set(c.Request.Context(), db, tags.Incomes, income.ID, accountID, tagNames); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save tags: " + err.Error()})
+				return
+			}
+		}
+
 		// Get category name if category_id exists
 		var categoryName *string
 		if categoryID != nil {
@@ -323,6 +372,13 @@ func UpdateIncome(db *pgxpool.Pool) gin.HandlerFunc {
 
 		income.CreatedAt = createdAt.Format(time.RFC3339)
 
+		incomeTags, err := tags.Load(c.Request.Context(), db, tags.Incomes, []string{income.ID})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch tags: " + err.Error()})
+			return
+		}
+		income.Tags = incomeTags[income.ID]
+
 		// Obtener user_id del contexto para logging
 		userID, _ := middleware.GetUserID(c)
 
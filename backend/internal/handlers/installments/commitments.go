@@ -0,0 +1,116 @@
+package installments
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/money"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// MonthlyCommitment es el total de cuotas que vencen en un mes
+type MonthlyCommitment struct {
+	Month                   string  `json:"month"` // YYYY-MM
+	AmountInPrimaryCurrency float64 `json:"amount_in_primary_currency"`
+	InstallmentsCount       int     `json:"installments_count"`
+	PurchasesCount          int     `json:"purchases_count"`
+}
+
+// GetInstallmentCommitments maneja GET /api/installments/commitments
+// Query param: months (1-60, default 12). Cuotas pendientes agrupadas por mes desde el mes actual
+func GetInstallmentCommitments(pool *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountID, exists := c.Get("account_id")
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "X-Account-ID header requerido",
+			})
+			return
+		}
+
+		months := 12
+		if m := c.Query("months"); m != "" {
+			parsed, err := strconv.Atoi(m)
+			if err != nil || parsed < 1 || parsed > 60 {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": "months debe ser un número entre 1 y 60",
+				})
+				return
+			}
+			months = parsed
+		}
+
+		ctx := c.Request.Context()
+		today := time.Now().UTC().Truncate(24 * time.Hour)
+		firstMonth := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC)
+		endDate := firstMonth.AddDate(0, months, 0)
+
+		var primaryCurrency string
+		err := pool.QueryRow(ctx, "SELECT currency FROM accounts WHERE id = $1", accountID).Scan(&primaryCurrency)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Error obteniendo moneda de la cuenta",
+			})
+			return
+		}
+
+		query := `
+			SELECT
+				TO_CHAR(e.date, 'YYYY-MM') AS month,
+				COALESCE(SUM(e.amount_in_primary_currency), 0),
+				COUNT(*),
+				COUNT(DISTINCT e.installment_purchase_id)
+			FROM expenses e
+			WHERE e.account_id = $1
+			  AND e.installment_purchase_id IS NOT NULL
+			  AND e.installment_number IS NOT NULL
+			  AND e.date > $2
+			  AND e.date < $3
+			GROUP BY month
+		`
+		rows, err := pool.Query(ctx, query, accountID, today, endDate)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Error obteniendo cuotas comprometidas",
+				"details": err.Error(),
+			})
+			return
+		}
+		defer rows.Close()
+
+		byMonth := map[string]MonthlyCommitment{}
+		for rows.Next() {
+			var m MonthlyCommitment
+			if err := rows.Scan(&m.Month, &m.AmountInPrimaryCurrency, &m.InstallmentsCount, &m.PurchasesCount); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error":   "Error leyendo cuotas comprometidas",
+					"details": err.Error(),
+				})
+				return
+			}
+			byMonth[m.Month] = m
+		}
+
+		// Todos los meses del rango, incluso los que no tienen cuotas
+		commitments := make([]MonthlyCommitment, 0, months)
+		total := 0.0
+		for i := 0; i < months; i++ {
+			month := firstMonth.AddDate(0, i, 0).Format("2006-01")
+			m, ok := byMonth[month]
+			if !ok {
+				m = MonthlyCommitment{Month: month}
+			}
+			m.AmountInPrimaryCurrency = money.Round(m.AmountInPrimaryCurrency)
+			total += m.AmountInPrimaryCurrency
+			commitments = append(commitments, m)
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"primary_currency": primaryCurrency,
+			"months":           commitments,
+			"total_committed":  money.Round(total),
+		})
+	}
+}
@@ -0,0 +1,274 @@
+package installments
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/LorenzoCampos/bolsillo-claro/internal/middleware"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/logger"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/money"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// CreateInstallmentPurchaseRequest representa el JSON para registrar una compra en cuotas
+type CreateInstallmentPurchaseRequest struct {
+	Description       string   `json:"description" binding:"required"`
+	TotalAmount       float64  `json:"total_amount" binding:"required,gt=0"` // Monto financiado (precio de contado)
+	Currency          string   `json:"currency" binding:"required,oneof=ARS USD EUR"`
+	InstallmentsCount int      `json:"installments_count" binding:"required,gte=1,lte=120"`
+	InterestRate      *float64 `json:"interest_rate" binding:"omitempty,gte=0"` // TNA/CFT anual en %. Default 0 (sin interés)
+	FirstDueDate      string   `json:"first_due_date" binding:"required"`       // YYYY-MM-DD
+	CategoryID        *string  `json:"category_id"`
+	FamilyMemberID    *string  `json:"family_member_id"`
+
+	// Multi-currency (optional) - amount_in_primary_currency se refiere a total_amount
+	ExchangeRate            *float64 `json:"exchange_rate,omitempty"`
+	AmountInPrimaryCurrency *float64 `json:"amount_in_primary_currency,omitempty"`
+}
+
+// InstallmentPurchaseResponse representa una compra en cuotas con su estado actual
+type InstallmentPurchaseResponse struct {
+	ID                  string  `json:"id"`
+	AccountID           string  `json:"account_id"`
+	Description         string  `json:"description"`
+	CategoryID          *string `json:"category_id,omitempty"`
+	CategoryName        *string `json:"category_name,omitempty"`
+	FamilyMemberID      *string `json:"family_member_id,omitempty"`
+	FamilyMemberName    *string `json:"family_member_name,omitempty"`
+	TotalAmount         float64 `json:"total_amount"`
+	Currency            string  `json:"currency"`
+	InstallmentsCount   int     `json:"installments_count"`
+	InterestRate        float64 `json:"interest_rate"`
+	InstallmentAmount   float64 `json:"installment_amount"`
+	TotalWithInterest   float64 `json:"total_with_interest"`
+	FirstDueDate        string  `json:"first_due_date"`
+	ExchangeRate        float64 `json:"exchange_rate"`
+	Status              string  `json:"status"` // active, completed, paid_off
+	PaidOffAt           *string `json:"paid_off_at,omitempty"`
+	PaidInstallments    int     `json:"paid_installments"`
+	PendingInstallments int     `json:"pending_installments"`
+	RemainingBalance    float64 `json:"remaining_balance"`   // Suma de cuotas pendientes
+	RemainingPrincipal  float64 `json:"remaining_principal"` // Capital adeudado (monto de cancelación anticipada)
+	NextDueDate         *string `json:"next_due_date,omitempty"`
+	CreatedAt           string  `json:"created_at"`
+	UpdatedAt           string  `json:"updated_at"`
+}
+
+// CreateInstallmentPurchase maneja POST /api/installments
+// Crea la compra y un expense por cada cuota (todo en una transacción)
+func CreateInstallmentPurchase(pool *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req CreateInstallmentPurchaseRequest
+
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Datos inválidos",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		accountID, exists := c.Get("account_id")
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "X-Account-ID header requerido",
+			})
+			return
+		}
+
+		userID, _ := middleware.GetUserID(c)
+
+		ctx := c.Request.Context()
+
+		firstDueDate, err := time.Parse("2006-01-02", req.FirstDueDate)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "first_due_date debe tener formato YYYY-MM-DD",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		interestRate := 0.0
+		if req.InterestRate != nil {
+			interestRate = *req.InterestRate
+		}
+
+		// Validar family_member_id si existe
+		if req.FamilyMemberID != nil {
+			var memberExists bool
+			checkMemberQuery := `
+				SELECT EXISTS(
+					SELECT 1 FROM family_members
+					WHERE id = $1 AND account_id = $2
+				)
+			`
+			err := pool.QueryRow(ctx, checkMemberQuery, *req.FamilyMemberID, accountID).Scan(&memberExists)
+			if err != nil || !memberExists {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": "family_member_id no pertenece a esta cuenta",
+				})
+				return
+			}
+		}
+
+		// Validar category_id si existe (de la cuenta o del sistema)
+		if req.CategoryID != nil {
+			var categoryExists bool
+			checkCategoryQuery := `
+				SELECT EXISTS(
+					SELECT 1 FROM expense_categories
+					WHERE id = $1 AND (account_id = $2 OR is_system)
+				)
+			`
+			err := pool.QueryRow(ctx, checkCategoryQuery, *req.CategoryID, accountID).Scan(&categoryExists)
+			if err != nil || !categoryExists {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": "category_id no pertenece a esta cuenta",
+				})
+				return
+			}
+		}
+
+		// Obtener moneda primaria de la cuenta
+		var primaryCurrency string
+		err = pool.QueryRow(ctx, "SELECT currency FROM accounts WHERE id = $1", accountID).Scan(&primaryCurrency)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Error obteniendo moneda de la cuenta",
+			})
+			return
+		}
+
+		// Calcular exchange_rate (Modo 3 multi-currency), se aplica a todas las cuotas
+		var exchangeRate float64
+
+		if req.Currency == primaryCurrency {
+			// Modo 1: Misma moneda
+			exchangeRate = 1.0
+		} else if req.AmountInPrimaryCurrency != nil {
+			// Modo 3: Usuario provee amount_in_primary_currency (del total)
+			exchangeRate = *req.AmountInPrimaryCurrency / req.TotalAmount
+		} else if req.ExchangeRate != nil {
+			// Modo 2: Usuario provee exchange_rate
+			exchangeRate = *req.ExchangeRate
+		} else {
+			// Modo Auto: Buscar tasa en exchange_rates table
+			rateQuery := `
+				SELECT rate FROM exchange_rates
+				WHERE from_currency = $1 AND to_currency = $2 AND rate_date = $3
+				ORDER BY created_at DESC LIMIT 1
+			`
+			err := pool.QueryRow(ctx, rateQuery, req.Currency, primaryCurrency, req.FirstDueDate).Scan(&exchangeRate)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": "No se encontró tasa de cambio. Proporcione exchange_rate o amount_in_primary_currency",
+				})
+				return
+			}
+		}
+
+		if exchangeRate <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "exchange_rate debe ser mayor a 0",
+			})
+			return
+		}
+
+		schedule := buildSchedule(req.TotalAmount, req.InstallmentsCount, interestRate, firstDueDate)
+		totalWithInterest := scheduleTotal(schedule)
+
+		tx, err := pool.Begin(ctx)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Error iniciando transacción",
+				"details": err.Error(),
+			})
+			return
+		}
+		defer tx.Rollback(ctx)
+
+		insertQuery := `
+			INSERT INTO installment_purchases (
+				account_id, description, category_id, family_member_id,
+				total_amount, currency, installments_count, interest_rate,
+				installment_amount, total_with_interest, first_due_date, exchange_rate
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+			RETURNING id
+		`
+
+		var purchaseID string
+		err = tx.QueryRow(ctx, insertQuery,
+			accountID, req.Description, req.CategoryID, req.FamilyMemberID,
+			req.TotalAmount, req.Currency, req.InstallmentsCount, interestRate,
+			schedule[0].Amount, totalWithInterest, firstDueDate, exchangeRate,
+		).Scan(&purchaseID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Error creando compra en cuotas",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		// Un expense por cuota, con la fecha de vencimiento de cada una
+		expenseQuery := `
+			INSERT INTO expenses (
+				account_id, family_member_id, category_id, description,
+				amount, currency, exchange_rate, amount_in_primary_currency,
+				expense_type, date, installment_purchase_id, installment_number
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, 'one-time', $9, $10, $11)
+		`
+		for _, entry := range schedule {
+			_, err = tx.Exec(ctx, expenseQuery,
+				accountID, req.FamilyMemberID, req.CategoryID,
+				fmt.Sprintf("%s (cuota %d/%d)", req.Description, entry.Number, req.InstallmentsCount),
+				entry.Amount, req.Currency, exchangeRate, money.Round(entry.Amount*exchangeRate),
+				entry.dueDate, purchaseID, entry.Number,
+			)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error":   "Error generando cuotas",
+					"details": err.Error(),
+				})
+				return
+			}
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Error confirmando transacción",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		purchase, err := getPurchase(ctx, pool, purchaseID, accountID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Error obteniendo compra en cuotas",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		logger.Info("installment_purchase.created", "Compra en cuotas creada", map[string]interface{}{
+			"installment_purchase_id": purchaseID,
+			"account_id":              accountID,
+			"user_id":                 userID,
+			"total_amount":            req.TotalAmount,
+			"currency":                req.Currency,
+			"installments_count":      req.InstallmentsCount,
+			"interest_rate":           interestRate,
+			"ip":                      c.ClientIP(),
+		})
+
+		c.JSON(http.StatusCreated, gin.H{
+			"message":              "Compra en cuotas creada exitosamente",
+			"installment_purchase": purchase,
+			"schedule":             schedule,
+		})
+	}
+}
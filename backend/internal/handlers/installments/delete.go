@@ -0,0 +1,59 @@
+package installments
+
+import (
+	"net/http"
+
+	"github.com/LorenzoCampos/bolsillo-claro/internal/middleware"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// DeleteInstallmentPurchase maneja DELETE /api/installments/:id
+// HARD DELETE: elimina la compra y todos sus gastos (cuotas y cancelación) vía ON DELETE CASCADE
+// Pensado para corregir compras cargadas por error
+func DeleteInstallmentPurchase(pool *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		purchaseID := c.Param("id")
+
+		accountID, exists := c.Get("account_id")
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "X-Account-ID header requerido",
+			})
+			return
+		}
+
+		userID, _ := middleware.GetUserID(c)
+
+		ctx := c.Request.Context()
+
+		result, err := pool.Exec(ctx, "DELETE FROM installment_purchases WHERE id = $1 AND account_id = $2", purchaseID, accountID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Error eliminando compra en cuotas",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		if result.RowsAffected() == 0 {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Compra en cuotas no encontrada",
+			})
+			return
+		}
+
+		logger.Info("installment_purchase.deleted", "Compra en cuotas eliminada", map[string]interface{}{
+			"installment_purchase_id": purchaseID,
+			"account_id":              accountID,
+			"user_id":                 userID,
+			"ip":                      c.ClientIP(),
+		})
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Compra en cuotas eliminada exitosamente",
+			"id":      purchaseID,
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package installments
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// InstallmentItem es una cuota del plan junto con el expense que la representa
+type InstallmentItem struct {
+	ScheduleEntry
+	Status    string  `json:"status"` // paid, pending, cancelled (reemplazada por el pago anticipado)
+	ExpenseID *string `json:"expense_id,omitempty"`
+}
+
+// GetInstallmentPurchase maneja GET /api/installments/:id
+// Devuelve la compra, el plan de cuotas (capital/interés) y el gasto de pago anticipado si existe
+func GetInstallmentPurchase(pool *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		purchaseID := c.Param("id")
+
+		accountID, exists := c.Get("account_id")
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "X-Account-ID header requerido",
+			})
+			return
+		}
+
+		ctx := c.Request.Context()
+		today := time.Now().UTC().Truncate(24 * time.Hour)
+
+		purchase, err := getPurchase(ctx, pool, purchaseID, accountID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Compra en cuotas no encontrada",
+			})
+			return
+		}
+
+		expensesQuery := `
+			SELECT id, installment_number, date, amount
+			FROM expenses
+			WHERE installment_purchase_id = $1
+			ORDER BY date ASC
+		`
+		rows, err := pool.Query(ctx, expensesQuery, purchaseID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Error obteniendo cuotas",
+				"details": err.Error(),
+			})
+			return
+		}
+		defer rows.Close()
+
+		expenseByNumber := map[int]string{}
+		var payoff gin.H
+		for rows.Next() {
+			var id string
+			var number *int
+			var date time.Time
+			var amount float64
+			if err := rows.Scan(&id, &number, &date, &amount); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error":   "Error leyendo cuotas",
+					"details": err.Error(),
+				})
+				return
+			}
+
+			if number == nil {
+				payoff = gin.H{
+					"expense_id": id,
+					"date":       date.Format("2006-01-02"),
+					"amount":     amount,
+				}
+				continue
+			}
+			expenseByNumber[*number] = id
+		}
+
+		firstDueDate, _ := time.Parse("2006-01-02", purchase.FirstDueDate)
+		schedule := buildSchedule(purchase.TotalAmount, purchase.InstallmentsCount, purchase.InterestRate, firstDueDate)
+
+		installments := make([]InstallmentItem, 0, len(schedule))
+		for _, entry := range schedule {
+			item := InstallmentItem{ScheduleEntry: entry}
+
+			if id, ok := expenseByNumber[entry.Number]; ok {
+				expenseID := id
+				item.ExpenseID = &expenseID
+				if entry.dueDate.After(today) {
+					item.Status = "pending"
+				} else {
+					item.Status = "paid"
+				}
+			} else {
+				item.Status = "cancelled"
+			}
+
+			installments = append(installments, item)
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"installment_purchase": purchase,
+			"installments":         installments,
+			"payoff":               payoff,
+		})
+	}
+}
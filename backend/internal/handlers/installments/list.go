@@ -0,0 +1,155 @@
+package installments
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/money"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// purchaseSelect obtiene compras con el resumen de cuotas pagadas/pendientes
+// Una cuota se considera pagada cuando su fecha de vencimiento (date del expense) ya pasó
+// $1 = account_id, $2 = hoy
+const purchaseSelect = `
+	SELECT
+		ip.id, ip.account_id, ip.description,
+		ip.category_id, ec.name,
+		ip.family_member_id, fm.name,
+		ip.total_amount, ip.currency, ip.installments_count, ip.interest_rate,
+		ip.installment_amount, ip.total_with_interest, ip.first_due_date, ip.exchange_rate,
+		ip.status, ip.paid_off_at, ip.created_at, ip.updated_at,
+		COALESCE(s.paid_count, 0), COALESCE(s.pending_count, 0),
+		COALESCE(s.pending_amount, 0), s.next_due_date
+	FROM installment_purchases ip
+	LEFT JOIN expense_categories ec ON ip.category_id = ec.id
+	LEFT JOIN family_members fm ON ip.family_member_id = fm.id
+	LEFT JOIN LATERAL (
+		SELECT
+			COUNT(*) FILTER (WHERE e.date <= $2) AS paid_count,
+			COUNT(*) FILTER (WHERE e.date > $2) AS pending_count,
+			SUM(e.amount) FILTER (WHERE e.date > $2) AS pending_amount,
+			MIN(e.date) FILTER (WHERE e.date > $2) AS next_due_date
+		FROM expenses e
+		WHERE e.installment_purchase_id = ip.id
+		  AND e.installment_number IS NOT NULL
+	) s ON true
+	WHERE ip.account_id = $1`
+
+func scanPurchase(row pgx.Row) (*InstallmentPurchaseResponse, error) {
+	var p InstallmentPurchaseResponse
+	var firstDueDate time.Time
+	var paidOffAt, nextDueDate *time.Time
+	var createdAt, updatedAt time.Time
+
+	err := row.Scan(
+		&p.ID, &p.AccountID, &p.Description,
+		&p.CategoryID, &p.CategoryName,
+		&p.FamilyMemberID, &p.FamilyMemberName,
+		&p.TotalAmount, &p.Currency, &p.InstallmentsCount, &p.InterestRate,
+		&p.InstallmentAmount, &p.TotalWithInterest, &firstDueDate, &p.ExchangeRate,
+		&p.Status, &paidOffAt, &createdAt, &updatedAt,
+		&p.PaidInstallments, &p.PendingInstallments,
+		&p.RemainingBalance, &nextDueDate,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	p.FirstDueDate = firstDueDate.Format("2006-01-02")
+	p.CreatedAt = createdAt.Format(time.RFC3339)
+	p.UpdatedAt = updatedAt.Format(time.RFC3339)
+
+	if paidOffAt != nil {
+		d := paidOffAt.Format("2006-01-02")
+		p.PaidOffAt = &d
+	}
+	if nextDueDate != nil {
+		d := nextDueDate.Format("2006-01-02")
+		p.NextDueDate = &d
+	}
+
+	// Capital adeudado según el plan (lo que se pagaría cancelando hoy)
+	if p.Status == "active" && p.PendingInstallments > 0 {
+		schedule := buildSchedule(p.TotalAmount, p.InstallmentsCount, p.InterestRate, firstDueDate)
+		p.RemainingPrincipal = outstandingPrincipal(schedule, p.TotalAmount, p.InstallmentsCount-p.PendingInstallments+1)
+	}
+
+	// completed se deriva: plan activo sin cuotas pendientes
+	if p.Status == "active" && p.PendingInstallments == 0 {
+		p.Status = "completed"
+	}
+
+	return &p, nil
+}
+
+// getPurchase obtiene una compra verificando que pertenezca a la cuenta
+func getPurchase(ctx context.Context, pool *pgxpool.Pool, purchaseID string, accountID interface{}) (*InstallmentPurchaseResponse, error) {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	return scanPurchase(pool.QueryRow(ctx, purchaseSelect+" AND ip.id = $3", accountID, today, purchaseID))
+}
+
+// ListInstallmentPurchases maneja GET /api/installments
+// Query param: status = active | completed | paid_off | all (default: all)
+func ListInstallmentPurchases(pool *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountID, exists := c.Get("account_id")
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "X-Account-ID header requerido",
+			})
+			return
+		}
+
+		status := c.DefaultQuery("status", "all")
+		if status != "all" && status != "active" && status != "completed" && status != "paid_off" {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "status debe ser active, completed, paid_off o all",
+			})
+			return
+		}
+
+		ctx := c.Request.Context()
+		today := time.Now().UTC().Truncate(24 * time.Hour)
+
+		rows, err := pool.Query(ctx, purchaseSelect+" ORDER BY ip.first_due_date DESC, ip.created_at DESC", accountID, today)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Error obteniendo compras en cuotas",
+				"details": err.Error(),
+			})
+			return
+		}
+		defer rows.Close()
+
+		purchases := []InstallmentPurchaseResponse{}
+		totalRemaining := 0.0
+		for rows.Next() {
+			p, err := scanPurchase(rows)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error":   "Error leyendo compras en cuotas",
+					"details": err.Error(),
+				})
+				return
+			}
+
+			// El status completed es derivado, por eso el filtro se aplica después de escanear
+			if status != "all" && p.Status != status {
+				continue
+			}
+
+			totalRemaining += p.RemainingBalance * p.ExchangeRate
+			purchases = append(purchases, *p)
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"installment_purchases":               purchases,
+			"count":                               len(purchases),
+			"total_remaining_in_primary_currency": money.Round(totalRemaining),
+		})
+	}
+}
@@ -0,0 +1,209 @@
+package installments
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/LorenzoCampos/bolsillo-claro/internal/middleware"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/logger"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/money"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PayOffInstallmentPurchaseRequest representa el JSON para cancelar anticipadamente
+type PayOffInstallmentPurchaseRequest struct {
+	Date   *string  `json:"date"`                            // YYYY-MM-DD (default: hoy)
+	Amount *float64 `json:"amount" binding:"omitempty,gt=0"` // Default: capital adeudado según el plan
+}
+
+// PayOffInstallmentPurchase maneja POST /api/installments/:id/payoff
+// Elimina las cuotas pendientes (fecha posterior a date) y registra un único gasto por el monto cancelado
+func PayOffInstallmentPurchase(pool *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		purchaseID := c.Param("id")
+
+		// El body es opcional (sin body = cancelar hoy por el capital adeudado)
+		var req PayOffInstallmentPurchaseRequest
+		if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Datos inválidos",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		accountID, exists := c.Get("account_id")
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "X-Account-ID header requerido",
+			})
+			return
+		}
+
+		userID, _ := middleware.GetUserID(c)
+
+		ctx := c.Request.Context()
+
+		payoffDate := time.Now().UTC().Truncate(24 * time.Hour)
+		if req.Date != nil {
+			parsed, err := time.Parse("2006-01-02", *req.Date)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error":   "date debe tener formato YYYY-MM-DD",
+					"details": err.Error(),
+				})
+				return
+			}
+			payoffDate = parsed
+		}
+
+		tx, err := pool.Begin(ctx)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Error iniciando transacción",
+				"details": err.Error(),
+			})
+			return
+		}
+		defer tx.Rollback(ctx)
+
+		// Bloquear la compra mientras se cancela
+		var description, currency, status string
+		var totalAmount, interestRate, exchangeRate float64
+		var installmentsCount int
+		var firstDueDate time.Time
+		var categoryID, familyMemberID *string
+		purchaseQuery := `
+			SELECT description, currency, status, total_amount, interest_rate, exchange_rate,
+			       installments_count, first_due_date, category_id, family_member_id
+			FROM installment_purchases
+			WHERE id = $1 AND account_id = $2
+			FOR UPDATE
+		`
+		err = tx.QueryRow(ctx, purchaseQuery, purchaseID, accountID).Scan(
+			&description, &currency, &status, &totalAmount, &interestRate, &exchangeRate,
+			&installmentsCount, &firstDueDate, &categoryID, &familyMemberID,
+		)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Compra en cuotas no encontrada",
+			})
+			return
+		}
+
+		if status == "paid_off" {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "La compra ya fue cancelada anticipadamente",
+			})
+			return
+		}
+
+		// Cuotas pendientes = vencen después de la fecha de cancelación
+		var firstPending *int
+		var pendingCount int
+		var pendingAmount float64
+		pendingQuery := `
+			SELECT MIN(installment_number), COUNT(*), COALESCE(SUM(amount), 0)
+			FROM expenses
+			WHERE installment_purchase_id = $1
+			  AND installment_number IS NOT NULL
+			  AND date > $2
+		`
+		err = tx.QueryRow(ctx, pendingQuery, purchaseID, payoffDate).Scan(&firstPending, &pendingCount, &pendingAmount)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Error obteniendo cuotas pendientes",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		if pendingCount == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "No hay cuotas pendientes posteriores a la fecha de cancelación",
+			})
+			return
+		}
+
+		// Monto de cancelación: capital adeudado (sin intereses futuros) salvo que se indique otro
+		schedule := buildSchedule(totalAmount, installmentsCount, interestRate, firstDueDate)
+		payoffAmount := outstandingPrincipal(schedule, totalAmount, *firstPending)
+		if req.Amount != nil {
+			payoffAmount = *req.Amount
+		}
+
+		_, err = tx.Exec(ctx, `
+			DELETE FROM expenses
+			WHERE installment_purchase_id = $1
+			  AND installment_number IS NOT NULL
+			  AND date > $2
+		`, purchaseID, payoffDate)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Error eliminando cuotas pendientes",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		var expenseID string
+		insertQuery := `
+			INSERT INTO expenses (
+				account_id, family_member_id, category_id, description,
+				amount, currency, exchange_rate, amount_in_primary_currency,
+				expense_type, date, installment_purchase_id
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, 'one-time', $9, $10)
+			RETURNING id
+		`
+		err = tx.QueryRow(ctx, insertQuery,
+			accountID, familyMemberID, categoryID, description+" (cancelación anticipada)",
+			payoffAmount, currency, exchangeRate, money.Round(payoffAmount*exchangeRate),
+			payoffDate, purchaseID,
+		).Scan(&expenseID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Error registrando gasto de cancelación",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		_, err = tx.Exec(ctx, "UPDATE installment_purchases SET status = 'paid_off', paid_off_at = $2 WHERE id = $1", purchaseID, payoffDate)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Error actualizando compra en cuotas",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Error confirmando transacción",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		logger.Info("installment_purchase.paid_off", "Compra en cuotas cancelada anticipadamente", map[string]interface{}{
+			"installment_purchase_id": purchaseID,
+			"account_id":              accountID,
+			"user_id":                 userID,
+			"cancelled_installments":  pendingCount,
+			"payoff_amount":           payoffAmount,
+			"ip":                      c.ClientIP(),
+		})
+
+		c.JSON(http.StatusOK, gin.H{
+			"message":                "Compra en cuotas cancelada exitosamente",
+			"expense_id":             expenseID,
+			"date":                   payoffDate.Format("2006-01-02"),
+			"payoff_amount":          payoffAmount,
+			"cancelled_installments": pendingCount,
+			"cancelled_amount":       money.Round(pendingAmount),
+			"interest_saved":         money.Round(pendingAmount - payoffAmount),
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package installments
+
+import (
+	"math"
+	"time"
+
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/money"
+)
+
+// ScheduleEntry es una cuota del plan (sistema francés: cuota fija, interés sobre saldo)
+type ScheduleEntry struct {
+	Number             int     `json:"number"`
+	DueDate            string  `json:"due_date"`
+	Amount             float64 `json:"amount"`
+	Principal          float64 `json:"principal"`
+	Interest           float64 `json:"interest"`
+	RemainingPrincipal float64 `json:"remaining_principal"` // Saldo de capital después de pagar esta cuota
+
+	dueDate time.Time
+}
+
+// buildSchedule calcula el plan de cuotas
+// annualRate es la TNA/CFT en porcentaje (ej: 85.5). Con 0 las cuotas son iguales y sin interés
+// La última cuota absorbe las diferencias de redondeo para que el capital cierre en 0
+func buildSchedule(totalAmount float64, count int, annualRate float64, firstDueDate time.Time) []ScheduleEntry {
+	monthlyRate := annualRate / 100 / 12
+
+	installment := totalAmount / float64(count)
+	if monthlyRate > 0 {
+		installment = totalAmount * monthlyRate / (1 - math.Pow(1+monthlyRate, -float64(count)))
+	}
+	installment = money.Round(installment)
+
+	schedule := make([]ScheduleEntry, 0, count)
+	remaining := totalAmount
+
+	for i := 1; i <= count; i++ {
+		interest := money.Round(remaining * monthlyRate)
+		principal := money.Round(installment - interest)
+		amount := installment
+
+		if i == count {
+			principal = money.Round(remaining)
+			amount = money.Round(principal + interest)
+		}
+
+		remaining = money.Round(remaining - principal)
+		dueDate := addMonths(firstDueDate, i-1)
+
+		schedule = append(schedule, ScheduleEntry{
+			Number:             i,
+			DueDate:            dueDate.Format("2006-01-02"),
+			Amount:             amount,
+			Principal:          principal,
+			Interest:           interest,
+			RemainingPrincipal: remaining,
+			dueDate:            dueDate,
+		})
+	}
+
+	return schedule
+}
+
+// scheduleTotal suma todas las cuotas del plan
+func scheduleTotal(schedule []ScheduleEntry) float64 {
+	total := 0.0
+	for _, e := range schedule {
+		total += e.Amount
+	}
+	return money.Round(total)
+}
+
+// outstandingPrincipal devuelve el capital adeudado antes de pagar la cuota number
+func outstandingPrincipal(schedule []ScheduleEntry, totalAmount float64, number int) float64 {
+	if number <= 1 {
+		return totalAmount
+	}
+	return schedule[number-2].RemainingPrincipal
+}
+
+// addMonths suma meses manteniendo el día de la primera cuota
+// Edge case: día 31 en meses cortos → último día del mes (igual que el scheduler de recurrentes)
+func addMonths(date time.Time, months int) time.Time {
+	firstOfMonth := time.Date(date.Year(), date.Month()+time.Month(months), 1, 0, 0, 0, 0, time.UTC)
+	lastDay := firstOfMonth.AddDate(0, 1, -1).Day()
+
+	day := date.Day()
+	if day > lastDay {
+		day = lastDay
+	}
+
+	return time.Date(firstOfMonth.Year(), firstOfMonth.Month(), day, 0, 0, 0, 0, time.UTC)
+}
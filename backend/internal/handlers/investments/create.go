@@ -0,0 +1,392 @@
+package investments
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/LorenzoCampos/bolsillo-claro/internal/database"
+	"github.com/LorenzoCampos/bolsillo-claro/internal/middleware"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/investments"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type CreateHoldingRequest struct {
+	Name           string  `json:"name" binding:"required,max=100"`
+	InstrumentType string  `json:"instrument_type" binding:"required,oneof=fixed_term mutual_fund crypto stock bond other"`
+	Symbol         *string `json:"symbol" binding:"omitempty,max=20"`              // Ticker for the price provider (BTC, GGAL, AL30...)
+	Currency       *string `json:"currency" binding:"omitempty,oneof=ARS USD EUR"` // Quote currency, defaults to the account currency
+	SavingsGoalID  *string `json:"savings_goal_id" binding:"omitempty,uuid"`
+	Notes          *string `json:"notes"`
+
+	// Initial buy (optional, not for fixed_term)
+	Quantity *float64 `json:"quantity" binding:"omitempty,gt=0"`
+	Price    *float64 `json:"price" binding:"omitempty,gte=0"`
+	Fees     *float64 `json:"fees" binding:"omitempty,gte=0"`
+	Date     *string  `json:"date"` // YYYY-MM-DD, defaults to today
+
+	// Fixed term only: maturity_date or term_days
+	Principal    *float64 `json:"principal" binding:"omitempty,gt=0"`
+	AnnualRate   *float64 `json:"annual_rate" binding:"omitempty,gte=0"` // TNA in %
+	StartDate    *string  `json:"start_date"`                            // YYYY-MM-DD, defaults to today
+	MaturityDate *string  `json:"maturity_date"`
+	TermDays     *int     `json:"term_days" binding:"omitempty,gt=0"`
+}
+
+// FixedTermResponse is the interest and maturity of a fixed-term deposit
+type FixedTermResponse struct {
+	Principal          float64 `json:"principal"`
+	AnnualRate         float64 `json:"annual_rate"`
+	StartDate          string  `json:"start_date"`
+	MaturityDate       string  `json:"maturity_date"`
+	TermDays           int     `json:"term_days"`
+	DaysRemaining      int     `json:"days_remaining"`
+	InterestAtMaturity float64 `json:"interest_at_maturity"`
+	AccruedInterest    float64 `json:"accrued_interest"`
+	ValueAtMaturity    float64 `json:"value_at_maturity"`
+	IsMatured          bool    `json:"is_matured"`
+}
+
+type HoldingResponse struct {
+	ID             string   `json:"id"`
+	AccountID      string   `json:"account_id"`
+	Name           string   `json:"name"`
+	InstrumentType string   `json:"instrument_type"`
+	Symbol         *string  `json:"symbol,omitempty"`
+	Currency       string   `json:"currency"`
+	Quantity       float64  `json:"quantity"`
+	CostBasis      float64  `json:"cost_basis"`
+	AverageCost    *float64 `json:"average_cost,omitempty"` // cost_basis / quantity
+	RealizedGain   float64  `json:"realized_gain"`
+
+	// Valuation (latest price snapshot, or principal + accrued interest for fixed_term)
+	CurrentPrice             *float64 `json:"current_price,omitempty"`
+	PriceDate                *string  `json:"price_date,omitempty"`
+	PriceSource              *string  `json:"price_source,omitempty"`
+	MarketValue              *float64 `json:"market_value"` // null until a price is loaded
+	UnrealizedGain           *float64 `json:"unrealized_gain"`
+	UnrealizedGainPercentage *float64 `json:"unrealized_gain_percentage"`
+
+	FixedTerm     *FixedTermResponse `json:"fixed_term,omitempty"`
+	SavingsGoalID *string            `json:"savings_goal_id,omitempty"`
+	Notes         *string            `json:"notes,omitempty"`
+	IsActive      bool               `json:"is_active"`
+	CreatedAt     string             `json:"created_at"`
+	UpdatedAt     string             `json:"updated_at"`
+}
+
+const holdingColumns = `
+	h.id, h.account_id, h.name, h.instrument_type, h.symbol, h.currency,
+	h.quantity, h.cost_basis, h.realized_gain, h.annual_rate, h.start_date, h.maturity_date,
+	h.savings_goal_id, h.notes, h.is_active, h.created_at, h.updated_at,
+	p.price, p.price_date, p.source
+`
+
+// holdingFrom suma a cada tenencia su último precio cargado
+const holdingFrom = `
+	investment_holdings h
+	LEFT JOIN LATERAL (
+		SELECT price, price_date, source FROM investment_prices
+		WHERE holding_id = h.id
+		ORDER BY price_date DESC
+		LIMIT 1
+	) p ON true
+`
+
+func scanHolding(row pgx.Row) (*HoldingResponse, error) {
+	var h HoldingResponse
+	var annualRate *float64
+	var startDate, maturityDate, priceDate *time.Time
+	var createdAt, updatedAt time.Time
+
+	err := row.Scan(
+		&h.ID, &h.AccountID, &h.Name, &h.InstrumentType, &h.Symbol, &h.Currency,
+		&h.Quantity, &h.CostBasis, &h.RealizedGain, &annualRate, &startDate, &maturityDate,
+		&h.SavingsGoalID, &h.Notes, &h.IsActive, &createdAt, &updatedAt,
+		&h.CurrentPrice, &priceDate, &h.PriceSource,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if priceDate != nil {
+		formatted := priceDate.Format("2006-01-02")
+		h.PriceDate = &formatted
+	}
+	h.CreatedAt = createdAt.Format(time.RFC3339)
+	h.UpdatedAt = updatedAt.Format(time.RFC3339)
+
+	now := time.Now().UTC()
+	var fixedTerm *investments.FixedTerm
+	if annualRate != nil && startDate != nil && maturityDate != nil {
+		fixedTerm = &investments.FixedTerm{
+			Principal:    h.CostBasis,
+			AnnualRate:   *annualRate,
+			StartDate:    *startDate,
+			MaturityDate: *maturityDate,
+		}
+		h.FixedTerm = newFixedTermResponse(*fixedTerm, now)
+	} else if h.Quantity > 0 {
+		averageCost := h.CostBasis / h.Quantity
+		h.AverageCost = &averageCost
+	}
+
+	position := investments.Position{Quantity: h.Quantity, CostBasis: h.CostBasis}
+	h.MarketValue = investments.MarketValue(position, h.CurrentPrice, fixedTerm, now)
+	if h.MarketValue != nil {
+		gain := *h.MarketValue - h.CostBasis
+		h.UnrealizedGain = &gain
+		if h.CostBasis > 0 {
+			percentage := gain / h.CostBasis * 100
+			h.UnrealizedGainPercentage = &percentage
+		}
+	}
+
+	return &h, nil
+}
+
+func newFixedTermResponse(f investments.FixedTerm, asOf time.Time) *FixedTermResponse {
+	interest := f.InterestAtMaturity()
+	return &FixedTermResponse{
+		Principal:          f.Principal,
+		AnnualRate:         f.AnnualRate,
+		StartDate:          f.StartDate.Format("2006-01-02"),
+		MaturityDate:       f.MaturityDate.Format("2006-01-02"),
+		TermDays:           f.TermDays(),
+		DaysRemaining:      f.DaysRemaining(asOf),
+		InterestAtMaturity: interest,
+		AccruedInterest:    f.AccruedInterest(asOf),
+		ValueAtMaturity:    f.Principal + interest,
+		IsMatured:          f.IsMatured(asOf),
+	}
+}
+
+// fetchHolding lee una tenencia de la cuenta con su último precio
+func fetchHolding(ctx context.Context, q database.Querier, holdingID string, accountID interface{}) (*HoldingResponse, error) {
+	return scanHolding(q.QueryRow(ctx,
+		`SELECT `+holdingColumns+` FROM `+holdingFrom+` WHERE h.id = $1 AND h.account_id = $2`,
+		holdingID, accountID,
+	))
+}
+
+// isDuplicateName detects the unique (account_id, LOWER(name)) violation
+func isDuplicateName(err error) bool {
+	if pgErr, ok := err.(*pgconn.PgError); ok {
+		return pgErr.Code == "23505" && pgErr.ConstraintName == "idx_investment_holdings_unique_name_per_account"
+	}
+	return false
+}
+
+// savingsGoalBelongsToAccount checks the goal a holding backs
+func savingsGoalBelongsToAccount(ctx context.Context, q database.Querier, goalID string, accountID interface{}) (bool, error) {
+	var found bool
+	err := q.QueryRow(ctx,
+		`SELECT EXISTS(SELECT 1 FROM savings_goals WHERE id = $1 AND account_id = $2)`,
+		goalID, accountID,
+	).Scan(&found)
+	return found, err
+}
+
+// parsePastDate parses an optional YYYY-MM-DD date (default today) that can't be in the future
+func parsePastDate(value *string, field string) (time.Time, string) {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	if value == nil || *value == "" {
+		return today, ""
+	}
+
+	date, err := time.Parse("2006-01-02", *value)
+	if err != nil {
+		return time.Time{}, "invalid " + field + " format, use YYYY-MM-DD"
+	}
+	if date.After(today) {
+		return time.Time{}, field + " cannot be in the future"
+	}
+	return date, ""
+}
+
+// resolveFixedTerm validates the fixed-term fields and computes the maturity date
+func resolveFixedTerm(req CreateHoldingRequest) (*investments.FixedTerm, string) {
+	if req.Quantity != nil || req.Price != nil || req.Fees != nil || req.Date != nil {
+		return nil, "fixed_term uses principal, annual_rate and start_date instead of quantity/price"
+	}
+	if req.Principal == nil || req.AnnualRate == nil {
+		return nil, "fixed_term requires principal and annual_rate"
+	}
+	if (req.MaturityDate == nil) == (req.TermDays == nil) {
+		return nil, "fixed_term requires maturity_date or term_days (not both)"
+	}
+
+	startDate, msg := parsePastDate(req.StartDate, "start_date")
+	if msg != "" {
+		return nil, msg
+	}
+
+	var maturityDate time.Time
+	if req.TermDays != nil {
+		maturityDate = investments.MaturityFromTerm(startDate, *req.TermDays)
+	} else {
+		parsed, err := time.Parse("2006-01-02", *req.MaturityDate)
+		if err != nil {
+			return nil, "invalid maturity_date format, use YYYY-MM-DD"
+		}
+		maturityDate = parsed
+	}
+	if !maturityDate.After(startDate) {
+		return nil, "maturity_date must be after start_date"
+	}
+
+	return &investments.FixedTerm{
+		Principal:    *req.Principal,
+		AnnualRate:   *req.AnnualRate,
+		StartDate:    startDate,
+		MaturityDate: maturityDate,
+	}, ""
+}
+
+// CreateHolding handles POST /api/investments
+// Market instruments can include an initial buy (quantity + price); fixed_term takes
+// principal, annual_rate, start_date and maturity_date/term_days instead
+func CreateHolding(db *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountID, exists := c.Get("account_id")
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "account_id not found in context"})
+			return
+		}
+
+		var req CreateHoldingRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		var fixedTerm *investments.FixedTerm
+		var initialBuy *investments.Trade
+		var buyDate time.Time
+
+		if req.InstrumentType == investments.InstrumentFixedTerm {
+			var msg string
+			fixedTerm, msg = resolveFixedTerm(req)
+			if msg != "" {
+				c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+				return
+			}
+		} else {
+			if req.Principal != nil || req.AnnualRate != nil || req.StartDate != nil || req.MaturityDate != nil || req.TermDays != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "principal, annual_rate, start_date, maturity_date and term_days only apply to fixed_term"})
+				return
+			}
+			if (req.Quantity == nil) != (req.Price == nil) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "the initial buy requires both quantity and price"})
+				return
+			}
+			if req.Quantity != nil {
+				var msg string
+				buyDate, msg = parsePastDate(req.Date, "date")
+				if msg != "" {
+					c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+					return
+				}
+				initialBuy = &investments.Trade{Type: investments.TradeBuy, Quantity: *req.Quantity, Price: *req.Price}
+				if req.Fees != nil {
+					initialBuy.Fees = *req.Fees
+				}
+			}
+		}
+
+		ctx := c.Request.Context()
+
+		if req.SavingsGoalID != nil {
+			found, err := savingsGoalBelongsToAccount(ctx, db, *req.SavingsGoalID, accountID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check savings goal"})
+				return
+			}
+			if !found {
+				c.JSON(http.StatusNotFound, gin.H{"error": "savings goal not found"})
+				return
+			}
+		}
+
+		tx, err := db.Begin(ctx)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to start transaction: " + err.Error()})
+			return
+		}
+		defer tx.Rollback(ctx)
+
+		var principal *float64
+		var annualRate *float64
+		var startDate, maturityDate *time.Time
+		if fixedTerm != nil {
+			principal, annualRate = &fixedTerm.Principal, &fixedTerm.AnnualRate
+			startDate, maturityDate = &fixedTerm.StartDate, &fixedTerm.MaturityDate
+		}
+
+		// Plazo fijo: quantity = cost_basis = capital. currency NULL → moneda de la cuenta
+		var holdingID string
+		err = tx.QueryRow(ctx, `
+			INSERT INTO investment_holdings (
+				account_id, name, instrument_type, symbol, currency,
+				quantity, cost_basis, annual_rate, start_date, maturity_date,
+				savings_goal_id, notes
+			)
+			VALUES (
+				$1, $2, $3, $4, COALESCE($5::currency, (SELECT currency FROM accounts WHERE id = $1)),
+				COALESCE($6, 0), COALESCE($6, 0), $7, $8, $9,
+				$10, $11
+			)
+			RETURNING id
+		`,
+			accountID, req.Name, req.InstrumentType, req.Symbol, req.Currency,
+			principal, annualRate, startDate, maturityDate,
+			req.SavingsGoalID, req.Notes,
+		).Scan(&holdingID)
+		if err != nil {
+			if isDuplicateName(err) {
+				c.JSON(http.StatusConflict, gin.H{"error": "an investment with that name already exists in this account"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create investment: " + err.Error()})
+			return
+		}
+
+		var transaction *TransactionResponse
+		if initialBuy != nil {
+			transaction, err = recordTrade(ctx, tx, holdingID, investments.Position{}, *initialBuy, buyDate, nil)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to record initial buy: " + err.Error()})
+				return
+			}
+		}
+
+		holding, err := fetchHolding(ctx, tx, holdingID, accountID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch investment: " + err.Error()})
+			return
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to commit transaction: " + err.Error()})
+			return
+		}
+
+		userID, _ := middleware.GetUserID(c)
+		logger.Info("investment.created", "Inversión creada", map[string]interface{}{
+			"holding_id":      holding.ID,
+			"account_id":      accountID,
+			"user_id":         userID,
+			"instrument_type": holding.InstrumentType,
+			"ip":              c.ClientIP(),
+		})
+
+		c.JSON(http.StatusCreated, gin.H{
+			"investment":  holding,
+			"transaction": transaction,
+		})
+	}
+}
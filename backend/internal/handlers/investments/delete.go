@@ -0,0 +1,51 @@
+package investments
+
+import (
+	"net/http"
+
+	"github.com/LorenzoCampos/bolsillo-claro/internal/middleware"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// DeleteHolding handles DELETE /api/investments/:id
+// Deletes the holding with its transactions and prices. To keep the history of a sold
+// or collected investment, set is_active=false instead
+func DeleteHolding(db *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountID, exists := c.Get("account_id")
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "account_id not found in context"})
+			return
+		}
+
+		holdingID := c.Param("id")
+
+		result, err := db.Exec(c.Request.Context(),
+			`DELETE FROM investment_holdings WHERE id = $1 AND account_id = $2`,
+			holdingID, accountID,
+		)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete investment: " + err.Error()})
+			return
+		}
+		if result.RowsAffected() == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "investment not found"})
+			return
+		}
+
+		userID, _ := middleware.GetUserID(c)
+		logger.Info("investment.deleted", "Inversión eliminada", map[string]interface{}{
+			"holding_id": holdingID,
+			"account_id": accountID,
+			"user_id":    userID,
+			"ip":         c.ClientIP(),
+		})
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "investment deleted successfully",
+			"id":      holdingID,
+		})
+	}
+}
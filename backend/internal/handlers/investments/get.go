@@ -0,0 +1,107 @@
+package investments
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PriceResponse is a price snapshot of a holding
+type PriceResponse struct {
+	Price     float64 `json:"price"`
+	PriceDate string  `json:"price_date"`
+	Source    string  `json:"source"` // manual, trade or the provider name
+}
+
+// maxPriceHistory limita el historial de precios del detalle
+const maxPriceHistory = 90
+
+// GetHolding handles GET /api/investments/:id
+// Returns the holding with its buy/sell transactions and the latest price snapshots
+func GetHolding(db *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountID, exists := c.Get("account_id")
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "account_id not found in context"})
+			return
+		}
+
+		holdingID := c.Param("id")
+		ctx := c.Request.Context()
+
+		holding, err := fetchHolding(ctx, db, holdingID, accountID)
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "investment not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch investment: " + err.Error()})
+			return
+		}
+
+		rows, err := db.Query(ctx, `
+			SELECT `+transactionColumns+`
+			FROM investment_transactions
+			WHERE holding_id = $1
+			ORDER BY date DESC, created_at DESC
+		`, holdingID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch transactions: " + err.Error()})
+			return
+		}
+		defer rows.Close()
+
+		transactions := []TransactionResponse{}
+		for rows.Next() {
+			t, err := scanTransaction(rows)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to parse transaction: " + err.Error()})
+				return
+			}
+			transactions = append(transactions, *t)
+		}
+		if err := rows.Err(); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error reading transactions"})
+			return
+		}
+		rows.Close()
+
+		priceRows, err := db.Query(ctx, `
+			SELECT price, price_date, source
+			FROM investment_prices
+			WHERE holding_id = $1
+			ORDER BY price_date DESC
+			LIMIT $2
+		`, holdingID, maxPriceHistory)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch prices: " + err.Error()})
+			return
+		}
+		defer priceRows.Close()
+
+		prices := []PriceResponse{}
+		for priceRows.Next() {
+			var p PriceResponse
+			var priceDate time.Time
+			if err := priceRows.Scan(&p.Price, &priceDate, &p.Source); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to parse price: " + err.Error()})
+				return
+			}
+			p.PriceDate = priceDate.Format("2006-01-02")
+			prices = append(prices, p)
+		}
+		if err := priceRows.Err(); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error reading prices"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"investment":    holding,
+			"transactions":  transactions,
+			"price_history": prices,
+		})
+	}
+}
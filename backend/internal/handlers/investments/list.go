@@ -0,0 +1,98 @@
+package investments
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// CurrencyTotals summarizes the holdings quoted in one currency
+type CurrencyTotals struct {
+	Currency       string  `json:"currency"`
+	CostBasis      float64 `json:"cost_basis"`
+	MarketValue    float64 `json:"market_value"` // Only holdings with a price
+	UnrealizedGain float64 `json:"unrealized_gain"`
+	RealizedGain   float64 `json:"realized_gain"`
+	UnpricedCount  int     `json:"unpriced_count"` // Holdings left out of market_value (no price yet)
+}
+
+// ListHoldings handles GET /api/investments
+// Query params: instrument_type, savings_goal_id (optional), is_active = true | false | all (default: true)
+func ListHoldings(db *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountID, exists := c.Get("account_id")
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "account_id not found in context"})
+			return
+		}
+
+		query := `SELECT ` + holdingColumns + ` FROM ` + holdingFrom + ` WHERE h.account_id = $1`
+		args := []interface{}{accountID}
+
+		isActiveParam := c.DefaultQuery("is_active", "true")
+		if isActiveParam == "true" {
+			query += " AND h.is_active = true"
+		} else if isActiveParam == "false" {
+			query += " AND h.is_active = false"
+		}
+
+		if instrumentType := c.Query("instrument_type"); instrumentType != "" {
+			args = append(args, instrumentType)
+			query += " AND h.instrument_type = $" + strconv.Itoa(len(args))
+		}
+
+		if goalID := c.Query("savings_goal_id"); goalID != "" {
+			args = append(args, goalID)
+			query += " AND h.savings_goal_id = $" + strconv.Itoa(len(args))
+		}
+
+		query += " ORDER BY h.instrument_type, h.name ASC"
+
+		rows, err := db.Query(c.Request.Context(), query, args...)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch investments: " + err.Error()})
+			return
+		}
+		defer rows.Close()
+
+		holdings := []HoldingResponse{}
+		totals := []CurrencyTotals{}
+		totalsIndex := map[string]int{}
+		for rows.Next() {
+			h, err := scanHolding(rows)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to parse investment: " + err.Error()})
+				return
+			}
+			holdings = append(holdings, *h)
+
+			i, ok := totalsIndex[h.Currency]
+			if !ok {
+				i = len(totals)
+				totalsIndex[h.Currency] = i
+				totals = append(totals, CurrencyTotals{Currency: h.Currency})
+			}
+			totals[i].RealizedGain += h.RealizedGain
+			if h.MarketValue == nil {
+				totals[i].UnpricedCount++
+				continue
+			}
+			totals[i].CostBasis += h.CostBasis
+			totals[i].MarketValue += *h.MarketValue
+			totals[i].UnrealizedGain += *h.UnrealizedGain
+		}
+
+		if err := rows.Err(); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error reading investments"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"investments": holdings,
+			"totals":      totals,
+			"count":       len(holdings),
+		})
+	}
+}
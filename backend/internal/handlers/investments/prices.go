@@ -0,0 +1,186 @@
+package investments
+
+import (
+	"net/http"
+
+	"github.com/LorenzoCampos/bolsillo-claro/internal/middleware"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/investments"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type AddPriceRequest struct {
+	Price float64 `json:"price" binding:"required,gt=0"` // Per unit, in the holding currency
+	Date  *string `json:"date"`                          // YYYY-MM-DD, defaults to today
+}
+
+// upsertPriceQuery guarda un precio por tenencia y día; el último cargado pisa al anterior
+const upsertPriceQuery = `
+	INSERT INTO investment_prices (holding_id, price, price_date, source)
+	VALUES ($1, $2, $3, $4)
+	ON CONFLICT (holding_id, price_date) DO UPDATE SET price = EXCLUDED.price, source = EXCLUDED.source
+`
+
+// AddPrice handles POST /api/investments/:id/prices (manual price snapshot)
+func AddPrice(db *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountID, exists := c.Get("account_id")
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "account_id not found in context"})
+			return
+		}
+
+		holdingID := c.Param("id")
+
+		var req AddPriceRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		date, msg := parsePastDate(req.Date, "date")
+		if msg != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+			return
+		}
+
+		ctx := c.Request.Context()
+
+		current, err := fetchHolding(ctx, db, holdingID, accountID)
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "investment not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch investment: " + err.Error()})
+			return
+		}
+		if current.FixedTerm != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "fixed_term investments are valued with their annual_rate, not prices"})
+			return
+		}
+
+		if _, err := db.Exec(ctx, upsertPriceQuery, holdingID, req.Price, date, "manual"); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save price: " + err.Error()})
+			return
+		}
+
+		holding, err := fetchHolding(ctx, db, holdingID, accountID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch investment: " + err.Error()})
+			return
+		}
+
+		userID, _ := middleware.GetUserID(c)
+		logger.Info("investment.price.added", "Precio de inversión cargado", map[string]interface{}{
+			"holding_id": holdingID,
+			"account_id": accountID,
+			"user_id":    userID,
+			"price":      req.Price,
+			"date":       date.Format("2006-01-02"),
+			"ip":         c.ClientIP(),
+		})
+
+		c.JSON(http.StatusCreated, gin.H{"investment": holding})
+	}
+}
+
+// RefreshResult is the outcome of fetching a quote for one holding
+type RefreshResult struct {
+	HoldingID string   `json:"holding_id"`
+	Name      string   `json:"name"`
+	Symbol    string   `json:"symbol"`
+	Price     *float64 `json:"price,omitempty"`
+	PriceDate *string  `json:"price_date,omitempty"`
+	Error     *string  `json:"error,omitempty"`
+}
+
+// RefreshPrices handles POST /api/investments/prices/refresh
+// Asks the price provider for every active holding with a symbol (fixed_term excluded)
+// and stores the quotes. Holdings the provider doesn't know are reported, not failed
+func RefreshPrices(db *pgxpool.Pool, provider investments.PriceProvider) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountID, exists := c.Get("account_id")
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "account_id not found in context"})
+			return
+		}
+
+		ctx := c.Request.Context()
+
+		rows, err := db.Query(ctx, `
+			SELECT id, name, instrument_type, symbol, currency
+			FROM investment_holdings
+			WHERE account_id = $1
+			  AND is_active = true
+			  AND symbol IS NOT NULL
+			  AND instrument_type <> 'fixed_term'
+			ORDER BY name
+		`, accountID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch investments: " + err.Error()})
+			return
+		}
+		defer rows.Close()
+
+		type pendingHolding struct {
+			id, name, instrumentType, symbol, currency string
+		}
+		var pending []pendingHolding
+		for rows.Next() {
+			var h pendingHolding
+			if err := rows.Scan(&h.id, &h.name, &h.instrumentType, &h.symbol, &h.currency); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to parse investment: " + err.Error()})
+				return
+			}
+			pending = append(pending, h)
+		}
+		if err := rows.Err(); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error reading investments"})
+			return
+		}
+		rows.Close()
+
+		results := []RefreshResult{}
+		updated := 0
+		for _, h := range pending {
+			result := RefreshResult{HoldingID: h.id, Name: h.name, Symbol: h.symbol}
+
+			quote, err := provider.Quote(ctx, h.instrumentType, h.symbol, h.currency)
+			if err == nil {
+				_, err = db.Exec(ctx, upsertPriceQuery, h.id, quote.Price, quote.Date, provider.Name())
+			}
+			if err != nil {
+				message := err.Error()
+				result.Error = &message
+				results = append(results, result)
+				continue
+			}
+
+			priceDate := quote.Date.Format("2006-01-02")
+			result.Price = &quote.Price
+			result.PriceDate = &priceDate
+			results = append(results, result)
+			updated++
+		}
+
+		userID, _ := middleware.GetUserID(c)
+		logger.Info("investment.prices.refreshed", "Precios de inversiones actualizados", map[string]interface{}{
+			"account_id": accountID,
+			"user_id":    userID,
+			"provider":   provider.Name(),
+			"updated":    updated,
+			"failed":     len(results) - updated,
+			"ip":         c.ClientIP(),
+		})
+
+		c.JSON(http.StatusOK, gin.H{
+			"provider": provider.Name(),
+			"results":  results,
+			"updated":  updated,
+			"failed":   len(results) - updated,
+		})
+	}
+}
@@ -0,0 +1,207 @@
+package investments
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/LorenzoCampos/bolsillo-claro/internal/middleware"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/investments"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type CreateTransactionRequest struct {
+	TransactionType string   `json:"transaction_type" binding:"required,oneof=buy sell"`
+	Quantity        float64  `json:"quantity" binding:"required,gt=0"`
+	Price           float64  `json:"price" binding:"gte=0"` // Per unit, in the holding currency
+	Fees            *float64 `json:"fees" binding:"omitempty,gte=0"`
+	Date            *string  `json:"date"` // YYYY-MM-DD, defaults to today
+	Description     *string  `json:"description"`
+}
+
+type TransactionResponse struct {
+	ID              string   `json:"id"`
+	TransactionType string   `json:"transaction_type"`
+	Quantity        float64  `json:"quantity"`
+	Price           float64  `json:"price"`
+	Fees            float64  `json:"fees"`
+	Amount          float64  `json:"amount"`                  // buy: total cost with fees, sell: net proceeds
+	RealizedGain    *float64 `json:"realized_gain,omitempty"` // Only sells (weighted average cost)
+	Date            string   `json:"date"`
+	Description     *string  `json:"description,omitempty"`
+	CreatedAt       string   `json:"created_at"`
+}
+
+const transactionColumns = `id, transaction_type, quantity, price, fees, amount, realized_gain, date, description, created_at`
+
+func scanTransaction(row pgx.Row) (*TransactionResponse, error) {
+	var t TransactionResponse
+	var date, createdAt time.Time
+
+	err := row.Scan(
+		&t.ID, &t.TransactionType, &t.Quantity, &t.Price, &t.Fees,
+		&t.Amount, &t.RealizedGain, &date, &t.Description, &createdAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	t.Date = date.Format("2006-01-02")
+	t.CreatedAt = createdAt.Format(time.RFC3339)
+
+	return &t, nil
+}
+
+// recordTrade registra la compra/venta, actualiza la posición de la tenencia y guarda el precio
+// operado como cotización del día (sin pisar una manual o del proveedor)
+func recordTrade(ctx context.Context, tx pgx.Tx, holdingID string, position investments.Position, trade investments.Trade, date time.Time, description *string) (*TransactionResponse, error) {
+	result, err := position.Apply(trade)
+	if err != nil {
+		return nil, err
+	}
+
+	transaction, err := scanTransaction(tx.QueryRow(ctx, `
+		INSERT INTO investment_transactions (
+			holding_id, transaction_type, quantity, price, fees, amount, realized_gain, date, description
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING `+transactionColumns,
+		holdingID, trade.Type, trade.Quantity, trade.Price, trade.Fees,
+		result.Amount, result.RealizedGain, date, description,
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	realizedGain := 0.0
+	if result.RealizedGain != nil {
+		realizedGain = *result.RealizedGain
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE investment_holdings
+		SET quantity = $1, cost_basis = $2, realized_gain = realized_gain + $3
+		WHERE id = $4
+	`, result.Position.Quantity, result.Position.CostBasis, realizedGain, holdingID)
+	if err != nil {
+		return nil, err
+	}
+
+	if trade.Price > 0 {
+		_, err = tx.Exec(ctx, `
+			INSERT INTO investment_prices (holding_id, price, price_date, source)
+			VALUES ($1, $2, $3, 'trade')
+			ON CONFLICT (holding_id, price_date) DO NOTHING
+		`, holdingID, trade.Price, date)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return transaction, nil
+}
+
+// CreateTransaction handles POST /api/investments/:id/transactions
+// Buys add to quantity and cost basis; sells remove the weighted average cost of the units
+// sold and record the realized gain. fixed_term holdings don't take trades
+func CreateTransaction(db *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountID, exists := c.Get("account_id")
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "account_id not found in context"})
+			return
+		}
+
+		holdingID := c.Param("id")
+
+		var req CreateTransactionRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		date, msg := parsePastDate(req.Date, "date")
+		if msg != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+			return
+		}
+
+		trade := investments.Trade{Type: req.TransactionType, Quantity: req.Quantity, Price: req.Price}
+		if req.Fees != nil {
+			trade.Fees = *req.Fees
+		}
+
+		ctx := c.Request.Context()
+
+		tx, err := db.Begin(ctx)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to start transaction: " + err.Error()})
+			return
+		}
+		defer tx.Rollback(ctx)
+
+		// FOR UPDATE: evita que dos operaciones simultáneas pisen la posición
+		var instrumentType string
+		var position investments.Position
+		err = tx.QueryRow(ctx, `
+			SELECT instrument_type, quantity, cost_basis
+			FROM investment_holdings
+			WHERE id = $1 AND account_id = $2
+			FOR UPDATE
+		`, holdingID, accountID).Scan(&instrumentType, &position.Quantity, &position.CostBasis)
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "investment not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch investment: " + err.Error()})
+			return
+		}
+
+		if instrumentType == investments.InstrumentFixedTerm {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "fixed_term investments don't take buy/sell transactions, set is_active=false once collected"})
+			return
+		}
+
+		transaction, err := recordTrade(ctx, tx, holdingID, position, trade, date, req.Description)
+		if err == investments.ErrInsufficientQuantity {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":     "cannot sell more units than the investment holds",
+				"available": position.Quantity,
+			})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to record transaction: " + err.Error()})
+			return
+		}
+
+		holding, err := fetchHolding(ctx, tx, holdingID, accountID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch investment: " + err.Error()})
+			return
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to commit transaction: " + err.Error()})
+			return
+		}
+
+		userID, _ := middleware.GetUserID(c)
+		logger.Info("investment.transaction.created", "Operación de inversión registrada", map[string]interface{}{
+			"holding_id":       holdingID,
+			"transaction_id":   transaction.ID,
+			"transaction_type": transaction.TransactionType,
+			"account_id":       accountID,
+			"user_id":          userID,
+			"ip":               c.ClientIP(),
+		})
+
+		c.JSON(http.StatusCreated, gin.H{
+			"transaction": transaction,
+			"investment":  holding,
+		})
+	}
+}
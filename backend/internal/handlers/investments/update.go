@@ -0,0 +1,160 @@
+package investments
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/LorenzoCampos/bolsillo-claro/internal/middleware"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// UpdateHoldingRequest updates the descriptive fields of a holding. Quantity and cost basis
+// only change through transactions; instrument_type and currency can't change
+type UpdateHoldingRequest struct {
+	Name          *string `json:"name" binding:"omitempty,max=100"`
+	Symbol        *string `json:"symbol" binding:"omitempty,max=20"` // Empty string clears it
+	Notes         *string `json:"notes"`
+	SavingsGoalID *string `json:"savings_goal_id"` // Empty string unlinks the goal
+	IsActive      *bool   `json:"is_active"`
+
+	// Fixed term only
+	AnnualRate   *float64 `json:"annual_rate" binding:"omitempty,gte=0"`
+	MaturityDate *string  `json:"maturity_date"`
+}
+
+// UpdateHolding handles PUT /api/investments/:id
+func UpdateHolding(db *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountID, exists := c.Get("account_id")
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "account_id not found in context"})
+			return
+		}
+
+		holdingID := c.Param("id")
+
+		var req UpdateHoldingRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx := c.Request.Context()
+
+		current, err := fetchHolding(ctx, db, holdingID, accountID)
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "investment not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch investment: " + err.Error()})
+			return
+		}
+
+		// Merge request over current values
+		name := current.Name
+		if req.Name != nil {
+			name = *req.Name
+		}
+		symbol := current.Symbol
+		if req.Symbol != nil {
+			symbol = req.Symbol
+			if *req.Symbol == "" {
+				symbol = nil
+			}
+		}
+		notes := current.Notes
+		if req.Notes != nil {
+			notes = req.Notes
+		}
+		isActive := current.IsActive
+		if req.IsActive != nil {
+			isActive = *req.IsActive
+		}
+
+		savingsGoalID := current.SavingsGoalID
+		if req.SavingsGoalID != nil {
+			savingsGoalID = nil
+			if *req.SavingsGoalID != "" {
+				found, err := savingsGoalBelongsToAccount(ctx, db, *req.SavingsGoalID, accountID)
+				if err != nil {
+					c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check savings goal"})
+					return
+				}
+				if !found {
+					c.JSON(http.StatusNotFound, gin.H{"error": "savings goal not found"})
+					return
+				}
+				savingsGoalID = req.SavingsGoalID
+			}
+		}
+
+		var annualRate *float64
+		var maturityDate *time.Time
+		if current.FixedTerm == nil {
+			if req.AnnualRate != nil || req.MaturityDate != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "annual_rate and maturity_date only apply to fixed_term"})
+				return
+			}
+		} else {
+			annualRate = &current.FixedTerm.AnnualRate
+			if req.AnnualRate != nil {
+				annualRate = req.AnnualRate
+			}
+
+			startDate, _ := time.Parse("2006-01-02", current.FixedTerm.StartDate)
+			maturity, _ := time.Parse("2006-01-02", current.FixedTerm.MaturityDate)
+			if req.MaturityDate != nil {
+				maturity, err = time.Parse("2006-01-02", *req.MaturityDate)
+				if err != nil {
+					c.JSON(http.StatusBadRequest, gin.H{"error": "invalid maturity_date format, use YYYY-MM-DD"})
+					return
+				}
+				if !maturity.After(startDate) {
+					c.JSON(http.StatusBadRequest, gin.H{"error": "maturity_date must be after start_date"})
+					return
+				}
+			}
+			maturityDate = &maturity
+		}
+
+		_, err = db.Exec(ctx, `
+			UPDATE investment_holdings SET
+				name = $1,
+				symbol = $2,
+				notes = $3,
+				savings_goal_id = $4,
+				is_active = $5,
+				annual_rate = $6,
+				maturity_date = $7
+			WHERE id = $8 AND account_id = $9
+		`, name, symbol, notes, savingsGoalID, isActive, annualRate, maturityDate, holdingID, accountID)
+		if err != nil {
+			if isDuplicateName(err) {
+				c.JSON(http.StatusConflict, gin.H{"error": "an investment with that name already exists in this account"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update investment: " + err.Error()})
+			return
+		}
+
+		holding, err := fetchHolding(ctx, db, holdingID, accountID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch investment: " + err.Error()})
+			return
+		}
+
+		userID, _ := middleware.GetUserID(c)
+		logger.Info("investment.updated", "Inversión actualizada", map[string]interface{}{
+			"holding_id": holdingID,
+			"account_id": accountID,
+			"user_id":    userID,
+			"ip":         c.ClientIP(),
+		})
+
+		c.JSON(http.StatusOK, gin.H{"investment": holding})
+	}
+}
@@ -0,0 +1,532 @@
+package net_worth
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/LorenzoCampos/bolsillo-claro/internal/database"
+	"github.com/LorenzoCampos/bolsillo-claro/internal/middleware"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type CreateItemRequest struct {
+	Name     string  `json:"name" binding:"required,max=100"`
+	Kind     string  `json:"kind" binding:"required,oneof=asset liability"`
+	Category *string `json:"category" binding:"omitempty,max=50"`            // Free text: property, vehicle, loan, credit_card...
+	Currency *string `json:"currency" binding:"omitempty,oneof=ARS USD EUR"` // Defaults to the account currency
+	Notes    *string `json:"notes"`
+
+	// Initial valuation (optional)
+	Value         *float64 `json:"value" binding:"omitempty,gte=0"`
+	ValuationDate *string  `json:"valuation_date"` // YYYY-MM-DD, defaults to today
+}
+
+type UpdateItemRequest struct {
+	Name     *string `json:"name" binding:"omitempty,max=100"`
+	Category *string `json:"category" binding:"omitempty,max=50"` // Empty string clears it
+	Notes    *string `json:"notes"`
+}
+
+type AddValuationRequest struct {
+	Value float64 `json:"value" binding:"gte=0"` // 0 = sold / paid off
+	Date  *string `json:"date"`                  // YYYY-MM-DD, defaults to today
+	Notes *string `json:"notes"`
+}
+
+type ItemResponse struct {
+	ID            string   `json:"id"`
+	AccountID     string   `json:"account_id"`
+	Name          string   `json:"name"`
+	Kind          string   `json:"kind"`
+	Category      *string  `json:"category,omitempty"`
+	Currency      string   `json:"currency"`
+	Notes         *string  `json:"notes,omitempty"`
+	CurrentValue  *float64 `json:"current_value"` // Latest valuation, null if none yet
+	ValuationDate *string  `json:"valuation_date,omitempty"`
+	CreatedAt     string   `json:"created_at"`
+	UpdatedAt     string   `json:"updated_at"`
+}
+
+type ValuationResponse struct {
+	ID            string  `json:"id"`
+	Value         float64 `json:"value"`
+	ValuationDate string  `json:"valuation_date"`
+	Notes         *string `json:"notes,omitempty"`
+	CreatedAt     string  `json:"created_at"`
+}
+
+const itemColumns = `
+	i.id, i.account_id, i.name, i.kind, i.category, i.currency, i.notes, i.created_at, i.updated_at,
+	v.value, v.valuation_date
+`
+
+// itemFrom suma a cada item su última valuación
+const itemFrom = `
+	net_worth_items i
+	LEFT JOIN LATERAL (
+		SELECT value, valuation_date FROM net_worth_valuations
+		WHERE item_id = i.id
+		ORDER BY valuation_date DESC
+		LIMIT 1
+	) v ON true
+`
+
+func scanItem(row pgx.Row) (*ItemResponse, error) {
+	var item ItemResponse
+	var valuationDate *time.Time
+	var createdAt, updatedAt time.Time
+
+	err := row.Scan(
+		&item.ID, &item.AccountID, &item.Name, &item.Kind, &item.Category, &item.Currency, &item.Notes,
+		&createdAt, &updatedAt, &item.CurrentValue, &valuationDate,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if valuationDate != nil {
+		formatted := valuationDate.Format("2006-01-02")
+		item.ValuationDate = &formatted
+	}
+	item.CreatedAt = createdAt.Format(time.RFC3339)
+	item.UpdatedAt = updatedAt.Format(time.RFC3339)
+
+	return &item, nil
+}
+
+func fetchItem(ctx context.Context, q database.Querier, itemID string, accountID interface{}) (*ItemResponse, error) {
+	return scanItem(q.QueryRow(ctx,
+		`SELECT `+itemColumns+` FROM `+itemFrom+` WHERE i.id = $1 AND i.account_id = $2`,
+		itemID, accountID,
+	))
+}
+
+// isDuplicateName detects the unique (account_id, LOWER(name)) violation
+func isDuplicateName(err error) bool {
+	if pgErr, ok := err.(*pgconn.PgError); ok {
+		return pgErr.Code == "23505" && pgErr.ConstraintName == "idx_net_worth_items_unique_name_per_account"
+	}
+	return false
+}
+
+// parseDate parses an optional YYYY-MM-DD date (default today) that can't be in the future
+func parseDate(value *string, field string) (time.Time, string) {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	if value == nil || *value == "" {
+		return today, ""
+	}
+
+	date, err := time.Parse("2006-01-02", *value)
+	if err != nil {
+		return time.Time{}, "invalid " + field + " format, use YYYY-MM-DD"
+	}
+	if date.After(today) {
+		return time.Time{}, field + " cannot be in the future"
+	}
+	return date, ""
+}
+
+// upsertValuationQuery guarda una valuación por item y día; la última cargada pisa a la anterior
+const upsertValuationQuery = `
+	INSERT INTO net_worth_valuations (item_id, value, valuation_date, notes)
+	VALUES ($1, $2, $3, $4)
+	ON CONFLICT (item_id, valuation_date) DO UPDATE SET value = EXCLUDED.value, notes = EXCLUDED.notes
+	RETURNING id, value, valuation_date, notes, created_at
+`
+
+func scanValuation(row pgx.Row) (*ValuationResponse, error) {
+	var v ValuationResponse
+	var valuationDate, createdAt time.Time
+
+	if err := row.Scan(&v.ID, &v.Value, &valuationDate, &v.Notes, &createdAt); err != nil {
+		return nil, err
+	}
+
+	v.ValuationDate = valuationDate.Format("2006-01-02")
+	v.CreatedAt = createdAt.Format(time.RFC3339)
+
+	return &v, nil
+}
+
+// CreateItem handles POST /api/net-worth/items
+func CreateItem(db *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountID, exists := c.Get("account_id")
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "account_id not found in context"})
+			return
+		}
+
+		var req CreateItemRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		valuationDate, msg := parseDate(req.ValuationDate, "valuation_date")
+		if msg != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+			return
+		}
+
+		ctx := c.Request.Context()
+
+		tx, err := db.Begin(ctx)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to start transaction: " + err.Error()})
+			return
+		}
+		defer tx.Rollback(ctx)
+
+		// currency NULL → moneda de la cuenta
+		var itemID string
+		err = tx.QueryRow(ctx, `
+			INSERT INTO net_worth_items (account_id, name, kind, category, currency, notes)
+			VALUES ($1, $2, $3, $4, COALESCE($5::currency, (SELECT currency FROM accounts WHERE id = $1)), $6)
+			RETURNING id
+		`, accountID, req.Name, req.Kind, req.Category, req.Currency, req.Notes).Scan(&itemID)
+		if err != nil {
+			if isDuplicateName(err) {
+				c.JSON(http.StatusConflict, gin.H{"error": "an item with that name already exists in this account"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create item: " + err.Error()})
+			return
+		}
+
+		if req.Value != nil {
+			_, err = tx.Exec(ctx, `
+				INSERT INTO net_worth_valuations (item_id, value, valuation_date)
+				VALUES ($1, $2, $3)
+			`, itemID, *req.Value, valuationDate)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create valuation: " + err.Error()})
+				return
+			}
+		}
+
+		item, err := fetchItem(ctx, tx, itemID, accountID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch item: " + err.Error()})
+			return
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to commit transaction: " + err.Error()})
+			return
+		}
+
+		userID, _ := middleware.GetUserID(c)
+		logger.Info("net_worth.item.created", "Activo/pasivo creado", map[string]interface{}{
+			"item_id":    item.ID,
+			"account_id": accountID,
+			"user_id":    userID,
+			"kind":       item.Kind,
+			"ip":         c.ClientIP(),
+		})
+
+		c.JSON(http.StatusCreated, item)
+	}
+}
+
+// ListItems handles GET /api/net-worth/items
+// Query params: kind = asset | liability (optional)
+func ListItems(db *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountID, exists := c.Get("account_id")
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "account_id not found in context"})
+			return
+		}
+
+		query := `SELECT ` + itemColumns + ` FROM ` + itemFrom + ` WHERE i.account_id = $1`
+		args := []interface{}{accountID}
+
+		if kind := c.Query("kind"); kind != "" {
+			query += " AND i.kind = $2"
+			args = append(args, kind)
+		}
+
+		query += " ORDER BY i.kind, i.name ASC"
+
+		rows, err := db.Query(c.Request.Context(), query, args...)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch items: " + err.Error()})
+			return
+		}
+		defer rows.Close()
+
+		items := []ItemResponse{}
+		for rows.Next() {
+			item, err := scanItem(rows)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to parse item: " + err.Error()})
+				return
+			}
+			items = append(items, *item)
+		}
+
+		if err := rows.Err(); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error reading items"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"items": items,
+			"count": len(items),
+		})
+	}
+}
+
+// GetItem handles GET /api/net-worth/items/:id (item with its valuation history)
+func GetItem(db *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountID, exists := c.Get("account_id")
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "account_id not found in context"})
+			return
+		}
+
+		itemID := c.Param("id")
+		ctx := c.Request.Context()
+
+		item, err := fetchItem(ctx, db, itemID, accountID)
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "item not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch item: " + err.Error()})
+			return
+		}
+
+		rows, err := db.Query(ctx, `
+			SELECT id, value, valuation_date, notes, created_at
+			FROM net_worth_valuations
+			WHERE item_id = $1
+			ORDER BY valuation_date DESC
+		`, itemID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch valuations: " + err.Error()})
+			return
+		}
+		defer rows.Close()
+
+		valuations := []ValuationResponse{}
+		for rows.Next() {
+			v, err := scanValuation(rows)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to parse valuation: " + err.Error()})
+				return
+			}
+			valuations = append(valuations, *v)
+		}
+
+		if err := rows.Err(); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error reading valuations"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"item":       item,
+			"valuations": valuations,
+		})
+	}
+}
+
+// UpdateItem handles PUT /api/net-worth/items/:id
+// kind and currency can't change (they give meaning to the existing valuations)
+func UpdateItem(db *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountID, exists := c.Get("account_id")
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "account_id not found in context"})
+			return
+		}
+
+		itemID := c.Param("id")
+
+		var req UpdateItemRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx := c.Request.Context()
+
+		result, err := db.Exec(ctx, `
+			UPDATE net_worth_items SET
+				name = COALESCE($1, name),
+				category = CASE WHEN $2::text IS NULL THEN category ELSE NULLIF($2, '') END,
+				notes = COALESCE($3, notes)
+			WHERE id = $4 AND account_id = $5
+		`, req.Name, req.Category, req.Notes, itemID, accountID)
+		if err != nil {
+			if isDuplicateName(err) {
+				c.JSON(http.StatusConflict, gin.H{"error": "an item with that name already exists in this account"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update item: " + err.Error()})
+			return
+		}
+		if result.RowsAffected() == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "item not found"})
+			return
+		}
+
+		item, err := fetchItem(ctx, db, itemID, accountID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch item: " + err.Error()})
+			return
+		}
+
+		userID, _ := middleware.GetUserID(c)
+		logger.Info("net_worth.item.updated", "Activo/pasivo actualizado", map[string]interface{}{
+			"item_id":    itemID,
+			"account_id": accountID,
+			"user_id":    userID,
+			"ip":         c.ClientIP(),
+		})
+
+		c.JSON(http.StatusOK, item)
+	}
+}
+
+// DeleteItem handles DELETE /api/net-worth/items/:id
+// Removes the item and its valuations from every date, history included. To record that it
+// was sold or paid off, add a valuation of 0 instead
+func DeleteItem(db *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountID, exists := c.Get("account_id")
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "account_id not found in context"})
+			return
+		}
+
+		itemID := c.Param("id")
+
+		result, err := db.Exec(c.Request.Context(),
+			`DELETE FROM net_worth_items WHERE id = $1 AND account_id = $2`,
+			itemID, accountID,
+		)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete item: " + err.Error()})
+			return
+		}
+		if result.RowsAffected() == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "item not found"})
+			return
+		}
+
+		userID, _ := middleware.GetUserID(c)
+		logger.Info("net_worth.item.deleted", "Activo/pasivo eliminado", map[string]interface{}{
+			"item_id":    itemID,
+			"account_id": accountID,
+			"user_id":    userID,
+			"ip":         c.ClientIP(),
+		})
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "item deleted successfully",
+			"id":      itemID,
+		})
+	}
+}
+
+// AddValuation handles POST /api/net-worth/items/:id/valuations
+// One valuation per day: a second one on the same date replaces the first
+func AddValuation(db *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountID, exists := c.Get("account_id")
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "account_id not found in context"})
+			return
+		}
+
+		itemID := c.Param("id")
+
+		var req AddValuationRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		date, msg := parseDate(req.Date, "date")
+		if msg != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+			return
+		}
+
+		ctx := c.Request.Context()
+
+		var found bool
+		err := db.QueryRow(ctx,
+			`SELECT EXISTS(SELECT 1 FROM net_worth_items WHERE id = $1 AND account_id = $2)`,
+			itemID, accountID,
+		).Scan(&found)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch item: " + err.Error()})
+			return
+		}
+		if !found {
+			c.JSON(http.StatusNotFound, gin.H{"error": "item not found"})
+			return
+		}
+
+		valuation, err := scanValuation(db.QueryRow(ctx, upsertValuationQuery, itemID, req.Value, date, req.Notes))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save valuation: " + err.Error()})
+			return
+		}
+
+		userID, _ := middleware.GetUserID(c)
+		logger.Info("net_worth.valuation.added", "Valuación cargada", map[string]interface{}{
+			"item_id":    itemID,
+			"account_id": accountID,
+			"user_id":    userID,
+			"value":      req.Value,
+			"date":       valuation.ValuationDate,
+			"ip":         c.ClientIP(),
+		})
+
+		c.JSON(http.StatusCreated, valuation)
+	}
+}
+
+// DeleteValuation handles DELETE /api/net-worth/items/:id/valuations/:valuation_id
+func DeleteValuation(db *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountID, exists := c.Get("account_id")
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "account_id not found in context"})
+			return
+		}
+
+		itemID := c.Param("id")
+		valuationID := c.Param("valuation_id")
+
+		result, err := db.Exec(c.Request.Context(), `
+			DELETE FROM net_worth_valuations v
+			USING net_worth_items i
+			WHERE v.id = $1 AND v.item_id = $2 AND i.id = v.item_id AND i.account_id = $3
+		`, valuationID, itemID, accountID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete valuation: " + err.Error()})
+			return
+		}
+		if result.RowsAffected() == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "valuation not found"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "valuation deleted successfully",
+			"id":      valuationID,
+		})
+	}
+}
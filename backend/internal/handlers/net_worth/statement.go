@@ -0,0 +1,411 @@
+package net_worth
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/LorenzoCampos/bolsillo-claro/internal/middleware"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/debts"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/investments"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/money"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/savings"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Line types
+const (
+	LineCash        = "cash"
+	LineSavingsGoal = "savings_goal"
+	LineInvestment  = "investment"
+	LineAsset       = "asset"
+	LineLiability   = "liability"
+
+	LineDebtReceivable = "debt_receivable" // Money lent (see /api/debts)
+	LineDebtPayable    = "debt_payable"    // Money borrowed
+)
+
+const (
+	defaultHistoryMonths = 12
+	maxHistoryMonths     = 60
+)
+
+// Line is one component of the statement, in its own currency and converted
+type Line struct {
+	Type          string   `json:"type"` // cash | savings_goal | investment | asset | liability | debt_receivable | debt_payable
+	ID            string   `json:"id"`   // account, goal, holding, item or debt id
+	Name          string   `json:"name"`
+	AccountID     string   `json:"account_id"`
+	Currency      string   `json:"currency"`
+	Amount        float64  `json:"amount"`
+	Converted     *float64 `json:"converted"` // null if there's no exchange rate (excluded from totals)
+	ValuationDate *string  `json:"valuation_date,omitempty"`
+}
+
+type Totals struct {
+	Assets      float64 `json:"assets"`
+	Liabilities float64 `json:"liabilities"`
+	NetWorth    float64 `json:"net_worth"`
+}
+
+type HistoryPoint struct {
+	Month       string  `json:"month"` // YYYY-MM
+	Date        string  `json:"date"`  // Month end (the requested date for the last point)
+	Assets      float64 `json:"assets"`
+	Liabilities float64 `json:"liabilities"`
+	NetWorth    float64 `json:"net_worth"`
+}
+
+type StatementResponse struct {
+	Date             string             `json:"date"`
+	Currency         string             `json:"currency"`
+	Assets           []Line             `json:"assets"`
+	Liabilities      []Line             `json:"liabilities"`
+	Totals           Totals             `json:"totals"`
+	ByType           map[string]float64 `json:"by_type"`
+	UnconvertedCount int                `json:"unconverted_count"`
+	History          []HistoryPoint     `json:"history"`
+}
+
+type statementAccount struct {
+	id, name, currency string
+}
+
+// statement es el patrimonio a una fecha; se reusa para cada punto del histórico
+type statement struct {
+	assets      []Line
+	liabilities []Line
+	totals      Totals
+	byType      map[string]float64
+	unconverted int
+}
+
+// converter convierte a la moneda del reporte con las tasas vigentes a una fecha (cacheadas por moneda)
+type converter struct {
+	db       *pgxpool.Pool
+	currency string
+	date     string
+	rates    map[string]*float64
+}
+
+func (cv *converter) convert(ctx context.Context, amount float64, from string) (*float64, error) {
+	rate, cached := cv.rates[from]
+	if !cached {
+		r, err := savings.LookupRate(ctx, cv.db, from, cv.currency, cv.date)
+		if err != nil && err != savings.ErrRateNotFound {
+			return nil, err
+		}
+		if err == nil {
+			rate = &r
+		}
+		cv.rates[from] = rate
+	}
+	if rate == nil {
+		return nil, nil
+	}
+
+	converted := money.Round(amount * *rate)
+	return &converted, nil
+}
+
+func (s *statement) add(line Line) {
+	isLiability := line.Type == LineLiability || line.Type == LineDebtPayable
+	if isLiability {
+		s.liabilities = append(s.liabilities, line)
+	} else {
+		s.assets = append(s.assets, line)
+	}
+
+	if line.Converted == nil {
+		s.unconverted++
+		return
+	}
+
+	s.byType[line.Type] = money.Round(s.byType[line.Type] + *line.Converted)
+	if isLiability {
+		s.totals.Liabilities = money.Round(s.totals.Liabilities + *line.Converted)
+	} else {
+		s.totals.Assets = money.Round(s.totals.Assets + *line.Converted)
+	}
+	s.totals.NetWorth = money.Round(s.totals.Assets - s.totals.Liabilities)
+}
+
+// buildStatement arma el patrimonio de las cuentas a date:
+//   - cash: saldo disponible de cada cuenta (ingresos - gastos - neto asignado a metas)
+//   - savings_goal: saldo de cada meta según su ledger
+//   - investment: tenencias valuadas a esa fecha, salvo las que respaldan una meta (ya cuentan en la meta)
+//   - asset / liability: última valuación manual <= date
+//   - debt_receivable / debt_payable: capital pendiente de préstamos otorgados / tomados con los pagos hasta date
+func buildStatement(ctx context.Context, db *pgxpool.Pool, accounts []statementAccount, currency string, date time.Time) (*statement, error) {
+	cv := &converter{db: db, currency: currency, date: date.Format("2006-01-02"), rates: map[string]*float64{}}
+	s := &statement{assets: []Line{}, liabilities: []Line{}, byType: map[string]float64{}}
+
+	accountIDs := make([]string, 0, len(accounts))
+	for _, account := range accounts {
+		accountIDs = append(accountIDs, account.id)
+	}
+
+	for _, account := range accounts {
+		balance, err := savings.AvailableBalance(ctx, db, account.id, date)
+		if err != nil {
+			return nil, err
+		}
+
+		line := Line{Type: LineCash, ID: account.id, Name: account.name, AccountID: account.id, Currency: account.currency, Amount: money.Round(balance)}
+		if line.Converted, err = cv.convert(ctx, line.Amount, line.Currency); err != nil {
+			return nil, err
+		}
+		s.add(line)
+	}
+
+	rows, err := db.Query(ctx, `
+		SELECT sg.id, sg.name, sg.account_id, sg.currency,
+		       SUM(CASE WHEN t.transaction_type = 'deposit' THEN t.amount ELSE -t.amount END) AS balance
+		FROM savings_goals sg
+		JOIN savings_goal_transactions t ON t.savings_goal_id = sg.id AND t.date <= $2
+		WHERE sg.account_id = ANY($1)
+		GROUP BY sg.id, sg.name, sg.account_id, sg.currency
+		HAVING SUM(CASE WHEN t.transaction_type = 'deposit' THEN t.amount ELSE -t.amount END) <> 0
+		ORDER BY sg.name
+	`, accountIDs, date)
+	if err != nil {
+		return nil, err
+	}
+	var goals []Line
+	for rows.Next() {
+		line := Line{Type: LineSavingsGoal}
+		if err := rows.Scan(&line.ID, &line.Name, &line.AccountID, &line.Currency, &line.Amount); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		goals = append(goals, line)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	for _, line := range goals {
+		if line.Converted, err = cv.convert(ctx, line.Amount, line.Currency); err != nil {
+			return nil, err
+		}
+		s.add(line)
+	}
+
+	for _, account := range accounts {
+		holdings, err := investments.AccountValuations(ctx, db, account.id, date)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, h := range holdings {
+			if h.SavingsGoalID != nil {
+				continue
+			}
+
+			line := Line{Type: LineInvestment, ID: h.HoldingID, Name: h.Name, AccountID: h.AccountID, Currency: h.Currency}
+			if h.MarketValue == nil {
+				// Sin precio a esa fecha no se puede valuar
+				s.add(line)
+				continue
+			}
+			line.Amount = *h.MarketValue
+			if line.Converted, err = cv.convert(ctx, line.Amount, line.Currency); err != nil {
+				return nil, err
+			}
+			s.add(line)
+		}
+	}
+
+	rows, err = db.Query(ctx, `
+		SELECT i.id, i.name, i.account_id, i.kind, i.currency, v.value, v.valuation_date
+		FROM net_worth_items i
+		JOIN LATERAL (
+			SELECT value, valuation_date FROM net_worth_valuations
+			WHERE item_id = i.id AND valuation_date <= $2
+			ORDER BY valuation_date DESC
+			LIMIT 1
+		) v ON true
+		WHERE i.account_id = ANY($1)
+		  AND v.value > 0
+		ORDER BY i.kind, i.name
+	`, accountIDs, date)
+	if err != nil {
+		return nil, err
+	}
+	var items []Line
+	for rows.Next() {
+		var line Line
+		var valuationDate time.Time
+		if err := rows.Scan(&line.ID, &line.Name, &line.AccountID, &line.Type, &line.Currency, &line.Amount, &valuationDate); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		formatted := valuationDate.Format("2006-01-02")
+		line.ValuationDate = &formatted
+		items = append(items, line)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	for _, line := range items {
+		if line.Converted, err = cv.convert(ctx, line.Amount, line.Currency); err != nil {
+			return nil, err
+		}
+		s.add(line)
+	}
+
+	for _, account := range accounts {
+		accountDebts, err := debts.ListByAccount(ctx, db, account.id, &date)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range accountDebts {
+			debt := &accountDebts[i]
+			ledger, err := debt.Ledger(ctx, db, date)
+			if err != nil {
+				return nil, err
+			}
+			if ledger.OutstandingPrincipal <= 0 || debt.Status(ledger, date) == debts.StatusWrittenOff {
+				continue
+			}
+
+			line := Line{Type: LineDebtPayable, ID: debt.ID, Name: debt.Counterparty, AccountID: debt.AccountID, Currency: debt.Currency, Amount: ledger.OutstandingPrincipal}
+			if debt.Direction == debts.DirectionLent {
+				line.Type = LineDebtReceivable
+			}
+			if line.Converted, err = cv.convert(ctx, line.Amount, line.Currency); err != nil {
+				return nil, err
+			}
+			s.add(line)
+		}
+	}
+
+	return s, nil
+}
+
+// GetNetWorth handles GET /api/net-worth
+// Query params:
+//   - date: YYYY-MM-DD (default today)
+//   - currency: ARS | USD | EUR (default: currency of the user's first account)
+//   - months: history length, 0-60 (default 12)
+//   - account_id: restrict to one account (default: every account of the user)
+func GetNetWorth(db *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := middleware.GetUserID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+			return
+		}
+
+		dateParam := c.Query("date")
+		date, msg := parseDate(&dateParam, "date")
+		if msg != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+			return
+		}
+
+		currency := c.Query("currency")
+		if currency != "" && currency != "ARS" && currency != "USD" && currency != "EUR" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "currency must be ARS, USD or EUR"})
+			return
+		}
+
+		months := defaultHistoryMonths
+		if value := c.Query("months"); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed < 0 || parsed > maxHistoryMonths {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "months must be a number between 0 and 60"})
+				return
+			}
+			months = parsed
+		}
+
+		ctx := c.Request.Context()
+
+		query := `SELECT id, name, currency FROM accounts WHERE user_id = $1`
+		args := []interface{}{userID}
+		if accountID := c.Query("account_id"); accountID != "" {
+			query += " AND id::text = $2"
+			args = append(args, accountID)
+		}
+		query += " ORDER BY created_at ASC"
+
+		rows, err := db.Query(ctx, query, args...)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch accounts: " + err.Error()})
+			return
+		}
+		var accounts []statementAccount
+		for rows.Next() {
+			var account statementAccount
+			if err := rows.Scan(&account.id, &account.name, &account.currency); err != nil {
+				rows.Close()
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to parse account: " + err.Error()})
+				return
+			}
+			accounts = append(accounts, account)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error reading accounts"})
+			return
+		}
+
+		if len(accounts) == 0 {
+			if c.Query("account_id") != "" {
+				c.JSON(http.StatusNotFound, gin.H{"error": "account not found"})
+				return
+			}
+			c.JSON(http.StatusNotFound, gin.H{"error": "user has no accounts"})
+			return
+		}
+
+		if currency == "" {
+			currency = accounts[0].currency
+		}
+
+		current, err := buildStatement(ctx, db, accounts, currency, date)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to build net worth statement: " + err.Error()})
+			return
+		}
+
+		// Histórico: cierre de cada mes, el último punto es la fecha pedida
+		history := []HistoryPoint{}
+		for i := months - 1; i >= 0; i-- {
+			pointDate := date
+			point := current
+			if i > 0 {
+				firstOfMonth := time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, time.UTC)
+				pointDate = firstOfMonth.AddDate(0, -i+1, -1)
+				if point, err = buildStatement(ctx, db, accounts, currency, pointDate); err != nil {
+					c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to build net worth history: " + err.Error()})
+					return
+				}
+			}
+
+			history = append(history, HistoryPoint{
+				Month:       pointDate.Format("2006-01"),
+				Date:        pointDate.Format("2006-01-02"),
+				Assets:      point.totals.Assets,
+				Liabilities: point.totals.Liabilities,
+				NetWorth:    point.totals.NetWorth,
+			})
+		}
+
+		c.JSON(http.StatusOK, StatementResponse{
+			Date:             date.Format("2006-01-02"),
+			Currency:         currency,
+			Assets:           current.assets,
+			Liabilities:      current.liabilities,
+			Totals:           current.totals,
+			ByType:           current.byType,
+			UnconvertedCount: current.unconverted,
+			History:          history,
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package payment_methods
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/money"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PaymentMethodBalance is the balance of one wallet derived from its transactions
+type PaymentMethodBalance struct {
+	ID               string  `json:"id"`
+	Name             string  `json:"name"`
+	Type             string  `json:"type"`
+	Currency         string  `json:"currency"`
+	IsActive         bool    `json:"is_active"`
+	OpeningBalance   float64 `json:"opening_balance"`
+	TotalIncomes     float64 `json:"total_incomes"`
+	TotalExpenses    float64 `json:"total_expenses"`
+	Balance          float64 `json:"balance"` // opening_balance + total_incomes - total_expenses, in the wallet currency
+	IncomesCount     int     `json:"incomes_count"`
+	ExpensesCount    int     `json:"expenses_count"`
+	UnconvertedCount int     `json:"unconverted_count"` // Transactions in another currency that could not be converted (not included)
+}
+
+// GetPaymentMethodBalances handles GET /api/payment-methods/balances
+// Query params: as_of (YYYY-MM-DD, default: today), is_active = true | false | all (default: all)
+//
+// Amounts are summed in the wallet currency: transactions in the same currency use amount,
+// and if the wallet is in the account's primary currency, other currencies use amount_in_primary_currency.
+func GetPaymentMethodBalances(db *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountID, exists := c.Get("account_id")
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "account_id not found in context"})
+			return
+		}
+
+		asOf := c.DefaultQuery("as_of", time.Now().UTC().Format("2006-01-02"))
+		if _, err := time.Parse("2006-01-02", asOf); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid as_of format, use YYYY-MM-DD"})
+			return
+		}
+
+		query := `
+			SELECT
+				pm.id, pm.name, pm.type, pm.currency, pm.is_active, pm.opening_balance,
+				COALESCE(inc.total, 0), inc.count, inc.unconverted,
+				COALESCE(exp.total, 0), exp.count, exp.unconverted
+			FROM payment_methods pm
+			JOIN accounts a ON a.id = pm.account_id
+			LEFT JOIN LATERAL (
+				SELECT
+					SUM(CASE
+						WHEN i.currency = pm.currency THEN i.amount
+						WHEN pm.currency = a.currency THEN i.amount_in_primary_currency
+					END) AS total,
+					COUNT(*) AS count,
+					COUNT(*) FILTER (WHERE i.currency <> pm.currency AND pm.currency <> a.currency) AS unconverted
+				FROM incomes i
+				WHERE i.payment_method_id = pm.id AND i.date <= $2
+			) inc ON true
+			LEFT JOIN LATERAL (
+				SELECT
+					SUM(CASE
+						WHEN e.currency = pm.currency THEN e.amount
+						WHEN pm.currency = a.currency THEN e.amount_in_primary_currency
+					END) AS total,
+					COUNT(*) AS count,
+					COUNT(*) FILTER (WHERE e.currency <> pm.currency AND pm.currency <> a.currency) AS unconverted
+				FROM expenses e
+				WHERE e.payment_method_id = pm.id AND NOT e.is_draft AND e.date <= $2
+			) exp ON true
+			WHERE pm.account_id = $1
+		`
+
+		isActiveParam := c.DefaultQuery("is_active", "all")
+		if isActiveParam == "true" {
+			query += " AND pm.is_active = true"
+		} else if isActiveParam == "false" {
+			query += " AND pm.is_active = false"
+		}
+
+		query += " ORDER BY pm.name ASC"
+
+		rows, err := db.Query(c.Request.Context(), query, accountID, asOf)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to calculate balances: " + err.Error()})
+			return
+		}
+		defer rows.Close()
+
+		balances := []PaymentMethodBalance{}
+		totalsByCurrency := map[string]float64{}
+		for rows.Next() {
+			var b PaymentMethodBalance
+			var incomesUnconverted, expensesUnconverted int
+			err := rows.Scan(
+				&b.ID, &b.Name, &b.Type, &b.Currency, &b.IsActive, &b.OpeningBalance,
+				&b.TotalIncomes, &b.IncomesCount, &incomesUnconverted,
+				&b.TotalExpenses, &b.ExpensesCount, &expensesUnconverted,
+			)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to parse balance: " + err.Error()})
+				return
+			}
+
+			b.TotalIncomes = money.Round(b.TotalIncomes)
+			b.TotalExpenses = money.Round(b.TotalExpenses)
+			b.Balance = money.Round(b.OpeningBalance + b.TotalIncomes - b.TotalExpenses)
+			b.UnconvertedCount = incomesUnconverted + expensesUnconverted
+
+			totalsByCurrency[b.Currency] = money.Round(totalsByCurrency[b.Currency] + b.Balance)
+			balances = append(balances, b)
+		}
+
+		if err := rows.Err(); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error reading balances"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"as_of":              asOf,
+			"balances":           balances,
+			"totals_by_currency": totalsByCurrency,
+			"count":              len(balances),
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package payment_methods
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/LorenzoCampos/bolsillo-claro/internal/middleware"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/logger"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/statement"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type CreatePaymentMethodRequest struct {
+	Name       string `json:"name" binding:"required,max=100"`
+	Type       string `json:"type" binding:"required,oneof=credit_card debit_card cash bank_transfer digital_wallet"`
+	ClosingDay *int   `json:"closing_day" binding:"omitempty,gte=1,lte=31"` // Required for credit_card
+	DueDay     *int   `json:"due_day" binding:"omitempty,gte=1,lte=31"`     // Required for credit_card
+
+	// Wallet tracking (optional)
+	Currency       *string  `json:"currency" binding:"omitempty,oneof=ARS USD EUR"` // Defaults to the account currency
+	OpeningBalance *float64 `json:"opening_balance"`                                // Defaults to 0 (negative = debt)
+}
+
+// CycleResponse is a credit card statement cycle
+type CycleResponse struct {
+	Start   string `json:"start"`
+	Closing string `json:"closing"`
+	Due     string `json:"due"`
+}
+
+type PaymentMethodResponse struct {
+	ID             string         `json:"id"`
+	AccountID      string         `json:"account_id"`
+	Name           string         `json:"name"`
+	Type           string         `json:"type"`
+	ClosingDay     *int           `json:"closing_day,omitempty"`
+	DueDay         *int           `json:"due_day,omitempty"`
+	Currency       string         `json:"currency"`
+	OpeningBalance float64        `json:"opening_balance"`
+	CurrentCycle   *CycleResponse `json:"current_cycle,omitempty"` // Only credit_card: cycle a purchase made today falls into
+	IsActive       bool           `json:"is_active"`
+	CreatedAt      string         `json:"created_at"`
+	UpdatedAt      string         `json:"updated_at"`
+}
+
+const paymentMethodColumns = `id, account_id, name, type, closing_day, due_day, currency, opening_balance, is_active, created_at, updated_at`
+
+func scanPaymentMethod(row pgx.Row) (*PaymentMethodResponse, error) {
+	var pm PaymentMethodResponse
+	var createdAt, updatedAt time.Time
+
+	err := row.Scan(
+		&pm.ID, &pm.AccountID, &pm.Name, &pm.Type,
+		&pm.ClosingDay, &pm.DueDay, &pm.Currency, &pm.OpeningBalance, &pm.IsActive,
+		&createdAt, &updatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	pm.CreatedAt = createdAt.Format(time.RFC3339)
+	pm.UpdatedAt = updatedAt.Format(time.RFC3339)
+
+	if pm.ClosingDay != nil && pm.DueDay != nil {
+		cycle := statement.CycleFor(*pm.ClosingDay, *pm.DueDay, time.Now().UTC())
+		pm.CurrentCycle = newCycleResponse(cycle)
+	}
+
+	return &pm, nil
+}
+
+func newCycleResponse(cycle statement.Cycle) *CycleResponse {
+	return &CycleResponse{
+		Start:   cycle.Start.Format("2006-01-02"),
+		Closing: cycle.Closing.Format("2006-01-02"),
+		Due:     cycle.Due.Format("2006-01-02"),
+	}
+}
+
+// validateCycleDays checks that closing_day/due_day are present only for credit cards
+func validateCycleDays(paymentType string, closingDay, dueDay *int) string {
+	if paymentType == "credit_card" {
+		if closingDay == nil || dueDay == nil {
+			return "credit_card requires closing_day and due_day (1-31)"
+		}
+		return ""
+	}
+	if closingDay != nil || dueDay != nil {
+		return "closing_day and due_day only apply to credit_card"
+	}
+	return ""
+}
+
+// isDuplicateName detects the unique (account_id, LOWER(name)) violation
+func isDuplicateName(err error) bool {
+	if pgErr, ok := err.(*pgconn.PgError); ok {
+		return pgErr.Code == "23505" && pgErr.ConstraintName == "idx_payment_methods_unique_name_per_account"
+	}
+	return false
+}
+
+// CreatePaymentMethod handles POST /api/payment-methods
+func CreatePaymentMethod(db *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountID, exists := c.Get("account_id")
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "account_id not found in context"})
+			return
+		}
+
+		var req CreatePaymentMethodRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if msg := validateCycleDays(req.Type, req.ClosingDay, req.DueDay); msg != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+			return
+		}
+
+		openingBalance := 0.0
+		if req.OpeningBalance != nil {
+			openingBalance = *req.OpeningBalance
+		}
+
+		// currency NULL → moneda de la cuenta
+		query := `
+			INSERT INTO payment_methods (account_id, name, type, closing_day, due_day, currency, opening_balance)
+			VALUES ($1, $2, $3, $4, $5, COALESCE($6::currency, (SELECT currency FROM accounts WHERE id = $1)), $7)
+			RETURNING ` + paymentMethodColumns
+
+		pm, err := scanPaymentMethod(db.QueryRow(c.Request.Context(), query,
+			accountID, req.Name, req.Type, req.ClosingDay, req.DueDay, req.Currency, openingBalance,
+		))
+		if err != nil {
+			if isDuplicateName(err) {
+				c.JSON(http.StatusConflict, gin.H{"error": "a payment method with that name already exists in this account"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create payment method: " + err.Error()})
+			return
+		}
+
+		userID, _ := middleware.GetUserID(c)
+		logger.Info("payment_method.created", "Medio de pago creado", map[string]interface{}{
+			"payment_method_id": pm.ID,
+			"account_id":        accountID,
+			"user_id":           userID,
+			"type":              pm.Type,
+			"ip":                c.ClientIP(),
+		})
+
+		c.JSON(http.StatusCreated, pm)
+	}
+}
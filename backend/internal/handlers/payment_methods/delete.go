@@ -0,0 +1,83 @@
+package payment_methods
+
+import (
+	"net/http"
+
+	"github.com/LorenzoCampos/bolsillo-claro/internal/middleware"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// DeletePaymentMethod handles DELETE /api/payment-methods/:id
+// Only allowed if no expenses/incomes reference it; otherwise deactivate it (is_active=false)
+func DeletePaymentMethod(db *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountID, exists := c.Get("account_id")
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "account_id not found in context"})
+			return
+		}
+
+		paymentMethodID := c.Param("id")
+		if paymentMethodID == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "payment_method_id is required"})
+			return
+		}
+
+		ctx := c.Request.Context()
+
+		var found bool
+		err := db.QueryRow(ctx,
+			`SELECT EXISTS(SELECT 1 FROM payment_methods WHERE id = $1 AND account_id = $2)`,
+			paymentMethodID, accountID,
+		).Scan(&found)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch payment method: " + err.Error()})
+			return
+		}
+		if !found {
+			c.JSON(http.StatusNotFound, gin.H{"error": "payment method not found"})
+			return
+		}
+
+		var expenseCount, incomeCount int
+		err = db.QueryRow(ctx, `
+			SELECT
+				(SELECT COUNT(*) FROM expenses WHERE payment_method_id = $1),
+				(SELECT COUNT(*) FROM incomes WHERE payment_method_id = $1)
+		`, paymentMethodID).Scan(&expenseCount, &incomeCount)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check payment method usage"})
+			return
+		}
+
+		if expenseCount > 0 || incomeCount > 0 {
+			c.JSON(http.StatusConflict, gin.H{
+				"error":         "cannot delete payment method with associated transactions, set is_active=false instead",
+				"expense_count": expenseCount,
+				"income_count":  incomeCount,
+			})
+			return
+		}
+
+		_, err = db.Exec(ctx, `DELETE FROM payment_methods WHERE id = $1 AND account_id = $2`, paymentMethodID, accountID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete payment method: " + err.Error()})
+			return
+		}
+
+		userID, _ := middleware.GetUserID(c)
+		logger.Info("payment_method.deleted", "Medio de pago eliminado", map[string]interface{}{
+			"payment_method_id": paymentMethodID,
+			"account_id":        accountID,
+			"user_id":           userID,
+			"ip":                c.ClientIP(),
+		})
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "payment method deleted successfully",
+			"id":      paymentMethodID,
+		})
+	}
+}
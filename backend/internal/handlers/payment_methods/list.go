@@ -0,0 +1,64 @@
+package payment_methods
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ListPaymentMethods handles GET /api/payment-methods
+// Query params: type (optional), is_active = true | false | all (default: true)
+func ListPaymentMethods(db *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountID, exists := c.Get("account_id")
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "account_id not found in context"})
+			return
+		}
+
+		query := `SELECT ` + paymentMethodColumns + ` FROM payment_methods WHERE account_id = $1`
+		args := []interface{}{accountID}
+
+		isActiveParam := c.DefaultQuery("is_active", "true")
+		if isActiveParam == "true" {
+			query += " AND is_active = true"
+		} else if isActiveParam == "false" {
+			query += " AND is_active = false"
+		}
+
+		if paymentType := c.Query("type"); paymentType != "" {
+			query += " AND type = $2"
+			args = append(args, paymentType)
+		}
+
+		query += " ORDER BY name ASC"
+
+		rows, err := db.Query(c.Request.Context(), query, args...)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch payment methods: " + err.Error()})
+			return
+		}
+		defer rows.Close()
+
+		paymentMethods := []PaymentMethodResponse{}
+		for rows.Next() {
+			pm, err := scanPaymentMethod(rows)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to parse payment method: " + err.Error()})
+				return
+			}
+			paymentMethods = append(paymentMethods, *pm)
+		}
+
+		if err := rows.Err(); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error reading payment methods"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"payment_methods": paymentMethods,
+			"count":           len(paymentMethods),
+		})
+	}
+}
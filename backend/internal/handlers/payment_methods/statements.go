@@ -0,0 +1,208 @@
+package payment_methods
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/money"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/statement"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// StatementExpense is an expense inside a card statement
+type StatementExpense struct {
+	ID                      string  `json:"id"`
+	Description             string  `json:"description"`
+	Date                    string  `json:"date"`
+	Amount                  float64 `json:"amount"`
+	Currency                string  `json:"currency"`
+	AmountInPrimaryCurrency float64 `json:"amount_in_primary_currency"`
+	CategoryName            *string `json:"category_name,omitempty"`
+	InstallmentNumber       *int    `json:"installment_number,omitempty"`
+}
+
+// Statement groups the expenses of one card cycle
+type Statement struct {
+	PeriodStart      string             `json:"period_start"`
+	ClosingDate      string             `json:"closing_date"`
+	DueDate          string             `json:"due_date"`
+	Status           string             `json:"status"`    // upcoming, open, closed (awaiting payment), past
+	TotalDue         float64            `json:"total_due"` // In primary currency
+	TotalsByCurrency map[string]float64 `json:"totals_by_currency"`
+	ExpensesCount    int                `json:"expenses_count"`
+	Expenses         []StatementExpense `json:"expenses"`
+}
+
+// GetPaymentMethodStatements handles GET /api/payment-methods/:id/statements
+// Query param: cycles (1-24, default 6) = closed/open cycles to include, counting back from the current one.
+// Later cycles that already have expenses (e.g. future installments) are always included.
+func GetPaymentMethodStatements(db *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountID, exists := c.Get("account_id")
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "account_id not found in context"})
+			return
+		}
+
+		paymentMethodID := c.Param("id")
+		if paymentMethodID == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "payment_method_id is required"})
+			return
+		}
+
+		cycles := 6
+		if v := c.Query("cycles"); v != "" {
+			parsed, err := strconv.Atoi(v)
+			if err != nil || parsed < 1 || parsed > 24 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "cycles must be a number between 1 and 24"})
+				return
+			}
+			cycles = parsed
+		}
+
+		ctx := c.Request.Context()
+
+		pm, err := scanPaymentMethod(db.QueryRow(ctx,
+			`SELECT `+paymentMethodColumns+` FROM payment_methods WHERE id = $1 AND account_id = $2`,
+			paymentMethodID, accountID,
+		))
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "payment method not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch payment method: " + err.Error()})
+			return
+		}
+
+		if pm.Type != "credit_card" || pm.ClosingDay == nil || pm.DueDay == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "statements are only available for credit_card payment methods"})
+			return
+		}
+
+		var primaryCurrency string
+		err = db.QueryRow(ctx, "SELECT currency FROM accounts WHERE id = $1", accountID).Scan(&primaryCurrency)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch account currency"})
+			return
+		}
+
+		today := time.Now().UTC().Truncate(24 * time.Hour)
+		current := statement.CycleFor(*pm.ClosingDay, *pm.DueDay, today)
+
+		// Ciclos vacíos desde el más viejo pedido hasta el actual, para que se vean aunque no tengan gastos
+		statements := map[string]*Statement{}
+		var oldestClosing time.Time
+		for i := cycles - 1; i >= 0; i-- {
+			monthStart := time.Date(current.Closing.Year(), current.Closing.Month()-time.Month(i), 1, 0, 0, 0, 0, time.UTC)
+			cycle := statement.CycleFor(*pm.ClosingDay, *pm.DueDay, monthStart)
+			if oldestClosing.IsZero() {
+				oldestClosing = cycle.Closing
+			}
+			statements[cycle.Closing.Format("2006-01-02")] = newStatement(cycle.Start, cycle.Closing, cycle.Due)
+		}
+
+		query := `
+			SELECT
+				e.id, e.description, e.date, e.amount, e.currency, e.amount_in_primary_currency,
+				ec.name, e.installment_number, e.statement_closing_date, e.statement_due_date
+			FROM expenses e
+			LEFT JOIN expense_categories ec ON e.category_id = ec.id
+			WHERE e.payment_method_id = $1
+			  AND e.account_id = $2 AND NOT e.is_draft
+			  AND e.statement_closing_date >= $3
+			ORDER BY e.statement_closing_date, e.date, e.created_at
+		`
+		rows, err := db.Query(ctx, query, paymentMethodID, accountID, oldestClosing)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch statement expenses: " + err.Error()})
+			return
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var e StatementExpense
+			var date, closing, due time.Time
+			err := rows.Scan(
+				&e.ID, &e.Description, &date, &e.Amount, &e.Currency, &e.AmountInPrimaryCurrency,
+				&e.CategoryName, &e.InstallmentNumber, &closing, &due,
+			)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to parse statement expense: " + err.Error()})
+				return
+			}
+			e.Date = date.Format("2006-01-02")
+
+			key := closing.Format("2006-01-02")
+			s, ok := statements[key]
+			if !ok {
+				// Ciclo futuro (ej: cuotas) o cerrado con otros días de cierre: usamos las fechas guardadas
+				start := statement.CycleFor(*pm.ClosingDay, *pm.DueDay, closing).Start
+				s = newStatement(start, closing, due)
+				statements[key] = s
+			}
+
+			s.Expenses = append(s.Expenses, e)
+			s.ExpensesCount++
+			s.TotalDue += e.AmountInPrimaryCurrency
+			s.TotalsByCurrency[e.Currency] += e.Amount
+		}
+
+		if err := rows.Err(); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error reading statement expenses"})
+			return
+		}
+
+		result := make([]Statement, 0, len(statements))
+		for _, s := range statements {
+			s.TotalDue = money.Round(s.TotalDue)
+			for currency, total := range s.TotalsByCurrency {
+				s.TotalsByCurrency[currency] = money.Round(total)
+			}
+			s.Status = statementStatus(s, today)
+			result = append(result, *s)
+		}
+
+		// Más reciente primero
+		sort.Slice(result, func(i, j int) bool {
+			return result[i].ClosingDate > result[j].ClosingDate
+		})
+
+		c.JSON(http.StatusOK, gin.H{
+			"payment_method":   pm,
+			"primary_currency": primaryCurrency,
+			"current_cycle":    newCycleResponse(current),
+			"statements":       result,
+			"count":            len(result),
+		})
+	}
+}
+
+func newStatement(start, closing, due time.Time) *Statement {
+	return &Statement{
+		PeriodStart:      start.Format("2006-01-02"),
+		ClosingDate:      closing.Format("2006-01-02"),
+		DueDate:          due.Format("2006-01-02"),
+		TotalsByCurrency: map[string]float64{},
+		Expenses:         []StatementExpense{},
+	}
+}
+
+// statementStatus compara las fechas del ciclo contra hoy (formato YYYY-MM-DD ordena como string)
+func statementStatus(s *Statement, today time.Time) string {
+	t := today.Format("2006-01-02")
+	switch {
+	case s.PeriodStart > t:
+		return "upcoming"
+	case s.ClosingDate >= t:
+		return "open"
+	case s.DueDate >= t:
+		return "closed"
+	default:
+		return "past"
+	}
+}
@@ -0,0 +1,224 @@
+package payment_methods
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/LorenzoCampos/bolsillo-claro/internal/middleware"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/logger"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/statement"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type UpdatePaymentMethodRequest struct {
+	Name       *string `json:"name" binding:"omitempty,max=100"`
+	Type       *string `json:"type" binding:"omitempty,oneof=credit_card debit_card cash bank_transfer digital_wallet"`
+	ClosingDay *int    `json:"closing_day" binding:"omitempty,gte=1,lte=31"`
+	DueDay     *int    `json:"due_day" binding:"omitempty,gte=1,lte=31"`
+	IsActive   *bool   `json:"is_active"`
+
+	Currency       *string  `json:"currency" binding:"omitempty,oneof=ARS USD EUR"`
+	OpeningBalance *float64 `json:"opening_balance"`
+}
+
+// UpdatePaymentMethod handles PUT /api/payment-methods/:id
+// If the cycle days (or the type) change, expenses in open statements are re-assigned
+// to their new closing/due dates. Closed statements are left untouched.
+func UpdatePaymentMethod(db *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountID, exists := c.Get("account_id")
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "account_id not found in context"})
+			return
+		}
+
+		paymentMethodID := c.Param("id")
+		if paymentMethodID == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "payment_method_id is required"})
+			return
+		}
+
+		var req UpdatePaymentMethodRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx := c.Request.Context()
+
+		current, err := scanPaymentMethod(db.QueryRow(ctx,
+			`SELECT `+paymentMethodColumns+` FROM payment_methods WHERE id = $1 AND account_id = $2`,
+			paymentMethodID, accountID,
+		))
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "payment method not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch payment method: " + err.Error()})
+			return
+		}
+
+		// Merge request over current values
+		name := current.Name
+		if req.Name != nil {
+			name = *req.Name
+		}
+		paymentType := current.Type
+		if req.Type != nil {
+			paymentType = *req.Type
+		}
+		isActive := current.IsActive
+		if req.IsActive != nil {
+			isActive = *req.IsActive
+		}
+		currency := current.Currency
+		if req.Currency != nil {
+			currency = *req.Currency
+		}
+		openingBalance := current.OpeningBalance
+		if req.OpeningBalance != nil {
+			openingBalance = *req.OpeningBalance
+		}
+
+		closingDay, dueDay := current.ClosingDay, current.DueDay
+		if paymentType != "credit_card" && req.ClosingDay == nil && req.DueDay == nil {
+			// Dejó de ser tarjeta de crédito: los días ya no aplican
+			closingDay, dueDay = nil, nil
+		}
+		if req.ClosingDay != nil {
+			closingDay = req.ClosingDay
+		}
+		if req.DueDay != nil {
+			dueDay = req.DueDay
+		}
+
+		if msg := validateCycleDays(paymentType, closingDay, dueDay); msg != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+			return
+		}
+
+		cycleChanged := !sameDay(current.ClosingDay, closingDay) || !sameDay(current.DueDay, dueDay)
+
+		tx, err := db.Begin(ctx)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to start transaction: " + err.Error()})
+			return
+		}
+		defer tx.Rollback(ctx)
+
+		updateQuery := `
+			UPDATE payment_methods SET
+				name = $1,
+				type = $2,
+				closing_day = $3,
+				due_day = $4,
+				is_active = $5,
+				currency = $8,
+				opening_balance = $9
+			WHERE id = $6 AND account_id = $7
+			RETURNING ` + paymentMethodColumns
+
+		pm, err := scanPaymentMethod(tx.QueryRow(ctx, updateQuery,
+			name, paymentType, closingDay, dueDay, isActive, paymentMethodID, accountID,
+			currency, openingBalance,
+		))
+		if err != nil {
+			if isDuplicateName(err) {
+				c.JSON(http.StatusConflict, gin.H{"error": "a payment method with that name already exists in this account"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update payment method: " + err.Error()})
+			return
+		}
+
+		rescheduled := 0
+		if cycleChanged {
+			rescheduled, err = rescheduleOpenStatements(ctx, tx, paymentMethodID, closingDay, dueDay)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update statement dates: " + err.Error()})
+				return
+			}
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to commit transaction: " + err.Error()})
+			return
+		}
+
+		userID, _ := middleware.GetUserID(c)
+		logger.Info("payment_method.updated", "Medio de pago actualizado", map[string]interface{}{
+			"payment_method_id":    paymentMethodID,
+			"account_id":           accountID,
+			"user_id":              userID,
+			"cycle_changed":        cycleChanged,
+			"rescheduled_expenses": rescheduled,
+			"ip":                   c.ClientIP(),
+		})
+
+		c.JSON(http.StatusOK, gin.H{
+			"payment_method":       pm,
+			"rescheduled_expenses": rescheduled,
+		})
+	}
+}
+
+// rescheduleOpenStatements recalcula cierre/vencimiento de los gastos cuyo resumen todavía no cerró
+func rescheduleOpenStatements(ctx context.Context, tx pgx.Tx, paymentMethodID string, closingDay, dueDay *int) (int, error) {
+	today := time.Now().UTC().Format("2006-01-02")
+
+	rows, err := tx.Query(ctx, `
+		SELECT id, date
+		FROM expenses
+		WHERE payment_method_id = $1
+		  AND COALESCE(statement_closing_date, date) >= $2
+	`, paymentMethodID, today)
+	if err != nil {
+		return 0, err
+	}
+
+	type pendingExpense struct {
+		id   string
+		date time.Time
+	}
+	var pending []pendingExpense
+	for rows.Next() {
+		var e pendingExpense
+		if err := rows.Scan(&e.id, &e.date); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		pending = append(pending, e)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, e := range pending {
+		var closing, due *time.Time
+		if closingDay != nil && dueDay != nil {
+			cycle := statement.CycleFor(*closingDay, *dueDay, e.date)
+			closing, due = &cycle.Closing, &cycle.Due
+		}
+		_, err := tx.Exec(ctx,
+			`UPDATE expenses SET statement_closing_date = $1, statement_due_date = $2 WHERE id = $3`,
+			closing, due, e.id,
+		)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return len(pending), nil
+}
+
+func sameDay(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
@@ -0,0 +1,229 @@
+package receipts
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/LorenzoCampos/bolsillo-claro/internal/middleware"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/logger"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/receipts"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/tags"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// DraftResponse is a draft expense together with the receipt it was read from
+type DraftResponse struct {
+	ID                      string            `json:"id"`
+	FamilyMemberID          *string           `json:"family_member_id,omitempty"`
+	CategoryID              *string           `json:"category_id,omitempty"`
+	CategoryName            *string           `json:"category_name,omitempty"`
+	Description             string            `json:"description"`
+	Amount                  float64           `json:"amount"`
+	Currency                string            `json:"currency"`
+	ExchangeRate            float64           `json:"exchange_rate"`
+	AmountInPrimaryCurrency float64           `json:"amount_in_primary_currency"`
+	Date                    string            `json:"date"`
+	Tags                    []string          `json:"tags"`
+	IsDraft                 bool              `json:"is_draft"`
+	Receipt                 *receipts.Receipt `json:"receipt"` // What the OCR read (null fields = not found, the draft used a default)
+	AttachmentID            *string           `json:"attachment_id"`
+	DownloadURL             *string           `json:"download_url"`
+	OCRText                 string            `json:"ocr_text,omitempty"` // Only in the scan response
+	CreatedAt               string            `json:"created_at"`
+}
+
+const draftQuery = `
+	SELECT e.id, e.family_member_id, e.category_id, ec.name,
+	       e.description, e.amount, e.currency, e.exchange_rate, e.amount_in_primary_currency,
+	       e.date, e.is_draft, a.ocr_data, a.id, e.created_at
+	FROM expenses e
+	LEFT JOIN expense_categories ec ON e.category_id = ec.id
+	LEFT JOIN LATERAL (
+		SELECT id, ocr_data FROM attachments
+		WHERE expense_id = e.id AND ocr_provider IS NOT NULL
+		ORDER BY created_at ASC
+		LIMIT 1
+	) a ON true
+`
+
+func scanDraft(row pgx.Row) (*DraftResponse, error) {
+	var d DraftResponse
+	var date, createdAt time.Time
+
+	err := row.Scan(
+		&d.ID, &d.FamilyMemberID, &d.CategoryID, &d.CategoryName,
+		&d.Description, &d.Amount, &d.Currency, &d.ExchangeRate, &d.AmountInPrimaryCurrency,
+		&date, &d.IsDraft, &d.Receipt, &d.AttachmentID, &createdAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	d.Date = date.Format("2006-01-02")
+	d.CreatedAt = createdAt.Format(time.RFC3339)
+	if d.AttachmentID != nil {
+		url := "/api/attachments/" + *d.AttachmentID + "/download"
+		d.DownloadURL = &url
+	}
+	return &d, nil
+}
+
+// ListDrafts handles GET /api/receipts/drafts
+// Drafts waiting for confirmation, oldest first
+func ListDrafts(db *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountID, exists := c.Get("account_id")
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "account_id not found in context"})
+			return
+		}
+
+		rows, err := db.Query(c.Request.Context(), draftQuery+`
+			WHERE e.account_id = $1 AND e.is_draft
+			ORDER BY e.created_at ASC
+		`, accountID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch drafts: " + err.Error()})
+			return
+		}
+		defer rows.Close()
+
+		drafts := []DraftResponse{}
+		ids := []string{}
+		for rows.Next() {
+			draft, err := scanDraft(rows)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to parse draft: " + err.Error()})
+				return
+			}
+			drafts = append(drafts, *draft)
+			ids = append(ids, draft.ID)
+		}
+		if err := rows.Err(); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error reading drafts"})
+			return
+		}
+
+		draftTags, err := tags.Load(c.Request.Context(), db, tags.Expenses, ids)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch tags: " + err.Error()})
+			return
+		}
+		for i := range drafts {
+			drafts[i].Tags = draftTags[drafts[i].ID]
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"drafts": drafts,
+			"count":  len(drafts),
+		})
+	}
+}
+
+// ConfirmDraft handles POST /api/receipts/drafts/:id/confirm
+// The user fixes what the OCR got wrong with PUT /api/expenses/:id and then confirms;
+// from then on it's a regular expense and counts in the dashboard
+func ConfirmDraft(db *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountID, exists := c.Get("account_id")
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "account_id not found in context"})
+			return
+		}
+		userID, _ := middleware.GetUserID(c)
+		expenseID := c.Param("id")
+
+		var isDraft bool
+		err := db.QueryRow(c.Request.Context(), `
+			SELECT is_draft FROM expenses WHERE id = $1 AND account_id = $2
+		`, expenseID, accountID).Scan(&isDraft)
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "draft not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch draft: " + err.Error()})
+			return
+		}
+		if !isDraft {
+			c.JSON(http.StatusConflict, gin.H{"error": "expense is not a draft (already confirmed)"})
+			return
+		}
+
+		result, err := db.Exec(c.Request.Context(), `
+			UPDATE expenses
+			SET is_draft = FALSE, updated_at = CURRENT_TIMESTAMP
+			WHERE id = $1 AND account_id = $2 AND is_draft
+		`, expenseID, accountID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to confirm draft: " + err.Error()})
+			return
+		}
+		if result.RowsAffected() == 0 { // Confirmed by another request in between
+			c.JSON(http.StatusConflict, gin.H{"error": "expense is not a draft (already confirmed)"})
+			return
+		}
+
+		draft, err := scanDraft(db.QueryRow(c.Request.Context(), draftQuery+`WHERE e.id = $1`, expenseID))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch expense: " + err.Error()})
+			return
+		}
+		expenseTags, err := tags.Load(c.Request.Context(), db, tags.Expenses, []string{draft.ID})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch tags: " + err.Error()})
+			return
+		}
+		draft.Tags = expenseTags[draft.ID]
+
+		logger.Info("receipt.draft_confirmed", "Borrador de gasto confirmado", map[string]interface{}{
+			"expense_id": expenseID,
+			"account_id": accountID,
+			"user_id":    userID,
+			"ip":         c.ClientIP(),
+		})
+
+		c.JSON(http.StatusOK, draft)
+	}
+}
+
+// DiscardDraft handles DELETE /api/receipts/drafts/:id
+// Only deletes drafts, so a confirmed expense can't be removed from here by mistake.
+// The attachment goes with it (cascade) and its file is queued for the scheduler
+func DiscardDraft(db *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountID, exists := c.Get("account_id")
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "account_id not found in context"})
+			return
+		}
+		userID, _ := middleware.GetUserID(c)
+		expenseID := c.Param("id")
+
+		result, err := db.Exec(c.Request.Context(), `
+			DELETE FROM expenses WHERE id = $1 AND account_id = $2 AND is_draft
+		`, expenseID, accountID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to discard draft: " + err.Error()})
+			return
+		}
+		if result.RowsAffected() == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "draft not found"})
+			return
+		}
+
+		logger.Info("receipt.draft_discarded", "Borrador de gasto descartado", map[string]interface{}{
+			"expense_id": expenseID,
+			"account_id": accountID,
+			"user_id":    userID,
+			"ip":         c.ClientIP(),
+		})
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "draft discarded successfully",
+			"id":      expenseID,
+		})
+	}
+}
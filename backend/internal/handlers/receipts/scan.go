@@ -0,0 +1,243 @@
+package receipts
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/LorenzoCampos/bolsillo-claro/internal/middleware"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/attachments"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/logger"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/receipts"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/rules"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/suggest"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/tags"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// defaultDescription is used when the OCR can't find the merchant
+const defaultDescription = "Comprobante"
+
+// ScanReceipt handles POST /api/receipts/scan (multipart, field "file")
+// Reads the receipt with the OCR provider and creates a draft expense with the file attached.
+// Whatever the OCR doesn't find is filled with a default (description, today, the account currency);
+// only the total is required
+func ScanReceipt(db *pgxpool.Pool, store attachments.Storage, provider receipts.OCRProvider) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountID, exists := c.Get("account_id")
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "account_id not found in context"})
+			return
+		}
+		userID, _ := middleware.GetUserID(c)
+		ctx := c.Request.Context()
+
+		file, err := attachments.FromRequest(c.Writer, c.Request)
+		if err != nil {
+			c.JSON(attachments.HTTPStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		text, err := provider.Text(ctx, file.Data, file.ContentType)
+		if err == receipts.ErrOCRUnavailable {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+			return
+		}
+		if err == receipts.ErrUnsupportedFormat {
+			c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to read receipt: " + err.Error()})
+			return
+		}
+
+		text = strings.ReplaceAll(text, "\x00", "") // PostgreSQL TEXT doesn't accept NUL
+		receipt := receipts.Parse(text, time.Now())
+		if receipt.Total == nil {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{
+				"error":      "could not find the total in the receipt",
+				"suggestion": "create the expense manually with POST /api/expenses",
+				"receipt":    receipt,
+				"ocr_text":   text,
+			})
+			return
+		}
+
+		var primaryCurrency string
+		err = db.QueryRow(ctx, `SELECT currency FROM accounts WHERE id = $1`, accountID).Scan(&primaryCurrency)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get account currency"})
+			return
+		}
+
+		description := defaultDescription
+		if receipt.Merchant != nil {
+			description = *receipt.Merchant
+		}
+		date := time.Now().Format("2006-01-02")
+		if receipt.Date != nil {
+			date = *receipt.Date
+		}
+		currency := primaryCurrency
+		if receipt.Currency != nil {
+			currency = *receipt.Currency
+		}
+		amount := *receipt.Total
+
+		// Receipts rarely carry a rate: take the latest one up to the receipt date
+		exchangeRate := 1.0
+		if currency != primaryCurrency {
+			err = db.QueryRow(ctx, `
+				SELECT rate FROM exchange_rates
+				WHERE from_currency = $1 AND to_currency = $2 AND rate_date <= $3
+				ORDER BY rate_date DESC, created_at DESC
+				LIMIT 1
+			`, currency, primaryCurrency, date).Scan(&exchangeRate)
+			if err != nil {
+				c.JSON(http.StatusUnprocessableEntity, gin.H{
+					"error":      "no exchange rate found for this date",
+					"suggestion": "create the expense manually with POST /api/expenses providing 'exchange_rate' or 'amount_in_primary_currency'",
+					"receipt":    receipt,
+					"details": map[string]string{
+						"from_currency": currency,
+						"to_currency":   primaryCurrency,
+						"date":          date,
+					},
+				})
+				return
+			}
+		}
+
+		// Same categorization as a manual expense: rules first, then a confident suggestion from the history
+		movement := rules.Movement{Description: description, Amount: amount, Currency: currency}
+		appliedRules, err := rules.Categorize(ctx, db, accountID, rules.Expense, &movement)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to apply categorization rules: " + err.Error()})
+			return
+		}
+		if movement.CategoryID == nil && receipt.Merchant != nil {
+			suggestions, err := suggest.Suggest(ctx, db, accountID, description)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to suggest category: " + err.Error()})
+				return
+			}
+			if len(suggestions) > 0 && suggestions[0].IsConfident() {
+				movement.CategoryID = &suggestions[0].CategoryID
+			}
+		}
+
+		key := attachments.Key(accountID.(string), file.ContentType)
+		if err := store.Put(ctx, key, file.Data, file.ContentType); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to store file: " + err.Error()})
+			return
+		}
+
+		expenseID, err := createDraft(ctx, db, draftInput{
+			accountID:    accountID,
+			userID:       userID,
+			movement:     movement,
+			date:         date,
+			exchangeRate: exchangeRate,
+			file:         file,
+			storage:      store.Name(),
+			key:          key,
+			provider:     provider.Name(),
+			text:         text,
+			receipt:      receipt,
+		})
+		if err != nil {
+			store.Delete(ctx, key) // Best effort: without the row nobody can reach the file
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create draft: " + err.Error()})
+			return
+		}
+
+		draft, err := scanDraft(db.QueryRow(ctx, draftQuery+`WHERE e.id = $1`, expenseID))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch draft: " + err.Error()})
+			return
+		}
+		draft.Tags = movement.Tags
+		if draft.Tags == nil {
+			draft.Tags = []string{}
+		}
+		draft.OCRText = text
+
+		logger.Info("receipt.scanned", "Comprobante escaneado, borrador creado", map[string]interface{}{
+			"expense_id":     expenseID,
+			"attachment_id":  draft.AttachmentID,
+			"ocr_provider":   provider.Name(),
+			"found_merchant": receipt.Merchant != nil,
+			"found_date":     receipt.Date != nil,
+			"found_currency": receipt.Currency != nil,
+			"applied_rules":  appliedRules,
+			"account_id":     accountID,
+			"user_id":        userID,
+			"ip":             c.ClientIP(),
+		})
+
+		c.JSON(http.StatusCreated, draft)
+	}
+}
+
+type draftInput struct {
+	accountID    interface{}
+	userID       string
+	movement     rules.Movement
+	date         string
+	exchangeRate float64
+	file         *attachments.File
+	storage      string
+	key          string
+	provider     string
+	text         string
+	receipt      receipts.Receipt
+}
+
+// createDraft inserts the draft expense, its tags and the attachment in one transaction
+func createDraft(ctx context.Context, db *pgxpool.Pool, in draftInput) (string, error) {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback(ctx)
+
+	m := in.movement
+	var expenseID string
+	err = tx.QueryRow(ctx, `
+		INSERT INTO expenses (
+			account_id, family_member_id, category_id, description,
+			amount, currency, exchange_rate, amount_in_primary_currency,
+			expense_type, date, is_draft
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, 'one-time', $9, TRUE)
+		RETURNING id
+	`, in.accountID, m.FamilyMemberID, m.CategoryID, m.Description,
+		m.Amount, m.Currency, in.exchangeRate, m.Amount*in.exchangeRate,
+		in.date,
+	).Scan(&expenseID)
+	if err != nil {
+		return "", err
+	}
+
+	if len(m.Tags) > 0 {
+		if err := tags.Set(ctx, tx, tags.Expenses, expenseID, in.accountID, m.Tags); err != nil {
+			return "", err
+		}
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO attachments (
+			account_id, expense_id, storage_backend, storage_key, filename, content_type, size_bytes, sha256, uploaded_by,
+			ocr_provider, ocr_text, ocr_data
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`, in.accountID, expenseID, in.storage, in.key, in.file.Filename, in.file.ContentType, len(in.file.Data), in.file.SHA256, in.userID,
+		in.provider, in.text, in.receipt,
+	)
+	if err != nil {
+		return "", err
+	}
+
+	return expenseID, tx.Commit(ctx)
+}
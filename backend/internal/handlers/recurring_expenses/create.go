@@ -8,6 +8,7 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/LorenzoCampos/bolsillo-claro/internal/middleware"
 	"github.com/LorenzoCampos/bolsillo-claro/pkg/logger"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/tags"
 )
 
 // CreateRecurringExpenseRequest representa el JSON para crear un gasto recurrente
@@ -17,6 +18,8 @@ type CreateRecurringExpenseRequest struct {
 	Currency          string   `json:"currency" binding:"required,oneof=ARS USD EUR"`
 	CategoryID        *string  `json:"category_id"`
 	FamilyMemberID    *string  `json:"family_member_id"`
+	PaymentMethodID   *string  `json:"payment_method_id"` // Se copia a cada movimiento generado
+	Tags              []string `json:"tags"`              // Se copian a cada movimiento generado
 	
 	// Recurrence configuration
 	RecurrenceFrequency   string `json:"recurrence_frequency" binding:"required,oneof=daily weekly monthly yearly"`
@@ -45,6 +48,7 @@ type CreateRecurringExpenseResponse struct {
 	CategoryName              *string  `json:"category_name,omitempty"`
 	FamilyMemberID            *string  `json:"family_member_id,omitempty"`
 	FamilyMemberName          *string  `json:"family_member_name,omitempty"`
+	PaymentMethodID           *string  `json:"payment_method_id,omitempty"`
 	RecurrenceFrequency       string   `json:"recurrence_frequency"`
 	RecurrenceInterval        int      `json:"recurrence_interval"`
 	RecurrenceDayOfMonth      *int     `json:"recurrence_day_of_month,omitempty"`
@@ -56,6 +60,7 @@ type CreateRecurringExpenseResponse struct {
 	ExchangeRate              *float64 `json:"exchange_rate,omitempty"`
 	AmountInPrimaryCurrency   *float64 `json:"amount_in_primary_currency,omitempty"`
 	IsActive                  bool     `json:"is_active"`
+	Tags                      []string `json:"tags"`
 	CreatedAt                 string   `json:"created_at"`
 }
 
@@ -152,6 +157,16 @@ func CreateRecurringExpense(pool *pgxpool.Pool) gin.HandlerFunc {
 			return
 		}
 
+		// Normalizar tags ("Vacaciones 2026" → "vacaciones-2026")
+		tagNames, err := tags.NormalizeAll(req.Tags)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Tags inválidos",
+				"details": err.Error(),
+			})
+			return
+		}
+
 		// Validar family_member_id si existe
 		if req.FamilyMemberID != nil {
 			var memberExists bool
@@ -170,6 +185,24 @@ func CreateRecurringExpense(pool *pgxpool.Pool) gin.HandlerFunc {
 			}
 		}
 
+		// Validar payment_method_id si existe
+		if req.PaymentMethodID != nil {
+			var paymentMethodExists bool
+			checkPaymentMethodQuery := `
+				SELECT EXISTS(
+					SELECT 1 FROM payment_methods
+					WHERE id = $1 AND account_id = $2 AND is_active = true
+				)
+			`
+			err := pool.QueryRow(ctx, checkPaymentMethodQuery, *req.PaymentMethodID, accountID).Scan(&paymentMethodExists)
+			if err != nil || !paymentMethodExists {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": "payment_method_id no pertenece a esta cuenta o está inactivo",
+				})
+				return
+			}
+		}
+
 		// Obtener moneda primaria de la cuenta
 		var primaryCurrency string
 		accountQuery := "SELECT currency FROM accounts WHERE id = $1"
@@ -235,14 +268,25 @@ func CreateRecurringExpense(pool *pgxpool.Pool) gin.HandlerFunc {
 			interval = req.RecurrenceInterval
 		}
 
+		// El template y su versión 1 se crean en la misma transacción
+		tx, err := pool.Begin(ctx)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Error iniciando transacción",
+				"details": err.Error(),
+			})
+			return
+		}
+		defer tx.Rollback(ctx)
+
 		// INSERT en recurring_expenses
 		insertQuery := `
 			INSERT INTO recurring_expenses (
 				account_id, description, amount, currency, category_id, family_member_id,
 				recurrence_frequency, recurrence_interval, recurrence_day_of_month, recurrence_day_of_week,
 				start_date, end_date, total_occurrences,
-				exchange_rate, amount_in_primary_currency
-			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+				exchange_rate, amount_in_primary_currency, payment_method_id
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
 			RETURNING id, current_occurrence, is_active, created_at
 		`
 
@@ -251,7 +295,7 @@ func CreateRecurringExpense(pool *pgxpool.Pool) gin.HandlerFunc {
 		var isActive bool
 		var createdAt time.Time
 
-		err = pool.QueryRow(
+		err = tx.QueryRow(
 			ctx,
 			insertQuery,
 			accountID,
@@ -269,6 +313,7 @@ func CreateRecurringExpense(pool *pgxpool.Pool) gin.HandlerFunc {
 			req.TotalOccurrences,
 			exchangeRate,
 			amountInPrimaryCurrency,
+			req.PaymentMethodID,
 		).Scan(&recurringID, &currentOccurrence, &isActive, &createdAt)
 
 		if err != nil {
@@ -279,6 +324,40 @@ func CreateRecurringExpense(pool *pgxpool.Pool) gin.HandlerFunc {
 			return
 		}
 
+		// Versión 1 del template (base del historial de precios)
+		err = insertInitialVersion(ctx, tx, recurringID, startDate, templateVersion{
+			Description:             req.Description,
+			Amount:                  req.Amount,
+			Currency:                req.Currency,
+			CategoryID:              req.CategoryID,
+			FamilyMemberID:          req.FamilyMemberID,
+			ExchangeRate:            &exchangeRate,
+			AmountInPrimaryCurrency: &amountInPrimaryCurrency,
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Error creando versión inicial del gasto recurrente",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		if err := tags.Set(ctx, tx, tags.RecurringExpenses, recurringID, accountID, tagNames); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Error guardando tags",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Error confirmando transacción",
+				"details": err.Error(),
+			})
+			return
+		}
+
 		// Obtener nombres de category y family_member si existen (para response)
 		var categoryName *string
 		var familyMemberName *string
@@ -324,6 +403,7 @@ func CreateRecurringExpense(pool *pgxpool.Pool) gin.HandlerFunc {
 			CategoryName:            categoryName,
 			FamilyMemberID:          req.FamilyMemberID,
 			FamilyMemberName:        familyMemberName,
+			PaymentMethodID:         req.PaymentMethodID,
 			RecurrenceFrequency:     req.RecurrenceFrequency,
 			RecurrenceInterval:      interval,
 			RecurrenceDayOfMonth:    req.RecurrenceDayOfMonth,
@@ -335,6 +415,7 @@ func CreateRecurringExpense(pool *pgxpool.Pool) gin.HandlerFunc {
 			ExchangeRate:            &exchangeRate,
 			AmountInPrimaryCurrency: &amountInPrimaryCurrency,
 			IsActive:                isActive,
+			Tags:                    tagNames,
 			CreatedAt:               createdAt.Format(time.RFC3339),
 		}
 
@@ -3,9 +3,11 @@ package recurring_expenses
 import (
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/tags"
 )
 
 // RecurringExpenseDetail representa el detalle completo de un gasto recurrente
@@ -19,6 +21,8 @@ type RecurringExpenseDetail struct {
 	CategoryName              *string  `json:"category_name,omitempty"`
 	FamilyMemberID            *string  `json:"family_member_id,omitempty"`
 	FamilyMemberName          *string  `json:"family_member_name,omitempty"`
+	PaymentMethodID           *string  `json:"payment_method_id,omitempty"`
+	PaymentMethodName         *string  `json:"payment_method_name,omitempty"`
 	RecurrenceFrequency       string   `json:"recurrence_frequency"`
 	RecurrenceInterval        int      `json:"recurrence_interval"`
 	RecurrenceDayOfMonth      *int     `json:"recurrence_day_of_month,omitempty"`
@@ -30,6 +34,8 @@ type RecurringExpenseDetail struct {
 	ExchangeRate              *float64 `json:"exchange_rate,omitempty"`
 	AmountInPrimaryCurrency   *float64 `json:"amount_in_primary_currency,omitempty"`
 	IsActive                  bool     `json:"is_active"`
+	PausedUntil               *string  `json:"paused_until,omitempty"` // Pausado hasta esta fecha (inclusive)
+	Tags                      []string `json:"tags"`
 	CreatedAt                 string   `json:"created_at"`
 	UpdatedAt                 string   `json:"updated_at"`
 	GeneratedExpensesCount    int      `json:"generated_expenses_count"` // Cuántos gastos se generaron
@@ -74,11 +80,15 @@ func GetRecurringExpense(pool *pgxpool.Pool) gin.HandlerFunc {
 				re.exchange_rate,
 				re.amount_in_primary_currency,
 				re.is_active,
+				re.paused_until,
+				re.payment_method_id,
+				pm.name AS payment_method_name,
 				re.created_at,
 				re.updated_at
 			FROM recurring_expenses re
 			LEFT JOIN expense_categories ec ON re.category_id = ec.id
 			LEFT JOIN family_members fm ON re.family_member_id = fm.id
+			LEFT JOIN payment_methods pm ON re.payment_method_id = pm.id
 			WHERE re.id = $1 AND re.account_id = $2
 		`
 
@@ -87,6 +97,7 @@ func GetRecurringExpense(pool *pgxpool.Pool) gin.HandlerFunc {
 		var dayOfMonth, dayOfWeek, totalOccurrences *int
 		var exchangeRate, amountInPrimaryCurrency *float64
 		var startDate, endDate, createdAt, updatedAt interface{}
+		var pausedUntil *time.Time
 
 		err := pool.QueryRow(ctx, query, recurringID, accountID).Scan(
 			&detail.ID,
@@ -109,6 +120,9 @@ func GetRecurringExpense(pool *pgxpool.Pool) gin.HandlerFunc {
 			&exchangeRate,
 			&amountInPrimaryCurrency,
 			&detail.IsActive,
+			&pausedUntil,
+			&detail.PaymentMethodID,
+			&detail.PaymentMethodName,
 			&createdAt,
 			&updatedAt,
 		)
@@ -141,6 +155,11 @@ func GetRecurringExpense(pool *pgxpool.Pool) gin.HandlerFunc {
 			detail.EndDate = &endDateStr
 		}
 		
+		if pausedUntil != nil {
+			pausedUntilStr := pausedUntil.Format("2006-01-02")
+			detail.PausedUntil = &pausedUntilStr
+		}
+		
 		if createdAt != nil {
 			detail.CreatedAt = fmt.Sprint(createdAt)
 		}
@@ -160,6 +179,16 @@ func GetRecurringExpense(pool *pgxpool.Pool) gin.HandlerFunc {
 			detail.GeneratedExpensesCount = 0
 		}
 
+		tagsByID, err := tags.Load(ctx, pool, tags.RecurringExpenses, []string{recurringID})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Error obteniendo tags",
+				"details": err.Error(),
+			})
+			return
+		}
+		detail.Tags = tagsByID[recurringID]
+
 		c.JSON(http.StatusOK, detail)
 	}
 }
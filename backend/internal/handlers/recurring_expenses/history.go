@@ -0,0 +1,225 @@
+package recurring_expenses
+
+import (
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// GeneratedExpenseItem es un gasto generado por una versión del template
+type GeneratedExpenseItem struct {
+	ID                      string  `json:"id"`
+	Date                    string  `json:"date"`
+	Amount                  float64 `json:"amount"`
+	AmountInPrimaryCurrency float64 `json:"amount_in_primary_currency"`
+}
+
+// TemplateVersionItem representa una versión del template en el historial
+type TemplateVersionItem struct {
+	ID                      string                 `json:"id"`
+	VersionNumber           int                    `json:"version_number"`
+	EffectiveFrom           string                 `json:"effective_from"`
+	EffectiveTo             *string                `json:"effective_to"` // null = vigente sin fin (última versión)
+	Description             string                 `json:"description"`
+	Amount                  float64                `json:"amount"`
+	Currency                string                 `json:"currency"`
+	ExchangeRate            *float64               `json:"exchange_rate,omitempty"`
+	AmountInPrimaryCurrency *float64               `json:"amount_in_primary_currency,omitempty"`
+	CategoryID              *string                `json:"category_id,omitempty"`
+	CategoryName            *string                `json:"category_name,omitempty"`
+	FamilyMemberID          *string                `json:"family_member_id,omitempty"`
+	FamilyMemberName        *string                `json:"family_member_name,omitempty"`
+	IsCurrent               bool                   `json:"is_current"`
+	PreviousAmount          *float64               `json:"previous_amount,omitempty"`
+	AmountChange            *float64               `json:"amount_change,omitempty"`
+	AmountChangePercentage  *float64               `json:"amount_change_percentage,omitempty"`
+	GeneratedExpenses       []GeneratedExpenseItem `json:"generated_expenses"`
+	GeneratedCount          int                    `json:"generated_count"`
+	GeneratedTotal          float64                `json:"generated_total"`
+}
+
+// GetRecurringExpenseHistory maneja GET /api/recurring-expenses/:id/history
+// Devuelve las versiones del template (cambios de monto en el tiempo) con los gastos generados por cada una
+func GetRecurringExpenseHistory(pool *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		recurringID := c.Param("id")
+
+		accountID, exists := c.Get("account_id")
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "X-Account-ID header requerido",
+			})
+			return
+		}
+
+		ctx := c.Request.Context()
+		today := time.Now().UTC().Truncate(24 * time.Hour)
+
+		var description string
+		templateQuery := "SELECT description FROM recurring_expenses WHERE id = $1 AND account_id = $2"
+		err := pool.QueryRow(ctx, templateQuery, recurringID, accountID).Scan(&description)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Gasto recurrente no encontrado",
+			})
+			return
+		}
+
+		versionsQuery := `
+			SELECT
+				v.id, v.version_number, v.effective_from, v.description, v.amount, v.currency,
+				v.exchange_rate, v.amount_in_primary_currency,
+				v.category_id, ec.name,
+				v.family_member_id, fm.name
+			FROM recurring_expense_versions v
+			LEFT JOIN expense_categories ec ON v.category_id = ec.id
+			LEFT JOIN family_members fm ON v.family_member_id = fm.id
+			WHERE v.recurring_expense_id = $1
+			ORDER BY v.effective_from ASC
+		`
+		rows, err := pool.Query(ctx, versionsQuery, recurringID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Error obteniendo historial",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		versions := []TemplateVersionItem{}
+		effectiveDates := []time.Time{}
+		for rows.Next() {
+			var v TemplateVersionItem
+			var effectiveFrom time.Time
+			err := rows.Scan(
+				&v.ID, &v.VersionNumber, &effectiveFrom, &v.Description, &v.Amount, &v.Currency,
+				&v.ExchangeRate, &v.AmountInPrimaryCurrency,
+				&v.CategoryID, &v.CategoryName,
+				&v.FamilyMemberID, &v.FamilyMemberName,
+			)
+			if err != nil {
+				rows.Close()
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error":   "Error leyendo historial",
+					"details": err.Error(),
+				})
+				return
+			}
+			v.EffectiveFrom = effectiveFrom.Format("2006-01-02")
+			v.GeneratedExpenses = []GeneratedExpenseItem{}
+			versions = append(versions, v)
+			effectiveDates = append(effectiveDates, effectiveFrom)
+		}
+		rows.Close()
+
+		// effective_to, versión vigente y variación de monto respecto a la versión anterior
+		currentIndex := -1
+		versionIndex := map[string]int{}
+		for i := range versions {
+			versionIndex[versions[i].ID] = i
+
+			if i+1 < len(versions) {
+				to := effectiveDates[i+1].AddDate(0, 0, -1).Format("2006-01-02")
+				versions[i].EffectiveTo = &to
+			}
+
+			if !effectiveDates[i].After(today) {
+				currentIndex = i
+			}
+
+			if i > 0 {
+				previous := versions[i-1].Amount
+				change := math.Round((versions[i].Amount-previous)*100) / 100
+				versions[i].PreviousAmount = &previous
+				versions[i].AmountChange = &change
+				if previous > 0 {
+					percentage := math.Round(change/previous*10000) / 100
+					versions[i].AmountChangePercentage = &percentage
+				}
+			}
+		}
+		if currentIndex == -1 && len(versions) > 0 {
+			// El template todavía no empezó: la primera versión es la que va a aplicar
+			currentIndex = 0
+		}
+		if currentIndex >= 0 {
+			versions[currentIndex].IsCurrent = true
+		}
+
+		// Gastos generados por el template, asignados a la versión que los generó
+		expensesQuery := `
+			SELECT id, date, amount, amount_in_primary_currency, recurring_expense_version_id
+			FROM expenses
+			WHERE recurring_expense_id = $1
+			ORDER BY date ASC
+		`
+		rows, err = pool.Query(ctx, expensesQuery, recurringID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Error obteniendo gastos generados",
+				"details": err.Error(),
+			})
+			return
+		}
+		defer rows.Close()
+
+		totalGenerated := 0
+		for rows.Next() {
+			var e GeneratedExpenseItem
+			var date time.Time
+			var versionID *string
+			if err := rows.Scan(&e.ID, &date, &e.Amount, &e.AmountInPrimaryCurrency, &versionID); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error":   "Error leyendo gastos generados",
+					"details": err.Error(),
+				})
+				return
+			}
+			e.Date = date.Format("2006-01-02")
+
+			// Gastos sin versión (generados antes del versionado): se asignan por fecha
+			index := -1
+			if versionID != nil {
+				if i, ok := versionIndex[*versionID]; ok {
+					index = i
+				}
+			}
+			if index == -1 {
+				for i := range effectiveDates {
+					if effectiveDates[i].After(date) {
+						break
+					}
+					index = i
+				}
+			}
+			if index == -1 {
+				continue
+			}
+
+			versions[index].GeneratedExpenses = append(versions[index].GeneratedExpenses, e)
+			versions[index].GeneratedCount++
+			versions[index].GeneratedTotal += e.Amount
+			totalGenerated++
+		}
+
+		amountChanges := 0
+		for i := range versions {
+			versions[i].GeneratedTotal = math.Round(versions[i].GeneratedTotal*100) / 100
+			if versions[i].AmountChange != nil && *versions[i].AmountChange != 0 {
+				amountChanges++
+			}
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"recurring_expense_id": recurringID,
+			"description":          description,
+			"versions":             versions,
+			"version_count":        len(versions),
+			"amount_changes":       amountChanges,
+			"generated_count":      totalGenerated,
+		})
+	}
+}
@@ -0,0 +1,538 @@
+package recurring_expenses
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/LorenzoCampos/bolsillo-claro/internal/middleware"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/logger"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/scheduler"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// UpsertOccurrenceRequest representa el JSON para saltear o modificar una ocurrencia puntual
+type UpsertOccurrenceRequest struct {
+	ExceptionType           string   `json:"exception_type" binding:"required,oneof=skip override"`
+	Amount                  *float64 `json:"amount" binding:"omitempty,gt=0"`                     // Requerido para override
+	AmountInPrimaryCurrency *float64 `json:"amount_in_primary_currency" binding:"omitempty,gt=0"` // Opcional para override
+	Note                    *string  `json:"note"`
+}
+
+// OccurrenceItem representa una ocurrencia (pasada o futura) de un template
+type OccurrenceItem struct {
+	Date           string   `json:"date"`
+	Status         string   `json:"status"` // generated, scheduled, skipped, overridden, paused
+	Amount         float64  `json:"amount"`
+	ExpenseID      *string  `json:"expense_id,omitempty"`
+	ExceptionType  *string  `json:"exception_type,omitempty"`
+	OverrideNote   *string  `json:"note,omitempty"`
+	OriginalAmount *float64 `json:"original_amount,omitempty"` // Solo si hay override
+}
+
+// occurrenceTemplate agrupa lo necesario para calcular ocurrencias de un template
+type occurrenceTemplate struct {
+	scheduler.RecurringExpenseTemplate
+	PausedUntil *time.Time
+	IsActive    bool
+}
+
+// loadOccurrenceTemplate obtiene el template verificando que pertenezca a la cuenta
+func loadOccurrenceTemplate(ctx context.Context, pool *pgxpool.Pool, recurringID string, accountID interface{}) (*occurrenceTemplate, error) {
+	query := `
+		SELECT
+			id, account_id, description, amount, currency,
+			recurrence_frequency, recurrence_interval,
+			recurrence_day_of_month, recurrence_day_of_week,
+			start_date, end_date,
+			total_occurrences, current_occurrence,
+			exchange_rate, amount_in_primary_currency,
+			paused_until, is_active
+		FROM recurring_expenses
+		WHERE id = $1 AND account_id = $2
+	`
+
+	var t occurrenceTemplate
+	err := pool.QueryRow(ctx, query, recurringID, accountID).Scan(
+		&t.ID, &t.AccountID, &t.Description, &t.Amount, &t.Currency,
+		&t.RecurrenceFrequency, &t.RecurrenceInterval,
+		&t.RecurrenceDayOfMonth, &t.RecurrenceDayOfWeek,
+		&t.StartDate, &t.EndDate,
+		&t.TotalOccurrences, &t.CurrentOccurrence,
+		&t.ExchangeRate, &t.AmountInPrimaryCurrency,
+		&t.PausedUntil, &t.IsActive,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+// ListRecurringExpenseOccurrences maneja GET /api/recurring-expenses/:id/occurrences
+// Query params: from, to (YYYY-MM-DD). Default: desde hoy hasta 90 días adelante (máximo 366 días)
+func ListRecurringExpenseOccurrences(pool *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		recurringID := c.Param("id")
+
+		// Obtener account_id del contexto
+		accountID, exists := c.Get("account_id")
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "X-Account-ID header requerido",
+			})
+			return
+		}
+
+		ctx := c.Request.Context()
+		today := time.Now().UTC().Truncate(24 * time.Hour)
+
+		// Parsear rango de fechas
+		from := today
+		to := today.AddDate(0, 0, 90)
+
+		if fromParam := c.Query("from"); fromParam != "" {
+			parsed, err := time.Parse("2006-01-02", fromParam)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": "from debe tener formato YYYY-MM-DD",
+				})
+				return
+			}
+			from = parsed
+		}
+
+		if toParam := c.Query("to"); toParam != "" {
+			parsed, err := time.Parse("2006-01-02", toParam)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": "to debe tener formato YYYY-MM-DD",
+				})
+				return
+			}
+			to = parsed
+		}
+
+		if to.Before(from) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "to debe ser mayor o igual a from",
+			})
+			return
+		}
+
+		if to.Sub(from) > 366*24*time.Hour {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "El rango máximo es de 366 días",
+			})
+			return
+		}
+
+		template, err := loadOccurrenceTemplate(ctx, pool, recurringID, accountID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Gasto recurrente no encontrado",
+			})
+			return
+		}
+
+		// Excepciones en el rango
+		exceptionsQuery := `
+			SELECT occurrence_date, exception_type, override_amount, note
+			FROM recurring_expense_exceptions
+			WHERE recurring_expense_id = $1 AND occurrence_date BETWEEN $2 AND $3
+		`
+		rows, err := pool.Query(ctx, exceptionsQuery, recurringID, from, to)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Error obteniendo excepciones",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		type exceptionRow struct {
+			exceptionType  string
+			overrideAmount *float64
+			note           *string
+		}
+		exceptions := map[string]exceptionRow{}
+		for rows.Next() {
+			var date time.Time
+			var e exceptionRow
+			if err := rows.Scan(&date, &e.exceptionType, &e.overrideAmount, &e.note); err != nil {
+				rows.Close()
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error":   "Error leyendo excepciones",
+					"details": err.Error(),
+				})
+				return
+			}
+			exceptions[date.Format("2006-01-02")] = e
+		}
+		rows.Close()
+
+		// Gastos ya generados en el rango
+		generatedQuery := `
+			SELECT id, date, amount
+			FROM expenses
+			WHERE recurring_expense_id = $1 AND date BETWEEN $2 AND $3
+		`
+		rows, err = pool.Query(ctx, generatedQuery, recurringID, from, to)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Error obteniendo gastos generados",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		type generatedRow struct {
+			id     string
+			amount float64
+		}
+		generated := map[string]generatedRow{}
+		for rows.Next() {
+			var date time.Time
+			var g generatedRow
+			if err := rows.Scan(&g.id, &date, &g.amount); err != nil {
+				rows.Close()
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error":   "Error leyendo gastos generados",
+					"details": err.Error(),
+				})
+				return
+			}
+			generated[date.Format("2006-01-02")] = g
+		}
+		rows.Close()
+
+		// Versiones del template: las ocurrencias futuras usan el monto vigente en su fecha
+		versions, err := loadTemplateVersions(ctx, pool, recurringID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Error obteniendo versiones del gasto recurrente",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		// Unir fechas calculadas + fechas con gastos generados
+		dateSet := map[string]bool{}
+		for _, d := range scheduler.ExpenseOccurrencesBetween(template.RecurringExpenseTemplate, from, to) {
+			dateSet[d.Format("2006-01-02")] = true
+		}
+		for d := range generated {
+			dateSet[d] = true
+		}
+
+		dates := make([]string, 0, len(dateSet))
+		for d := range dateSet {
+			dates = append(dates, d)
+		}
+		sort.Strings(dates)
+
+		// Ocurrencias pendientes según total_occurrences (nil = sin límite)
+		var remaining *int
+		if template.TotalOccurrences != nil {
+			r := *template.TotalOccurrences - template.CurrentOccurrence
+			remaining = &r
+		}
+
+		occurrences := []OccurrenceItem{}
+		for _, d := range dates {
+			item := OccurrenceItem{
+				Date:   d,
+				Amount: template.Amount,
+			}
+
+			if g, ok := generated[d]; ok {
+				expenseID := g.id
+				item.Status = "generated"
+				item.Amount = g.amount
+				item.ExpenseID = &expenseID
+				occurrences = append(occurrences, item)
+				continue
+			}
+
+			date, _ := time.Parse("2006-01-02", d)
+
+			if v := versionOn(versions, date); v != nil {
+				item.Amount = v.Amount
+			}
+
+			// Ocurrencias pasadas no generadas (ej: template inactivo en ese momento) no se listan
+			if date.Before(today) {
+				continue
+			}
+
+			// Un template inactivo no genera ocurrencias futuras
+			if !template.IsActive {
+				continue
+			}
+
+			item.Status = "scheduled"
+
+			if e, ok := exceptions[d]; ok {
+				exceptionType := e.exceptionType
+				item.ExceptionType = &exceptionType
+				item.OverrideNote = e.note
+
+				if e.exceptionType == "skip" {
+					item.Status = "skipped"
+				} else {
+					original := item.Amount
+					item.Status = "overridden"
+					item.Amount = *e.overrideAmount
+					item.OriginalAmount = &original
+				}
+			}
+
+			if template.PausedUntil != nil && !date.After(*template.PausedUntil) {
+				item.Status = "paused"
+			}
+
+			// Las ocurrencias salteadas/pausadas no consumen total_occurrences
+			if item.Status == "scheduled" || item.Status == "overridden" {
+				if remaining != nil {
+					if *remaining <= 0 {
+						continue
+					}
+					*remaining--
+				}
+			}
+
+			occurrences = append(occurrences, item)
+		}
+
+		var pausedUntil *string
+		if template.PausedUntil != nil {
+			p := template.PausedUntil.Format("2006-01-02")
+			pausedUntil = &p
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"recurring_expense_id": recurringID,
+			"is_active":            template.IsActive,
+			"paused_until":         pausedUntil,
+			"from":                 from.Format("2006-01-02"),
+			"to":                   to.Format("2006-01-02"),
+			"occurrences":          occurrences,
+			"count":                len(occurrences),
+		})
+	}
+}
+
+// UpsertRecurringExpenseOccurrence maneja PUT /api/recurring-expenses/:id/occurrences/:date
+// Crea o reemplaza la excepción (skip u override) de UNA ocurrencia sin modificar el template
+func UpsertRecurringExpenseOccurrence(pool *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		recurringID := c.Param("id")
+		dateParam := c.Param("date")
+
+		var req UpsertOccurrenceRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Datos inválidos",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		// Obtener account_id del contexto
+		accountID, exists := c.Get("account_id")
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "X-Account-ID header requerido",
+			})
+			return
+		}
+
+		userID, _ := middleware.GetUserID(c)
+
+		ctx := c.Request.Context()
+
+		occurrenceDate, err := time.Parse("2006-01-02", dateParam)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "La fecha de la ocurrencia debe tener formato YYYY-MM-DD",
+			})
+			return
+		}
+
+		// Validación de negocio: override requiere amount, skip no acepta montos
+		if req.ExceptionType == "override" && req.Amount == nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "exception_type=override requiere amount",
+			})
+			return
+		}
+
+		if req.ExceptionType == "skip" && (req.Amount != nil || req.AmountInPrimaryCurrency != nil) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "exception_type=skip no acepta amount ni amount_in_primary_currency",
+			})
+			return
+		}
+
+		template, err := loadOccurrenceTemplate(ctx, pool, recurringID, accountID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Gasto recurrente no encontrado",
+			})
+			return
+		}
+
+		// No se pueden crear excepciones sobre ocurrencias pasadas
+		today := time.Now().UTC().Truncate(24 * time.Hour)
+		if occurrenceDate.Before(today) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Solo se pueden modificar ocurrencias de hoy en adelante",
+			})
+			return
+		}
+
+		// La fecha debe corresponder a una ocurrencia real del template
+		if len(scheduler.ExpenseOccurrencesBetween(template.RecurringExpenseTemplate, occurrenceDate, occurrenceDate)) == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "La fecha no corresponde a una ocurrencia de este gasto recurrente",
+			})
+			return
+		}
+
+		// Si ya se generó el gasto, la excepción no tendría efecto
+		var alreadyGenerated bool
+		generatedQuery := "SELECT EXISTS(SELECT 1 FROM expenses WHERE recurring_expense_id = $1 AND date = $2)"
+		err = pool.QueryRow(ctx, generatedQuery, recurringID, occurrenceDate).Scan(&alreadyGenerated)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Error verificando gastos generados",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		if alreadyGenerated {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "El gasto de esta ocurrencia ya fue generado. Editá o eliminá el gasto directamente",
+			})
+			return
+		}
+
+		upsertQuery := `
+			INSERT INTO recurring_expense_exceptions (
+				recurring_expense_id, occurrence_date, exception_type,
+				override_amount, override_amount_in_primary_currency, note
+			) VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (recurring_expense_id, occurrence_date) DO UPDATE SET
+				exception_type = EXCLUDED.exception_type,
+				override_amount = EXCLUDED.override_amount,
+				override_amount_in_primary_currency = EXCLUDED.override_amount_in_primary_currency,
+				note = EXCLUDED.note
+			RETURNING id, created_at, updated_at
+		`
+
+		var exceptionID string
+		var createdAt, updatedAt time.Time
+		err = pool.QueryRow(ctx, upsertQuery,
+			recurringID, occurrenceDate, req.ExceptionType,
+			req.Amount, req.AmountInPrimaryCurrency, req.Note,
+		).Scan(&exceptionID, &createdAt, &updatedAt)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Error guardando excepción de la ocurrencia",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		logger.Info("recurring_expense.occurrence_exception", "Excepción de ocurrencia guardada", map[string]interface{}{
+			"recurring_expense_id": recurringID,
+			"account_id":           accountID,
+			"user_id":              userID,
+			"occurrence_date":      dateParam,
+			"exception_type":       req.ExceptionType,
+			"ip":                   c.ClientIP(),
+		})
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Ocurrencia actualizada exitosamente",
+			"exception": gin.H{
+				"id":                         exceptionID,
+				"recurring_expense_id":       recurringID,
+				"occurrence_date":            dateParam,
+				"exception_type":             req.ExceptionType,
+				"amount":                     req.Amount,
+				"amount_in_primary_currency": req.AmountInPrimaryCurrency,
+				"note":                       req.Note,
+				"created_at":                 createdAt.Format(time.RFC3339),
+				"updated_at":                 updatedAt.Format(time.RFC3339),
+			},
+		})
+	}
+}
+
+// DeleteRecurringExpenseOccurrence maneja DELETE /api/recurring-expenses/:id/occurrences/:date
+// Elimina la excepción: la ocurrencia vuelve a generarse con los valores del template
+func DeleteRecurringExpenseOccurrence(pool *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		recurringID := c.Param("id")
+		dateParam := c.Param("date")
+
+		// Obtener account_id del contexto
+		accountID, exists := c.Get("account_id")
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "X-Account-ID header requerido",
+			})
+			return
+		}
+
+		userID, _ := middleware.GetUserID(c)
+
+		ctx := c.Request.Context()
+
+		if _, err := time.Parse("2006-01-02", dateParam); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "La fecha de la ocurrencia debe tener formato YYYY-MM-DD",
+			})
+			return
+		}
+
+		deleteQuery := `
+			DELETE FROM recurring_expense_exceptions ree
+			USING recurring_expenses re
+			WHERE ree.recurring_expense_id = re.id
+			  AND re.id = $1 AND re.account_id = $2
+			  AND ree.occurrence_date = $3
+		`
+		commandTag, err := pool.Exec(ctx, deleteQuery, recurringID, accountID, dateParam)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Error eliminando excepción de la ocurrencia",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		if commandTag.RowsAffected() == 0 {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "La ocurrencia no tiene excepciones",
+			})
+			return
+		}
+
+		logger.Info("recurring_expense.occurrence_exception_deleted", "Excepción de ocurrencia eliminada", map[string]interface{}{
+			"recurring_expense_id": recurringID,
+			"account_id":           accountID,
+			"user_id":              userID,
+			"occurrence_date":      dateParam,
+			"ip":                   c.ClientIP(),
+		})
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Excepción eliminada. La ocurrencia se generará con los valores del template",
+		})
+	}
+}
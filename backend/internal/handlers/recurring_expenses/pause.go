@@ -0,0 +1,140 @@
+package recurring_expenses
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/LorenzoCampos/bolsillo-claro/internal/middleware"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PauseRecurringExpenseRequest representa el JSON para pausar un template
+type PauseRecurringExpenseRequest struct {
+	PausedUntil string `json:"paused_until" binding:"required"` // YYYY-MM-DD (inclusive)
+}
+
+// PauseRecurringExpense maneja POST /api/recurring-expenses/:id/pause
+// No se generan gastos hasta paused_until (inclusive). La generación se reanuda al día siguiente
+// Las ocurrencias pausadas NO cuentan para total_occurrences
+func PauseRecurringExpense(pool *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		recurringID := c.Param("id")
+
+		var req PauseRecurringExpenseRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Datos inválidos",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		// Obtener account_id del contexto
+		accountID, exists := c.Get("account_id")
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "X-Account-ID header requerido",
+			})
+			return
+		}
+
+		userID, _ := middleware.GetUserID(c)
+
+		ctx := c.Request.Context()
+
+		pausedUntil, err := time.Parse("2006-01-02", req.PausedUntil)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "paused_until debe tener formato YYYY-MM-DD",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		today := time.Now().UTC().Truncate(24 * time.Hour)
+		if pausedUntil.Before(today) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "paused_until no puede ser una fecha pasada",
+			})
+			return
+		}
+
+		updateQuery := `
+			UPDATE recurring_expenses SET paused_until = $1
+			WHERE id = $2 AND account_id = $3
+			RETURNING updated_at
+		`
+		var updatedAt time.Time
+		err = pool.QueryRow(ctx, updateQuery, pausedUntil, recurringID, accountID).Scan(&updatedAt)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Gasto recurrente no encontrado",
+			})
+			return
+		}
+
+		logger.Info("recurring_expense.paused", "Gasto recurrente pausado", map[string]interface{}{
+			"recurring_expense_id": recurringID,
+			"account_id":           accountID,
+			"user_id":              userID,
+			"paused_until":         req.PausedUntil,
+			"ip":                   c.ClientIP(),
+		})
+
+		c.JSON(http.StatusOK, gin.H{
+			"message":      "Gasto recurrente pausado exitosamente",
+			"paused_until": req.PausedUntil,
+			"resumes_on":   pausedUntil.AddDate(0, 0, 1).Format("2006-01-02"),
+			"updated_at":   updatedAt.Format(time.RFC3339),
+		})
+	}
+}
+
+// ResumeRecurringExpense maneja POST /api/recurring-expenses/:id/resume
+// Elimina la pausa: la generación continúa desde la próxima ocurrencia
+func ResumeRecurringExpense(pool *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		recurringID := c.Param("id")
+
+		// Obtener account_id del contexto
+		accountID, exists := c.Get("account_id")
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "X-Account-ID header requerido",
+			})
+			return
+		}
+
+		userID, _ := middleware.GetUserID(c)
+
+		ctx := c.Request.Context()
+
+		updateQuery := `
+			UPDATE recurring_expenses SET paused_until = NULL
+			WHERE id = $1 AND account_id = $2
+			RETURNING updated_at
+		`
+		var updatedAt time.Time
+		err := pool.QueryRow(ctx, updateQuery, recurringID, accountID).Scan(&updatedAt)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Gasto recurrente no encontrado",
+			})
+			return
+		}
+
+		logger.Info("recurring_expense.resumed", "Gasto recurrente reanudado", map[string]interface{}{
+			"recurring_expense_id": recurringID,
+			"account_id":           accountID,
+			"user_id":              userID,
+			"ip":                   c.ClientIP(),
+		})
+
+		c.JSON(http.StatusOK, gin.H{
+			"message":    "Gasto recurrente reanudado exitosamente",
+			"updated_at": updatedAt.Format(time.RFC3339),
+		})
+	}
+}
@@ -1,15 +1,18 @@
 package recurring_expenses
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/LorenzoCampos/bolsillo-claro/internal/middleware"
 	"github.com/LorenzoCampos/bolsillo-claro/pkg/logger"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/tags"
 )
 
 // UpdateRecurringExpenseRequest representa el JSON para actualizar
@@ -26,11 +29,19 @@ type UpdateRecurringExpenseRequest struct {
 	EndDate                *string  `json:"end_date"` // YYYY-MM-DD o null para eliminar
 	TotalOccurrences       *int     `json:"total_occurrences" binding:"omitempty,gt=0"`
 	IsActive               *bool    `json:"is_active"` // Para activar/desactivar
+	PaymentMethodID        *string  `json:"payment_method_id"` // "" para quitarlo. Aplica a los próximos movimientos generados
+	Tags                   *[]string `json:"tags"`             // Reemplaza los tags ([] para quitarlos). Aplica a los próximos movimientos generados
+
+	// Versionado ("este y futuros"): los cambios de description/amount/currency/category_id/family_member_id
+	// crean una nueva versión desde effective_from (default: hoy)
+	EffectiveFrom           *string  `json:"effective_from"` // YYYY-MM-DD
+	ExchangeRate            *float64 `json:"exchange_rate" binding:"omitempty,gt=0"`
+	AmountInPrimaryCurrency *float64 `json:"amount_in_primary_currency" binding:"omitempty,gt=0"`
 }
 
 // UpdateRecurringExpense maneja PUT /api/recurring-expenses/:id
 // IMPORTANTE: Actualizar el template NO afecta gastos ya generados (histórico preservado)
-// Solo afecta FUTUROS gastos que se generen desde este template
+// Los campos versionados crean una versión nueva desde effective_from; el resto aplica de inmediato
 func UpdateRecurringExpense(pool *pgxpool.Pool) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		recurringID := c.Param("id")
@@ -75,6 +86,33 @@ func UpdateRecurringExpense(pool *pgxpool.Pool) gin.HandlerFunc {
 			return
 		}
 
+		today := time.Now().UTC().Truncate(24 * time.Hour)
+
+		// ¿Cambia algún campo versionado? (monto, moneda, descripción, categoría, miembro)
+		versionedChange := req.Description != nil || req.Amount != nil || req.Currency != nil ||
+			req.CategoryID != nil || req.FamilyMemberID != nil ||
+			req.ExchangeRate != nil || req.AmountInPrimaryCurrency != nil
+
+		if !versionedChange && req.EffectiveFrom != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "effective_from solo aplica a cambios de description, amount, currency, category_id o family_member_id",
+			})
+			return
+		}
+
+		// Normalizar tags (nil = no se tocan)
+		var tagNames []string
+		if req.Tags != nil {
+			tagNames, err = tags.NormalizeAll(*req.Tags)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error":   "Tags inválidos",
+					"details": err.Error(),
+				})
+				return
+			}
+		}
+
 		// Validar family_member_id si se está actualizando
 		if req.FamilyMemberID != nil && *req.FamilyMemberID != "" {
 			var memberExists bool
@@ -93,6 +131,24 @@ func UpdateRecurringExpense(pool *pgxpool.Pool) gin.HandlerFunc {
 			}
 		}
 
+		// Validar payment_method_id si se está actualizando
+		if req.PaymentMethodID != nil && *req.PaymentMethodID != "" {
+			var paymentMethodExists bool
+			checkPaymentMethodQuery := `
+				SELECT EXISTS(
+					SELECT 1 FROM payment_methods
+					WHERE id = $1 AND account_id = $2 AND is_active = true
+				)
+			`
+			err := pool.QueryRow(ctx, checkPaymentMethodQuery, *req.PaymentMethodID, accountID).Scan(&paymentMethodExists)
+			if err != nil || !paymentMethodExists {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": "payment_method_id no pertenece a esta cuenta o está inactivo",
+				})
+				return
+			}
+		}
+
 		// Validar end_date formato si se está actualizando
 		var endDate *time.Time
 		if req.EndDate != nil && *req.EndDate != "" {
@@ -126,49 +182,177 @@ func UpdateRecurringExpense(pool *pgxpool.Pool) gin.HandlerFunc {
 			}
 		}
 
-		// Construir UPDATE dinámico (solo actualizar campos enviados)
-		updateFields := []string{}
-		args := []interface{}{}
-		argCount := 1
-
-		if req.Description != nil {
-			updateFields = append(updateFields, "description = $"+itoa(argCount))
-			args = append(args, *req.Description)
-			argCount++
+		tx, err := pool.Begin(ctx)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Error iniciando transacción",
+				"details": err.Error(),
+			})
+			return
 		}
+		defer tx.Rollback(ctx)
 
-		if req.Amount != nil {
-			updateFields = append(updateFields, "amount = $"+itoa(argCount))
-			args = append(args, *req.Amount)
-			argCount++
-		}
+		// Calcular la nueva versión (valores vigentes en effective_from + campos enviados)
+		var newVersion templateVersion
+		effectiveFrom := today
 
-		if req.Currency != nil {
-			updateFields = append(updateFields, "currency = $"+itoa(argCount))
-			args = append(args, *req.Currency)
-			argCount++
-		}
+		if versionedChange {
+			// Bloquear el template antes de leer sus versiones: dos ediciones simultáneas partirían
+			// de la misma versión vigente y calcularían el mismo version_number
+			_, err := tx.Exec(ctx, `SELECT id FROM recurring_expenses WHERE id = $1 FOR UPDATE`, recurringID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error":   "Error obteniendo gasto recurrente",
+					"details": err.Error(),
+				})
+				return
+			}
 
-		if req.CategoryID != nil {
-			if *req.CategoryID == "" {
-				updateFields = append(updateFields, "category_id = NULL")
-			} else {
-				updateFields = append(updateFields, "category_id = $"+itoa(argCount))
-				args = append(args, *req.CategoryID)
-				argCount++
+			if req.EffectiveFrom != nil {
+				parsed, err := time.Parse("2006-01-02", *req.EffectiveFrom)
+				if err != nil {
+					c.JSON(http.StatusBadRequest, gin.H{
+						"error":   "effective_from debe tener formato YYYY-MM-DD",
+						"details": err.Error(),
+					})
+					return
+				}
+				effectiveFrom = parsed
+			}
+
+			var startDate time.Time
+			var lastGenerated *time.Time
+			var primaryCurrency string
+			infoQuery := `
+				SELECT r.start_date,
+				       (SELECT MAX(date) FROM expenses WHERE recurring_expense_id = r.id),
+				       a.currency
+				FROM recurring_expenses r
+				JOIN accounts a ON a.id = r.account_id
+				WHERE r.id = $1
+			`
+			err = tx.QueryRow(ctx, infoQuery, recurringID).Scan(&startDate, &lastGenerated, &primaryCurrency)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error":   "Error obteniendo gasto recurrente",
+					"details": err.Error(),
+				})
+				return
+			}
+
+			// Sin effective_from el cambio aplica desde el próximo gasto que falte generar
+			if req.EffectiveFrom == nil {
+				if effectiveFrom.Before(startDate) {
+					effectiveFrom = startDate
+				}
+				if lastGenerated != nil && !effectiveFrom.After(*lastGenerated) {
+					effectiveFrom = lastGenerated.AddDate(0, 0, 1)
+				}
+			}
+
+			if effectiveFrom.Before(startDate) {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": "effective_from no puede ser anterior a start_date (" + startDate.Format("2006-01-02") + ")",
+				})
+				return
+			}
+
+			// Los gastos ya generados conservan los valores con los que se generaron
+			if lastGenerated != nil && !effectiveFrom.After(*lastGenerated) {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": "effective_from debe ser posterior al último gasto generado (" + lastGenerated.Format("2006-01-02") + ")",
+				})
+				return
+			}
+
+			versions, err := loadTemplateVersions(ctx, tx, recurringID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error":   "Error obteniendo versiones del gasto recurrente",
+					"details": err.Error(),
+				})
+				return
+			}
+
+			base := versionOn(versions, effectiveFrom)
+			if base == nil {
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error": "El gasto recurrente no tiene una versión vigente en effective_from",
+				})
+				return
 			}
-		}
 
-		if req.FamilyMemberID != nil {
-			if *req.FamilyMemberID == "" {
-				updateFields = append(updateFields, "family_member_id = NULL")
+			newVersion = *base
+			if req.Description != nil {
+				newVersion.Description = *req.Description
+			}
+			if req.Amount != nil {
+				newVersion.Amount = *req.Amount
+			}
+			if req.Currency != nil {
+				newVersion.Currency = *req.Currency
+			}
+			if req.CategoryID != nil {
+				newVersion.CategoryID = nullIfEmpty(*req.CategoryID)
+			}
+			if req.FamilyMemberID != nil {
+				newVersion.FamilyMemberID = nullIfEmpty(*req.FamilyMemberID)
+			}
+
+			// Recalcular exchange_rate y amount_in_primary_currency (Modo 3 multi-currency)
+			var exchangeRate float64
+			var amountInPrimaryCurrency float64
+
+			if newVersion.Currency == primaryCurrency {
+				// Modo 1: Misma moneda
+				exchangeRate = 1.0
+				amountInPrimaryCurrency = newVersion.Amount
+			} else if req.AmountInPrimaryCurrency != nil {
+				// Modo 3: Usuario provee amount_in_primary_currency
+				amountInPrimaryCurrency = *req.AmountInPrimaryCurrency
+				exchangeRate = amountInPrimaryCurrency / newVersion.Amount
+			} else if req.ExchangeRate != nil {
+				// Modo 2: Usuario provee exchange_rate
+				exchangeRate = *req.ExchangeRate
+				amountInPrimaryCurrency = newVersion.Amount * exchangeRate
+			} else if newVersion.Currency == base.Currency && base.ExchangeRate != nil {
+				// Misma moneda que la versión anterior: conservar su tasa
+				exchangeRate = *base.ExchangeRate
+				amountInPrimaryCurrency = newVersion.Amount * exchangeRate
 			} else {
-				updateFields = append(updateFields, "family_member_id = $"+itoa(argCount))
-				args = append(args, *req.FamilyMemberID)
-				argCount++
+				// Modo Auto: Buscar tasa en exchange_rates table
+				rateQuery := `
+					SELECT rate FROM exchange_rates 
+					WHERE from_currency = $1 AND to_currency = $2 AND rate_date = $3
+					ORDER BY created_at DESC LIMIT 1
+				`
+				err := tx.QueryRow(ctx, rateQuery, newVersion.Currency, primaryCurrency, effectiveFrom.Format("2006-01-02")).Scan(&exchangeRate)
+				if err != nil {
+					c.JSON(http.StatusBadRequest, gin.H{
+						"error": "No se encontró tasa de cambio. Proporcione exchange_rate o amount_in_primary_currency",
+					})
+					return
+				}
+				amountInPrimaryCurrency = newVersion.Amount * exchangeRate
+			}
+
+			if exchangeRate <= 0 || amountInPrimaryCurrency <= 0 {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": "exchange_rate y amount_in_primary_currency deben ser mayores a 0",
+				})
+				return
 			}
+
+			newVersion.ExchangeRate = &exchangeRate
+			newVersion.AmountInPrimaryCurrency = &amountInPrimaryCurrency
 		}
 
+		// Construir UPDATE dinámico (solo actualizar campos enviados)
+		// Los campos versionados se copian desde la versión vigente (syncTemplateWithCurrentVersion)
+		updateFields := []string{}
+		args := []interface{}{}
+		argCount := 1
+
 		if req.RecurrenceInterval != nil {
 			updateFields = append(updateFields, "recurrence_interval = $"+itoa(argCount))
 			args = append(args, *req.RecurrenceInterval)
@@ -209,23 +393,75 @@ func UpdateRecurringExpense(pool *pgxpool.Pool) gin.HandlerFunc {
 			argCount++
 		}
 
+		if req.PaymentMethodID != nil {
+			if *req.PaymentMethodID == "" {
+				updateFields = append(updateFields, "payment_method_id = NULL")
+			} else {
+				updateFields = append(updateFields, "payment_method_id = $"+itoa(argCount))
+				args = append(args, *req.PaymentMethodID)
+				argCount++
+			}
+		}
+
 		// Si no hay campos para actualizar
-		if len(updateFields) == 0 {
+		if len(updateFields) == 0 && !versionedChange && req.Tags == nil {
 			c.JSON(http.StatusBadRequest, gin.H{
 				"error": "No hay campos para actualizar",
 			})
 			return
 		}
 
-		// Agregar WHERE clause
-		args = append(args, recurringID, accountID)
-		whereClause := " WHERE id = $" + itoa(argCount) + " AND account_id = $" + itoa(argCount+1)
+		if len(updateFields) > 0 {
+			// Agregar WHERE clause
+			args = append(args, recurringID, accountID)
+			whereClause := " WHERE id = $" + itoa(argCount) + " AND account_id = $" + itoa(argCount+1)
+
+			// Construir query completo
+			updateQuery := "UPDATE recurring_expenses SET " + join(updateFields, ", ") + whereClause
+
+			_, err = tx.Exec(ctx, updateQuery, args...)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error":   "Error actualizando gasto recurrente",
+					"details": err.Error(),
+				})
+				return
+			}
+		}
+
+		var savedVersion *templateVersion
+		if versionedChange {
+			savedVersion, err = saveTemplateVersion(ctx, tx, recurringID, effectiveFrom, newVersion, req)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error":   "Error guardando versión del gasto recurrente",
+					"details": err.Error(),
+				})
+				return
+			}
 
-		// Construir query completo
-		updateQuery := "UPDATE recurring_expenses SET " + join(updateFields, ", ") + whereClause + " RETURNING updated_at"
+			err = syncTemplateWithCurrentVersion(ctx, tx, recurringID, today)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error":   "Error actualizando gasto recurrente",
+					"details": err.Error(),
+				})
+				return
+			}
+		}
+
+		if req.Tags != nil {
+			if err := tags.Set(ctx, tx, tags.RecurringExpenses, recurringID, accountID, tagNames); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error":   "Error guardando tags",
+					"details": err.Error(),
+				})
+				return
+			}
+		}
 
 		var updatedAt time.Time
-		err = pool.QueryRow(ctx, updateQuery, args...).Scan(&updatedAt)
+		err = tx.QueryRow(ctx, "SELECT updated_at FROM recurring_expenses WHERE id = $1", recurringID).Scan(&updatedAt)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error":   "Error actualizando gasto recurrente",
@@ -234,21 +470,146 @@ func UpdateRecurringExpense(pool *pgxpool.Pool) gin.HandlerFunc {
 			return
 		}
 
+		if err := tx.Commit(ctx); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Error confirmando transacción",
+				"details": err.Error(),
+			})
+			return
+		}
+
 		// Log de actualización
 		logger.Info("recurring_expense.updated", "Gasto recurrente actualizado", map[string]interface{}{
 			"recurring_expense_id": recurringID,
 			"account_id":           accountID,
 			"user_id":              userID,
 			"fields_updated":       len(updateFields),
+			"versioned":            versionedChange,
+			"tags_updated":         req.Tags != nil,
 			"ip":                   c.ClientIP(),
 		})
 
-		c.JSON(http.StatusOK, gin.H{
+		response := gin.H{
 			"message":    "Gasto recurrente actualizado exitosamente",
 			"updated_at": updatedAt.Format(time.RFC3339),
 			"note":       "Los gastos ya generados NO se modifican. Solo afecta futuros gastos.",
-		})
+		}
+
+		if req.Tags != nil {
+			response["tags"] = tagNames
+		}
+
+		if savedVersion != nil {
+			response["version"] = gin.H{
+				"id":                         savedVersion.ID,
+				"version_number":             savedVersion.VersionNumber,
+				"effective_from":             savedVersion.EffectiveFrom.Format("2006-01-02"),
+				"description":                savedVersion.Description,
+				"amount":                     savedVersion.Amount,
+				"currency":                   savedVersion.Currency,
+				"exchange_rate":              savedVersion.ExchangeRate,
+				"amount_in_primary_currency": savedVersion.AmountInPrimaryCurrency,
+			}
+		}
+
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+// saveTemplateVersion guarda la versión con effective_from (reemplaza la de esa fecha si ya existe)
+// y propaga los campos enviados a las versiones posteriores ("este y futuros")
+func saveTemplateVersion(ctx context.Context, tx pgx.Tx, recurringID string, effectiveFrom time.Time, v templateVersion, req UpdateRecurringExpenseRequest) (*templateVersion, error) {
+	upsertQuery := `
+		INSERT INTO recurring_expense_versions (
+			recurring_expense_id, version_number, effective_from,
+			description, amount, currency, category_id, family_member_id,
+			exchange_rate, amount_in_primary_currency
+		) VALUES (
+			$1,
+			(SELECT COALESCE(MAX(version_number), 0) + 1 FROM recurring_expense_versions WHERE recurring_expense_id = $1),
+			$2, $3, $4, $5, $6, $7, $8, $9
+		)
+		ON CONFLICT (recurring_expense_id, effective_from) DO UPDATE
+		SET description = EXCLUDED.description,
+		    amount = EXCLUDED.amount,
+		    currency = EXCLUDED.currency,
+		    category_id = EXCLUDED.category_id,
+		    family_member_id = EXCLUDED.family_member_id,
+		    exchange_rate = EXCLUDED.exchange_rate,
+		    amount_in_primary_currency = EXCLUDED.amount_in_primary_currency
+		RETURNING id, version_number
+	`
+	err := tx.QueryRow(ctx, upsertQuery,
+		recurringID, effectiveFrom,
+		v.Description, v.Amount, v.Currency, v.CategoryID, v.FamilyMemberID,
+		v.ExchangeRate, v.AmountInPrimaryCurrency,
+	).Scan(&v.ID, &v.VersionNumber)
+	if err != nil {
+		return nil, err
+	}
+	v.EffectiveFrom = effectiveFrom
+
+	// Propagar solo los campos enviados a las versiones posteriores
+	setFields := []string{}
+	args := []interface{}{recurringID, effectiveFrom}
+	argCount := 3
+
+	amountExpr := "amount"
+	rateExpr := "exchange_rate"
+
+	if req.Description != nil {
+		setFields = append(setFields, "description = $"+itoa(argCount))
+		args = append(args, v.Description)
+		argCount++
+	}
+	if req.CategoryID != nil {
+		setFields = append(setFields, "category_id = $"+itoa(argCount))
+		args = append(args, v.CategoryID)
+		argCount++
+	}
+	if req.FamilyMemberID != nil {
+		setFields = append(setFields, "family_member_id = $"+itoa(argCount))
+		args = append(args, v.FamilyMemberID)
+		argCount++
+	}
+	if req.Currency != nil {
+		setFields = append(setFields, "currency = $"+itoa(argCount))
+		args = append(args, v.Currency)
+		argCount++
+	}
+	if req.Amount != nil {
+		amountExpr = "$" + itoa(argCount)
+		setFields = append(setFields, "amount = "+amountExpr)
+		args = append(args, v.Amount)
+		argCount++
+	}
+	if req.Currency != nil || req.ExchangeRate != nil || req.AmountInPrimaryCurrency != nil {
+		rateExpr = "$" + itoa(argCount)
+		setFields = append(setFields, "exchange_rate = "+rateExpr)
+		args = append(args, v.ExchangeRate)
+		argCount++
+	}
+	if req.Amount != nil || req.Currency != nil || req.ExchangeRate != nil || req.AmountInPrimaryCurrency != nil {
+		setFields = append(setFields, "amount_in_primary_currency = ROUND(("+amountExpr+")::numeric * COALESCE(("+rateExpr+")::numeric, 1), 2)")
+	}
+
+	if len(setFields) > 0 {
+		propagateQuery := "UPDATE recurring_expense_versions SET " + join(setFields, ", ") +
+			" WHERE recurring_expense_id = $1 AND effective_from > $2"
+		if _, err := tx.Exec(ctx, propagateQuery, args...); err != nil {
+			return nil, err
+		}
+	}
+
+	return &v, nil
+}
+
+// nullIfEmpty convierte "" en NULL (para desasignar category_id/family_member_id)
+func nullIfEmpty(s string) *string {
+	if s == "" {
+		return nil
 	}
+	return &s
 }
 
 // Helper functions
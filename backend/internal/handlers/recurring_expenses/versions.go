@@ -0,0 +1,112 @@
+package recurring_expenses
+
+import (
+	"context"
+	"time"
+
+	"github.com/LorenzoCampos/bolsillo-claro/internal/database"
+	"github.com/jackc/pgx/v5"
+)
+
+// templateVersion es una fila de recurring_expense_versions
+// La versión vigente en una fecha es la de mayor effective_from <= fecha
+type templateVersion struct {
+	ID                      string
+	VersionNumber           int
+	EffectiveFrom           time.Time
+	Description             string
+	Amount                  float64
+	Currency                string
+	CategoryID              *string
+	FamilyMemberID          *string
+	ExchangeRate            *float64
+	AmountInPrimaryCurrency *float64
+}
+
+// loadTemplateVersions obtiene las versiones de un template ordenadas por effective_from
+func loadTemplateVersions(ctx context.Context, q database.Querier, recurringID string) ([]templateVersion, error) {
+	query := `
+		SELECT id, version_number, effective_from, description, amount, currency,
+		       category_id, family_member_id, exchange_rate, amount_in_primary_currency
+		FROM recurring_expense_versions
+		WHERE recurring_expense_id = $1
+		ORDER BY effective_from ASC
+	`
+	rows, err := q.Query(ctx, query, recurringID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	versions := []templateVersion{}
+	for rows.Next() {
+		var v templateVersion
+		err := rows.Scan(
+			&v.ID, &v.VersionNumber, &v.EffectiveFrom, &v.Description, &v.Amount, &v.Currency,
+			&v.CategoryID, &v.FamilyMemberID, &v.ExchangeRate, &v.AmountInPrimaryCurrency,
+		)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, v)
+	}
+
+	return versions, rows.Err()
+}
+
+// versionOn devuelve la versión vigente en una fecha (nil si la fecha es anterior a la primera versión)
+// versions debe venir ordenado por effective_from ASC
+func versionOn(versions []templateVersion, date time.Time) *templateVersion {
+	var current *templateVersion
+	for i := range versions {
+		if versions[i].EffectiveFrom.After(date) {
+			break
+		}
+		current = &versions[i]
+	}
+	return current
+}
+
+// insertInitialVersion crea la versión 1 de un template recién creado (effective_from = start_date)
+func insertInitialVersion(ctx context.Context, tx pgx.Tx, recurringID string, startDate time.Time, v templateVersion) error {
+	query := `
+		INSERT INTO recurring_expense_versions (
+			recurring_expense_id, version_number, effective_from,
+			description, amount, currency, category_id, family_member_id,
+			exchange_rate, amount_in_primary_currency
+		) VALUES ($1, 1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+	_, err := tx.Exec(ctx, query,
+		recurringID, startDate,
+		v.Description, v.Amount, v.Currency, v.CategoryID, v.FamilyMemberID,
+		v.ExchangeRate, v.AmountInPrimaryCurrency,
+	)
+	return err
+}
+
+// syncTemplateWithCurrentVersion copia al template los valores de la versión vigente HOY
+// Si el template todavía no empezó, usa la primera versión
+func syncTemplateWithCurrentVersion(ctx context.Context, tx pgx.Tx, recurringID string, today time.Time) error {
+	query := `
+		UPDATE recurring_expenses r
+		SET description = v.description,
+		    amount = v.amount,
+		    currency = v.currency,
+		    category_id = v.category_id,
+		    family_member_id = v.family_member_id,
+		    exchange_rate = v.exchange_rate,
+		    amount_in_primary_currency = v.amount_in_primary_currency
+		FROM (
+			SELECT description, amount, currency, category_id, family_member_id,
+			       exchange_rate, amount_in_primary_currency
+			FROM recurring_expense_versions
+			WHERE recurring_expense_id = $1
+			ORDER BY CASE WHEN effective_from <= $2 THEN effective_from END DESC NULLS LAST,
+			         effective_from ASC
+			LIMIT 1
+		) v
+		WHERE r.id = $1
+	`
+	_, err := tx.Exec(ctx, query, recurringID, today)
+	return err
+}
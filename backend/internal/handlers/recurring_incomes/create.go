@@ -8,6 +8,7 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/LorenzoCampos/bolsillo-claro/internal/middleware"
 	"github.com/LorenzoCampos/bolsillo-claro/pkg/logger"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/tags"
 )
 
 // CreateRecurringIncomeRequest representa el JSON para crear un ingreso recurrente
@@ -17,6 +18,8 @@ type CreateRecurringIncomeRequest struct {
 	Currency          string   `json:"currency" binding:"required,oneof=ARS USD EUR"`
 	CategoryID        *string  `json:"category_id"`
 	FamilyMemberID    *string  `json:"family_member_id"`
+	PaymentMethodID   *string  `json:"payment_method_id"` // Se copia a cada movimiento generado
+	Tags              []string `json:"tags"`              // Se copian a cada movimiento generado
 	
 	// Recurrence configuration
 	RecurrenceFrequency   string `json:"recurrence_frequency" binding:"required,oneof=daily weekly monthly yearly"`
@@ -45,6 +48,7 @@ type CreateRecurringIncomeResponse struct {
 	CategoryName              *string  `json:"category_name,omitempty"`
 	FamilyMemberID            *string  `json:"family_member_id,omitempty"`
 	FamilyMemberName          *string  `json:"family_member_name,omitempty"`
+	PaymentMethodID           *string  `json:"payment_method_id,omitempty"`
 	RecurrenceFrequency       string   `json:"recurrence_frequency"`
 	RecurrenceInterval        int      `json:"recurrence_interval"`
 	RecurrenceDayOfMonth      *int     `json:"recurrence_day_of_month,omitempty"`
@@ -56,6 +60,7 @@ type CreateRecurringIncomeResponse struct {
 	ExchangeRate              *float64 `json:"exchange_rate,omitempty"`
 	AmountInPrimaryCurrency   *float64 `json:"amount_in_primary_currency,omitempty"`
 	IsActive                  bool     `json:"is_active"`
+	Tags                      []string `json:"tags"`
 	CreatedAt                 string   `json:"created_at"`
 }
 
@@ -152,6 +157,16 @@ func CreateRecurringIncome(pool *pgxpool.Pool) gin.HandlerFunc {
 			return
 		}
 
+		// Normalizar tags ("Vacaciones 2026" → "vacaciones-2026")
+		tagNames, err := tags.NormalizeAll(req.Tags)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Tags inválidos",
+				"details": err.Error(),
+			})
+			return
+		}
+
 		// Validar family_member_id si existe
 		if req.FamilyMemberID != nil {
 			var memberExists bool
@@ -170,6 +185,24 @@ func CreateRecurringIncome(pool *pgxpool.Pool) gin.HandlerFunc {
 			}
 		}
 
+		// Validar payment_method_id si existe
+		if req.PaymentMethodID != nil {
+			var paymentMethodExists bool
+			checkPaymentMethodQuery := `
+				SELECT EXISTS(
+					SELECT 1 FROM payment_methods
+					WHERE id = $1 AND account_id = $2 AND is_active = true
+				)
+			`
+			err := pool.QueryRow(ctx, checkPaymentMethodQuery, *req.PaymentMethodID, accountID).Scan(&paymentMethodExists)
+			if err != nil || !paymentMethodExists {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": "payment_method_id no pertenece a esta cuenta o está inactivo",
+				})
+				return
+			}
+		}
+
 		// Obtener moneda primaria de la cuenta
 		var primaryCurrency string
 		accountQuery := "SELECT currency FROM accounts WHERE id = $1"
@@ -241,8 +274,8 @@ func CreateRecurringIncome(pool *pgxpool.Pool) gin.HandlerFunc {
 				account_id, description, amount, currency, category_id, family_member_id,
 				recurrence_frequency, recurrence_interval, recurrence_day_of_month, recurrence_day_of_week,
 				start_date, end_date, total_occurrences,
-				exchange_rate, amount_in_primary_currency
-			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+				exchange_rate, amount_in_primary_currency, payment_method_id
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
 			RETURNING id, current_occurrence, is_active, created_at
 		`
 
@@ -269,6 +302,7 @@ func CreateRecurringIncome(pool *pgxpool.Pool) gin.HandlerFunc {
 			req.TotalOccurrences,
 			exchangeRate,
 			amountInPrimaryCurrency,
+			req.PaymentMethodID,
 		).Scan(&recurringID, &currentOccurrence, &isActive, &createdAt)
 
 		if err != nil {
@@ -279,6 +313,14 @@ func CreateRecurringIncome(pool *pgxpool.Pool) gin.HandlerFunc {
 			return
 		}
 
+		if err := tags.Set(ctx, pool, tags.RecurringIncomes, recurringID, accountID, tagNames); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Error guardando tags",
+				"details": err.Error(),
+			})
+			return
+		}
+
 		// Obtener nombres de category y family_member si existen (para response)
 		var categoryName *string
 		var familyMemberName *string
@@ -324,6 +366,7 @@ func CreateRecurringIncome(pool *pgxpool.Pool) gin.HandlerFunc {
 			CategoryName:            categoryName,
 			FamilyMemberID:          req.FamilyMemberID,
 			FamilyMemberName:        familyMemberName,
+			PaymentMethodID:         req.PaymentMethodID,
 			RecurrenceFrequency:     req.RecurrenceFrequency,
 			RecurrenceInterval:      interval,
 			RecurrenceDayOfMonth:    req.RecurrenceDayOfMonth,
@@ -335,6 +378,7 @@ func CreateRecurringIncome(pool *pgxpool.Pool) gin.HandlerFunc {
 			ExchangeRate:            &exchangeRate,
 			AmountInPrimaryCurrency: &amountInPrimaryCurrency,
 			IsActive:                isActive,
+			Tags:                    tagNames,
 			CreatedAt:               createdAt.Format(time.RFC3339),
 		}
 
@@ -7,6 +7,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/tags"
 )
 
 // RecurringIncomeListItem representa un item en la lista
@@ -17,6 +18,7 @@ type RecurringIncomeListItem struct {
 	Currency                string   `json:"currency"`
 	CategoryName            *string  `json:"category_name,omitempty"`
 	FamilyMemberName        *string  `json:"family_member_name,omitempty"`
+	PaymentMethodName       *string  `json:"payment_method_name,omitempty"`
 	RecurrenceFrequency     string   `json:"recurrence_frequency"`
 	RecurrenceInterval      int      `json:"recurrence_interval"`
 	RecurrenceDayOfMonth    *int     `json:"recurrence_day_of_month,omitempty"`
@@ -26,6 +28,7 @@ type RecurringIncomeListItem struct {
 	TotalOccurrences        *int     `json:"total_occurrences,omitempty"`
 	CurrentOccurrence       int      `json:"current_occurrence"`
 	IsActive                bool     `json:"is_active"`
+	Tags                    []string `json:"tags"`
 	CreatedAt               string   `json:"created_at"`
 }
 
@@ -69,6 +72,7 @@ func ListRecurringIncomes(pool *pgxpool.Pool) gin.HandlerFunc {
 				re.currency,
 				ec.name AS category_name,
 				fm.name AS family_member_name,
+				pm.name AS payment_method_name,
 				re.recurrence_frequency,
 				re.recurrence_interval,
 				re.recurrence_day_of_month,
@@ -82,6 +86,7 @@ func ListRecurringIncomes(pool *pgxpool.Pool) gin.HandlerFunc {
 			FROM recurring_incomes re
 			LEFT JOIN income_categories ec ON re.category_id = ec.id
 			LEFT JOIN family_members fm ON re.family_member_id = fm.id
+			LEFT JOIN payment_methods pm ON re.payment_method_id = pm.id
 			WHERE re.account_id = $1
 		`
 
@@ -142,6 +147,7 @@ func ListRecurringIncomes(pool *pgxpool.Pool) gin.HandlerFunc {
 				&item.Currency,
 				&categoryName,
 				&familyMemberName,
+				&item.PaymentMethodName,
 				&item.RecurrenceFrequency,
 				&item.RecurrenceInterval,
 				&dayOfMonth,
@@ -186,6 +192,23 @@ func ListRecurringIncomes(pool *pgxpool.Pool) gin.HandlerFunc {
 			recurringExpenses = append(recurringExpenses, item)
 		}
 
+		// Tags de todos los templates de la página en una sola query
+		ids := make([]string, len(recurringExpenses))
+		for i := range recurringExpenses {
+			ids[i] = recurringExpenses[i].ID
+		}
+		tagsByID, err := tags.Load(ctx, pool, tags.RecurringIncomes, ids)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Error obteniendo tags",
+				"details": err.Error(),
+			})
+			return
+		}
+		for i := range recurringExpenses {
+			recurringExpenses[i].Tags = tagsByID[recurringExpenses[i].ID]
+		}
+
 		// Contar total (para paginación)
 		countQuery := `
 			SELECT COUNT(*) 
@@ -10,6 +10,7 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/LorenzoCampos/bolsillo-claro/internal/middleware"
 	"github.com/LorenzoCampos/bolsillo-claro/pkg/logger"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/tags"
 )
 
 // UpdateRecurringIncomeRequest representa el JSON para actualizar
@@ -26,6 +27,8 @@ type UpdateRecurringIncomeRequest struct {
 	EndDate                *string  `json:"end_date"` // YYYY-MM-DD o null para eliminar
 	TotalOccurrences       *int     `json:"total_occurrences" binding:"omitempty,gt=0"`
 	IsActive               *bool    `json:"is_active"` // Para activar/desactivar
+	PaymentMethodID        *string  `json:"payment_method_id"` // "" para quitarlo. Aplica a los próximos movimientos generados
+	Tags                   *[]string `json:"tags"`             // Reemplaza los tags ([] para quitarlos). Aplica a los próximos movimientos generados
 }
 
 // UpdateRecurringIncome maneja PUT /api/recurring-expenses/:id
@@ -75,6 +78,19 @@ func UpdateRecurringIncome(pool *pgxpool.Pool) gin.HandlerFunc {
 			return
 		}
 
+		// Normalizar tags (nil = no se tocan)
+		var tagNames []string
+		if req.Tags != nil {
+			tagNames, err = tags.NormalizeAll(*req.Tags)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error":   "Tags inválidos",
+					"details": err.Error(),
+				})
+				return
+			}
+		}
+
 		// Validar family_member_id si se está actualizando
 		if req.FamilyMemberID != nil && *req.FamilyMemberID != "" {
 			var memberExists bool
@@ -93,6 +109,24 @@ func UpdateRecurringIncome(pool *pgxpool.Pool) gin.HandlerFunc {
 			}
 		}
 
+		// Validar payment_method_id si se está actualizando
+		if req.PaymentMethodID != nil && *req.PaymentMethodID != "" {
+			var paymentMethodExists bool
+			checkPaymentMethodQuery := `
+				SELECT EXISTS(
+					SELECT 1 FROM payment_methods
+					WHERE id = $1 AND account_id = $2 AND is_active = true
+				)
+			`
+			err := pool.QueryRow(ctx, checkPaymentMethodQuery, *req.PaymentMethodID, accountID).Scan(&paymentMethodExists)
+			if err != nil || !paymentMethodExists {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": "payment_method_id no pertenece a esta cuenta o está inactivo",
+				})
+				return
+			}
+		}
+
 		// Validar end_date formato si se está actualizando
 		var endDate *time.Time
 		if req.EndDate != nil && *req.EndDate != "" {
@@ -209,13 +243,39 @@ func UpdateRecurringIncome(pool *pgxpool.Pool) gin.HandlerFunc {
 			argCount++
 		}
 
+		if req.PaymentMethodID != nil {
+			if *req.PaymentMethodID == "" {
+				updateFields = append(updateFields, "payment_method_id = NULL")
+			} else {
+				updateFields = append(updateFields, "payment_method_id = $"+itoa(argCount))
+				args = append(args, *req.PaymentMethodID)
+				argCount++
+			}
+		}
+
 		// Si no hay campos para actualizar
-		if len(updateFields) == 0 {
+		if len(updateFields) == 0 && req.Tags == nil {
 			c.JSON(http.StatusBadRequest, gin.H{
 				"error": "No hay campos para actualizar",
 			})
 			return
 		}
+		fieldsUpdated := len(updateFields)
+
+		// Si solo cambian los tags, igual tocamos updated_at
+		if len(updateFields) == 0 {
+			updateFields = append(updateFields, "updated_at = NOW()")
+		}
+
+		tx, err := pool.Begin(ctx)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Error iniciando transacción",
+				"details": err.Error(),
+			})
+			return
+		}
+		defer tx.Rollback(ctx)
 
 		// Agregar WHERE clause
 		args = append(args, recurringID, accountID)
@@ -225,7 +285,7 @@ func UpdateRecurringIncome(pool *pgxpool.Pool) gin.HandlerFunc {
 		updateQuery := "UPDATE recurring_incomes SET " + join(updateFields, ", ") + whereClause + " RETURNING updated_at"
 
 		var updatedAt time.Time
-		err = pool.QueryRow(ctx, updateQuery, args...).Scan(&updatedAt)
+		err = tx.QueryRow(ctx, updateQuery, args...).Scan(&updatedAt)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error":   "Error actualizando ingreso recurrente",
@@ -234,20 +294,45 @@ func UpdateRecurringIncome(pool *pgxpool.Pool) gin.HandlerFunc {
 			return
 		}
 
+		if req.Tags != nil {
+			if err := tags.Set(ctx, tx, tags.RecurringIncomes, recurringID, accountID, tagNames); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error":   "Error guardando tags",
+					"details": err.Error(),
+				})
+				return
+			}
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Error confirmando transacción",
+				"details": err.Error(),
+			})
+			return
+		}
+
 		// Log de actualización
 		logger.Info("recurring_expense.updated", "Ingreso recurrente actualizado", map[string]interface{}{
 			"recurring_income_id": recurringID,
 			"account_id":           accountID,
 			"user_id":              userID,
-			"fields_updated":       len(updateFields),
+			"fields_updated":       fieldsUpdated,
+			"tags_updated":         req.Tags != nil,
 			"ip":                   c.ClientIP(),
 		})
 
-		c.JSON(http.StatusOK, gin.H{
+		response := gin.H{
 			"message":    "Ingreso recurrente actualizado exitosamente",
 			"updated_at": updatedAt.Format(time.RFC3339),
 			"note":       "Los gastos ya generados NO se modifican. Solo afecta futuros gastos.",
-		})
+		}
+
+		if req.Tags != nil {
+			response["tags"] = tagNames
+		}
+
+		c.JSON(http.StatusOK, response)
 	}
 }
 
@@ -0,0 +1,347 @@
+package reports
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/money"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/splits"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// CategoryTotal represents a member's expenses in one category
+type CategoryTotal struct {
+	CategoryID    *string `json:"category_id,omitempty"`
+	CategoryName  *string `json:"category_name,omitempty"`
+	CategoryIcon  *string `json:"category_icon,omitempty"`
+	CategoryColor *string `json:"category_color,omitempty"`
+	Total         float64 `json:"total"`
+	Percentage    float64 `json:"percentage"` // Of the member's expenses
+}
+
+// MemberReport represents the income and expenses assigned to one member in the month
+type MemberReport struct {
+	FamilyMemberID     *string         `json:"family_member_id"` // nil for movements without a member
+	FamilyMemberName   string          `json:"family_member_name"`
+	IsActive           bool            `json:"is_active"`
+	TotalIncome        float64         `json:"total_income"`
+	TotalExpenses      float64         `json:"total_expenses"`
+	Net                float64         `json:"net"`
+	MonthlyAllowance   *float64        `json:"monthly_allowance"`
+	AllowanceRemaining *float64        `json:"allowance_remaining"` // Negative when overspent
+	AllowanceUsed      *float64        `json:"allowance_used_percentage"`
+	ExpensesByCategory []CategoryTotal `json:"expenses_by_category"`
+}
+
+// MembersReportResponse represents the per-member report of a month
+type MembersReportResponse struct {
+	Period          string         `json:"period"` // YYYY-MM
+	Basis           string         `json:"basis"`  // accrual or cash, same as the dashboard
+	PrimaryCurrency string         `json:"primary_currency"`
+	Members         []MemberReport `json:"members"`
+	Unassigned      MemberReport   `json:"unassigned"` // Movements without family_member_id
+	TotalIncome     float64        `json:"total_income"`
+	TotalExpenses   float64        `json:"total_expenses"`
+}
+
+// GetMembersReport handles GET /api/reports/members
+// Income, expenses and category breakdown per family member for a month, plus the allowance left.
+// Deactivated members still show up when they have movements in the month
+func GetMembersReport(db *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountID, exists := c.Get("account_id")
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "account_id not found in context"})
+			return
+		}
+
+		month := c.DefaultQuery("month", time.Now().Format("2006-01"))
+		if _, err := time.Parse("2006-01", month); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid month format, use YYYY-MM"})
+			return
+		}
+
+		basis := c.DefaultQuery("basis", "accrual")
+		expenseDate := "e.date"
+		switch basis {
+		case "accrual":
+		case "cash":
+			expenseDate = "COALESCE(e.statement_due_date, e.date)"
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "basis must be accrual or cash"})
+			return
+		}
+
+		ctx := c.Request.Context()
+
+		var primaryCurrency, accountType string
+		err := db.QueryRow(ctx, `SELECT currency, type FROM accounts WHERE id = $1`, accountID).Scan(&primaryCurrency, &accountType)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get account"})
+			return
+		}
+		if accountType != "family" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "member reports are only available for family accounts"})
+			return
+		}
+
+		// ============================================================================
+		// 1. MEMBERS (active and inactive: history must keep the deactivated ones)
+		// ============================================================================
+		rows, err := db.Query(ctx, `
+			SELECT id, name, is_active, monthly_allowance
+			FROM family_members
+			WHERE account_id = $1
+			ORDER BY created_at ASC
+		`, accountID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get members"})
+			return
+		}
+		defer rows.Close()
+
+		var order []string
+		reports := map[string]*MemberReport{}
+		for rows.Next() {
+			var id string
+			report := MemberReport{ExpensesByCategory: []CategoryTotal{}}
+			if err := rows.Scan(&id, &report.FamilyMemberName, &report.IsActive, &report.MonthlyAllowance); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to parse member"})
+				return
+			}
+			report.FamilyMemberID = &id
+			reports[id] = &report
+			order = append(order, id)
+		}
+		if err := rows.Err(); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error reading members"})
+			return
+		}
+
+		unassigned := MemberReport{FamilyMemberName: "Sin asignar", ExpensesByCategory: []CategoryTotal{}}
+		reportFor := func(memberID *string) *MemberReport {
+			if memberID != nil {
+				if report, ok := reports[*memberID]; ok {
+					return report
+				}
+			}
+			return &unassigned
+		}
+
+		// ============================================================================
+		// 2. INCOME PER MEMBER
+		// ============================================================================
+		rows, err = db.Query(ctx, `
+			SELECT family_member_id, SUM(amount_in_primary_currency)
+			FROM incomes
+			WHERE account_id = $1
+			  AND TO_CHAR(date, 'YYYY-MM') = $2
+			GROUP BY family_member_id
+		`, accountID, month)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get income per member"})
+			return
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var memberID *string
+			var total float64
+			if err := rows.Scan(&memberID, &total); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to parse member income"})
+				return
+			}
+			reportFor(memberID).TotalIncome += total
+		}
+		if err := rows.Err(); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error reading member income"})
+			return
+		}
+
+		// ============================================================================
+		// 3. EXPENSES PER MEMBER AND CATEGORY
+		// ============================================================================
+		// Category totals are accumulated per member: a split expense adds to several members
+		type categoryKey struct {
+			report   *MemberReport
+			category string // "" = no category
+		}
+		categories := map[categoryKey]*CategoryTotal{}
+		var categoryOrder []categoryKey
+		addExpense := func(report *MemberReport, cat CategoryTotal) {
+			key := categoryKey{report: report}
+			if cat.CategoryID != nil {
+				key.category = *cat.CategoryID
+			}
+			if existing, ok := categories[key]; ok {
+				existing.Total += cat.Total
+				return
+			}
+			categories[key] = &cat
+			categoryOrder = append(categoryOrder, key)
+		}
+
+		// Expenses that are not split count in full for their member
+		rows, err = db.Query(ctx, `
+			SELECT
+				e.family_member_id,
+				e.category_id,
+				ec.name,
+				ec.icon,
+				ec.color,
+				SUM(e.amount_in_primary_currency) as total
+			FROM expenses e
+			LEFT JOIN expense_categories ec ON e.category_id = ec.id
+			WHERE e.account_id = $1 AND NOT e.is_draft AND e.split_type IS NULL
+			  AND TO_CHAR(`+expenseDate+`, 'YYYY-MM') = $2
+			GROUP BY e.family_member_id, e.category_id, ec.name, ec.icon, ec.color
+		`, accountID, month)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get expenses per member"})
+			return
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var memberID *string
+			var cat CategoryTotal
+			if err := rows.Scan(&memberID, &cat.CategoryID, &cat.CategoryName, &cat.CategoryIcon, &cat.CategoryColor, &cat.Total); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to parse member expense"})
+				return
+			}
+			addExpense(reportFor(memberID), cat)
+		}
+		if err := rows.Err(); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error reading member expenses"})
+			return
+		}
+
+		// Split expenses: each member gets their share, same amounts as GET /expenses/:id/split
+		type splitExpense struct {
+			splitType string
+			amount    float64
+			category  CategoryTotal
+			shares    []splits.Share
+		}
+		splitExpenses := map[string]*splitExpense{}
+		var splitOrder []string
+
+		rows, err = db.Query(ctx, `
+			SELECT e.id, e.split_type, e.amount_in_primary_currency,
+			       e.category_id, ec.name, ec.icon, ec.color,
+			       s.family_member_id, s.percentage, s.amount
+			FROM expenses e
+			JOIN expense_splits s ON s.expense_id = e.id
+			LEFT JOIN expense_categories ec ON e.category_id = ec.id
+			WHERE e.account_id = $1 AND NOT e.is_draft AND e.split_type IS NOT NULL
+			  AND TO_CHAR(`+expenseDate+`, 'YYYY-MM') = $2
+			ORDER BY e.id, s.created_at, s.family_member_id
+		`, accountID, month)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get split expenses per member"})
+			return
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var expenseID string
+			var e splitExpense
+			var share splits.Share
+			err := rows.Scan(
+				&expenseID, &e.splitType, &e.amount,
+				&e.category.CategoryID, &e.category.CategoryName, &e.category.CategoryIcon, &e.category.CategoryColor,
+				&share.MemberID, &share.Percentage, &share.Amount,
+			)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to parse split expense"})
+				return
+			}
+			if _, ok := splitExpenses[expenseID]; !ok {
+				splitExpenses[expenseID] = &e
+				splitOrder = append(splitOrder, expenseID)
+			}
+			splitExpenses[expenseID].shares = append(splitExpenses[expenseID].shares, share)
+		}
+		if err := rows.Err(); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error reading split expenses"})
+			return
+		}
+
+		for _, id := range splitOrder {
+			e := splitExpenses[id]
+			for i, amount := range splits.Amounts(e.splitType, e.amount, e.shares) {
+				cat := e.category
+				cat.Total = amount
+				addExpense(reportFor(&e.shares[i].MemberID), cat)
+			}
+		}
+
+		for _, key := range categoryOrder {
+			cat := categories[key]
+			cat.Total = money.Round(cat.Total)
+			if cat.Total <= 0 {
+				continue
+			}
+			key.report.TotalExpenses += cat.Total
+			key.report.ExpensesByCategory = append(key.report.ExpensesByCategory, *cat)
+		}
+		sortCategories := func(report *MemberReport) {
+			sort.SliceStable(report.ExpensesByCategory, func(i, j int) bool {
+				return report.ExpensesByCategory[i].Total > report.ExpensesByCategory[j].Total
+			})
+		}
+
+		// ============================================================================
+		// 4. TOTALS, PERCENTAGES AND ALLOWANCES
+		// ============================================================================
+		response := MembersReportResponse{
+			Period:          month,
+			Basis:           basis,
+			PrimaryCurrency: primaryCurrency,
+			Members:         []MemberReport{},
+		}
+
+		finish := func(report *MemberReport) {
+			sortCategories(report)
+			report.TotalIncome = money.Round(report.TotalIncome)
+			report.TotalExpenses = money.Round(report.TotalExpenses)
+			report.Net = money.Round(report.TotalIncome - report.TotalExpenses)
+			for i := range report.ExpensesByCategory {
+				if report.TotalExpenses > 0 {
+					report.ExpensesByCategory[i].Percentage = money.Round(report.ExpensesByCategory[i].Total / report.TotalExpenses * 100)
+				}
+			}
+			if report.MonthlyAllowance != nil {
+				remaining := money.Round(*report.MonthlyAllowance - report.TotalExpenses)
+				used := money.Round(report.TotalExpenses / *report.MonthlyAllowance * 100)
+				report.AllowanceRemaining = &remaining
+				report.AllowanceUsed = &used
+			}
+			response.TotalIncome += report.TotalIncome
+			response.TotalExpenses += report.TotalExpenses
+		}
+
+		for _, id := range order {
+			report := reports[id]
+			hasMovements := report.TotalIncome != 0 || report.TotalExpenses != 0
+			if !report.IsActive && !hasMovements {
+				continue
+			}
+			finish(report)
+			response.Members = append(response.Members, *report)
+		}
+		finish(&unassigned)
+		response.Unassigned = unassigned
+
+		sort.SliceStable(response.Members, func(i, j int) bool {
+			return response.Members[i].TotalExpenses > response.Members[j].TotalExpenses
+		})
+
+		response.TotalIncome = money.Round(response.TotalIncome)
+		response.TotalExpenses = money.Round(response.TotalExpenses)
+
+		c.JSON(http.StatusOK, response)
+	}
+}
@@ -0,0 +1,195 @@
+package reports
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/money"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/tags"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// TagReport represents the movements with one tag in the range
+type TagReport struct {
+	TagID        string  `json:"tag_id"`
+	TagName      string  `json:"tag_name"`
+	ExpenseTotal float64 `json:"expense_total"`
+	ExpenseCount int     `json:"expense_count"`
+	IncomeTotal  float64 `json:"income_total"`
+	IncomeCount  int     `json:"income_count"`
+	Net          float64 `json:"net"`
+}
+
+// TagsReportResponse represents the per-tag totals of a date range
+type TagsReportResponse struct {
+	DateFrom        string      `json:"date_from"`
+	DateTo          string      `json:"date_to"`
+	PrimaryCurrency string      `json:"primary_currency"`
+	Tags            []TagReport `json:"tags"`
+}
+
+// GetTagsReport handles GET /api/reports/tags
+// Expense and income totals per tag between date_from and date_to (default: current month).
+// A movement with several tags counts in each of them, so the rows don't add up to the account total
+func GetTagsReport(db *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountID, exists := c.Get("account_id")
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "account_id not found in context"})
+			return
+		}
+
+		now := time.Now()
+		monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+		dateFrom := c.DefaultQuery("date_from", monthStart.Format("2006-01-02"))
+		dateTo := c.DefaultQuery("date_to", monthStart.AddDate(0, 1, -1).Format("2006-01-02"))
+
+		from, err := time.Parse("2006-01-02", dateFrom)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid date_from format, use YYYY-MM-DD"})
+			return
+		}
+		to, err := time.Parse("2006-01-02", dateTo)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid date_to format, use YYYY-MM-DD"})
+			return
+		}
+		if to.Before(from) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "date_to must be after or equal to date_from"})
+			return
+		}
+
+		// Optional: only these tags
+		names, _, err := tags.ParseFilter(c.Query("tags"), "")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx := c.Request.Context()
+
+		var primaryCurrency string
+		err = db.QueryRow(ctx, `SELECT currency FROM accounts WHERE id = $1`, accountID).Scan(&primaryCurrency)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get account currency"})
+			return
+		}
+
+		args := []interface{}{accountID, dateFrom, dateTo}
+		tagFilter := ""
+		if len(names) > 0 {
+			args = append(args, names)
+			tagFilter = " AND t.name = ANY($4)"
+		}
+
+		var order []string
+		reports := map[string]*TagReport{}
+		reportFor := func(id, name string) *TagReport {
+			if report, ok := reports[id]; ok {
+				return report
+			}
+			report := &TagReport{TagID: id, TagName: name}
+			reports[id] = report
+			order = append(order, id)
+			return report
+		}
+
+		// ============================================================================
+		// 1. EXPENSES PER TAG
+		// ============================================================================
+		rows, err := db.Query(ctx, `
+			SELECT t.id, t.name, COUNT(*), SUM(e.amount_in_primary_currency)
+			FROM tags t
+			JOIN expense_tags et ON et.tag_id = t.id
+			JOIN expenses e ON e.id = et.expense_id
+			WHERE t.account_id = $1 AND NOT e.is_draft
+			  AND e.date BETWEEN $2 AND $3`+tagFilter+`
+			GROUP BY t.id, t.name
+		`, args...)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get expenses per tag"})
+			return
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var id, name string
+			var count int
+			var total float64
+			if err := rows.Scan(&id, &name, &count, &total); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to parse tag expenses"})
+				return
+			}
+			report := reportFor(id, name)
+			report.ExpenseCount = count
+			report.ExpenseTotal = total
+		}
+		if err := rows.Err(); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error reading tag expenses"})
+			return
+		}
+
+		// ============================================================================
+		// 2. INCOME PER TAG
+		// ============================================================================
+		rows, err = db.Query(ctx, `
+			SELECT t.id, t.name, COUNT(*), SUM(i.amount_in_primary_currency)
+			FROM tags t
+			JOIN income_tags it ON it.tag_id = t.id
+			JOIN incomes i ON i.id = it.income_id
+			WHERE t.account_id = $1
+			  AND i.date BETWEEN $2 AND $3`+tagFilter+`
+			GROUP BY t.id, t.name
+		`, args...)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get income per tag"})
+			return
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var id, name string
+			var count int
+			var total float64
+			if err := rows.Scan(&id, &name, &count, &total); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to parse tag income"})
+				return
+			}
+			report := reportFor(id, name)
+			report.IncomeCount = count
+			report.IncomeTotal = total
+		}
+		if err := rows.Err(); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error reading tag income"})
+			return
+		}
+
+		// ============================================================================
+		// 3. ROUNDING AND ORDER (biggest spending first)
+		// ============================================================================
+		response := TagsReportResponse{
+			DateFrom:        dateFrom,
+			DateTo:          dateTo,
+			PrimaryCurrency: primaryCurrency,
+			Tags:            []TagReport{},
+		}
+		for _, id := range order {
+			report := reports[id]
+			report.ExpenseTotal = money.Round(report.ExpenseTotal)
+			report.IncomeTotal = money.Round(report.IncomeTotal)
+			report.Net = money.Round(report.IncomeTotal - report.ExpenseTotal)
+			response.Tags = append(response.Tags, *report)
+		}
+
+		sort.SliceStable(response.Tags, func(i, j int) bool {
+			if response.Tags[i].ExpenseTotal != response.Tags[j].ExpenseTotal {
+				return response.Tags[i].ExpenseTotal > response.Tags[j].ExpenseTotal
+			}
+			return response.Tags[i].TagName < response.Tags[j].TagName
+		})
+
+		c.JSON(http.StatusOK, response)
+	}
+}
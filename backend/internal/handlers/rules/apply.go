@@ -0,0 +1,271 @@
+package rules
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/LorenzoCampos/bolsillo-claro/internal/middleware"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/logger"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/rules"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/tags"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// RuleChange is an existing movement the rule would change, with its current and new values
+type RuleChange struct {
+	ID                string   `json:"id"`
+	Date              string   `json:"date"`
+	Description       string   `json:"description"`
+	Amount            float64  `json:"amount"`
+	Currency          string   `json:"currency"`
+	CategoryID        *string  `json:"category_id"`
+	NewCategoryID     *string  `json:"new_category_id"`
+	FamilyMemberID    *string  `json:"family_member_id"`
+	NewFamilyMemberID *string  `json:"new_family_member_id"`
+	AddedTags         []string `json:"added_tags"`
+}
+
+// retroactiveParams are the query params shared by dry-run and apply
+type retroactiveParams struct {
+	DateFrom  *string
+	DateTo    *string
+	Overwrite bool // true: also replace category/member already set (not only fill empty ones)
+}
+
+func parseRetroactiveParams(c *gin.Context) (retroactiveParams, string) {
+	var p retroactiveParams
+	for _, param := range []struct {
+		name   string
+		target **string
+	}{{"date_from", &p.DateFrom}, {"date_to", &p.DateTo}} {
+		value := c.Query(param.name)
+		if value == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", value); err != nil {
+			return p, "invalid " + param.name + " format, use YYYY-MM-DD"
+		}
+		*param.target = &value
+	}
+
+	switch c.Query("overwrite") {
+	case "", "false":
+	case "true":
+		p.Overwrite = true
+	default:
+		return p, "overwrite must be true or false"
+	}
+	return p, ""
+}
+
+// loadRule fetches a rule of the account (active or not), pgx.ErrNoRows if it doesn't exist
+func loadRule(ctx context.Context, db *pgxpool.Pool, ruleID string, accountID interface{}) (*rules.Rule, error) {
+	return rules.Scan(db.QueryRow(ctx,
+		`SELECT `+rules.Columns+` FROM categorization_rules WHERE id = $1 AND account_id = $2`,
+		ruleID, accountID,
+	))
+}
+
+// findChanges evaluates the rule alone (not the whole chain) against the existing movements
+// Returns how many movements match the conditions and which of them would actually change
+func findChanges(ctx context.Context, db *pgxpool.Pool, accountID interface{}, rule *rules.Rule, p retroactiveParams) (int, []RuleChange, error) {
+	table, link := "expenses", tags.Expenses
+	if rule.MovementType == rules.Income {
+		table, link = "incomes", tags.Incomes
+	}
+
+	// description_contains is pre-filtered in SQL; ILIKE wildcards can only match more, Matches decides
+	rows, err := db.Query(ctx, `
+		SELECT id, date, description, amount, currency::TEXT, payment_method_id, category_id, family_member_id
+		FROM `+table+`
+		WHERE account_id = $1
+		  AND ($2::DATE IS NULL OR date >= $2::DATE)
+		  AND ($3::DATE IS NULL OR date <= $3::DATE)
+		  AND ($4::TEXT IS NULL OR description ILIKE '%' || $4 || '%')
+		ORDER BY date DESC, created_at DESC
+	`, accountID, p.DateFrom, p.DateTo, rule.DescriptionContains)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer rows.Close()
+
+	var matched []RuleChange
+	var movements []rules.Movement
+	for rows.Next() {
+		var change RuleChange
+		var m rules.Movement
+		var date time.Time
+		if err := rows.Scan(&change.ID, &date, &m.Description, &m.Amount, &m.Currency, &m.PaymentMethodID, &m.CategoryID, &m.FamilyMemberID); err != nil {
+			return 0, nil, err
+		}
+		if !rule.Matches(m) {
+			continue
+		}
+		change.Date = date.Format("2006-01-02")
+		change.Description = m.Description
+		change.Amount = m.Amount
+		change.Currency = m.Currency
+		change.CategoryID = m.CategoryID
+		change.FamilyMemberID = m.FamilyMemberID
+		matched = append(matched, change)
+		movements = append(movements, m)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, nil, err
+	}
+
+	ids := make([]string, len(matched))
+	for i, change := range matched {
+		ids[i] = change.ID
+	}
+	tagsByID, err := tags.Load(ctx, db, link, ids)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	changes := []RuleChange{}
+	for i, change := range matched {
+		m := movements[i]
+		m.Tags = tagsByID[change.ID]
+		before := len(m.Tags)
+		if !rule.Apply(&m, p.Overwrite) {
+			continue
+		}
+		change.NewCategoryID = m.CategoryID
+		change.NewFamilyMemberID = m.FamilyMemberID
+		change.AddedTags = m.Tags[before:]
+		changes = append(changes, change)
+	}
+	return len(matched), changes, nil
+}
+
+// DryRunRule handles GET /api/rules/:id/dry-run?date_from=&date_to=&overwrite=
+// Shows which existing movements the rule would change, without changing anything
+func DryRunRule(db *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountID, exists := c.Get("account_id")
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "account_id not found in context"})
+			return
+		}
+
+		params, msg := parseRetroactiveParams(c)
+		if msg != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+			return
+		}
+
+		rule, err := loadRule(c.Request.Context(), db, c.Param("id"), accountID)
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "rule not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch rule: " + err.Error()})
+			return
+		}
+
+		matched, changes, err := findChanges(c.Request.Context(), db, accountID, rule, params)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to evaluate rule: " + err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"rule_id":   rule.ID,
+			"overwrite": params.Overwrite,
+			"matched":   matched,
+			"changes":   changes,
+			"count":     len(changes),
+		})
+	}
+}
+
+// ApplyRule handles POST /api/rules/:id/apply?date_from=&date_to=&overwrite=
+// Applies the rule retroactively to the movements the dry-run lists, in a single transaction
+func ApplyRule(db *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountID, exists := c.Get("account_id")
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "account_id not found in context"})
+			return
+		}
+
+		params, msg := parseRetroactiveParams(c)
+		if msg != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+			return
+		}
+
+		ctx := c.Request.Context()
+
+		rule, err := loadRule(ctx, db, c.Param("id"), accountID)
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "rule not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch rule: " + err.Error()})
+			return
+		}
+
+		_, changes, err := findChanges(ctx, db, accountID, rule, params)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to evaluate rule: " + err.Error()})
+			return
+		}
+
+		table, link := "expenses", tags.Expenses
+		if rule.MovementType == rules.Income {
+			table, link = "incomes", tags.Incomes
+		}
+
+		tx, err := db.Begin(ctx)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to start transaction"})
+			return
+		}
+		defer tx.Rollback(ctx)
+
+		for _, change := range changes {
+			_, err := tx.Exec(ctx,
+				`UPDATE `+table+` SET category_id = $1, family_member_id = $2 WHERE id = $3 AND account_id = $4`,
+				change.NewCategoryID, change.NewFamilyMemberID, change.ID, accountID,
+			)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update movement: " + err.Error()})
+				return
+			}
+			if err := tags.Add(ctx, tx, link, change.ID, accountID, change.AddedTags); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save tags: " + err.Error()})
+				return
+			}
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to commit transaction"})
+			return
+		}
+
+		userID, _ := middleware.GetUserID(c)
+		logger.Info("rule.applied", "Regla de categorización aplicada retroactivamente", map[string]interface{}{
+			"rule_id":    rule.ID,
+			"updated":    len(changes),
+			"overwrite":  params.Overwrite,
+			"account_id": accountID,
+			"user_id":    userID,
+			"ip":         c.ClientIP(),
+		})
+
+		c.JSON(http.StatusOK, gin.H{
+			"message":   "rule applied successfully",
+			"rule_id":   rule.ID,
+			"overwrite": params.Overwrite,
+			"updated":   len(changes),
+			"changes":   changes,
+		})
+	}
+}
@@ -0,0 +1,207 @@
+package rules
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/LorenzoCampos/bolsillo-claro/internal/middleware"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/categories"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/logger"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/rules"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/tags"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// RuleConditions: every condition that is set has to match
+type RuleConditions struct {
+	DescriptionContains *string  `json:"description_contains"`                 // Case-insensitive
+	AmountMin           *float64 `json:"amount_min" binding:"omitempty,gte=0"` // Inclusive, in the movement currency
+	AmountMax           *float64 `json:"amount_max" binding:"omitempty,gte=0"` // Inclusive, in the movement currency
+	Currency            *string  `json:"currency" binding:"omitempty,oneof=ARS USD EUR"`
+	PaymentMethodID     *string  `json:"payment_method_id"`
+}
+
+// RuleActions: what the rule fills in on a matching movement
+type RuleActions struct {
+	CategoryID     *string  `json:"category_id"` // expense or income category, depending on movement_type
+	FamilyMemberID *string  `json:"family_member_id"`
+	Tags           []string `json:"tags"` // Added to the movement's tags
+}
+
+// RuleRequest is the body of POST /api/rules and PUT /api/rules/:id (full replacement)
+type RuleRequest struct {
+	Name         string         `json:"name" binding:"required,max=100"`
+	MovementType string         `json:"movement_type" binding:"required,oneof=expense income"`
+	Priority     *int           `json:"priority"`  // Optional: defaults to 100 (lower runs first)
+	IsActive     *bool          `json:"is_active"` // Optional: defaults to true
+	Conditions   RuleConditions `json:"conditions"`
+	Actions      RuleActions    `json:"actions"`
+}
+
+type RuleResponse struct {
+	ID           string         `json:"id"`
+	Name         string         `json:"name"`
+	MovementType string         `json:"movement_type"`
+	Priority     int            `json:"priority"`
+	IsActive     bool           `json:"is_active"`
+	Conditions   RuleConditions `json:"conditions"`
+	Actions      RuleActions    `json:"actions"`
+	CreatedAt    string         `json:"created_at"`
+	UpdatedAt    string         `json:"updated_at"`
+}
+
+const ruleColumns = rules.Columns + `, is_active, created_at, updated_at`
+
+func scanRule(row pgx.Row) (*RuleResponse, error) {
+	var r RuleResponse
+	var createdAt, updatedAt time.Time
+
+	err := row.Scan(
+		&r.ID, &r.Name, &r.MovementType, &r.Priority,
+		&r.Conditions.DescriptionContains, &r.Conditions.AmountMin, &r.Conditions.AmountMax,
+		&r.Conditions.Currency, &r.Conditions.PaymentMethodID,
+		&r.Actions.CategoryID, &r.Actions.FamilyMemberID, &r.Actions.Tags,
+		&r.IsActive, &createdAt, &updatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.Actions.Tags == nil {
+		r.Actions.Tags = []string{}
+	}
+	r.CreatedAt = createdAt.Format(time.RFC3339)
+	r.UpdatedAt = updatedAt.Format(time.RFC3339)
+	return &r, nil
+}
+
+// validateRule checks the conditions and actions, and that every referenced id belongs to the account
+// Tags are normalized in place. Returns the status and error to respond with (status 0 = OK)
+func validateRule(ctx context.Context, db *pgxpool.Pool, accountID interface{}, req *RuleRequest) (int, string) {
+	cond := &req.Conditions
+	if cond.DescriptionContains != nil {
+		trimmed := strings.TrimSpace(*cond.DescriptionContains)
+		if trimmed == "" || len(trimmed) > 100 {
+			return http.StatusBadRequest, "description_contains must have between 1 and 100 characters"
+		}
+		cond.DescriptionContains = &trimmed
+	}
+	if cond.DescriptionContains == nil && cond.AmountMin == nil && cond.AmountMax == nil && cond.Currency == nil && cond.PaymentMethodID == nil {
+		return http.StatusBadRequest, "a rule needs at least one condition"
+	}
+	if cond.AmountMin != nil && cond.AmountMax != nil && *cond.AmountMin > *cond.AmountMax {
+		return http.StatusBadRequest, "amount_min must be less than or equal to amount_max"
+	}
+
+	tagNames, err := tags.NormalizeAll(req.Actions.Tags)
+	if err != nil {
+		return http.StatusBadRequest, err.Error()
+	}
+	req.Actions.Tags = tagNames
+	if req.Actions.CategoryID == nil && req.Actions.FamilyMemberID == nil && len(tagNames) == 0 {
+		return http.StatusBadRequest, "a rule needs at least one action (category_id, family_member_id or tags)"
+	}
+
+	categoryTable := categories.ExpenseTable
+	if req.MovementType == rules.Income {
+		categoryTable = categories.IncomeTable
+	}
+
+	checks := []struct {
+		id    *string
+		query string
+		msg   string
+	}{
+		{cond.PaymentMethodID, `SELECT EXISTS(SELECT 1 FROM payment_methods WHERE id = $1 AND account_id = $2)`, "payment_method_id does not belong to this account"},
+		{req.Actions.CategoryID, `SELECT EXISTS(SELECT 1 FROM ` + categoryTable + ` WHERE id = $1 AND (account_id IS NULL OR account_id = $2))`, "category_id does not exist or does not belong to this account"},
+		{req.Actions.FamilyMemberID, `SELECT EXISTS(SELECT 1 FROM family_members WHERE id = $1 AND account_id = $2)`, "family_member_id does not belong to this account"},
+	}
+	for _, check := range checks {
+		if check.id == nil {
+			continue
+		}
+		var found bool
+		if err := db.QueryRow(ctx, check.query, *check.id, accountID).Scan(&found); err != nil {
+			return http.StatusInternalServerError, "failed to validate rule: " + err.Error()
+		}
+		if !found {
+			return http.StatusBadRequest, check.msg
+		}
+	}
+
+	return 0, ""
+}
+
+// categoryColumns returns the category action split into the expense and income columns
+func categoryColumns(req RuleRequest) (expenseCategoryID, incomeCategoryID *string) {
+	if req.MovementType == rules.Income {
+		return nil, req.Actions.CategoryID
+	}
+	return req.Actions.CategoryID, nil
+}
+
+// CreateRule handles POST /api/rules
+func CreateRule(db *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountID, exists := c.Get("account_id")
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "account_id not found in context"})
+			return
+		}
+
+		var req RuleRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if status, msg := validateRule(c.Request.Context(), db, accountID, &req); status != 0 {
+			c.JSON(status, gin.H{"error": msg})
+			return
+		}
+
+		priority := 100
+		if req.Priority != nil {
+			priority = *req.Priority
+		}
+		isActive := true
+		if req.IsActive != nil {
+			isActive = *req.IsActive
+		}
+		expenseCategoryID, incomeCategoryID := categoryColumns(req)
+
+		query := `
+			INSERT INTO categorization_rules (
+				account_id, name, movement_type, priority, is_active,
+				description_contains, amount_min, amount_max, currency, payment_method_id,
+				set_expense_category_id, set_income_category_id, set_family_member_id, add_tags
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9::currency, $10, $11, $12, $13, $14)
+			RETURNING ` + ruleColumns
+
+		rule, err := scanRule(db.QueryRow(c.Request.Context(), query,
+			accountID, req.Name, req.MovementType, priority, isActive,
+			req.Conditions.DescriptionContains, req.Conditions.AmountMin, req.Conditions.AmountMax,
+			req.Conditions.Currency, req.Conditions.PaymentMethodID,
+			expenseCategoryID, incomeCategoryID, req.Actions.FamilyMemberID, req.Actions.Tags,
+		))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create rule: " + err.Error()})
+			return
+		}
+
+		userID, _ := middleware.GetUserID(c)
+		logger.Info("rule.created", "Regla de categorización creada", map[string]interface{}{
+			"rule_id":       rule.ID,
+			"movement_type": rule.MovementType,
+			"account_id":    accountID,
+			"user_id":       userID,
+			"ip":            c.ClientIP(),
+		})
+
+		c.JSON(http.StatusCreated, rule)
+	}
+}
@@ -0,0 +1,50 @@
+package rules
+
+import (
+	"net/http"
+
+	"github.com/LorenzoCampos/bolsillo-claro/internal/middleware"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// DeleteRule handles DELETE /api/rules/:id
+// Movements the rule already categorized keep their category, member and tags
+func DeleteRule(db *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountID, exists := c.Get("account_id")
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "account_id not found in context"})
+			return
+		}
+
+		ruleID := c.Param("id")
+
+		result, err := db.Exec(c.Request.Context(),
+			`DELETE FROM categorization_rules WHERE id = $1 AND account_id = $2`,
+			ruleID, accountID,
+		)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete rule: " + err.Error()})
+			return
+		}
+		if result.RowsAffected() == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "rule not found"})
+			return
+		}
+
+		userID, _ := middleware.GetUserID(c)
+		logger.Info("rule.deleted", "Regla de categorización eliminada", map[string]interface{}{
+			"rule_id":    ruleID,
+			"account_id": accountID,
+			"user_id":    userID,
+			"ip":         c.ClientIP(),
+		})
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "rule deleted successfully",
+			"id":      ruleID,
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package rules
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ListRules handles GET /api/rules?movement_type=expense|income
+// Rules come in evaluation order: lower priority first, then oldest first
+func ListRules(db *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountID, exists := c.Get("account_id")
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "account_id not found in context"})
+			return
+		}
+
+		var movementType *string
+		if value := c.Query("movement_type"); value != "" {
+			if value != "expense" && value != "income" {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "movement_type must be expense or income"})
+				return
+			}
+			movementType = &value
+		}
+
+		rows, err := db.Query(c.Request.Context(), `
+			SELECT `+ruleColumns+`
+			FROM categorization_rules
+			WHERE account_id = $1 AND ($2::TEXT IS NULL OR movement_type = $2)
+			ORDER BY movement_type ASC, priority ASC, created_at ASC
+		`, accountID, movementType)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch rules: " + err.Error()})
+			return
+		}
+		defer rows.Close()
+
+		result := []RuleResponse{}
+		for rows.Next() {
+			rule, err := scanRule(rows)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to parse rule: " + err.Error()})
+				return
+			}
+			result = append(result, *rule)
+		}
+		if err := rows.Err(); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error reading rules"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"rules": result,
+			"count": len(result),
+		})
+	}
+}
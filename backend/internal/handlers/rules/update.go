@@ -0,0 +1,81 @@
+package rules
+
+import (
+	"net/http"
+
+	"github.com/LorenzoCampos/bolsillo-claro/internal/middleware"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// UpdateRule handles PUT /api/rules/:id
+// The body replaces the whole rule (same shape as create); priority and is_active keep their value if omitted
+func UpdateRule(db *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountID, exists := c.Get("account_id")
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "account_id not found in context"})
+			return
+		}
+
+		ruleID := c.Param("id")
+
+		var req RuleRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if status, msg := validateRule(c.Request.Context(), db, accountID, &req); status != 0 {
+			c.JSON(status, gin.H{"error": msg})
+			return
+		}
+
+		expenseCategoryID, incomeCategoryID := categoryColumns(req)
+
+		query := `
+			UPDATE categorization_rules SET
+				name = $3,
+				movement_type = $4,
+				priority = COALESCE($5, priority),
+				is_active = COALESCE($6, is_active),
+				description_contains = $7,
+				amount_min = $8,
+				amount_max = $9,
+				currency = $10::currency,
+				payment_method_id = $11,
+				set_expense_category_id = $12,
+				set_income_category_id = $13,
+				set_family_member_id = $14,
+				add_tags = $15
+			WHERE id = $1 AND account_id = $2
+			RETURNING ` + ruleColumns
+
+		rule, err := scanRule(db.QueryRow(c.Request.Context(), query,
+			ruleID, accountID, req.Name, req.MovementType, req.Priority, req.IsActive,
+			req.Conditions.DescriptionContains, req.Conditions.AmountMin, req.Conditions.AmountMax,
+			req.Conditions.Currency, req.Conditions.PaymentMethodID,
+			expenseCategoryID, incomeCategoryID, req.Actions.FamilyMemberID, req.Actions.Tags,
+		))
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "rule not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update rule: " + err.Error()})
+			return
+		}
+
+		userID, _ := middleware.GetUserID(c)
+		logger.Info("rule.updated", "Regla de categorización actualizada", map[string]interface{}{
+			"rule_id":    rule.ID,
+			"account_id": accountID,
+			"user_id":    userID,
+			"ip":         c.ClientIP(),
+		})
+
+		c.JSON(http.StatusOK, rule)
+	}
+}
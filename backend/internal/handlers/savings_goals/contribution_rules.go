@@ -0,0 +1,368 @@
+package savings_goals
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/LorenzoCampos/bolsillo-claro/internal/middleware"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// CreateContributionRuleRequest represents the request to create an automatic contribution
+type CreateContributionRuleRequest struct {
+	RuleType   string   `json:"rule_type" binding:"required,oneof=fixed_amount income_percentage"`
+	Amount     *float64 `json:"amount,omitempty" binding:"omitempty,gt=0"`             // Solo fixed_amount (moneda de la meta)
+	Percentage *float64 `json:"percentage,omitempty" binding:"omitempty,gt=0,lte=100"` // Solo income_percentage
+	Frequency  string   `json:"frequency" binding:"required,oneof=daily weekly monthly"`
+	StartDate  *string  `json:"start_date,omitempty"` // Format: YYYY-MM-DD, defaults to today
+}
+
+// UpdateContributionRuleRequest represents the request to update an automatic contribution
+type UpdateContributionRuleRequest struct {
+	Amount     *float64 `json:"amount,omitempty" binding:"omitempty,gt=0"`
+	Percentage *float64 `json:"percentage,omitempty" binding:"omitempty,gt=0,lte=100"`
+	Frequency  *string  `json:"frequency,omitempty" binding:"omitempty,oneof=daily weekly monthly"`
+	IsActive   *bool    `json:"is_active,omitempty"`
+}
+
+// ContributionRuleResponse represents an automatic contribution rule
+type ContributionRuleResponse struct {
+	ID            string   `json:"id"`
+	SavingsGoalID string   `json:"savings_goal_id"`
+	RuleType      string   `json:"rule_type"`
+	Amount        *float64 `json:"amount,omitempty"`
+	Percentage    *float64 `json:"percentage,omitempty"`
+	Frequency     string   `json:"frequency"`
+	StartDate     string   `json:"start_date"`
+	NextRunDate   string   `json:"next_run_date"`
+	LastRunDate   *string  `json:"last_run_date,omitempty"`
+	LastRunStatus *string  `json:"last_run_status,omitempty"`
+	IsActive      bool     `json:"is_active"`
+	CreatedAt     string   `json:"created_at"`
+	UpdatedAt     string   `json:"updated_at"`
+}
+
+const contributionRuleColumns = `
+	id, savings_goal_id, rule_type, amount, percentage, frequency,
+	start_date, next_run_date, last_run_date, last_run_status, is_active, created_at, updated_at
+`
+
+func scanContributionRule(row pgx.Row) (*ContributionRuleResponse, error) {
+	var r ContributionRuleResponse
+	var startDate, nextRunDate, createdAt, updatedAt time.Time
+	var lastRunDate *time.Time
+
+	err := row.Scan(
+		&r.ID, &r.SavingsGoalID, &r.RuleType, &r.Amount, &r.Percentage, &r.Frequency,
+		&startDate, &nextRunDate, &lastRunDate, &r.LastRunStatus, &r.IsActive, &createdAt, &updatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	r.StartDate = startDate.Format("2006-01-02")
+	r.NextRunDate = nextRunDate.Format("2006-01-02")
+	if lastRunDate != nil {
+		formatted := lastRunDate.Format("2006-01-02")
+		r.LastRunDate = &formatted
+	}
+	r.CreatedAt = createdAt.Format(time.RFC3339)
+	r.UpdatedAt = updatedAt.Format(time.RFC3339)
+
+	return &r, nil
+}
+
+// validateRuleValue verifica que cada tipo de regla tenga solo su campo (amount o percentage)
+func validateRuleValue(ruleType string, amount, percentage *float64) string {
+	if ruleType == "fixed_amount" && (amount == nil || percentage != nil) {
+		return "las reglas fixed_amount requieren amount y no admiten percentage"
+	}
+	if ruleType == "income_percentage" && (percentage == nil || amount != nil) {
+		return "las reglas income_percentage requieren percentage y no admiten amount"
+	}
+	return ""
+}
+
+// goalBelongsToAccount verifica que la meta exista y sea de la cuenta
+func goalBelongsToAccount(c *gin.Context, db *pgxpool.Pool, goalID, accountID string) bool {
+	var found bool
+	err := db.QueryRow(c.Request.Context(),
+		`SELECT EXISTS(SELECT 1 FROM savings_goals WHERE id = $1 AND account_id = $2)`,
+		goalID, accountID,
+	).Scan(&found)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check savings goal"})
+		return false
+	}
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "meta de ahorro no encontrada o no pertenece a esta cuenta"})
+		return false
+	}
+	return true
+}
+
+// CreateContributionRule handles POST /api/savings-goals/:id/contribution-rules
+// La primera ejecución es start_date; el CRON diario ejecuta las reglas con next_run_date <= hoy
+func CreateContributionRule(db *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountID, exists := middleware.GetAccountID(c)
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "account_id not found in context"})
+			return
+		}
+
+		goalID := c.Param("id")
+		if goalID == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "savings_goal_id is required"})
+			return
+		}
+
+		var req CreateContributionRuleRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if msg := validateRuleValue(req.RuleType, req.Amount, req.Percentage); msg != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+			return
+		}
+
+		today := time.Now().UTC().Truncate(24 * time.Hour)
+		startDate := today
+		if req.StartDate != nil && *req.StartDate != "" {
+			parsed, err := time.Parse("2006-01-02", *req.StartDate)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid start_date format, use YYYY-MM-DD"})
+				return
+			}
+			// Una fecha pasada haría que el CRON genere todos los aportes atrasados de golpe
+			if parsed.Before(today) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "start_date no puede ser una fecha pasada"})
+				return
+			}
+			startDate = parsed
+		}
+
+		if !goalBelongsToAccount(c, db, goalID, accountID) {
+			return
+		}
+
+		ctx := c.Request.Context()
+
+		rule, err := scanContributionRule(db.QueryRow(ctx, `
+			INSERT INTO savings_goal_contribution_rules (
+				savings_goal_id, rule_type, amount, percentage, frequency, start_date, next_run_date
+			) VALUES ($1, $2, $3, $4, $5, $6, $6)
+			RETURNING `+contributionRuleColumns,
+			goalID, req.RuleType, req.Amount, req.Percentage, req.Frequency, startDate,
+		))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create contribution rule: " + err.Error()})
+			return
+		}
+
+		userID, _ := middleware.GetUserID(c)
+		logger.Info("savings_goal.contribution_rule.created", "Aporte automático creado", map[string]interface{}{
+			"goal_id":    goalID,
+			"rule_id":    rule.ID,
+			"rule_type":  rule.RuleType,
+			"frequency":  rule.Frequency,
+			"account_id": accountID,
+			"user_id":    userID,
+			"ip":         c.ClientIP(),
+		})
+
+		c.JSON(http.StatusCreated, rule)
+	}
+}
+
+// ListContributionRules handles GET /api/savings-goals/:id/contribution-rules
+func ListContributionRules(db *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountID, exists := middleware.GetAccountID(c)
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "account_id not found in context"})
+			return
+		}
+
+		goalID := c.Param("id")
+		if goalID == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "savings_goal_id is required"})
+			return
+		}
+
+		if !goalBelongsToAccount(c, db, goalID, accountID) {
+			return
+		}
+
+		rows, err := db.Query(c.Request.Context(), `
+			SELECT `+contributionRuleColumns+`
+			FROM savings_goal_contribution_rules
+			WHERE savings_goal_id = $1
+			ORDER BY created_at ASC
+		`, goalID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch contribution rules"})
+			return
+		}
+		defer rows.Close()
+
+		rules := []ContributionRuleResponse{}
+		for rows.Next() {
+			rule, err := scanContributionRule(rows)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to parse contribution rule"})
+				return
+			}
+			rules = append(rules, *rule)
+		}
+
+		if err := rows.Err(); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error reading contribution rules"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"contribution_rules": rules,
+			"count":              len(rules),
+		})
+	}
+}
+
+// UpdateContributionRule handles PUT /api/savings-goals/:id/contribution-rules/:rule_id
+// Al reactivar una regla con next_run_date vencida, la próxima ejecución pasa a ser hoy
+// (no se generan los aportes del tiempo en que estuvo pausada)
+func UpdateContributionRule(db *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountID, exists := middleware.GetAccountID(c)
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "account_id not found in context"})
+			return
+		}
+
+		goalID := c.Param("id")
+		ruleID := c.Param("rule_id")
+
+		var req UpdateContributionRuleRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if !goalBelongsToAccount(c, db, goalID, accountID) {
+			return
+		}
+
+		ctx := c.Request.Context()
+
+		current, err := scanContributionRule(db.QueryRow(ctx,
+			`SELECT `+contributionRuleColumns+` FROM savings_goal_contribution_rules WHERE id = $1 AND savings_goal_id = $2`,
+			ruleID, goalID,
+		))
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "regla de aporte no encontrada"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch contribution rule"})
+			return
+		}
+
+		// El tipo de regla no cambia: solo se actualiza el campo que le corresponde
+		amount, percentage := current.Amount, current.Percentage
+		if req.Amount != nil {
+			amount = req.Amount
+		}
+		if req.Percentage != nil {
+			percentage = req.Percentage
+		}
+		if msg := validateRuleValue(current.RuleType, amount, percentage); msg != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+			return
+		}
+
+		frequency := current.Frequency
+		if req.Frequency != nil {
+			frequency = *req.Frequency
+		}
+		isActive := current.IsActive
+		if req.IsActive != nil {
+			isActive = *req.IsActive
+		}
+
+		rule, err := scanContributionRule(db.QueryRow(ctx, `
+			UPDATE savings_goal_contribution_rules
+			SET amount = $1, percentage = $2, frequency = $3, is_active = $4,
+			    next_run_date = CASE WHEN $4 AND NOT is_active THEN GREATEST(next_run_date, CURRENT_DATE) ELSE next_run_date END
+			WHERE id = $5 AND savings_goal_id = $6
+			RETURNING `+contributionRuleColumns,
+			amount, percentage, frequency, isActive, ruleID, goalID,
+		))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update contribution rule: " + err.Error()})
+			return
+		}
+
+		userID, _ := middleware.GetUserID(c)
+		logger.Info("savings_goal.contribution_rule.updated", "Aporte automático actualizado", map[string]interface{}{
+			"goal_id":    goalID,
+			"rule_id":    ruleID,
+			"is_active":  rule.IsActive,
+			"account_id": accountID,
+			"user_id":    userID,
+			"ip":         c.ClientIP(),
+		})
+
+		c.JSON(http.StatusOK, rule)
+	}
+}
+
+// DeleteContributionRule handles DELETE /api/savings-goals/:id/contribution-rules/:rule_id
+// Los depósitos ya generados se mantienen (contribution_rule_id queda en NULL)
+func DeleteContributionRule(db *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountID, exists := middleware.GetAccountID(c)
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "account_id not found in context"})
+			return
+		}
+
+		goalID := c.Param("id")
+		ruleID := c.Param("rule_id")
+
+		if !goalBelongsToAccount(c, db, goalID, accountID) {
+			return
+		}
+
+		result, err := db.Exec(c.Request.Context(),
+			`DELETE FROM savings_goal_contribution_rules WHERE id = $1 AND savings_goal_id = $2`,
+			ruleID, goalID,
+		)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete contribution rule"})
+			return
+		}
+		if result.RowsAffected() == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "regla de aporte no encontrada"})
+			return
+		}
+
+		userID, _ := middleware.GetUserID(c)
+		logger.Info("savings_goal.contribution_rule.deleted", "Aporte automático eliminado", map[string]interface{}{
+			"goal_id":    goalID,
+			"rule_id":    ruleID,
+			"account_id": accountID,
+			"user_id":    userID,
+			"ip":         c.ClientIP(),
+		})
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Regla de aporte eliminada exitosamente",
+			"id":      ruleID,
+		})
+	}
+}
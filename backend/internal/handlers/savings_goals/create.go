@@ -13,29 +13,52 @@ import (
 
 // CreateSavingsGoalRequest represents the request to create a savings goal
 type CreateSavingsGoalRequest struct {
-	Name         string  `json:"name" binding:"required,min=1,max=255"`
-	Description  *string `json:"description,omitempty"`
-	TargetAmount float64 `json:"target_amount" binding:"required,gt=0"`
-	SavedIn      *string `json:"saved_in,omitempty" binding:"omitempty,max=255"`
-	Deadline     *string `json:"deadline,omitempty"` // Format: YYYY-MM-DD
+	Name         string                   `json:"name" binding:"required,min=1,max=255"`
+	Description  *string                  `json:"description,omitempty"`
+	TargetAmount float64                  `json:"target_amount" binding:"required,gt=0"`
+	Currency     *string                  `json:"currency,omitempty" binding:"omitempty,oneof=ARS USD EUR"` // Defaults to the account currency
+	SavedIn      *string                  `json:"saved_in,omitempty" binding:"omitempty,max=255"`
+	Deadline     *string                  `json:"deadline,omitempty"`                  // Format: YYYY-MM-DD
+	Milestones   []CreateMilestoneRequest `json:"milestones,omitempty" binding:"dive"` // nil = 25/50/75/100%, [] = none
 }
 
 // SavingsGoalResponse represents a savings goal
 type SavingsGoalResponse struct {
-	ID                     string   `json:"id"`
-	AccountID              string   `json:"account_id"`
-	Name                   string   `json:"name"`
-	Description            *string  `json:"description,omitempty"`
-	TargetAmount           float64  `json:"target_amount"`
-	CurrentAmount          float64  `json:"current_amount"`
-	Currency               string   `json:"currency"`
-	SavedIn                *string  `json:"saved_in,omitempty"`
-	Deadline               *string  `json:"deadline,omitempty"`
-	ProgressPercentage     float64  `json:"progress_percentage"`
-	RequiredMonthlySavings *float64 `json:"required_monthly_savings,omitempty"`
-	IsActive               bool     `json:"is_active"`
-	CreatedAt              string   `json:"created_at"`
-	UpdatedAt              string   `json:"updated_at"`
+	ID                     string                 `json:"id"`
+	AccountID              string                 `json:"account_id"`
+	Name                   string                 `json:"name"`
+	Description            *string                `json:"description,omitempty"`
+	TargetAmount           float64                `json:"target_amount"`
+	CurrentAmount          float64                `json:"current_amount"`
+	Currency               string                 `json:"currency"`
+	SavedIn                *string                `json:"saved_in,omitempty"`
+	Deadline               *string                `json:"deadline,omitempty"`
+	ProgressPercentage     float64                `json:"progress_percentage"`
+	RequiredMonthlySavings *float64               `json:"required_monthly_savings,omitempty"`
+	InPrimaryCurrency      *GoalInPrimaryCurrency `json:"in_primary_currency,omitempty"` // Only when the goal currency differs from the account
+	Status                 string                 `json:"status"`                        // active, achieved, archived, overdue
+	AchievedAt             *string                `json:"achieved_at,omitempty"`
+	IsActive               bool                   `json:"is_active"`
+	CreatedAt              string                 `json:"created_at"`
+	UpdatedAt              string                 `json:"updated_at"`
+}
+
+// goalStatusSQL deriva el estado de la meta: archived (is_active = false), achieved,
+// overdue (pasó el deadline sin completarse) o active
+const goalStatusSQL = `CASE
+	WHEN NOT is_active THEN 'archived'
+	WHEN achieved_at IS NOT NULL THEN 'achieved'
+	WHEN deadline < CURRENT_DATE THEN 'overdue'
+	ELSE 'active'
+END`
+
+// formatTimestamp formatea un timestamp opcional (achieved_at, reached_at) para la respuesta
+func formatTimestamp(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	formatted := t.Format(time.RFC3339)
+	return &formatted
 }
 
 // calculateRequiredMonthlySavings calcula cuánto hay que ahorrar por mes para alcanzar la meta
@@ -90,6 +113,18 @@ func CreateSavingsGoal(db *pgxpool.Pool) gin.HandlerFunc {
 			return
 		}
 
+		// Milestones: nil = 25/50/75/100%, [] = ninguno
+		milestones := req.Milestones
+		if milestones == nil {
+			milestones = defaultMilestones()
+		}
+		for _, m := range milestones {
+			if msg := validateMilestone(m); msg != "" {
+				c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+				return
+			}
+		}
+
 		ctx := c.Request.Context()
 
 		// Validate deadline (if provided, must be future date)
@@ -110,13 +145,16 @@ func CreateSavingsGoal(db *pgxpool.Pool) gin.HandlerFunc {
 			deadlineDate = &parsedDate
 		}
 
-		// Get account currency (savings goal inherits currency from account)
+		// Get account currency (savings goal inherits currency from account unless another one is given)
 		var currency string
 		err := db.QueryRow(ctx, `SELECT currency FROM accounts WHERE id = $1`, accountID).Scan(&currency)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get account currency"})
 			return
 		}
+		if req.Currency != nil {
+			currency = *req.Currency
+		}
 
 		// Check if a goal with the same name already exists for this account
 		var goalExists bool
@@ -133,6 +171,14 @@ func CreateSavingsGoal(db *pgxpool.Pool) gin.HandlerFunc {
 			return
 		}
 
+		// Start transaction (goal + milestones)
+		tx, err := db.Begin(ctx)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to start transaction"})
+			return
+		}
+		defer tx.Rollback(ctx)
+
 		// Insert savings goal
 		var goalID uuid.UUID
 		var createdAt, updatedAt time.Time
@@ -144,7 +190,7 @@ func CreateSavingsGoal(db *pgxpool.Pool) gin.HandlerFunc {
 			RETURNING id, created_at, updated_at
 		`
 
-		err = db.QueryRow(ctx, insertQuery,
+		err = tx.QueryRow(ctx, insertQuery,
 			accountID, req.Name, req.Description, req.TargetAmount,
 			currency, req.SavedIn, deadlineDate,
 		).Scan(&goalID, &createdAt, &updatedAt)
@@ -154,6 +200,23 @@ func CreateSavingsGoal(db *pgxpool.Pool) gin.HandlerFunc {
 			return
 		}
 
+		createdMilestones := []MilestoneResponse{}
+		for _, m := range milestones {
+			milestone, err := insertMilestone(ctx, tx, goalID.String(), m)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create milestone: " + err.Error()})
+				return
+			}
+			createdMilestones = append(createdMilestones, *milestone)
+		}
+
+		// Commit transaction
+		err = tx.Commit(ctx)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to commit transaction"})
+			return
+		}
+
 		// Obtener user_id del contexto para logging
 		userID, _ := middleware.GetUserID(c)
 
@@ -184,6 +247,7 @@ func CreateSavingsGoal(db *pgxpool.Pool) gin.HandlerFunc {
 			Deadline:               req.Deadline,
 			ProgressPercentage:     0,
 			RequiredMonthlySavings: requiredMonthlySavings,
+			Status:                 "active",
 			IsActive:               true,
 			CreatedAt:              createdAt.Format(time.RFC3339),
 			UpdatedAt:              updatedAt.Format(time.RFC3339),
@@ -192,6 +256,7 @@ func CreateSavingsGoal(db *pgxpool.Pool) gin.HandlerFunc {
 		c.JSON(http.StatusCreated, gin.H{
 			"message":      "Meta de ahorro creada exitosamente",
 			"savings_goal": response,
+			"milestones":   createdMilestones,
 		})
 	}
 }
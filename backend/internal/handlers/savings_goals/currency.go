@@ -0,0 +1,56 @@
+package savings_goals
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/savings"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// GoalInPrimaryCurrency is the progress of a goal expressed in the account primary currency
+type GoalInPrimaryCurrency struct {
+	Currency         string   `json:"currency"`
+	ExchangeRate     *float64 `json:"exchange_rate,omitempty"`  // Latest goal currency → primary currency rate
+	CurrentAmount    *float64 `json:"current_amount,omitempty"` // current_amount valued at exchange_rate
+	TargetAmount     *float64 `json:"target_amount,omitempty"`
+	NetContributed   float64  `json:"net_contributed"`             // Deposits - withdrawals at the rate of each transaction
+	UnconvertedCount int      `json:"unconverted_count,omitempty"` // Transactions without amount_in_primary_currency (not included)
+}
+
+// goalInPrimaryCurrency calcula el progreso de una meta en la moneda principal de la cuenta
+// Retorna nil si la meta está en la misma moneda que la cuenta
+func goalInPrimaryCurrency(ctx context.Context, db *pgxpool.Pool, goalID, goalCurrency, primaryCurrency string, currentAmount, targetAmount float64) (*GoalInPrimaryCurrency, error) {
+	if goalCurrency == primaryCurrency {
+		return nil, nil
+	}
+
+	result := &GoalInPrimaryCurrency{Currency: primaryCurrency}
+
+	today := time.Now().UTC().Format("2006-01-02")
+	rate, err := savings.LookupRate(ctx, db, goalCurrency, primaryCurrency, today)
+	if err != nil && err != savings.ErrRateNotFound {
+		return nil, err
+	}
+	if err == nil {
+		current := math.Round(currentAmount*rate*100) / 100
+		target := math.Round(targetAmount*rate*100) / 100
+		result.ExchangeRate = &rate
+		result.CurrentAmount = &current
+		result.TargetAmount = &target
+	}
+
+	err = db.QueryRow(ctx, `
+		SELECT
+			COALESCE(SUM(CASE WHEN transaction_type = 'deposit' THEN 1 ELSE -1 END * amount_in_primary_currency), 0),
+			COUNT(*) FILTER (WHERE amount_in_primary_currency IS NULL)
+		FROM savings_goal_transactions
+		WHERE savings_goal_id = $1
+	`, goalID).Scan(&result.NetContributed, &result.UnconvertedCount)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
@@ -0,0 +1,94 @@
+package savings_goals
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/LorenzoCampos/bolsillo-claro/internal/middleware"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/savings"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// GoalEventResponse represents a milestone reached or the goal being achieved
+type GoalEventResponse struct {
+	ID            string  `json:"id"`
+	EventType     string  `json:"event_type"` // milestone_reached, goal_achieved
+	MilestoneID   *string `json:"milestone_id,omitempty"`
+	MilestoneName *string `json:"milestone_name,omitempty"`
+	TransactionID *string `json:"transaction_id,omitempty"`
+	CurrentAmount float64 `json:"current_amount"` // Goal balance when the event happened
+	OccurredAt    string  `json:"occurred_at"`
+}
+
+// newGoalEvents convierte los eventos que devuelve savings.Record / savings.SyncProgress
+func newGoalEvents(events []savings.Event) []GoalEventResponse {
+	result := make([]GoalEventResponse, 0, len(events))
+	for _, e := range events {
+		event := GoalEventResponse{
+			ID:            e.ID,
+			EventType:     e.EventType,
+			MilestoneID:   e.MilestoneID,
+			MilestoneName: e.MilestoneName,
+			CurrentAmount: e.CurrentAmount,
+			OccurredAt:    e.OccurredAt.Format(time.RFC3339),
+		}
+		if e.TransactionID != nil {
+			transactionID := e.TransactionID.String()
+			event.TransactionID = &transactionID
+		}
+		result = append(result, event)
+	}
+	return result
+}
+
+// ListGoalEvents handles GET /api/savings-goals/:id/events
+func ListGoalEvents(db *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountID, exists := middleware.GetAccountID(c)
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "account_id not found in context"})
+			return
+		}
+
+		goalID := c.Param("id")
+		if !goalBelongsToAccount(c, db, goalID, accountID) {
+			return
+		}
+
+		rows, err := db.Query(c.Request.Context(), `
+			SELECT id, event_type, milestone_id, milestone_name, transaction_id, current_amount, occurred_at
+			FROM savings_goal_events
+			WHERE savings_goal_id = $1
+			ORDER BY occurred_at DESC
+		`, goalID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch goal events"})
+			return
+		}
+		defer rows.Close()
+
+		events := []GoalEventResponse{}
+		for rows.Next() {
+			var e GoalEventResponse
+			var occurredAt time.Time
+			err := rows.Scan(&e.ID, &e.EventType, &e.MilestoneID, &e.MilestoneName, &e.TransactionID, &e.CurrentAmount, &occurredAt)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to parse goal event"})
+				return
+			}
+			e.OccurredAt = occurredAt.Format(time.RFC3339)
+			events = append(events, e)
+		}
+
+		if err := rows.Err(); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error reading goal events"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"events": events,
+			"count":  len(events),
+		})
+	}
+}
@@ -13,12 +13,16 @@ import (
 
 // SavingsGoalTransaction represents a transaction for a savings goal
 type SavingsGoalTransaction struct {
-	ID              string  `json:"id"`
-	Amount          float64 `json:"amount"`           // Positive for deposit, negative for withdrawal (display)
-	TransactionType string  `json:"transaction_type"` // "deposit" or "withdrawal"
-	Description     *string `json:"description,omitempty"`
-	Date            string  `json:"date"`
-	CreatedAt       string  `json:"created_at"`
+	ID                      string   `json:"id"`
+	Amount                  float64  `json:"amount"`           // In goal currency. Positive for deposit, negative for withdrawal (display)
+	TransactionType         string   `json:"transaction_type"` // "deposit" or "withdrawal"
+	SourceCurrency          string   `json:"source_currency"`
+	SourceAmount            float64  `json:"source_amount"`
+	ExchangeRate            float64  `json:"exchange_rate"` // source_currency → goal currency
+	AmountInPrimaryCurrency *float64 `json:"amount_in_primary_currency,omitempty"`
+	Description             *string  `json:"description,omitempty"`
+	Date                    string   `json:"date"`
+	CreatedAt               string   `json:"created_at"`
 }
 
 // PaginationMetadata represents pagination information
@@ -34,9 +38,15 @@ type SavingsGoalDetailResponse struct {
 	SavingsGoalResponse
 	Transactions []SavingsGoalTransaction `json:"transactions"`
 	Pagination   PaginationMetadata       `json:"pagination"`
+	Projection   *GoalProjection          `json:"projection,omitempty"`
+
+	// Inversiones que respaldan la meta (investment_holdings.savings_goal_id)
+	Investments   []GoalInvestment `json:"investments,omitempty"`
+	InvestedValue *float64         `json:"invested_value,omitempty"` // Market value in the goal currency
 }
 
 // GetSavingsGoal handles GET /api/savings-goals/:id
+// Query param: monthly_contribution (opcional) = aporte mensual hipotético para projection.what_if
 func GetSavingsGoal(db *pgxpool.Pool) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Get account_id from context
@@ -53,19 +63,26 @@ func GetSavingsGoal(db *pgxpool.Pool) gin.HandlerFunc {
 			return
 		}
 
+		whatIf, ok := parseWhatIf(c)
+		if !ok {
+			return
+		}
+
 		ctx := c.Request.Context()
 
 		// Query savings goal
 		var goal SavingsGoalResponse
+		var primaryCurrency string
 		var description, savedIn *string
-		var deadline *time.Time
+		var deadline, achievedAt *time.Time
 		var createdAt, updatedAt time.Time
 
 		query := `
 			SELECT 
 				id, account_id, name, description, target_amount, 
 				current_amount, currency, saved_in, deadline, 
-				is_active, created_at, updated_at
+				is_active, achieved_at, ` + goalStatusSQL + `, created_at, updated_at,
+				(SELECT currency FROM accounts WHERE id = savings_goals.account_id)
 			FROM savings_goals
 			WHERE id = $1 AND account_id = $2
 		`
@@ -73,7 +90,8 @@ func GetSavingsGoal(db *pgxpool.Pool) gin.HandlerFunc {
 		err := db.QueryRow(ctx, query, goalID, accountID).Scan(
 			&goal.ID, &goal.AccountID, &goal.Name, &description,
 			&goal.TargetAmount, &goal.CurrentAmount, &goal.Currency,
-			&savedIn, &deadline, &goal.IsActive, &createdAt, &updatedAt,
+			&savedIn, &deadline, &goal.IsActive, &achievedAt, &goal.Status,
+			&createdAt, &updatedAt, &primaryCurrency,
 		)
 
 		if err == pgx.ErrNoRows {
@@ -89,6 +107,7 @@ func GetSavingsGoal(db *pgxpool.Pool) gin.HandlerFunc {
 		// Set optional fields
 		goal.Description = description
 		goal.SavedIn = savedIn
+		goal.AchievedAt = formatTimestamp(achievedAt)
 
 		if deadline != nil {
 			deadlineStr := deadline.Format("2006-01-02")
@@ -108,6 +127,26 @@ func GetSavingsGoal(db *pgxpool.Pool) gin.HandlerFunc {
 	goal.CreatedAt = createdAt.Format(time.RFC3339)
 	goal.UpdatedAt = updatedAt.Format(time.RFC3339)
 
+	// Progreso en la moneda principal de la cuenta (solo si la meta está en otra moneda)
+	goal.InPrimaryCurrency, err = goalInPrimaryCurrency(ctx, db, goalID, goal.Currency, primaryCurrency, goal.CurrentAmount, goal.TargetAmount)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to convert goal to primary currency"})
+		return
+	}
+
+	// Proyección en base al historial de aportes
+	projection, err := buildGoalProjection(ctx, db, goalID, goal.CurrentAmount, goal.TargetAmount, deadline, whatIf)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to calculate projection"})
+		return
+	}
+
+	backingInvestments, investedValue, err := goalInvestments(ctx, db, goalID, goal.Currency)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to value goal investments"})
+		return
+	}
+
 	// Parse pagination parameters
 	pageStr := c.DefaultQuery("page", "1")
 	limitStr := c.DefaultQuery("limit", "20")
@@ -146,7 +185,8 @@ func GetSavingsGoal(db *pgxpool.Pool) gin.HandlerFunc {
 	// Query transactions history with pagination
 	transactionsQuery := `
 		SELECT 
-			id, amount, transaction_type, description, 
+			id, amount, transaction_type, source_currency, source_amount,
+			exchange_rate, amount_in_primary_currency, description, 
 			date::TEXT, created_at::TEXT
 		FROM savings_goal_transactions
 		WHERE savings_goal_id = $1
@@ -167,8 +207,8 @@ func GetSavingsGoal(db *pgxpool.Pool) gin.HandlerFunc {
 			var description *string
 
 			err := rows.Scan(
-				&txn.ID, &txn.Amount, &txn.TransactionType,
-				&description, &txn.Date, &txn.CreatedAt,
+				&txn.ID, &txn.Amount, &txn.TransactionType, &txn.SourceCurrency, &txn.SourceAmount,
+				&txn.ExchangeRate, &txn.AmountInPrimaryCurrency, &description, &txn.Date, &txn.CreatedAt,
 			)
 			if err != nil {
 				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to parse transaction"})
@@ -203,6 +243,9 @@ func GetSavingsGoal(db *pgxpool.Pool) gin.HandlerFunc {
 		SavingsGoalResponse: goal,
 		Transactions:        transactions,
 		Pagination:          pagination,
+		Projection:          projection,
+		Investments:         backingInvestments,
+		InvestedValue:       investedValue,
 	}
 
 	c.JSON(http.StatusOK, response)
@@ -103,7 +103,8 @@ func GetTransactions(db *pgxpool.Pool) gin.HandlerFunc {
 		// Build transactions query with type filter
 		transactionsQuery := `
 			SELECT 
-				id, amount, transaction_type, description, 
+				id, amount, transaction_type, source_currency, source_amount,
+				exchange_rate, amount_in_primary_currency, description, 
 				date::TEXT, created_at::TEXT
 			FROM savings_goal_transactions
 			WHERE savings_goal_id = $1`
@@ -135,8 +136,8 @@ func GetTransactions(db *pgxpool.Pool) gin.HandlerFunc {
 			var description *string
 
 			err := rows.Scan(
-				&txn.ID, &txn.Amount, &txn.TransactionType,
-				&description, &txn.Date, &txn.CreatedAt,
+				&txn.ID, &txn.Amount, &txn.TransactionType, &txn.SourceCurrency, &txn.SourceAmount,
+				&txn.ExchangeRate, &txn.AmountInPrimaryCurrency, &description, &txn.Date, &txn.CreatedAt,
 			)
 			if err != nil {
 				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to parse transaction"})
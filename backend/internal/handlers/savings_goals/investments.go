@@ -0,0 +1,63 @@
+package savings_goals
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/investments"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/savings"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// GoalInvestment is an investment holding that backs the goal (see /api/investments)
+type GoalInvestment struct {
+	ID                        string   `json:"id"`
+	Name                      string   `json:"name"`
+	InstrumentType            string   `json:"instrument_type"`
+	Currency                  string   `json:"currency"`
+	MarketValue               *float64 `json:"market_value"`                  // null until the holding has a price
+	MarketValueInGoalCurrency *float64 `json:"market_value_in_goal_currency"` // null without price or exchange rate
+}
+
+// goalInvestments valúa las inversiones activas que respaldan la meta y las convierte a su moneda
+// invested_value suma solo las que se pudieron valuar y convertir; nil si la meta no tiene inversiones
+func goalInvestments(ctx context.Context, db *pgxpool.Pool, goalID, goalCurrency string) ([]GoalInvestment, *float64, error) {
+	now := time.Now().UTC()
+	backings, err := investments.GoalBackings(ctx, db, goalID, now)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(backings) == 0 {
+		return nil, nil, nil
+	}
+
+	today := now.Format("2006-01-02")
+	total := 0.0
+	result := make([]GoalInvestment, 0, len(backings))
+	for _, b := range backings {
+		investment := GoalInvestment{
+			ID:             b.HoldingID,
+			Name:           b.Name,
+			InstrumentType: b.InstrumentType,
+			Currency:       b.Currency,
+			MarketValue:    b.MarketValue,
+		}
+
+		if b.MarketValue != nil {
+			rate, err := savings.LookupRate(ctx, db, b.Currency, goalCurrency, today)
+			if err != nil && err != savings.ErrRateNotFound {
+				return nil, nil, err
+			}
+			if err == nil {
+				value := math.Round(*b.MarketValue*rate*100) / 100
+				investment.MarketValueInGoalCurrency = &value
+				total += value
+			}
+		}
+
+		result = append(result, investment)
+	}
+
+	return result, &total, nil
+}
@@ -25,16 +25,31 @@ func ListSavingsGoals(db *pgxpool.Pool) gin.HandlerFunc {
 		// Options: "true", "false", "all"
 		isActiveParam := c.DefaultQuery("is_active", "true")
 
-		// Build query based on is_active filter
+		// status (opcional): active, achieved, overdue, archived o all. Si viene, reemplaza a is_active
+		statusParam := c.Query("status")
+		switch statusParam {
+		case "", "active", "achieved", "overdue", "archived", "all":
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "status inválido (active, achieved, overdue, archived o all)"})
+			return
+		}
+
+		// Build query based on status / is_active filter
 		query := `
 			SELECT 
 				id, account_id, name, description, target_amount, 
 				current_amount, currency, saved_in, deadline, 
-				is_active, created_at, updated_at
+				is_active, achieved_at, ` + goalStatusSQL + `, created_at, updated_at
 			FROM savings_goals
 			WHERE account_id = $1`
+		args := []interface{}{accountID}
 
-		if isActiveParam == "true" {
+		if statusParam != "" {
+			if statusParam != "all" {
+				query += " AND (" + goalStatusSQL + ") = $2"
+				args = append(args, statusParam)
+			}
+		} else if isActiveParam == "true" {
 			query += " AND is_active = true"
 		} else if isActiveParam == "false" {
 			query += " AND is_active = false"
@@ -43,7 +58,7 @@ func ListSavingsGoals(db *pgxpool.Pool) gin.HandlerFunc {
 
 		query += " ORDER BY created_at DESC"
 
-		rows, err := db.Query(ctx, query, accountID)
+		rows, err := db.Query(ctx, query, args...)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch savings goals"})
 			return
@@ -54,13 +69,14 @@ func ListSavingsGoals(db *pgxpool.Pool) gin.HandlerFunc {
 		for rows.Next() {
 			var goal SavingsGoalResponse
 			var description, savedIn *string
-			var deadline *time.Time
+			var deadline, achievedAt *time.Time
 			var createdAt, updatedAt time.Time
 
 			err := rows.Scan(
 				&goal.ID, &goal.AccountID, &goal.Name, &description,
 				&goal.TargetAmount, &goal.CurrentAmount, &goal.Currency,
-				&savedIn, &deadline, &goal.IsActive, &createdAt, &updatedAt,
+				&savedIn, &deadline, &goal.IsActive, &achievedAt, &goal.Status,
+				&createdAt, &updatedAt,
 			)
 			if err != nil {
 				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to parse savings goal"})
@@ -70,6 +86,7 @@ func ListSavingsGoals(db *pgxpool.Pool) gin.HandlerFunc {
 			// Set optional fields
 			goal.Description = description
 			goal.SavedIn = savedIn
+			goal.AchievedAt = formatTimestamp(achievedAt)
 
 			if deadline != nil {
 				deadlineStr := deadline.Format("2006-01-02")
@@ -96,6 +113,24 @@ func ListSavingsGoals(db *pgxpool.Pool) gin.HandlerFunc {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "error reading savings goals"})
 			return
 		}
+		rows.Close()
+
+		// Progreso en la moneda principal para las metas en otra moneda
+		var primaryCurrency string
+		err = db.QueryRow(ctx, `SELECT currency FROM accounts WHERE id = $1`, accountID).Scan(&primaryCurrency)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get account currency"})
+			return
+		}
+
+		for i := range savingsGoals {
+			goal := &savingsGoals[i]
+			goal.InPrimaryCurrency, err = goalInPrimaryCurrency(ctx, db, goal.ID, goal.Currency, primaryCurrency, goal.CurrentAmount, goal.TargetAmount)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to convert goal to primary currency"})
+				return
+			}
+		}
 
 		c.JSON(http.StatusOK, gin.H{
 			"savings_goals": savingsGoals,
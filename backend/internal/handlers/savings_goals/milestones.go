@@ -0,0 +1,247 @@
+package savings_goals
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/LorenzoCampos/bolsillo-claro/internal/database"
+	"github.com/LorenzoCampos/bolsillo-claro/internal/middleware"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/logger"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/savings"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// CreateMilestoneRequest represents a milestone: a percentage of the target or a fixed amount
+type CreateMilestoneRequest struct {
+	Name       *string  `json:"name,omitempty" binding:"omitempty,min=1,max=100"` // Defaults to "25%" or the amount
+	Percentage *float64 `json:"percentage,omitempty" binding:"omitempty,gt=0,lte=100"`
+	Amount     *float64 `json:"amount,omitempty" binding:"omitempty,gt=0"` // In goal currency
+}
+
+// MilestoneResponse represents a milestone of a savings goal
+type MilestoneResponse struct {
+	ID           string   `json:"id"`
+	Name         string   `json:"name"`
+	Percentage   *float64 `json:"percentage,omitempty"`
+	Amount       *float64 `json:"amount,omitempty"`
+	TargetAmount float64  `json:"target_amount"` // Amount to reach (percentage milestones follow the goal target)
+	IsReached    bool     `json:"is_reached"`
+	ReachedAt    *string  `json:"reached_at,omitempty"`
+	CreatedAt    string   `json:"created_at"`
+}
+
+const milestoneColumns = `
+	m.id, m.name, m.percentage, m.amount,
+	COALESCE(m.amount, sg.target_amount * m.percentage / 100), m.reached_at, m.created_at
+`
+
+func scanMilestone(row pgx.Row) (*MilestoneResponse, error) {
+	var m MilestoneResponse
+	var reachedAt *time.Time
+	var createdAt time.Time
+
+	err := row.Scan(&m.ID, &m.Name, &m.Percentage, &m.Amount, &m.TargetAmount, &reachedAt, &createdAt)
+	if err != nil {
+		return nil, err
+	}
+
+	m.IsReached = reachedAt != nil
+	m.ReachedAt = formatTimestamp(reachedAt)
+	m.CreatedAt = createdAt.Format(time.RFC3339)
+
+	return &m, nil
+}
+
+// validateMilestone verifica que el hito tenga percentage o amount (no los dos)
+func validateMilestone(m CreateMilestoneRequest) string {
+	if (m.Percentage == nil) == (m.Amount == nil) {
+		return "cada hito requiere percentage o amount (no ambos)"
+	}
+	return ""
+}
+
+// defaultMilestones son los hitos 25/50/75/100% que se crean si la meta no define otros
+func defaultMilestones() []CreateMilestoneRequest {
+	milestones := make([]CreateMilestoneRequest, 0, len(savings.DefaultMilestonePercentages))
+	for _, pct := range savings.DefaultMilestonePercentages {
+		pct := pct
+		milestones = append(milestones, CreateMilestoneRequest{Percentage: &pct})
+	}
+	return milestones
+}
+
+// insertMilestone crea un hito; si la meta ya lo superó queda marcado como alcanzado (sin evento)
+func insertMilestone(ctx context.Context, q database.Querier, goalID string, m CreateMilestoneRequest) (*MilestoneResponse, error) {
+	var name string
+	switch {
+	case m.Name != nil:
+		name = *m.Name
+	case m.Percentage != nil:
+		name = strconv.FormatFloat(*m.Percentage, 'f', -1, 64) + "%"
+	default:
+		name = strconv.FormatFloat(*m.Amount, 'f', -1, 64)
+	}
+
+	return scanMilestone(q.QueryRow(ctx, `
+		WITH inserted AS (
+			INSERT INTO savings_goal_milestones (savings_goal_id, name, percentage, amount, reached_at)
+			SELECT id, $2, $3, $4,
+			       CASE WHEN current_amount >= COALESCE($4, target_amount * $3 / 100) THEN NOW() END
+			FROM savings_goals
+			WHERE id = $1
+			RETURNING *
+		)
+		SELECT `+milestoneColumns+`
+		FROM inserted m
+		JOIN savings_goals sg ON sg.id = m.savings_goal_id
+	`, goalID, name, m.Percentage, m.Amount))
+}
+
+// ListMilestones handles GET /api/savings-goals/:id/milestones
+func ListMilestones(db *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountID, exists := middleware.GetAccountID(c)
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "account_id not found in context"})
+			return
+		}
+
+		goalID := c.Param("id")
+		if !goalBelongsToAccount(c, db, goalID, accountID) {
+			return
+		}
+
+		rows, err := db.Query(c.Request.Context(), `
+			SELECT `+milestoneColumns+`
+			FROM savings_goal_milestones m
+			JOIN savings_goals sg ON sg.id = m.savings_goal_id
+			WHERE m.savings_goal_id = $1
+			ORDER BY 5 ASC, m.created_at ASC
+		`, goalID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch milestones"})
+			return
+		}
+		defer rows.Close()
+
+		milestones := []MilestoneResponse{}
+		reached := 0
+		for rows.Next() {
+			m, err := scanMilestone(rows)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to parse milestone"})
+				return
+			}
+			if m.IsReached {
+				reached++
+			}
+			milestones = append(milestones, *m)
+		}
+
+		if err := rows.Err(); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error reading milestones"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"milestones":    milestones,
+			"count":         len(milestones),
+			"reached_count": reached,
+		})
+	}
+}
+
+// CreateMilestone handles POST /api/savings-goals/:id/milestones
+func CreateMilestone(db *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountID, exists := middleware.GetAccountID(c)
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "account_id not found in context"})
+			return
+		}
+
+		goalID := c.Param("id")
+
+		var req CreateMilestoneRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if msg := validateMilestone(req); msg != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+			return
+		}
+
+		if !goalBelongsToAccount(c, db, goalID, accountID) {
+			return
+		}
+
+		milestone, err := insertMilestone(c.Request.Context(), db, goalID, req)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create milestone: " + err.Error()})
+			return
+		}
+
+		userID, _ := middleware.GetUserID(c)
+		logger.Info("savings_goal.milestone.created", "Hito de meta creado", map[string]interface{}{
+			"goal_id":      goalID,
+			"milestone_id": milestone.ID,
+			"account_id":   accountID,
+			"user_id":      userID,
+			"ip":           c.ClientIP(),
+		})
+
+		c.JSON(http.StatusCreated, milestone)
+	}
+}
+
+// DeleteMilestone handles DELETE /api/savings-goals/:id/milestones/:milestone_id
+// Los eventos ya registrados se mantienen (conservan el nombre del hito)
+func DeleteMilestone(db *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountID, exists := middleware.GetAccountID(c)
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "account_id not found in context"})
+			return
+		}
+
+		goalID := c.Param("id")
+		milestoneID := c.Param("milestone_id")
+
+		if !goalBelongsToAccount(c, db, goalID, accountID) {
+			return
+		}
+
+		result, err := db.Exec(c.Request.Context(),
+			`DELETE FROM savings_goal_milestones WHERE id = $1 AND savings_goal_id = $2`,
+			milestoneID, goalID,
+		)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete milestone"})
+			return
+		}
+		if result.RowsAffected() == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "hito no encontrado"})
+			return
+		}
+
+		userID, _ := middleware.GetUserID(c)
+		logger.Info("savings_goal.milestone.deleted", "Hito de meta eliminado", map[string]interface{}{
+			"goal_id":      goalID,
+			"milestone_id": milestoneID,
+			"account_id":   accountID,
+			"user_id":      userID,
+			"ip":           c.ClientIP(),
+		})
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Hito eliminado exitosamente",
+			"id":      milestoneID,
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package savings_goals
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/LorenzoCampos/bolsillo-claro/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// daysPerMonth es el largo promedio de un mes, para pasar de días a meses con decimales
+const daysPerMonth = 30.4375
+
+// GoalProjection is the forecast of a savings goal based on its contribution history
+type GoalProjection struct {
+	AverageMonthlyContribution  float64           `json:"average_monthly_contribution"` // Depósitos - retiros, por mes desde el primer movimiento
+	HistoryMonths               float64           `json:"history_months"`
+	AmountRemaining             float64           `json:"amount_remaining"`
+	MonthsToComplete            *float64          `json:"months_to_complete,omitempty"`
+	ProjectedCompletionDate     *string           `json:"projected_completion_date,omitempty"`
+	RequiredMonthlyContribution *float64          `json:"required_monthly_contribution,omitempty"` // Para llegar al deadline
+	Status                      string            `json:"status"`                                  // achieved, on_track, behind, no_deadline
+	WhatIf                      *WhatIfProjection `json:"what_if,omitempty"`
+}
+
+// WhatIfProjection is the forecast with a hypothetical monthly contribution
+type WhatIfProjection struct {
+	MonthlyContribution     float64  `json:"monthly_contribution"`
+	MonthsToComplete        *float64 `json:"months_to_complete,omitempty"`
+	ProjectedCompletionDate *string  `json:"projected_completion_date,omitempty"`
+	MeetsDeadline           *bool    `json:"meets_deadline,omitempty"`
+}
+
+// parseWhatIf lee ?monthly_contribution= (opcional, > 0)
+func parseWhatIf(c *gin.Context) (*float64, bool) {
+	v := c.Query("monthly_contribution")
+	if v == "" {
+		return nil, true
+	}
+
+	parsed, err := strconv.ParseFloat(v, 64)
+	if err != nil || parsed <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "monthly_contribution debe ser un número mayor a 0"})
+		return nil, false
+	}
+	return &parsed, true
+}
+
+// projectCompletion calcula en cuántos meses (y en qué fecha) se completa lo que falta a un ritmo mensual
+func projectCompletion(remaining, monthly float64, today time.Time) (*float64, *string) {
+	if monthly <= 0 {
+		return nil, nil
+	}
+
+	months := math.Round(remaining/monthly*10) / 10
+	date := today.AddDate(0, 0, int(math.Ceil(remaining/monthly*daysPerMonth))).Format("2006-01-02")
+	return &months, &date
+}
+
+// buildGoalProjection calcula el ritmo promedio de aportes desde savings_goal_transactions
+// y la proyección de la meta; whatIf (opcional) es un aporte mensual hipotético
+func buildGoalProjection(ctx context.Context, db *pgxpool.Pool, goalID string, currentAmount, targetAmount float64, deadline *time.Time, whatIf *float64) (*GoalProjection, error) {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+
+	var netContributions float64
+	var firstDate *time.Time
+	err := db.QueryRow(ctx, `
+		SELECT
+			COALESCE(SUM(CASE WHEN transaction_type = 'deposit' THEN amount ELSE -amount END), 0),
+			MIN(date)
+		FROM savings_goal_transactions
+		WHERE savings_goal_id = $1 AND date <= $2
+	`, goalID, today).Scan(&netContributions, &firstDate)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &GoalProjection{
+		AmountRemaining: math.Max(targetAmount-currentAmount, 0),
+	}
+
+	if firstDate != nil {
+		// Con menos de un mes de historial se toma un mes, para no inflar el promedio
+		p.HistoryMonths = math.Round(today.Sub(*firstDate).Hours()/24/daysPerMonth*10) / 10
+		p.AverageMonthlyContribution = math.Round(netContributions/math.Max(p.HistoryMonths, 1)*100) / 100
+	}
+
+	p.RequiredMonthlyContribution = calculateRequiredMonthlySavings(currentAmount, targetAmount, deadline)
+
+	if p.AmountRemaining == 0 {
+		p.Status = "achieved"
+	} else {
+		p.MonthsToComplete, p.ProjectedCompletionDate = projectCompletion(p.AmountRemaining, p.AverageMonthlyContribution, today)
+
+		switch {
+		case deadline == nil:
+			p.Status = "no_deadline"
+		case p.ProjectedCompletionDate != nil && *p.ProjectedCompletionDate <= deadline.Format("2006-01-02"):
+			p.Status = "on_track"
+		default:
+			p.Status = "behind"
+		}
+	}
+
+	if whatIf != nil {
+		w := &WhatIfProjection{MonthlyContribution: *whatIf}
+		if p.AmountRemaining == 0 {
+			zero := 0.0
+			w.MonthsToComplete = &zero
+		} else {
+			w.MonthsToComplete, w.ProjectedCompletionDate = projectCompletion(p.AmountRemaining, *whatIf, today)
+		}
+		if deadline != nil {
+			meets := w.ProjectedCompletionDate == nil || *w.ProjectedCompletionDate <= deadline.Format("2006-01-02")
+			w.MeetsDeadline = &meets
+		}
+		p.WhatIf = w
+	}
+
+	return p, nil
+}
+
+// GetSavingsGoalProjection handles GET /api/savings-goals/:id/projection
+// Query param: monthly_contribution (opcional) = aporte mensual hipotético para el "what-if"
+func GetSavingsGoalProjection(db *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountID, exists := middleware.GetAccountID(c)
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "account_id not found in context"})
+			return
+		}
+
+		goalID := c.Param("id")
+		if goalID == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "savings_goal_id is required"})
+			return
+		}
+
+		whatIf, ok := parseWhatIf(c)
+		if !ok {
+			return
+		}
+
+		ctx := c.Request.Context()
+
+		var name, currency string
+		var currentAmount, targetAmount float64
+		var deadline *time.Time
+		err := db.QueryRow(ctx,
+			`SELECT name, currency, current_amount, target_amount, deadline FROM savings_goals WHERE id = $1 AND account_id = $2`,
+			goalID, accountID,
+		).Scan(&name, &currency, &currentAmount, &targetAmount, &deadline)
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "meta de ahorro no encontrada o no pertenece a esta cuenta"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch savings goal"})
+			return
+		}
+
+		projection, err := buildGoalProjection(ctx, db, goalID, currentAmount, targetAmount, deadline, whatIf)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to calculate projection"})
+			return
+		}
+
+		var deadlineStr *string
+		if deadline != nil {
+			formatted := deadline.Format("2006-01-02")
+			deadlineStr = &formatted
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"savings_goal": gin.H{
+				"id":             goalID,
+				"name":           name,
+				"currency":       currency,
+				"current_amount": currentAmount,
+				"target_amount":  targetAmount,
+				"deadline":       deadlineStr,
+			},
+			"projection": projection,
+		})
+	}
+}
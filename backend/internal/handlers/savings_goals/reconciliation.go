@@ -0,0 +1,117 @@
+package savings_goals
+
+import (
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/LorenzoCampos/bolsillo-claro/internal/middleware"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/savings"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// GoalLedgerReconciliation compares a goal's current_amount with the sum of its transactions
+type GoalLedgerReconciliation struct {
+	ID            string  `json:"id"`
+	Name          string  `json:"name"`
+	Currency      string  `json:"currency"`
+	IsActive      bool    `json:"is_active"`
+	CurrentAmount float64 `json:"current_amount"`
+	LedgerBalance float64 `json:"ledger_balance"` // Depósitos - retiros de savings_goal_transactions
+	Difference    float64 `json:"difference"`     // current_amount - ledger_balance
+	IsReconciled  bool    `json:"is_reconciled"`
+}
+
+// GetReconciliation handles GET /api/savings-goals/reconciliation
+// Verifica que current_amount de cada meta coincida con su ledger y muestra cómo impacta
+// lo asignado a metas en el saldo disponible de la cuenta (a hoy, en la moneda principal)
+func GetReconciliation(db *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountID, exists := middleware.GetAccountID(c)
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "account_id not found in context"})
+			return
+		}
+
+		ctx := c.Request.Context()
+		today := time.Now().UTC().Truncate(24 * time.Hour)
+
+		var primaryCurrency string
+		err := db.QueryRow(ctx, `SELECT currency FROM accounts WHERE id = $1`, accountID).Scan(&primaryCurrency)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get account currency"})
+			return
+		}
+
+		rows, err := db.Query(ctx, `
+			SELECT
+				sg.id, sg.name, sg.currency, sg.is_active, sg.current_amount,
+				COALESCE(SUM(CASE WHEN t.transaction_type = 'deposit' THEN t.amount ELSE -t.amount END), 0)
+			FROM savings_goals sg
+			LEFT JOIN savings_goal_transactions t ON t.savings_goal_id = sg.id
+			WHERE sg.account_id = $1
+			GROUP BY sg.id
+			ORDER BY sg.created_at ASC
+		`, accountID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch savings goals ledger"})
+			return
+		}
+		defer rows.Close()
+
+		goals := []GoalLedgerReconciliation{}
+		unreconciled := 0
+		for rows.Next() {
+			var g GoalLedgerReconciliation
+			err := rows.Scan(&g.ID, &g.Name, &g.Currency, &g.IsActive, &g.CurrentAmount, &g.LedgerBalance)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to parse savings goal ledger"})
+				return
+			}
+
+			g.Difference = math.Round((g.CurrentAmount-g.LedgerBalance)*100) / 100
+			g.IsReconciled = g.Difference == 0
+			if !g.IsReconciled {
+				unreconciled++
+			}
+			goals = append(goals, g)
+		}
+
+		if err := rows.Err(); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error reading savings goals ledger"})
+			return
+		}
+
+		var totalIncome, totalExpenses float64
+		err = db.QueryRow(ctx, `
+			SELECT
+				(SELECT COALESCE(SUM(amount_in_primary_currency), 0) FROM incomes WHERE account_id = $1 AND date <= $2),
+				(SELECT COALESCE(SUM(amount_in_primary_currency), 0) FROM expenses WHERE account_id = $1 AND NOT is_draft AND date <= $2)
+		`, accountID, today).Scan(&totalIncome, &totalExpenses)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to calculate account balance"})
+			return
+		}
+
+		assigned, err := savings.NetAssigned(ctx, db, accountID, nil, today)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to calculate total assigned to goals"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"as_of":              today.Format("2006-01-02"),
+			"goals":              goals,
+			"unreconciled_count": unreconciled,
+			"account": gin.H{
+				"primary_currency":              primaryCurrency,
+				"total_income":                  math.Round(totalIncome*100) / 100,
+				"total_expenses":                math.Round(totalExpenses*100) / 100,
+				"total_assigned_to_goals":       math.Round(assigned.Total*100) / 100,
+				"unconverted_goal_transactions": assigned.Unconverted,
+				"available_balance":             math.Round((totalIncome-totalExpenses-assigned.Total)*100) / 100,
+			},
+		})
+	}
+}
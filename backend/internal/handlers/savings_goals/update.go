@@ -9,6 +9,7 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/LorenzoCampos/bolsillo-claro/internal/middleware"
 	"github.com/LorenzoCampos/bolsillo-claro/pkg/logger"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/savings"
 )
 
 // UpdateSavingsGoalRequest represents the request to update a savings goal
@@ -105,6 +106,14 @@ func UpdateSavingsGoal(db *pgxpool.Pool) gin.HandlerFunc {
 			}
 		}
 
+		// Start transaction (update + hitos/achieved_at si cambió el target)
+		tx, err := db.Begin(ctx)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to start transaction"})
+			return
+		}
+		defer tx.Rollback(ctx)
+
 		// Build dynamic UPDATE query
 		updateQuery := `
 			UPDATE savings_goals SET
@@ -130,7 +139,7 @@ func UpdateSavingsGoal(db *pgxpool.Pool) gin.HandlerFunc {
 		var deadline *time.Time
 		var createdAt, updatedAt time.Time
 
-		err = db.QueryRow(ctx, updateQuery,
+		err = tx.QueryRow(ctx, updateQuery,
 			req.Name, req.Description, req.TargetAmount, req.SavedIn,
 			clearDeadline, deadlineDate, req.IsActive,
 			goalID, accountID,
@@ -145,9 +154,30 @@ func UpdateSavingsGoal(db *pgxpool.Pool) gin.HandlerFunc {
 			return
 		}
 
+		// Bajar el target puede completar hitos o la meta; subirlo la reabre
+		progress, err := savings.SyncProgress(ctx, tx, goalID, nil)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update milestones"})
+			return
+		}
+
+		err = tx.QueryRow(ctx, `SELECT `+goalStatusSQL+` FROM savings_goals WHERE id = $1`, goalID).Scan(&goal.Status)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get savings goal status"})
+			return
+		}
+
+		// Commit transaction
+		err = tx.Commit(ctx)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to commit transaction"})
+			return
+		}
+
 		// Set optional fields
 		goal.Description = description
 		goal.SavedIn = savedIn
+		goal.AchievedAt = formatTimestamp(progress.AchievedAt)
 
 		if deadline != nil {
 			deadlineStr := deadline.Format("2006-01-02")
@@ -178,6 +208,7 @@ func UpdateSavingsGoal(db *pgxpool.Pool) gin.HandlerFunc {
 		c.JSON(http.StatusOK, gin.H{
 			"message":      "Meta de ahorro actualizada exitosamente",
 			"savings_goal": goal,
+			"events":       newGoalEvents(progress.Events),
 		})
 	}
 }
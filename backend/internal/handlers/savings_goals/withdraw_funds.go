@@ -6,17 +6,21 @@ import (
 
 	"github.com/LorenzoCampos/bolsillo-claro/internal/middleware"
 	"github.com/LorenzoCampos/bolsillo-claro/pkg/logger"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/savings"
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 // WithdrawFundsRequest represents the request to withdraw funds from a savings goal
+// Currency is the currency the money is withdrawn to (defaults to the goal currency)
 type WithdrawFundsRequest struct {
-	Amount      float64 `json:"amount" binding:"required,gt=0"`
-	Description *string `json:"description,omitempty"`
-	Date        *string `json:"date,omitempty"` // Format: YYYY-MM-DD, defaults to today
+	Amount               float64  `json:"amount" binding:"required,gt=0"`
+	Currency             *string  `json:"currency,omitempty" binding:"omitempty,oneof=ARS USD EUR"`
+	AmountInGoalCurrency *float64 `json:"amount_in_goal_currency,omitempty" binding:"omitempty,gt=0"`
+	ExchangeRate         *float64 `json:"exchange_rate,omitempty" binding:"omitempty,gt=0"`
+	Description          *string  `json:"description,omitempty"`
+	Date                 *string  `json:"date,omitempty"` // Format: YYYY-MM-DD, defaults to today
 }
 
 // WithdrawFunds handles POST /api/savings-goals/:id/withdraw-funds
@@ -69,8 +73,14 @@ func WithdrawFunds(db *pgxpool.Pool) gin.HandlerFunc {
 		// First, we need to check the goal's deadline before starting the transaction
 		// to validate the transaction date
 		var goalDeadline *time.Time
-		preCheckQuery := `SELECT deadline FROM savings_goals WHERE id = $1 AND account_id = $2`
-		err = db.QueryRow(ctx, preCheckQuery, goalID, accountID).Scan(&goalDeadline)
+		var goalCurrency, primaryCurrency string
+		preCheckQuery := `
+			SELECT sg.deadline, sg.currency, a.currency
+			FROM savings_goals sg
+			JOIN accounts a ON a.id = sg.account_id
+			WHERE sg.id = $1 AND sg.account_id = $2
+		`
+		err = db.QueryRow(ctx, preCheckQuery, goalID, accountID).Scan(&goalDeadline, &goalCurrency, &primaryCurrency)
 
 		if err == pgx.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{"error": "meta de ahorro no encontrada o no pertenece a esta cuenta"})
@@ -105,69 +115,47 @@ func WithdrawFunds(db *pgxpool.Pool) gin.HandlerFunc {
 		}
 		defer tx.Rollback(ctx)
 
-		// Check if goal exists and belongs to this account
-		var currentAmount, targetAmount float64
-		var name string
-		var deadline *time.Time
-		checkQuery := `SELECT name, current_amount, target_amount, deadline FROM savings_goals WHERE id = $1 AND account_id = $2`
-		err = tx.QueryRow(ctx, checkQuery, goalID, accountID).Scan(&name, &currentAmount, &targetAmount, &deadline)
-
-		if err == pgx.ErrNoRows {
-			c.JSON(http.StatusNotFound, gin.H{"error": "meta de ahorro no encontrada o no pertenece a esta cuenta"})
+		// Convertir a la moneda de la meta (y a la moneda principal de la cuenta)
+		movement, err := savings.ResolveMovement(ctx, tx, "withdrawal", goalCurrency, primaryCurrency,
+			fundsSource(req.Amount, req.Currency, req.AmountInGoalCurrency, req.ExchangeRate), transactionDateStr)
+		if err == savings.ErrRateNotFound {
+			rateNotFound(c, *req.Currency, goalCurrency, transactionDateStr)
 			return
 		}
-
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check savings goal"})
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to convert amount to goal currency"})
+			return
+		}
+		movement.Description = req.Description
+
+		withdrawal, err := savings.Record(ctx, tx, goalID, accountID, *movement)
+		if err == savings.ErrGoalNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "meta de ahorro no encontrada o no pertenece a esta cuenta"})
 			return
 		}
 
 		// Validate that there are enough funds to withdraw
-		if req.Amount > currentAmount {
+		if err == savings.ErrInsufficientFunds {
 			c.JSON(http.StatusBadRequest, gin.H{
 				"error":          "No hay suficientes fondos para retirar",
-				"current_amount": currentAmount,
-				"requested":      req.Amount,
-				"available":      currentAmount,
+				"current_amount": withdrawal.Goal.CurrentAmount,
+				"requested":      movement.Amount,
+				"available":      withdrawal.Goal.CurrentAmount,
 			})
 			return
 		}
 
-		// Create transaction record
-		var transactionID uuid.UUID
-		var createdAt time.Time
-		insertTxnQuery := `
-			INSERT INTO savings_goal_transactions (
-				savings_goal_id, amount, transaction_type, description, date
-			) VALUES ($1, $2, 'withdrawal', $3, $4)
-			RETURNING id, created_at
-		`
-
-		err = tx.QueryRow(ctx, insertTxnQuery,
-			goalID, req.Amount, req.Description, transactionDateStr,
-		).Scan(&transactionID, &createdAt)
-
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create transaction"})
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to withdraw funds from savings goal"})
 			return
 		}
 
-		// Update savings goal current_amount
-		newAmount := currentAmount - req.Amount
-		updateQuery := `
-			UPDATE savings_goals 
-			SET current_amount = $1, updated_at = NOW()
-			WHERE id = $2
-			RETURNING current_amount, updated_at
-		`
-
-		var updatedAmount float64
-		var updatedAt time.Time
-		err = tx.QueryRow(ctx, updateQuery, newAmount, goalID).Scan(&updatedAmount, &updatedAt)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update savings goal"})
-			return
-		}
+		name := withdrawal.Goal.Name
+		targetAmount := withdrawal.Goal.TargetAmount
+		updatedAmount := withdrawal.Goal.CurrentAmount
+		updatedAt := withdrawal.Goal.UpdatedAt
+		transactionID := withdrawal.TransactionID
+		createdAt := withdrawal.CreatedAt
 
 		// Commit transaction
 		err = tx.Commit(ctx)
@@ -190,7 +178,8 @@ func WithdrawFunds(db *pgxpool.Pool) gin.HandlerFunc {
 			"goal_id":     goalID,
 			"account_id":  accountID,
 			"user_id":     userID,
-			"amount":      req.Amount,
+			"amount":      movement.Amount,
+			"currency":    movement.SourceCurrency,
 			"new_balance": updatedAmount,
 			"goal_name":   name,
 			"ip":          c.ClientIP(),
@@ -202,19 +191,26 @@ func WithdrawFunds(db *pgxpool.Pool) gin.HandlerFunc {
 			"savings_goal": gin.H{
 				"id":                  goalID,
 				"name":                name,
+				"currency":            goalCurrency,
 				"current_amount":      updatedAmount,
 				"target_amount":       targetAmount,
 				"progress_percentage": progressPercentage,
+				"achieved_at":         formatTimestamp(withdrawal.Goal.AchievedAt),
 				"updated_at":          updatedAt.Format(time.RFC3339),
 			},
 			"transaction": gin.H{
-				"id":               transactionID.String(),
-				"amount":           -req.Amount, // Negative for display
-				"transaction_type": "withdrawal",
-				"description":      req.Description,
-				"date":             transactionDateStr,
-				"created_at":       createdAt.Format(time.RFC3339),
+				"id":                         transactionID.String(),
+				"amount":                     -movement.Amount, // Negative for display
+				"source_currency":            movement.SourceCurrency,
+				"source_amount":              movement.SourceAmount,
+				"exchange_rate":              movement.ExchangeRate,
+				"amount_in_primary_currency": movement.AmountInPrimaryCurrency,
+				"transaction_type":           "withdrawal",
+				"description":                req.Description,
+				"date":                       transactionDateStr,
+				"created_at":                 createdAt.Format(time.RFC3339),
 			},
+			"events": newGoalEvents(withdrawal.Events),
 		})
 	}
 }
@@ -0,0 +1,323 @@
+package search
+
+import (
+	"html"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/search"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Result types
+const (
+	TypeExpense            = "expense"
+	TypeIncome             = "income"
+	TypeRecurringExpense   = "recurring_expense"
+	TypeRecurringIncome    = "recurring_income"
+	TypeSavingsTransaction = "savings_transaction"
+)
+
+// SearchResult is a movement matching the search, of any type
+type SearchResult struct {
+	Type            string  `json:"type"`
+	ID              string  `json:"id"`
+	Description     string  `json:"description"`
+	Highlight       string  `json:"highlight"` // HTML-escaped description with the matched words inside <mark></mark>
+	Amount          float64 `json:"amount"`
+	Currency        string  `json:"currency"`
+	Date            string  `json:"date"` // start_date for recurring templates
+	CategoryID      *string `json:"category_id,omitempty"`
+	CategoryName    *string `json:"category_name,omitempty"`
+	SavingsGoalID   *string `json:"savings_goal_id,omitempty"`
+	SavingsGoalName *string `json:"savings_goal_name,omitempty"`
+	TransactionType *string `json:"transaction_type,omitempty"` // deposit, withdrawal (savings transactions)
+	Rank            float64 `json:"rank"`
+}
+
+// ParsedQuery shows how the query was interpreted
+type ParsedQuery struct {
+	Text      string   `json:"text"`
+	AmountMin *float64 `json:"amount_min,omitempty"`
+	AmountMax *float64 `json:"amount_max,omitempty"`
+	DateFrom  *string  `json:"date_from,omitempty"`
+	DateTo    *string  `json:"date_to,omitempty"`
+}
+
+type SearchResponse struct {
+	Query      string         `json:"query"`
+	Parsed     ParsedQuery    `json:"parsed"`
+	Results    []SearchResult `json:"results"`
+	TotalCount int            `json:"total_count"`
+	Page       int            `json:"page"`
+	Limit      int            `json:"limit"`
+	TotalPages int            `json:"total_pages"`
+}
+
+// source describes how each searchable table maps to the common result columns
+type source struct {
+	Type            string
+	From            string
+	Account         string // condition on $1 (account_id)
+	Description     string
+	Document        string // searched text; has to match the index expression of migration 034
+	Amount          string
+	Currency        string
+	Date            string
+	CategoryID      string
+	CategoryName    string
+	SavingsGoalID   string
+	SavingsGoalName string
+	TransactionType string
+}
+
+// highlightMarks restores the <mark> tags of ts_headline after escaping the description as HTML
+var highlightMarks = strings.NewReplacer("&lt;mark&gt;", "<mark>", "&lt;/mark&gt;", "</mark>")
+
+// filter is an amount or date bound from the query, applied to every branch
+type filter struct {
+	value interface{}
+	op    string
+	date  bool // compares the date column instead of the amount
+}
+
+var sources = []source{
+	{
+		Type: TypeExpense, From: `expenses x LEFT JOIN expense_categories c ON c.id = x.category_id`,
+		Account: `x.account_id = $1 AND NOT x.is_draft`, Description: `x.description`, Document: `x.description`,
+		Amount: `x.amount`, Currency: `x.currency::TEXT`, Date: `x.date`,
+		CategoryID: `x.category_id::TEXT`, CategoryName: `c.name`,
+		SavingsGoalID: `NULL::TEXT`, SavingsGoalName: `NULL::TEXT`, TransactionType: `NULL::TEXT`,
+	},
+	{
+		Type: TypeIncome, From: `incomes x LEFT JOIN income_categories c ON c.id = x.category_id`,
+		Account: `x.account_id = $1`, Description: `x.description`, Document: `x.description`,
+		Amount: `x.amount`, Currency: `x.currency::TEXT`, Date: `x.date`,
+		CategoryID: `x.category_id::TEXT`, CategoryName: `c.name`,
+		SavingsGoalID: `NULL::TEXT`, SavingsGoalName: `NULL::TEXT`, TransactionType: `NULL::TEXT`,
+	},
+	{
+		Type: TypeRecurringExpense, From: `recurring_expenses x LEFT JOIN expense_categories c ON c.id = x.category_id`,
+		Account: `x.account_id = $1 AND x.is_active = true`, Description: `x.description`, Document: `x.description`,
+		Amount: `x.amount`, Currency: `x.currency::TEXT`, Date: `x.start_date`,
+		CategoryID: `x.category_id::TEXT`, CategoryName: `c.name`,
+		SavingsGoalID: `NULL::TEXT`, SavingsGoalName: `NULL::TEXT`, TransactionType: `NULL::TEXT`,
+	},
+	{
+		Type: TypeRecurringIncome, From: `recurring_incomes x LEFT JOIN income_categories c ON c.id = x.category_id`,
+		Account: `x.account_id = $1 AND x.is_active = true`, Description: `x.description`, Document: `x.description`,
+		Amount: `x.amount`, Currency: `x.currency::TEXT`, Date: `x.start_date`,
+		CategoryID: `x.category_id::TEXT`, CategoryName: `c.name`,
+		SavingsGoalID: `NULL::TEXT`, SavingsGoalName: `NULL::TEXT`, TransactionType: `NULL::TEXT`,
+	},
+	{
+		// Deposits and withdrawals are found by their own description or by the goal name
+		Type: TypeSavingsTransaction, From: `savings_goal_transactions x JOIN savings_goals g ON g.id = x.savings_goal_id`,
+		Account:     `g.account_id = $1 AND COALESCE(g.is_active, true) = true`,
+		Description: `COALESCE(x.description, g.name)`, Document: `COALESCE(x.description, '') || ' ' || g.name`,
+		Amount: `x.amount`, Currency: `g.currency::TEXT`, Date: `x.date`,
+		CategoryID: `NULL::TEXT`, CategoryName: `NULL::TEXT`,
+		SavingsGoalID: `g.id::TEXT`, SavingsGoalName: `g.name`, TransactionType: `x.transaction_type::TEXT`,
+	},
+}
+
+// SearchTransactions handles GET /api/search?q=&types=&page=&limit=
+// Full-text search (Spanish stemming, accents ignored) over expenses, incomes, recurring templates
+// and savings goal transactions. The query also accepts amount and date filters, see search.Parse
+func SearchTransactions(db *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountID, exists := c.Get("account_id")
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "account_id not found in context"})
+			return
+		}
+
+		input := strings.TrimSpace(c.Query("q"))
+		if input == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "q is required"})
+			return
+		}
+		if len(input) > 200 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "q must have at most 200 characters"})
+			return
+		}
+
+		query, err := search.Parse(input)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if !query.HasText() && query.AmountMin == nil && query.AmountMax == nil && query.DateFrom == nil && query.DateTo == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "q must have at least one word, amount or date"})
+			return
+		}
+
+		selected := sources
+		if typesParam := c.Query("types"); typesParam != "" {
+			wanted := map[string]bool{}
+			for _, t := range strings.Split(typesParam, ",") {
+				wanted[strings.TrimSpace(t)] = true
+			}
+			selected = nil
+			for _, s := range sources {
+				if wanted[s.Type] {
+					selected = append(selected, s)
+					delete(wanted, s.Type)
+				}
+			}
+			if len(wanted) > 0 || len(selected) == 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "types must be a comma-separated list of: expense, income, recurring_expense, recurring_income, savings_transaction"})
+				return
+			}
+		}
+
+		page := 1
+		if pageStr := c.Query("page"); pageStr != "" {
+			parsed, err := strconv.Atoi(pageStr)
+			if err != nil || parsed < 1 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "page must be a positive number"})
+				return
+			}
+			page = parsed
+		}
+		limit := 20
+		if limitStr := c.Query("limit"); limitStr != "" {
+			parsed, err := strconv.Atoi(limitStr)
+			if err != nil || parsed < 1 || parsed > 100 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 100"})
+				return
+			}
+			limit = parsed
+		}
+
+		// Shared placeholders: every branch of the UNION uses the same arguments
+		args := []interface{}{accountID}
+		argIndex := 2
+
+		tsQuery := ""
+		if query.HasText() {
+			tsQuery = `to_tsquery('` + search.Config + `', $` + strconv.Itoa(argIndex) + `)`
+			args = append(args, query.TSQuery())
+			argIndex++
+		}
+
+		var filters []filter
+		if query.AmountMin != nil {
+			filters = append(filters, filter{*query.AmountMin, ">=", false})
+		}
+		if query.AmountMax != nil {
+			filters = append(filters, filter{*query.AmountMax, "<=", false})
+		}
+		if query.DateFrom != nil {
+			filters = append(filters, filter{*query.DateFrom, ">=", true})
+		}
+		if query.DateTo != nil {
+			filters = append(filters, filter{*query.DateTo, "<=", true})
+		}
+		filterArgs := make([]int, len(filters))
+		for i, f := range filters {
+			filterArgs[i] = argIndex
+			args = append(args, f.value)
+			argIndex++
+		}
+
+		branches := make([]string, 0, len(selected))
+		for _, s := range selected {
+			document := `to_tsvector('` + search.Config + `', ` + s.Document + `)`
+			rank := `0::FLOAT8`
+			highlight := s.Description
+			where := s.Account
+			if tsQuery != "" {
+				rank = `ts_rank_cd(` + document + `, ` + tsQuery + `)::FLOAT8`
+				highlight = `ts_headline('` + search.Config + `', ` + s.Description + `, ` + tsQuery + `, 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true')`
+				where += ` AND ` + document + ` @@ ` + tsQuery
+			}
+			for i, f := range filters {
+				column := s.Amount
+				if f.date {
+					column = s.Date
+				}
+				where += ` AND ` + column + ` ` + f.op + ` $` + strconv.Itoa(filterArgs[i])
+			}
+
+			branches = append(branches, `
+				SELECT '`+s.Type+`' AS type, x.id::TEXT AS id, `+s.Description+` AS description, `+highlight+` AS highlight,
+					`+s.Amount+`::FLOAT8 AS amount, `+s.Currency+` AS currency, `+s.Date+` AS date,
+					`+s.CategoryID+` AS category_id, `+s.CategoryName+` AS category_name,
+					`+s.SavingsGoalID+` AS savings_goal_id, `+s.SavingsGoalName+` AS savings_goal_name,
+					`+s.TransactionType+` AS transaction_type, `+rank+` AS rank
+				FROM `+s.From+`
+				WHERE `+where)
+		}
+
+		sqlQuery := `
+			SELECT r.*, COUNT(*) OVER() AS total_count
+			FROM (` + strings.Join(branches, `
+				UNION ALL`) + `
+			) r
+			ORDER BY r.rank DESC, r.date DESC, r.id
+			LIMIT $` + strconv.Itoa(argIndex) + ` OFFSET $` + strconv.Itoa(argIndex+1)
+		args = append(args, limit, (page-1)*limit)
+
+		rows, err := db.Query(c.Request.Context(), sqlQuery, args...)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to search: " + err.Error()})
+			return
+		}
+		defer rows.Close()
+
+		results := []SearchResult{}
+		totalCount := 0
+		for rows.Next() {
+			var r SearchResult
+			var date time.Time
+			if err := rows.Scan(
+				&r.Type, &r.ID, &r.Description, &r.Highlight, &r.Amount, &r.Currency, &date,
+				&r.CategoryID, &r.CategoryName, &r.SavingsGoalID, &r.SavingsGoalName,
+				&r.TransactionType, &r.Rank, &totalCount,
+			); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to scan search result: " + err.Error()})
+				return
+			}
+			r.Date = date.Format("2006-01-02")
+			r.Highlight = highlightMarks.Replace(html.EscapeString(r.Highlight))
+			results = append(results, r)
+		}
+		if err := rows.Err(); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to search: " + err.Error()})
+			return
+		}
+
+		// Past the last page there are no rows to read the total from
+		if len(results) == 0 && page > 1 {
+			countQuery := `SELECT COUNT(*) FROM (` + strings.Join(branches, ` UNION ALL`) + `) r`
+			if err := db.QueryRow(c.Request.Context(), countQuery, args[:len(args)-2]...).Scan(&totalCount); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to count search results: " + err.Error()})
+				return
+			}
+		}
+
+		parsed := ParsedQuery{Text: query.Text(), AmountMin: query.AmountMin, AmountMax: query.AmountMax}
+		if query.DateFrom != nil {
+			from := query.DateFrom.Format("2006-01-02")
+			parsed.DateFrom = &from
+		}
+		if query.DateTo != nil {
+			to := query.DateTo.Format("2006-01-02")
+			parsed.DateTo = &to
+		}
+
+		c.JSON(http.StatusOK, SearchResponse{
+			Query:      input,
+			Parsed:     parsed,
+			Results:    results,
+			TotalCount: totalCount,
+			Page:       page,
+			Limit:      limit,
+			TotalPages: (totalCount + limit - 1) / limit,
+		})
+	}
+}
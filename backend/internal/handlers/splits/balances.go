@@ -0,0 +1,203 @@
+package splits
+
+import (
+	"context"
+	"net/http"
+	"sort"
+
+	"github.com/LorenzoCampos/bolsillo-claro/internal/database"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/money"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/splits"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type MemberBalance struct {
+	FamilyMemberID      string  `json:"family_member_id"`
+	FamilyMemberName    string  `json:"family_member_name"`
+	IsActive            bool    `json:"is_active"`
+	Paid                float64 `json:"paid"`                 // Split expenses this member paid
+	Share               float64 `json:"share"`                // This member's part of the split expenses
+	SettlementsPaid     float64 `json:"settlements_paid"`     // Reimbursements this member made
+	SettlementsReceived float64 `json:"settlements_received"` // Reimbursements this member got
+	Balance             float64 `json:"balance"`              // > 0 the others owe them, < 0 they owe
+}
+
+type TransferResponse struct {
+	FromMemberID   string  `json:"from_member_id"`
+	FromMemberName string  `json:"from_member_name"`
+	ToMemberID     string  `json:"to_member_id"`
+	ToMemberName   string  `json:"to_member_name"`
+	Amount         float64 `json:"amount"`
+}
+
+type BalancesResponse struct {
+	Currency  string             `json:"currency"`
+	Members   []MemberBalance    `json:"members"`
+	Transfers []TransferResponse `json:"transfers"` // Suggested payments to settle up
+}
+
+// computeBalances derives who owes whom from the split expenses and the settlements, in the
+// account currency (amount_in_primary_currency of each expense)
+func computeBalances(ctx context.Context, q database.Querier, accountID interface{}) ([]MemberBalance, []TransferResponse, error) {
+	balances := map[string]*MemberBalance{}
+
+	rows, err := q.Query(ctx, `SELECT id, name, is_active FROM family_members WHERE account_id = $1`, accountID)
+	if err != nil {
+		return nil, nil, err
+	}
+	for rows.Next() {
+		var m MemberBalance
+		if err := rows.Scan(&m.FamilyMemberID, &m.FamilyMemberName, &m.IsActive); err != nil {
+			rows.Close()
+			return nil, nil, err
+		}
+		balances[m.FamilyMemberID] = &m
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	type splitExpense struct {
+		paidBy    string
+		splitType string
+		amount    float64
+		shares    []splits.Share
+	}
+	expenses := map[string]*splitExpense{}
+	var order []string
+
+	rows, err = q.Query(ctx, `
+		SELECT e.id, e.paid_by_member_id, e.split_type, e.amount_in_primary_currency,
+		       s.family_member_id, s.percentage, s.amount
+		FROM expenses e
+		JOIN expense_splits s ON s.expense_id = e.id
+		WHERE e.account_id = $1 AND NOT e.is_draft AND e.split_type IS NOT NULL AND e.paid_by_member_id IS NOT NULL
+		ORDER BY e.id, s.created_at, s.family_member_id
+	`, accountID)
+	if err != nil {
+		return nil, nil, err
+	}
+	for rows.Next() {
+		var expenseID string
+		var e splitExpense
+		var share splits.Share
+		if err := rows.Scan(&expenseID, &e.paidBy, &e.splitType, &e.amount, &share.MemberID, &share.Percentage, &share.Amount); err != nil {
+			rows.Close()
+			return nil, nil, err
+		}
+		if _, ok := expenses[expenseID]; !ok {
+			expenses[expenseID] = &e
+			order = append(order, expenseID)
+		}
+		expenses[expenseID].shares = append(expenses[expenseID].shares, share)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	for _, id := range order {
+		e := expenses[id]
+		if m, ok := balances[e.paidBy]; ok {
+			m.Paid += e.amount
+		}
+		for i, amount := range splits.Amounts(e.splitType, e.amount, e.shares) {
+			if m, ok := balances[e.shares[i].MemberID]; ok {
+				m.Share += amount
+			}
+		}
+	}
+
+	rows, err = q.Query(ctx, `SELECT from_member_id, to_member_id, amount FROM split_settlements WHERE account_id = $1`, accountID)
+	if err != nil {
+		return nil, nil, err
+	}
+	for rows.Next() {
+		var from, to string
+		var amount float64
+		if err := rows.Scan(&from, &to, &amount); err != nil {
+			rows.Close()
+			return nil, nil, err
+		}
+		if m, ok := balances[from]; ok {
+			m.SettlementsPaid += amount
+		}
+		if m, ok := balances[to]; ok {
+			m.SettlementsReceived += amount
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	members := []MemberBalance{}
+	net := map[string]float64{}
+	for id, m := range balances {
+		m.Paid = money.Round(m.Paid)
+		m.Share = money.Round(m.Share)
+		m.SettlementsPaid = money.Round(m.SettlementsPaid)
+		m.SettlementsReceived = money.Round(m.SettlementsReceived)
+		m.Balance = money.Round(m.Paid - m.Share + m.SettlementsPaid - m.SettlementsReceived)
+
+		// Los miembros inactivos sin movimientos no aportan nada
+		if !m.IsActive && m.Paid == 0 && m.Share == 0 && m.SettlementsPaid == 0 && m.SettlementsReceived == 0 {
+			continue
+		}
+		members = append(members, *m)
+		net[id] = m.Balance
+	}
+	sort.Slice(members, func(i, j int) bool {
+		if members[i].Balance != members[j].Balance {
+			return members[i].Balance > members[j].Balance
+		}
+		return members[i].FamilyMemberName < members[j].FamilyMemberName
+	})
+
+	transfers := []TransferResponse{}
+	for _, t := range splits.Simplify(net) {
+		transfers = append(transfers, TransferResponse{
+			FromMemberID:   t.From,
+			FromMemberName: balances[t.From].FamilyMemberName,
+			ToMemberID:     t.To,
+			ToMemberName:   balances[t.To].FamilyMemberName,
+			Amount:         t.Amount,
+		})
+	}
+
+	return members, transfers, nil
+}
+
+// GetBalances handles GET /api/splits/balances
+// Returns what each member paid and owes for the split expenses, and the payments that settle up
+func GetBalances(db *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountID, exists := c.Get("account_id")
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "account_id not found in context"})
+			return
+		}
+
+		ctx := c.Request.Context()
+
+		var currency string
+		if err := db.QueryRow(ctx, `SELECT currency FROM accounts WHERE id = $1`, accountID).Scan(&currency); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get account currency"})
+			return
+		}
+
+		members, transfers, err := computeBalances(ctx, db, accountID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to compute balances: " + err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, BalancesResponse{
+			Currency:  currency,
+			Members:   members,
+			Transfers: transfers,
+		})
+	}
+}
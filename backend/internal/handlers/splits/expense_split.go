@@ -0,0 +1,374 @@
+package splits
+
+import (
+	"context"
+	"math"
+	"net/http"
+
+	"github.com/LorenzoCampos/bolsillo-claro/internal/middleware"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/logger"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/splits"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ShareRequest struct {
+	FamilyMemberID string   `json:"family_member_id" binding:"required,uuid"`
+	Percentage     *float64 `json:"percentage" binding:"omitempty,gt=0,lte=100"` // split_type = percentage
+	Amount         *float64 `json:"amount" binding:"omitempty,gt=0"`             // split_type = exact, in the expense currency
+}
+
+type SetSplitRequest struct {
+	PaidByMemberID *string        `json:"paid_by_member_id" binding:"omitempty,uuid"` // Defaults to the expense family_member_id
+	SplitType      string         `json:"split_type" binding:"required,oneof=equal percentage exact"`
+	Shares         []ShareRequest `json:"shares" binding:"required,min=1,dive"`
+}
+
+type ShareResponse struct {
+	FamilyMemberID          string   `json:"family_member_id"`
+	FamilyMemberName        string   `json:"family_member_name"`
+	Percentage              *float64 `json:"percentage,omitempty"`
+	Amount                  float64  `json:"amount"` // In the expense currency
+	AmountInPrimaryCurrency float64  `json:"amount_in_primary_currency"`
+}
+
+type ExpenseSplitResponse struct {
+	ExpenseID               string          `json:"expense_id"`
+	Description             string          `json:"description"`
+	Date                    string          `json:"date"`
+	Amount                  float64         `json:"amount"`
+	Currency                string          `json:"currency"`
+	AmountInPrimaryCurrency float64         `json:"amount_in_primary_currency"`
+	PaidByMemberID          string          `json:"paid_by_member_id"`
+	PaidByMemberName        string          `json:"paid_by_member_name"`
+	SplitType               string          `json:"split_type"`
+	Shares                  []ShareResponse `json:"shares"`
+}
+
+// isFamilyAccount checks the account type: splits only make sense between family members
+func isFamilyAccount(ctx context.Context, db *pgxpool.Pool, accountID interface{}) (bool, error) {
+	var accountType string
+	err := db.QueryRow(ctx, `SELECT type FROM accounts WHERE id = $1`, accountID).Scan(&accountType)
+	return accountType == "family", err
+}
+
+// activeMembers returns the names of the active members of the account
+func activeMembers(ctx context.Context, db *pgxpool.Pool, accountID interface{}) (map[string]string, error) {
+	rows, err := db.Query(ctx, `SELECT id, name FROM family_members WHERE account_id = $1 AND is_active = true`, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := map[string]string{}
+	for rows.Next() {
+		var id, name string
+		if err := rows.Scan(&id, &name); err != nil {
+			return nil, err
+		}
+		members[id] = name
+	}
+	return members, rows.Err()
+}
+
+// fetchSplit loads the split of an expense with the share amounts computed over its current amount
+// Returns pgx.ErrNoRows if the expense doesn't exist or isn't split
+func fetchSplit(ctx context.Context, db *pgxpool.Pool, expenseID string, accountID interface{}) (*ExpenseSplitResponse, error) {
+	var split ExpenseSplitResponse
+	err := db.QueryRow(ctx, `
+		SELECT e.id, e.description, e.date::TEXT, e.amount, e.currency, e.amount_in_primary_currency,
+		       e.paid_by_member_id, pb.name, e.split_type
+		FROM expenses e
+		JOIN family_members pb ON pb.id = e.paid_by_member_id
+		WHERE e.id = $1 AND e.account_id = $2 AND e.split_type IS NOT NULL
+	`, expenseID, accountID).Scan(
+		&split.ExpenseID, &split.Description, &split.Date, &split.Amount, &split.Currency,
+		&split.AmountInPrimaryCurrency, &split.PaidByMemberID, &split.PaidByMemberName, &split.SplitType,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(ctx, `
+		SELECT s.family_member_id, fm.name, s.percentage, s.amount
+		FROM expense_splits s
+		JOIN family_members fm ON fm.id = s.family_member_id
+		WHERE s.expense_id = $1
+		ORDER BY s.created_at, fm.name
+	`, expenseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var shares []splits.Share
+	for rows.Next() {
+		var share ShareResponse
+		var stored splits.Share
+		if err := rows.Scan(&share.FamilyMemberID, &share.FamilyMemberName, &stored.Percentage, &stored.Amount); err != nil {
+			return nil, err
+		}
+		share.Percentage = stored.Percentage
+		stored.MemberID = share.FamilyMemberID
+		shares = append(shares, stored)
+		split.Shares = append(split.Shares, share)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	amounts := splits.Amounts(split.SplitType, split.Amount, shares)
+	primaryAmounts := splits.Amounts(split.SplitType, split.AmountInPrimaryCurrency, shares)
+	for i := range split.Shares {
+		split.Shares[i].Amount = amounts[i]
+		split.Shares[i].AmountInPrimaryCurrency = primaryAmounts[i]
+	}
+
+	return &split, nil
+}
+
+// GetExpenseSplit handles GET /api/expenses/:id/split
+func GetExpenseSplit(db *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountID, exists := c.Get("account_id")
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "account_id not found in context"})
+			return
+		}
+
+		split, err := fetchSplit(c.Request.Context(), db, c.Param("id"), accountID)
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "expense not found or not split"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch split: " + err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, split)
+	}
+}
+
+// SetExpenseSplit handles PUT /api/expenses/:id/split
+// Splits the expense among family members (replaces any previous split) and records who paid it
+func SetExpenseSplit(db *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountID, exists := c.Get("account_id")
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "account_id not found in context"})
+			return
+		}
+
+		expenseID := c.Param("id")
+
+		var req SetSplitRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx := c.Request.Context()
+
+		family, err := isFamilyAccount(ctx, db, accountID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get account: " + err.Error()})
+			return
+		}
+		if !family {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "expenses can only be split in family accounts"})
+			return
+		}
+
+		var amount float64
+		var familyMemberID *string
+		err = db.QueryRow(ctx,
+			`SELECT amount, family_member_id FROM expenses WHERE id = $1 AND account_id = $2`,
+			expenseID, accountID,
+		).Scan(&amount, &familyMemberID)
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "expense not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch expense: " + err.Error()})
+			return
+		}
+
+		paidBy := familyMemberID
+		if req.PaidByMemberID != nil {
+			paidBy = req.PaidByMemberID
+		}
+		if paidBy == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "paid_by_member_id is required (the expense has no family_member_id)"})
+			return
+		}
+
+		members, err := activeMembers(ctx, db, accountID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch members: " + err.Error()})
+			return
+		}
+		if _, ok := members[*paidBy]; !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "paid_by_member_id is not an active member of this account"})
+			return
+		}
+
+		seen := map[string]bool{}
+		total := 0.0
+		for _, share := range req.Shares {
+			if _, ok := members[share.FamilyMemberID]; !ok {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "family_member_id " + share.FamilyMemberID + " is not an active member of this account"})
+				return
+			}
+			if seen[share.FamilyMemberID] {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "each member can appear only once in shares"})
+				return
+			}
+			seen[share.FamilyMemberID] = true
+
+			switch req.SplitType {
+			case splits.SplitEqual:
+				if share.Percentage != nil || share.Amount != nil {
+					c.JSON(http.StatusBadRequest, gin.H{"error": "equal splits don't take percentage or amount"})
+					return
+				}
+			case splits.SplitPercentage:
+				if share.Percentage == nil || share.Amount != nil {
+					c.JSON(http.StatusBadRequest, gin.H{"error": "percentage splits need a percentage (and no amount) per member"})
+					return
+				}
+				total += *share.Percentage
+			case splits.SplitExact:
+				if share.Amount == nil || share.Percentage != nil {
+					c.JSON(http.StatusBadRequest, gin.H{"error": "exact splits need an amount (and no percentage) per member"})
+					return
+				}
+				total += *share.Amount
+			}
+		}
+
+		if req.SplitType == splits.SplitPercentage && math.Abs(total-100) > 0.01 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "percentages must add up to 100", "total": total})
+			return
+		}
+		if req.SplitType == splits.SplitExact && math.Abs(total-amount) > 0.005 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "amounts must add up to the expense amount", "total": total, "expense_amount": amount})
+			return
+		}
+
+		tx, err := db.Begin(ctx)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to start transaction: " + err.Error()})
+			return
+		}
+		defer tx.Rollback(ctx)
+
+		_, err = tx.Exec(ctx,
+			`UPDATE expenses SET split_type = $1, paid_by_member_id = $2 WHERE id = $3 AND account_id = $4`,
+			req.SplitType, *paidBy, expenseID, accountID,
+		)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update expense: " + err.Error()})
+			return
+		}
+
+		if _, err := tx.Exec(ctx, `DELETE FROM expense_splits WHERE expense_id = $1`, expenseID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to replace split: " + err.Error()})
+			return
+		}
+
+		for _, share := range req.Shares {
+			_, err := tx.Exec(ctx, `
+				INSERT INTO expense_splits (expense_id, family_member_id, percentage, amount)
+				VALUES ($1, $2, $3, $4)
+			`, expenseID, share.FamilyMemberID, share.Percentage, share.Amount)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save split: " + err.Error()})
+				return
+			}
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to commit transaction: " + err.Error()})
+			return
+		}
+
+		split, err := fetchSplit(ctx, db, expenseID, accountID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch split: " + err.Error()})
+			return
+		}
+
+		userID, _ := middleware.GetUserID(c)
+		logger.Info("expense.split.set", "Gasto dividido entre miembros", map[string]interface{}{
+			"expense_id": expenseID,
+			"account_id": accountID,
+			"user_id":    userID,
+			"split_type": req.SplitType,
+			"members":    len(req.Shares),
+			"ip":         c.ClientIP(),
+		})
+
+		c.JSON(http.StatusOK, split)
+	}
+}
+
+// DeleteExpenseSplit handles DELETE /api/expenses/:id/split
+// The expense stays, it just stops counting in the balances
+func DeleteExpenseSplit(db *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountID, exists := c.Get("account_id")
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "account_id not found in context"})
+			return
+		}
+
+		expenseID := c.Param("id")
+		ctx := c.Request.Context()
+
+		tx, err := db.Begin(ctx)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to start transaction: " + err.Error()})
+			return
+		}
+		defer tx.Rollback(ctx)
+
+		result, err := tx.Exec(ctx, `
+			UPDATE expenses SET split_type = NULL, paid_by_member_id = NULL
+			WHERE id = $1 AND account_id = $2 AND split_type IS NOT NULL
+		`, expenseID, accountID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update expense: " + err.Error()})
+			return
+		}
+		if result.RowsAffected() == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "expense not found or not split"})
+			return
+		}
+
+		if _, err := tx.Exec(ctx, `DELETE FROM expense_splits WHERE expense_id = $1`, expenseID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete split: " + err.Error()})
+			return
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to commit transaction: " + err.Error()})
+			return
+		}
+
+		userID, _ := middleware.GetUserID(c)
+		logger.Info("expense.split.deleted", "División de gasto eliminada", map[string]interface{}{
+			"expense_id": expenseID,
+			"account_id": accountID,
+			"user_id":    userID,
+			"ip":         c.ClientIP(),
+		})
+
+		c.JSON(http.StatusOK, gin.H{
+			"message":    "split deleted successfully",
+			"expense_id": expenseID,
+		})
+	}
+}
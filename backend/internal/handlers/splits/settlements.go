@@ -0,0 +1,313 @@
+package splits
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/LorenzoCampos/bolsillo-claro/internal/middleware"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type CreateSettlementRequest struct {
+	FromMemberID string  `json:"from_member_id" binding:"required,uuid"`
+	ToMemberID   string  `json:"to_member_id" binding:"required,uuid"`
+	Amount       float64 `json:"amount" binding:"required,gt=0"` // In the account currency
+	Date         *string `json:"date"`                           // YYYY-MM-DD, defaults to today
+	Notes        *string `json:"notes"`
+}
+
+type SettleUpRequest struct {
+	Date  *string `json:"date"` // YYYY-MM-DD, defaults to today
+	Notes *string `json:"notes"`
+}
+
+type SettlementResponse struct {
+	ID             string  `json:"id"`
+	FromMemberID   string  `json:"from_member_id"`
+	FromMemberName string  `json:"from_member_name"`
+	ToMemberID     string  `json:"to_member_id"`
+	ToMemberName   string  `json:"to_member_name"`
+	Amount         float64 `json:"amount"`
+	Date           string  `json:"date"`
+	Notes          *string `json:"notes"`
+	CreatedAt      string  `json:"created_at"`
+}
+
+// parseSettlementDate defaults to today and rejects future dates
+func parseSettlementDate(value *string) (time.Time, string) {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	if value == nil || *value == "" {
+		return today, ""
+	}
+
+	date, err := time.Parse("2006-01-02", *value)
+	if err != nil {
+		return time.Time{}, "invalid date format, use YYYY-MM-DD"
+	}
+	if date.After(today) {
+		return time.Time{}, "date cannot be in the future"
+	}
+	return date, ""
+}
+
+// CreateSettlement handles POST /api/splits/settlements
+// Records a reimbursement between two members; it doesn't create an expense
+func CreateSettlement(db *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountID, exists := c.Get("account_id")
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "account_id not found in context"})
+			return
+		}
+
+		var req CreateSettlementRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if req.FromMemberID == req.ToMemberID {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "from_member_id and to_member_id must be different"})
+			return
+		}
+
+		date, msg := parseSettlementDate(req.Date)
+		if msg != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+			return
+		}
+
+		ctx := c.Request.Context()
+
+		family, err := isFamilyAccount(ctx, db, accountID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get account: " + err.Error()})
+			return
+		}
+		if !family {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "settlements are only available in family accounts"})
+			return
+		}
+
+		members, err := activeMembers(ctx, db, accountID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch members: " + err.Error()})
+			return
+		}
+		if _, ok := members[req.FromMemberID]; !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "from_member_id is not an active member of this account"})
+			return
+		}
+		if _, ok := members[req.ToMemberID]; !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "to_member_id is not an active member of this account"})
+			return
+		}
+
+		settlement := SettlementResponse{
+			FromMemberID:   req.FromMemberID,
+			FromMemberName: members[req.FromMemberID],
+			ToMemberID:     req.ToMemberID,
+			ToMemberName:   members[req.ToMemberID],
+			Amount:         req.Amount,
+			Notes:          req.Notes,
+		}
+		var createdAt time.Time
+		err = db.QueryRow(ctx, `
+			INSERT INTO split_settlements (account_id, from_member_id, to_member_id, amount, date, notes)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			RETURNING id, date::TEXT, created_at
+		`, accountID, req.FromMemberID, req.ToMemberID, req.Amount, date, req.Notes).Scan(&settlement.ID, &settlement.Date, &createdAt)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create settlement: " + err.Error()})
+			return
+		}
+		settlement.CreatedAt = createdAt.Format(time.RFC3339)
+
+		userID, _ := middleware.GetUserID(c)
+		logger.Info("split.settlement.created", "Reintegro entre miembros registrado", map[string]interface{}{
+			"settlement_id": settlement.ID,
+			"account_id":    accountID,
+			"user_id":       userID,
+			"amount":        req.Amount,
+			"ip":            c.ClientIP(),
+		})
+
+		c.JSON(http.StatusCreated, settlement)
+	}
+}
+
+// ListSettlements handles GET /api/splits/settlements
+func ListSettlements(db *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountID, exists := c.Get("account_id")
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "account_id not found in context"})
+			return
+		}
+
+		rows, err := db.Query(c.Request.Context(), `
+			SELECT s.id, s.from_member_id, f.name, s.to_member_id, t.name, s.amount, s.date::TEXT, s.notes, s.created_at
+			FROM split_settlements s
+			JOIN family_members f ON f.id = s.from_member_id
+			JOIN family_members t ON t.id = s.to_member_id
+			WHERE s.account_id = $1
+			ORDER BY s.date DESC, s.created_at DESC
+		`, accountID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch settlements: " + err.Error()})
+			return
+		}
+		defer rows.Close()
+
+		settlements := []SettlementResponse{}
+		for rows.Next() {
+			var s SettlementResponse
+			var createdAt time.Time
+			if err := rows.Scan(&s.ID, &s.FromMemberID, &s.FromMemberName, &s.ToMemberID, &s.ToMemberName, &s.Amount, &s.Date, &s.Notes, &createdAt); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to scan settlement: " + err.Error()})
+				return
+			}
+			s.CreatedAt = createdAt.Format(time.RFC3339)
+			settlements = append(settlements, s)
+		}
+		if err := rows.Err(); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch settlements: " + err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"settlements": settlements,
+			"count":       len(settlements),
+		})
+	}
+}
+
+// DeleteSettlement handles DELETE /api/splits/settlements/:id
+func DeleteSettlement(db *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountID, exists := c.Get("account_id")
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "account_id not found in context"})
+			return
+		}
+
+		settlementID := c.Param("id")
+
+		result, err := db.Exec(c.Request.Context(),
+			`DELETE FROM split_settlements WHERE id = $1 AND account_id = $2`,
+			settlementID, accountID,
+		)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete settlement: " + err.Error()})
+			return
+		}
+		if result.RowsAffected() == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "settlement not found"})
+			return
+		}
+
+		userID, _ := middleware.GetUserID(c)
+		logger.Info("split.settlement.deleted", "Reintegro entre miembros eliminado", map[string]interface{}{
+			"settlement_id": settlementID,
+			"account_id":    accountID,
+			"user_id":       userID,
+			"ip":            c.ClientIP(),
+		})
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "settlement deleted successfully",
+			"id":      settlementID,
+		})
+	}
+}
+
+// SettleUp handles POST /api/splits/settle-up
+// Records every suggested transfer as a settlement so all balances go back to 0
+func SettleUp(db *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountID, exists := c.Get("account_id")
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "account_id not found in context"})
+			return
+		}
+
+		// El body es opcional
+		var req SettleUpRequest
+		if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		date, msg := parseSettlementDate(req.Date)
+		if msg != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+			return
+		}
+
+		ctx := c.Request.Context()
+
+		tx, err := db.Begin(ctx)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to start transaction: " + err.Error()})
+			return
+		}
+		defer tx.Rollback(ctx)
+
+		// Serializa los settle-up de la cuenta para no registrar dos veces las mismas transferencias
+		if _, err := tx.Exec(ctx, `SELECT id FROM accounts WHERE id = $1 FOR UPDATE`, accountID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to lock account: " + err.Error()})
+			return
+		}
+
+		_, transfers, err := computeBalances(ctx, tx, accountID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to compute balances: " + err.Error()})
+			return
+		}
+
+		settlements := []SettlementResponse{}
+		for _, t := range transfers {
+			s := SettlementResponse{
+				FromMemberID:   t.FromMemberID,
+				FromMemberName: t.FromMemberName,
+				ToMemberID:     t.ToMemberID,
+				ToMemberName:   t.ToMemberName,
+				Amount:         t.Amount,
+				Notes:          req.Notes,
+			}
+			var createdAt time.Time
+			err := tx.QueryRow(ctx, `
+				INSERT INTO split_settlements (account_id, from_member_id, to_member_id, amount, date, notes)
+				VALUES ($1, $2, $3, $4, $5, $6)
+				RETURNING id, date::TEXT, created_at
+			`, accountID, t.FromMemberID, t.ToMemberID, t.Amount, date, req.Notes).Scan(&s.ID, &s.Date, &createdAt)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create settlement: " + err.Error()})
+				return
+			}
+			s.CreatedAt = createdAt.Format(time.RFC3339)
+			settlements = append(settlements, s)
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to commit transaction: " + err.Error()})
+			return
+		}
+
+		userID, _ := middleware.GetUserID(c)
+		logger.Info("split.settle_up", "Saldos entre miembros saldados", map[string]interface{}{
+			"account_id":  accountID,
+			"user_id":     userID,
+			"settlements": len(settlements),
+			"ip":          c.ClientIP(),
+		})
+
+		c.JSON(http.StatusCreated, gin.H{
+			"settlements": settlements,
+			"count":       len(settlements),
+		})
+	}
+}
@@ -0,0 +1,226 @@
+package tags
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/LorenzoCampos/bolsillo-claro/internal/middleware"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/logger"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/tags"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// TagResponse represents a tag with how many movements use it
+type TagResponse struct {
+	ID             string `json:"id"`
+	Name           string `json:"name"`
+	ExpenseCount   int    `json:"expense_count"`
+	IncomeCount    int    `json:"income_count"`
+	RecurringCount int    `json:"recurring_count"` // Recurring expense and income templates
+	UsageCount     int    `json:"usage_count"`
+	CreatedAt      string `json:"created_at"`
+}
+
+type RenameTagRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+const tagColumns = `
+	t.id, t.name, t.created_at,
+	(SELECT COUNT(*) FROM expense_tags WHERE tag_id = t.id) AS expense_count,
+	(SELECT COUNT(*) FROM income_tags WHERE tag_id = t.id) AS income_count,
+	(SELECT COUNT(*) FROM recurring_expense_tags WHERE tag_id = t.id)
+		+ (SELECT COUNT(*) FROM recurring_income_tags WHERE tag_id = t.id) AS recurring_count`
+
+func scanTag(row pgx.Row) (*TagResponse, error) {
+	var tag TagResponse
+	var createdAt time.Time
+	if err := row.Scan(&tag.ID, &tag.Name, &createdAt, &tag.ExpenseCount, &tag.IncomeCount, &tag.RecurringCount); err != nil {
+		return nil, err
+	}
+	tag.UsageCount = tag.ExpenseCount + tag.IncomeCount + tag.RecurringCount
+	tag.CreatedAt = createdAt.Format(time.RFC3339)
+	return &tag, nil
+}
+
+// isDuplicateName detects the unique (account_id, name) violation
+func isDuplicateName(err error) bool {
+	if pgErr, ok := err.(*pgconn.PgError); ok {
+		return pgErr.Code == "23505" && pgErr.ConstraintName == "unique_tag_name_per_account"
+	}
+	return false
+}
+
+// ListTags handles GET /api/tags
+// Autocomplete: tags of the account that start with q, most used first
+func ListTags(db *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountID, exists := c.Get("account_id")
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "account_id not found in context"})
+			return
+		}
+
+		limit := 20
+		if limitStr := c.Query("limit"); limitStr != "" {
+			parsed, err := strconv.Atoi(limitStr)
+			if err != nil || parsed < 1 || parsed > 100 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 100"})
+				return
+			}
+			limit = parsed
+		}
+
+		// The prefix is normalized like the tags themselves ("Vacaciones 20" → "vacaciones-20")
+		prefix := ""
+		if q := c.Query("q"); strings.TrimSpace(q) != "" {
+			normalized, err := tags.Normalize(q)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			prefix = strings.ReplaceAll(normalized, "_", `\_`)
+		}
+
+		rows, err := db.Query(c.Request.Context(), `
+			SELECT * FROM (
+				SELECT `+tagColumns+`
+				FROM tags t
+				WHERE t.account_id = $1 AND t.name LIKE $2 || '%'
+			) s
+			ORDER BY s.expense_count + s.income_count + s.recurring_count DESC, s.name ASC
+			LIMIT $3
+		`, accountID, prefix, limit)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch tags: " + err.Error()})
+			return
+		}
+		defer rows.Close()
+
+		result := []TagResponse{}
+		for rows.Next() {
+			tag, err := scanTag(rows)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to parse tag: " + err.Error()})
+				return
+			}
+			result = append(result, *tag)
+		}
+		if err := rows.Err(); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error reading tags"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"tags":  result,
+			"count": len(result),
+		})
+	}
+}
+
+// RenameTag handles PUT /api/tags/:id
+// Every movement with the tag shows the new name
+func RenameTag(db *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountID, exists := c.Get("account_id")
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "account_id not found in context"})
+			return
+		}
+
+		tagID := c.Param("id")
+
+		var req RenameTagRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		name, err := tags.Normalize(req.Name)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx := c.Request.Context()
+
+		result, err := db.Exec(ctx,
+			`UPDATE tags SET name = $1 WHERE id = $2 AND account_id = $3`,
+			name, tagID, accountID,
+		)
+		if err != nil {
+			if isDuplicateName(err) {
+				c.JSON(http.StatusConflict, gin.H{"error": "a tag with that name already exists in this account"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to rename tag: " + err.Error()})
+			return
+		}
+		if result.RowsAffected() == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "tag not found"})
+			return
+		}
+
+		tag, err := scanTag(db.QueryRow(ctx, `SELECT `+tagColumns+` FROM tags t WHERE t.id = $1`, tagID))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch tag: " + err.Error()})
+			return
+		}
+
+		userID, _ := middleware.GetUserID(c)
+		logger.Info("tag.renamed", "Tag renombrado", map[string]interface{}{
+			"tag_id":     tagID,
+			"name":       name,
+			"account_id": accountID,
+			"user_id":    userID,
+			"ip":         c.ClientIP(),
+		})
+
+		c.JSON(http.StatusOK, tag)
+	}
+}
+
+// DeleteTag handles DELETE /api/tags/:id
+// Removes the tag from every movement and template that has it; the movements stay
+func DeleteTag(db *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountID, exists := c.Get("account_id")
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "account_id not found in context"})
+			return
+		}
+
+		tagID := c.Param("id")
+
+		result, err := db.Exec(c.Request.Context(),
+			`DELETE FROM tags WHERE id = $1 AND account_id = $2`,
+			tagID, accountID,
+		)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete tag: " + err.Error()})
+			return
+		}
+		if result.RowsAffected() == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "tag not found"})
+			return
+		}
+
+		userID, _ := middleware.GetUserID(c)
+		logger.Info("tag.deleted", "Tag eliminado", map[string]interface{}{
+			"tag_id":     tagID,
+			"account_id": accountID,
+			"user_id":    userID,
+			"ip":         c.ClientIP(),
+		})
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "tag deleted successfully",
+			"id":      tagID,
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package views
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/LorenzoCampos/bolsillo-claro/internal/middleware"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/listing"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/logger"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/views"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ViewRequest is the body of POST /api/views and PUT /api/views/:id (full replacement)
+type ViewRequest struct {
+	Name         string         `json:"name" binding:"required,max=100"`
+	MovementType string         `json:"movement_type" binding:"required,oneof=expense income"`
+	IsPrivate    bool           `json:"is_private"` // Only visible to the member who created it
+	Filters      listing.Params `json:"filters"`    // Same params as GET /expenses or GET /incomes (type = expense_type/income_type)
+	DateRange    *string        `json:"date_range"` // Relative dates resolved when the view runs: this_month, last_90_days, ...
+	SortBy       string         `json:"sort_by"`    // Optional: defaults to date
+	Order        string         `json:"order"`      // Optional: defaults to desc
+}
+
+type ViewResponse struct {
+	ID           string         `json:"id"`
+	Name         string         `json:"name"`
+	MovementType string         `json:"movement_type"`
+	IsPrivate    bool           `json:"is_private"`
+	Filters      listing.Params `json:"filters"`
+	DateRange    *string        `json:"date_range"`
+	SortBy       string         `json:"sort_by"`
+	Order        string         `json:"order"`
+	CreatedBy    *string        `json:"created_by"`
+	CreatedAt    string         `json:"created_at"`
+	UpdatedAt    string         `json:"updated_at"`
+}
+
+const viewColumns = `id, name, movement_type, is_private, filters, date_range, sort_by, sort_order, created_by, created_at, updated_at`
+
+// visibleTo limits a query on saved_views to the views the user can see ($3 = user_id)
+const visibleTo = `(NOT is_private OR created_by = $3)`
+
+var (
+	errOrder             = errors.New("order must be asc or desc")
+	errDateRangeAndDates = errors.New("use either date_range or filters.date_from/date_to, not both")
+)
+
+func scanView(row pgx.Row) (*ViewResponse, error) {
+	var v ViewResponse
+	var createdAt, updatedAt time.Time
+
+	err := row.Scan(
+		&v.ID, &v.Name, &v.MovementType, &v.IsPrivate, &v.Filters, &v.DateRange,
+		&v.SortBy, &v.Order, &v.CreatedBy, &createdAt, &updatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	v.CreatedAt = createdAt.Format(time.RFC3339)
+	v.UpdatedAt = updatedAt.Format(time.RFC3339)
+	return &v, nil
+}
+
+// validateView checks the filters the same way GET /expenses and GET /incomes do, the relative
+// date range and the sort. Defaults are filled in place
+func validateView(req *ViewRequest) error {
+	if req.SortBy == "" {
+		req.SortBy = "date"
+	}
+	if req.Order == "" {
+		req.Order = "desc"
+	}
+	if !listing.ValidSort(req.SortBy) {
+		return listing.ErrInvalidSortBy
+	}
+	if req.Order != "asc" && req.Order != "desc" {
+		return errOrder
+	}
+
+	if req.DateRange != nil {
+		if !views.ValidRange(*req.DateRange) {
+			return views.ErrInvalidDateRange
+		}
+		if req.Filters.DateFrom != "" || req.Filters.DateTo != "" {
+			return errDateRangeAndDates
+		}
+	}
+
+	view := views.View{MovementType: req.MovementType, Params: req.Filters, DateRange: req.DateRange}
+	_, err := view.Filters(time.Now())
+	return err
+}
+
+// isDuplicateName detects the unique (account_id, name) violation
+func isDuplicateName(err error) bool {
+	if pgErr, ok := err.(*pgconn.PgError); ok {
+		return pgErr.Code == "23505" && pgErr.ConstraintName == "unique_saved_view_name_per_account"
+	}
+	return false
+}
+
+// CreateView handles POST /api/views
+func CreateView(db *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountID, exists := c.Get("account_id")
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "account_id not found in context"})
+			return
+		}
+		userID, _ := middleware.GetUserID(c)
+
+		var req ViewRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if err := validateView(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		view, err := scanView(db.QueryRow(c.Request.Context(), `
+			INSERT INTO saved_views (account_id, created_by, name, movement_type, is_private, filters, date_range, sort_by, sort_order)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			RETURNING `+viewColumns,
+			accountID, userID, req.Name, req.MovementType, req.IsPrivate, req.Filters, req.DateRange, req.SortBy, req.Order,
+		))
+		if err != nil {
+			if isDuplicateName(err) {
+				c.JSON(http.StatusConflict, gin.H{"error": "a view with this name already exists"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create view: " + err.Error()})
+			return
+		}
+
+		logger.Info("view.created", "Vista guardada creada", map[string]interface{}{
+			"view_id":       view.ID,
+			"movement_type": view.MovementType,
+			"account_id":    accountID,
+			"user_id":       userID,
+			"ip":            c.ClientIP(),
+		})
+
+		c.JSON(http.StatusCreated, view)
+	}
+}
@@ -0,0 +1,49 @@
+package views
+
+import (
+	"net/http"
+
+	"github.com/LorenzoCampos/bolsillo-claro/internal/middleware"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// DeleteView handles DELETE /api/views/:id
+func DeleteView(db *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountID, exists := c.Get("account_id")
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "account_id not found in context"})
+			return
+		}
+		userID, _ := middleware.GetUserID(c)
+
+		viewID := c.Param("id")
+
+		result, err := db.Exec(c.Request.Context(),
+			`DELETE FROM saved_views WHERE id = $1 AND account_id = $2 AND `+visibleTo,
+			viewID, accountID, userID,
+		)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete view: " + err.Error()})
+			return
+		}
+		if result.RowsAffected() == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "view not found"})
+			return
+		}
+
+		logger.Info("view.deleted", "Vista guardada eliminada", map[string]interface{}{
+			"view_id":    viewID,
+			"account_id": accountID,
+			"user_id":    userID,
+			"ip":         c.ClientIP(),
+		})
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "view deleted successfully",
+			"id":      viewID,
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package views
+
+import (
+	"encoding/csv"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// MaxExportRows caps the rows of a CSV export
+const MaxExportRows = 10000
+
+var exportHeader = []string{
+	"date", "description", "amount", "currency", "amount_in_primary_currency", "type",
+	"category", "family_member", "payment_method", "tags",
+}
+
+// exportFilename turns the view name into a safe file name ("Gastos con tarjeta" → gastos-con-tarjeta.csv)
+func exportFilename(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	filename := strings.TrimSuffix(b.String(), "-")
+	if filename == "" {
+		filename = "view"
+	}
+	return filename + ".csv"
+}
+
+func valueOrEmpty(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// ExportView handles GET /api/views/:id/export
+// CSV with the movements of the view (up to MaxExportRows), in the view's order
+func ExportView(db *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountID, exists := c.Get("account_id")
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "account_id not found in context"})
+			return
+		}
+
+		view, status, msg := resolveView(c, db, accountID)
+		if status != 0 {
+			c.JSON(status, gin.H{"error": msg})
+			return
+		}
+
+		whereClauses, args, argIndex := view.where(accountID)
+		query := `
+			SELECT ` + view.movementColumns() + `
+			FROM ` + view.from() + `
+			WHERE ` + strings.Join(whereClauses, " AND ") + `
+			ORDER BY ` + view.Table.OrderBy(view.SortBy, view.Order) + `
+			LIMIT $` + strconv.Itoa(argIndex)
+		args = append(args, MaxExportRows)
+
+		rows, err := db.Query(c.Request.Context(), query, args...)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to run view: " + err.Error()})
+			return
+		}
+		defer rows.Close()
+
+		movements := []ViewMovement{}
+		for rows.Next() {
+			m, err := scanMovement(rows)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to parse view result: " + err.Error()})
+				return
+			}
+			movements = append(movements, m)
+		}
+		if err := rows.Err(); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error reading view results"})
+			return
+		}
+
+		if err := loadTags(c.Request.Context(), db, view.Table.Tags, movements); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch tags: " + err.Error()})
+			return
+		}
+
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		c.Header("Content-Disposition", `attachment; filename="`+exportFilename(view.Name)+`"`)
+		c.Status(http.StatusOK)
+
+		w := csv.NewWriter(c.Writer)
+		w.Write(exportHeader)
+		for _, m := range movements {
+			w.Write([]string{
+				m.Date,
+				m.Description,
+				strconv.FormatFloat(m.Amount, 'f', 2, 64),
+				m.Currency,
+				strconv.FormatFloat(m.AmountInPrimaryCurrency, 'f', 2, 64),
+				m.Type,
+				valueOrEmpty(m.CategoryName),
+				valueOrEmpty(m.FamilyMemberName),
+				valueOrEmpty(m.PaymentMethodName),
+				strings.Join(m.Tags, ", "),
+			})
+		}
+		w.Flush()
+	}
+}
@@ -0,0 +1,92 @@
+package views
+
+import (
+	"net/http"
+
+	"github.com/LorenzoCampos/bolsillo-claro/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ListViews handles GET /api/views?movement_type=expense|income
+// Shared views of the account plus the user's private ones, by name
+func ListViews(db *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountID, exists := c.Get("account_id")
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "account_id not found in context"})
+			return
+		}
+		userID, _ := middleware.GetUserID(c)
+
+		var movementType *string
+		if value := c.Query("movement_type"); value != "" {
+			if value != "expense" && value != "income" {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "movement_type must be expense or income"})
+				return
+			}
+			movementType = &value
+		}
+
+		rows, err := db.Query(c.Request.Context(), `
+			SELECT `+viewColumns+`
+			FROM saved_views
+			WHERE account_id = $1 AND ($2::TEXT IS NULL OR movement_type = $2) AND `+visibleTo+`
+			ORDER BY name ASC
+		`, accountID, movementType, userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch views: " + err.Error()})
+			return
+		}
+		defer rows.Close()
+
+		result := []ViewResponse{}
+		for rows.Next() {
+			view, err := scanView(rows)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to parse view: " + err.Error()})
+				return
+			}
+			result = append(result, *view)
+		}
+		if err := rows.Err(); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error reading views"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"views": result,
+			"count": len(result),
+		})
+	}
+}
+
+// GetView handles GET /api/views/:id
+func GetView(db *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountID, exists := c.Get("account_id")
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "account_id not found in context"})
+			return
+		}
+		userID, _ := middleware.GetUserID(c)
+
+		view, err := scanView(db.QueryRow(c.Request.Context(), `
+			SELECT `+viewColumns+`
+			FROM saved_views
+			WHERE id = $1 AND account_id = $2 AND `+visibleTo,
+			c.Param("id"), accountID, userID,
+		))
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "view not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch view: " + err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, view)
+	}
+}
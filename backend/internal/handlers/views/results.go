@@ -0,0 +1,269 @@
+package views
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/LorenzoCampos/bolsillo-claro/internal/middleware"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/listing"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/tags"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/views"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ViewMovement is an expense or income matching a view
+type ViewMovement struct {
+	ID                      string   `json:"id"`
+	Date                    string   `json:"date"`
+	Description             string   `json:"description"`
+	Amount                  float64  `json:"amount"`
+	Currency                string   `json:"currency"`
+	AmountInPrimaryCurrency float64  `json:"amount_in_primary_currency"`
+	Type                    string   `json:"type"` // one-time, recurring (expense_type / income_type)
+	CategoryID              *string  `json:"category_id,omitempty"`
+	CategoryName            *string  `json:"category_name,omitempty"`
+	FamilyMemberID          *string  `json:"family_member_id,omitempty"`
+	FamilyMemberName        *string  `json:"family_member_name,omitempty"`
+	PaymentMethodID         *string  `json:"payment_method_id,omitempty"`
+	PaymentMethodName       *string  `json:"payment_method_name,omitempty"`
+	Tags                    []string `json:"tags"`
+}
+
+// resolvedView is a view with its filters as of today, ready to query
+type resolvedView struct {
+	*views.View
+	Table   listing.Table
+	Filters listing.Filters
+}
+
+// DateFrom / DateTo are the dates the view ran with (nil if it has no date filter)
+func (v resolvedView) DateFrom() *string {
+	if v.Filters.DateFrom == "" {
+		return nil
+	}
+	return &v.Filters.DateFrom
+}
+
+func (v resolvedView) DateTo() *string {
+	if v.Filters.DateTo == "" {
+		return nil
+	}
+	return &v.Filters.DateTo
+}
+
+// resolveView loads the view of the request and resolves its relative dates
+// Returns the status and error to respond with (status 0 = OK)
+func resolveView(c *gin.Context, db *pgxpool.Pool, accountID interface{}) (*resolvedView, int, string) {
+	userID, _ := middleware.GetUserID(c)
+
+	view, err := views.Load(c.Request.Context(), db, accountID, userID, c.Param("id"))
+	if err == pgx.ErrNoRows {
+		return nil, http.StatusNotFound, "view not found"
+	}
+	if err != nil {
+		return nil, http.StatusInternalServerError, "failed to fetch view: " + err.Error()
+	}
+
+	filters, err := view.Filters(time.Now())
+	if err != nil {
+		return nil, http.StatusBadRequest, "view filters are no longer valid: " + err.Error()
+	}
+	return &resolvedView{View: view, Table: view.Table(), Filters: filters}, 0, ""
+}
+
+// from is the FROM clause shared by results, summary and export
+func (v resolvedView) from() string {
+	t := v.Table
+	return t.Name + ` ` + t.Alias + `
+		LEFT JOIN ` + t.CategoryTable + ` ` + t.CategoryAlias + ` ON ` + t.CategoryAlias + `.id = ` + t.Alias + `.category_id
+		LEFT JOIN family_members ` + t.MemberAlias + ` ON ` + t.MemberAlias + `.id = ` + t.Alias + `.family_member_id
+		LEFT JOIN payment_methods pm ON pm.id = ` + t.Alias + `.payment_method_id`
+}
+
+// where builds the WHERE clauses of the view for the account, numbering params from $2
+func (v resolvedView) where(accountID interface{}) ([]string, []interface{}, int) {
+	return v.Filters.Where(v.Table, []string{v.Table.Alias + ".account_id = $1"}, []interface{}{accountID}, 2)
+}
+
+// movementColumns are the columns scanned by scanMovement
+func (v resolvedView) movementColumns() string {
+	t := v.Table
+	a := t.Alias + "."
+	return a + `id, ` + a + `date, ` + a + `description, ` + a + `amount, ` + a + `currency, ` + a + `amount_in_primary_currency, ` + a + t.TypeColumn + `,
+		` + a + `category_id, ` + t.CategoryAlias + `.name, ` + a + `family_member_id, ` + t.MemberAlias + `.name, ` + a + `payment_method_id, pm.name`
+}
+
+func scanMovement(rows pgx.Rows, extra ...interface{}) (ViewMovement, error) {
+	var m ViewMovement
+	var date time.Time
+	dest := []interface{}{
+		&m.ID, &date, &m.Description, &m.Amount, &m.Currency, &m.AmountInPrimaryCurrency, &m.Type,
+		&m.CategoryID, &m.CategoryName, &m.FamilyMemberID, &m.FamilyMemberName, &m.PaymentMethodID, &m.PaymentMethodName,
+	}
+	if err := rows.Scan(append(dest, extra...)...); err != nil {
+		return m, err
+	}
+	m.Date = date.Format("2006-01-02")
+	return m, nil
+}
+
+// loadTags fills in the tags of the movements in a single query
+func loadTags(ctx context.Context, db *pgxpool.Pool, link tags.Link, movements []ViewMovement) error {
+	ids := make([]string, len(movements))
+	for i, m := range movements {
+		ids[i] = m.ID
+	}
+	byID, err := tags.Load(ctx, db, link, ids)
+	if err != nil {
+		return err
+	}
+	for i := range movements {
+		movements[i].Tags = byID[movements[i].ID]
+		if movements[i].Tags == nil {
+			movements[i].Tags = []string{}
+		}
+	}
+	return nil
+}
+
+// ViewResults handles GET /api/views/:id/results?sort_by=&order=&page=&limit=&cursor=
+// Runs the view with its relative dates resolved as of today. sort_by/order default to the view's
+func ViewResults(db *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountID, exists := c.Get("account_id")
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "account_id not found in context"})
+			return
+		}
+
+		view, status, msg := resolveView(c, db, accountID)
+		if status != 0 {
+			c.JSON(status, gin.H{"error": msg})
+			return
+		}
+
+		sortBy := c.DefaultQuery("sort_by", view.SortBy)
+		order := c.DefaultQuery("order", view.Order)
+		if !listing.ValidSort(sortBy) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": listing.ErrInvalidSortBy.Error()})
+			return
+		}
+		if order != "asc" && order != "desc" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": errOrder.Error()})
+			return
+		}
+
+		page := 1
+		if pageStr := c.Query("page"); pageStr != "" {
+			parsed, err := strconv.Atoi(pageStr)
+			if err != nil || parsed < 1 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "page must be a positive number"})
+				return
+			}
+			page = parsed
+		}
+		limit := 20
+		if limitStr := c.Query("limit"); limitStr != "" {
+			parsed, err := strconv.Atoi(limitStr)
+			if err != nil || parsed < 1 || parsed > 100 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 100"})
+				return
+			}
+			limit = parsed
+		}
+
+		var cursor *listing.Cursor
+		if encoded := c.Query("cursor"); encoded != "" {
+			decoded, err := listing.DecodeCursor(encoded, sortBy, order)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			cursor = &decoded
+		}
+
+		whereClauses, args, argIndex := view.where(accountID)
+
+		var totalCount int
+		countQuery := "SELECT COUNT(*) FROM " + view.Table.Name + " " + view.Table.Alias + " WHERE " + strings.Join(whereClauses, " AND ")
+		if err := db.QueryRow(c.Request.Context(), countQuery, args...).Scan(&totalCount); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to count view results: " + err.Error()})
+			return
+		}
+
+		offset := (page - 1) * limit
+		if cursor != nil {
+			offset = 0
+			page = 0
+			whereClauses, args, argIndex = view.Table.After(*cursor, whereClauses, args, argIndex)
+		}
+
+		// One extra row tells if there is a next page
+		query := `
+			SELECT ` + view.movementColumns() + `, ` + view.Table.SortExpr(sortBy) + `::TEXT
+			FROM ` + view.from() + `
+			WHERE ` + strings.Join(whereClauses, " AND ") + `
+			ORDER BY ` + view.Table.OrderBy(sortBy, order) + `
+			LIMIT $` + strconv.Itoa(argIndex) + ` OFFSET $` + strconv.Itoa(argIndex+1)
+		args = append(args, limit+1, offset)
+
+		rows, err := db.Query(c.Request.Context(), query, args...)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to run view: " + err.Error()})
+			return
+		}
+		defer rows.Close()
+
+		movements := []ViewMovement{}
+		sortValues := []string{}
+		for rows.Next() {
+			var sortValue string
+			m, err := scanMovement(rows, &sortValue)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to parse view result: " + err.Error()})
+				return
+			}
+			movements = append(movements, m)
+			sortValues = append(sortValues, sortValue)
+		}
+		if err := rows.Err(); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error reading view results"})
+			return
+		}
+
+		var nextCursor *string
+		if len(movements) > limit {
+			movements = movements[:limit]
+			last := listing.Cursor{SortBy: sortBy, Order: order, Value: sortValues[limit-1], ID: movements[limit-1].ID}
+			encoded := last.Encode()
+			nextCursor = &encoded
+		}
+
+		if err := loadTags(c.Request.Context(), db, view.Table.Tags, movements); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch tags: " + err.Error()})
+			return
+		}
+
+		response := gin.H{
+			"view_id":       view.ID,
+			"name":          view.Name,
+			"movement_type": view.MovementType,
+			"date_from":     view.DateFrom(),
+			"date_to":       view.DateTo(),
+			"movements":     movements,
+			"total_count":   totalCount,
+			"limit":         limit,
+			"total_pages":   (totalCount + limit - 1) / limit,
+			"next_cursor":   nextCursor,
+		}
+		if page > 0 {
+			response["page"] = page
+		}
+		c.JSON(http.StatusOK, response)
+	}
+}
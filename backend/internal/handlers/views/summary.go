@@ -0,0 +1,136 @@
+package views
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type CurrencyTotal struct {
+	Currency string  `json:"currency"`
+	Count    int     `json:"count"`
+	Total    float64 `json:"total"` // In the original currency
+}
+
+type CategoryTotal struct {
+	CategoryID   *string `json:"category_id"` // null = uncategorized
+	CategoryName *string `json:"category_name"`
+	Count        int     `json:"count"`
+	Total        float64 `json:"total"` // In the primary currency
+	Percentage   float64 `json:"percentage"`
+}
+
+type ViewSummaryResponse struct {
+	ViewID          string          `json:"view_id"`
+	Name            string          `json:"name"`
+	MovementType    string          `json:"movement_type"`
+	DateFrom        *string         `json:"date_from"`
+	DateTo          *string         `json:"date_to"`
+	PrimaryCurrency string          `json:"primary_currency"`
+	Count           int             `json:"count"`
+	Total           float64         `json:"total"` // Sum of amount_in_primary_currency
+	ByCurrency      []CurrencyTotal `json:"by_currency"`
+	ByCategory      []CategoryTotal `json:"by_category"`
+}
+
+// ViewSummary handles GET /api/views/:id/summary
+// Totals of the view (by currency and by category) to use it as a dashboard widget
+func ViewSummary(db *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountID, exists := c.Get("account_id")
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "account_id not found in context"})
+			return
+		}
+
+		view, status, msg := resolveView(c, db, accountID)
+		if status != 0 {
+			c.JSON(status, gin.H{"error": msg})
+			return
+		}
+
+		ctx := c.Request.Context()
+		response := ViewSummaryResponse{
+			ViewID:       view.ID,
+			Name:         view.Name,
+			MovementType: view.MovementType,
+			DateFrom:     view.DateFrom(),
+			DateTo:       view.DateTo(),
+			ByCurrency:   []CurrencyTotal{},
+			ByCategory:   []CategoryTotal{},
+		}
+
+		if err := db.QueryRow(ctx, `SELECT currency FROM accounts WHERE id = $1`, accountID).Scan(&response.PrimaryCurrency); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch account: " + err.Error()})
+			return
+		}
+
+		whereClauses, args, _ := view.where(accountID)
+		whereClause := strings.Join(whereClauses, " AND ")
+		a := view.Table.Alias
+
+		err := db.QueryRow(ctx, `
+			SELECT COUNT(*), COALESCE(SUM(`+a+`.amount_in_primary_currency), 0)
+			FROM `+view.from()+`
+			WHERE `+whereClause, args...).Scan(&response.Count, &response.Total)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to calculate view total: " + err.Error()})
+			return
+		}
+
+		// 1. BY CURRENCY
+		rows, err := db.Query(ctx, `
+			SELECT `+a+`.currency, COUNT(*), SUM(`+a+`.amount)
+			FROM `+view.from()+`
+			WHERE `+whereClause+`
+			GROUP BY `+a+`.currency
+			ORDER BY 3 DESC`, args...)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to calculate totals by currency: " + err.Error()})
+			return
+		}
+		for rows.Next() {
+			var total CurrencyTotal
+			if err := rows.Scan(&total.Currency, &total.Count, &total.Total); err != nil {
+				rows.Close()
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to parse currency total: " + err.Error()})
+				return
+			}
+			response.ByCurrency = append(response.ByCurrency, total)
+		}
+		rows.Close()
+
+		// 2. BY CATEGORY
+		ca := view.Table.CategoryAlias
+		rows, err = db.Query(ctx, `
+			SELECT `+a+`.category_id, `+ca+`.name, COUNT(*), SUM(`+a+`.amount_in_primary_currency)
+			FROM `+view.from()+`
+			WHERE `+whereClause+`
+			GROUP BY `+a+`.category_id, `+ca+`.name
+			ORDER BY 4 DESC`, args...)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to calculate totals by category: " + err.Error()})
+			return
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var total CategoryTotal
+			if err := rows.Scan(&total.CategoryID, &total.CategoryName, &total.Count, &total.Total); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to parse category total: " + err.Error()})
+				return
+			}
+			if response.Total > 0 {
+				total.Percentage = total.Total / response.Total * 100
+			}
+			response.ByCategory = append(response.ByCategory, total)
+		}
+		if err := rows.Err(); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error reading category totals"})
+			return
+		}
+
+		c.JSON(http.StatusOK, response)
+	}
+}
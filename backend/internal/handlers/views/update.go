@@ -0,0 +1,75 @@
+package views
+
+import (
+	"net/http"
+
+	"github.com/LorenzoCampos/bolsillo-claro/internal/middleware"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// UpdateView handles PUT /api/views/:id
+// The body replaces the whole view (same shape as create). Only the creator can change is_private
+func UpdateView(db *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountID, exists := c.Get("account_id")
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "account_id not found in context"})
+			return
+		}
+		userID, _ := middleware.GetUserID(c)
+
+		viewID := c.Param("id")
+
+		var req ViewRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if err := validateView(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		query := `
+			UPDATE saved_views SET
+				name = $4,
+				movement_type = $5,
+				is_private = CASE WHEN created_by = $3 THEN $6 ELSE is_private END,
+				filters = $7,
+				date_range = $8,
+				sort_by = $9,
+				sort_order = $10
+			WHERE id = $1 AND account_id = $2 AND ` + visibleTo + `
+			RETURNING ` + viewColumns
+
+		view, err := scanView(db.QueryRow(c.Request.Context(), query,
+			viewID, accountID, userID, req.Name, req.MovementType, req.IsPrivate,
+			req.Filters, req.DateRange, req.SortBy, req.Order,
+		))
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "view not found"})
+			return
+		}
+		if err != nil {
+			if isDuplicateName(err) {
+				c.JSON(http.StatusConflict, gin.H{"error": "a view with this name already exists"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update view: " + err.Error()})
+			return
+		}
+
+		logger.Info("view.updated", "Vista guardada actualizada", map[string]interface{}{
+			"view_id":    view.ID,
+			"account_id": accountID,
+			"user_id":    userID,
+			"ip":         c.ClientIP(),
+		})
+
+		c.JSON(http.StatusOK, view)
+	}
+}
@@ -8,27 +8,44 @@ import (
 	"github.com/LorenzoCampos/bolsillo-claro/internal/config"
 	"github.com/LorenzoCampos/bolsillo-claro/internal/database"
 	accountsHandler "github.com/LorenzoCampos/bolsillo-claro/internal/handlers/accounts"
+	attachmentsHandler "github.com/LorenzoCampos/bolsillo-claro/internal/handlers/attachments"
 	authHandler "github.com/LorenzoCampos/bolsillo-claro/internal/handlers/auth"
 	categoriesHandler "github.com/LorenzoCampos/bolsillo-claro/internal/handlers/categories"
 	dashboardHandler "github.com/LorenzoCampos/bolsillo-claro/internal/handlers/dashboard"
 	expensesHandler "github.com/LorenzoCampos/bolsillo-claro/internal/handlers/expenses"
 	incomesHandler "github.com/LorenzoCampos/bolsillo-claro/internal/handlers/incomes"
+	reportsHandler "github.com/LorenzoCampos/bolsillo-claro/internal/handlers/reports"
 	recurringExpensesHandler "github.com/LorenzoCampos/bolsillo-claro/internal/handlers/recurring_expenses"
 	recurringIncomesHandler "github.com/LorenzoCampos/bolsillo-claro/internal/handlers/recurring_incomes"
+	installmentsHandler "github.com/LorenzoCampos/bolsillo-claro/internal/handlers/installments"
+	paymentMethodsHandler "github.com/LorenzoCampos/bolsillo-claro/internal/handlers/payment_methods"
+	receiptsHandler "github.com/LorenzoCampos/bolsillo-claro/internal/handlers/receipts"
+	investmentsHandler "github.com/LorenzoCampos/bolsillo-claro/internal/handlers/investments"
+	debtsHandler "github.com/LorenzoCampos/bolsillo-claro/internal/handlers/debts"
+	netWorthHandler "github.com/LorenzoCampos/bolsillo-claro/internal/handlers/net_worth"
 	savingsGoalsHandler "github.com/LorenzoCampos/bolsillo-claro/internal/handlers/savings_goals"
+	splitsHandler "github.com/LorenzoCampos/bolsillo-claro/internal/handlers/splits"
+	tagsHandler "github.com/LorenzoCampos/bolsillo-claro/internal/handlers/tags"
+	rulesHandler "github.com/LorenzoCampos/bolsillo-claro/internal/handlers/rules"
+	searchHandler "github.com/LorenzoCampos/bolsillo-claro/internal/handlers/search"
+	viewsHandler "github.com/LorenzoCampos/bolsillo-claro/internal/handlers/views"
 	"github.com/LorenzoCampos/bolsillo-claro/internal/middleware"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/attachments"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/investments"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/receipts"
 )
 
 // Server encapsula el servidor HTTP y su configuración
 type Server struct {
-	config *config.Config // Puntero a la configuración
-	router *gin.Engine    // El router de Gin que maneja las rutas HTTP
-	db     *database.DB   // Puntero al pool de conexiones de PostgreSQL
+	config *config.Config      // Puntero a la configuración
+	router *gin.Engine         // El router de Gin que maneja las rutas HTTP
+	db     *database.DB        // Puntero al pool de conexiones de PostgreSQL
+	store  attachments.Storage // Storage de los adjuntos (comprobantes)
 }
 
 // New crea una nueva instancia del servidor
-// Recibe la configuración y la conexión a la DB, retorna un puntero a Server
-func New(cfg *config.Config, db *database.DB) *Server {
+// Recibe la configuración, la conexión a la DB y el storage de adjuntos, retorna un puntero a Server
+func New(cfg *config.Config, db *database.DB, store attachments.Storage) *Server {
 	// Crear el router de Gin
 	// gin.Default() incluye middleware de logging y recovery automático
 	router := gin.Default()
@@ -38,6 +55,7 @@ func New(cfg *config.Config, db *database.DB) *Server {
 		config: cfg,
 		router: router,
 		db:     db,
+		store:  store,
 	}
 
 	// Configurar las rutas
@@ -56,6 +74,15 @@ func (s *Server) setupRoutes() {
 	authMiddleware := middleware.AuthMiddleware(s.config.JWTSecret)
 	accountMiddleware := middleware.AccountMiddleware(s.db)
 
+	// Proveedor de cotizaciones para inversiones (fixtures locales)
+	priceProvider := investments.NewFixtureProvider(s.config.PriceFixtures)
+
+	// Proveedor de OCR para comprobantes (tesseract local; fake devuelve un ticket de ejemplo)
+	var ocrProvider receipts.OCRProvider = receipts.NewTesseractProvider(s.config.OCRLanguages)
+	if s.config.OCRProvider == "fake" {
+		ocrProvider = &receipts.FakeProvider{}
+	}
+
 	// Grupo de rutas para la API
 	// Todas las rutas estarán bajo /api
 	api := s.router.Group("/api")
@@ -110,10 +137,32 @@ func (s *Server) setupRoutes() {
 		expensesRoutes.Use(accountMiddleware) // Luego validar X-Account-ID
 		{
 			expensesRoutes.POST("", expensesHandler.CreateExpense(s.db.Pool))       // Crear gasto
+			expensesRoutes.GET("/suggest-category", expensesHandler.SuggestCategory(s.db.Pool)) // Sugerir categoría según el historial
 			expensesRoutes.GET("/:id", expensesHandler.GetExpense(s.db.Pool))       // Obtener gasto por ID
 			expensesRoutes.PUT("/:id", expensesHandler.UpdateExpense(s.db.Pool))    // Actualizar gasto
 			expensesRoutes.DELETE("/:id", expensesHandler.DeleteExpense(s.db.Pool)) // Eliminar gasto
 			expensesRoutes.GET("", expensesHandler.ListExpenses(s.db.Pool))         // Listar gastos
+
+			// División del gasto entre miembros (solo cuentas family)
+			expensesRoutes.GET("/:id/split", splitsHandler.GetExpenseSplit(s.db.Pool))
+			expensesRoutes.PUT("/:id/split", splitsHandler.SetExpenseSplit(s.db.Pool))
+			expensesRoutes.DELETE("/:id/split", splitsHandler.DeleteExpenseSplit(s.db.Pool))
+
+			// Comprobantes adjuntos (multipart, campo "file")
+			expensesRoutes.POST("/:id/attachments", attachmentsHandler.UploadExpenseAttachment(s.db.Pool, s.store))
+			expensesRoutes.GET("/:id/attachments", attachmentsHandler.ListExpenseAttachments(s.db.Pool))
+		}
+
+		// Rutas de saldos entre miembros por gastos divididos (protegidas - requieren auth + account)
+		splitsRoutes := api.Group("/splits")
+		splitsRoutes.Use(authMiddleware)
+		splitsRoutes.Use(accountMiddleware)
+		{
+			splitsRoutes.GET("/balances", splitsHandler.GetBalances(s.db.Pool))
+			splitsRoutes.GET("/settlements", splitsHandler.ListSettlements(s.db.Pool))
+			splitsRoutes.POST("/settlements", splitsHandler.CreateSettlement(s.db.Pool))
+			splitsRoutes.DELETE("/settlements/:id", splitsHandler.DeleteSettlement(s.db.Pool))
+			splitsRoutes.POST("/settle-up", splitsHandler.SettleUp(s.db.Pool))
 		}
 
 		// Rutas de ingresos (protegidas - requieren auth + account)
@@ -126,6 +175,30 @@ func (s *Server) setupRoutes() {
 			incomesRoutes.PUT("/:id", incomesHandler.UpdateIncome(s.db.Pool))    // Actualizar ingreso
 			incomesRoutes.DELETE("/:id", incomesHandler.DeleteIncome(s.db.Pool)) // Eliminar ingreso
 			incomesRoutes.GET("", incomesHandler.ListIncomes(s.db.Pool))         // Listar ingresos
+
+			// Comprobantes adjuntos (multipart, campo "file")
+			incomesRoutes.POST("/:id/attachments", attachmentsHandler.UploadIncomeAttachment(s.db.Pool, s.store))
+			incomesRoutes.GET("/:id/attachments", attachmentsHandler.ListIncomeAttachments(s.db.Pool))
+		}
+
+		// Descarga y borrado de adjuntos (protegidas - requieren auth + account)
+		attachmentsRoutes := api.Group("/attachments")
+		attachmentsRoutes.Use(authMiddleware)
+		attachmentsRoutes.Use(accountMiddleware)
+		{
+			attachmentsRoutes.GET("/:id/download", attachmentsHandler.DownloadAttachment(s.db.Pool, s.store))
+			attachmentsRoutes.DELETE("/:id", attachmentsHandler.DeleteAttachment(s.db.Pool, s.store))
+		}
+
+		// Escaneo de comprobantes: OCR → gasto borrador que el usuario confirma (protegidas - requieren auth + account)
+		receiptsRoutes := api.Group("/receipts")
+		receiptsRoutes.Use(authMiddleware)
+		receiptsRoutes.Use(accountMiddleware)
+		{
+			receiptsRoutes.POST("/scan", receiptsHandler.ScanReceipt(s.db.Pool, s.store, ocrProvider))
+			receiptsRoutes.GET("/drafts", receiptsHandler.ListDrafts(s.db.Pool))
+			receiptsRoutes.POST("/drafts/:id/confirm", receiptsHandler.ConfirmDraft(s.db.Pool))
+			receiptsRoutes.DELETE("/drafts/:id", receiptsHandler.DiscardDraft(s.db.Pool))
 		}
 
 		// Rutas de categorías de gastos (protegidas - requieren auth + account)
@@ -137,6 +210,7 @@ func (s *Server) setupRoutes() {
 			expenseCategoriesRoutes.POST("", categoriesHandler.CreateExpenseCategory(s.db.Pool))
 			expenseCategoriesRoutes.PUT("/:id", categoriesHandler.UpdateExpenseCategory(s.db.Pool))
 			expenseCategoriesRoutes.DELETE("/:id", categoriesHandler.DeleteExpenseCategory(s.db.Pool))
+			expenseCategoriesRoutes.POST("/:id/merge", categoriesHandler.MergeExpenseCategory(s.db.Pool))
 		}
 
 		// Rutas de categorías de ingresos (protegidas - requieren auth + account)
@@ -148,6 +222,57 @@ func (s *Server) setupRoutes() {
 			incomeCategoriesRoutes.POST("", categoriesHandler.CreateIncomeCategory(s.db.Pool))
 			incomeCategoriesRoutes.PUT("/:id", categoriesHandler.UpdateIncomeCategory(s.db.Pool))
 			incomeCategoriesRoutes.DELETE("/:id", categoriesHandler.DeleteIncomeCategory(s.db.Pool))
+			incomeCategoriesRoutes.POST("/:id/merge", categoriesHandler.MergeIncomeCategory(s.db.Pool))
+		}
+
+		// Rutas de tags (protegidas - requieren auth + account)
+		// Los tags se crean al usarlos en un gasto/ingreso; acá se autocompletan, renombran y eliminan
+		tagsRoutes := api.Group("/tags")
+		tagsRoutes.Use(authMiddleware)
+		tagsRoutes.Use(accountMiddleware)
+		{
+			tagsRoutes.GET("", tagsHandler.ListTags(s.db.Pool))
+			tagsRoutes.PUT("/:id", tagsHandler.RenameTag(s.db.Pool))
+			tagsRoutes.DELETE("/:id", tagsHandler.DeleteTag(s.db.Pool))
+		}
+
+		// Rutas de reglas de auto-categorización (protegidas - requieren auth + account)
+		// Se evalúan al crear gastos/ingresos (API y scheduler) y completan categoría, miembro y tags
+		rulesRoutes := api.Group("/rules")
+		rulesRoutes.Use(authMiddleware)
+		rulesRoutes.Use(accountMiddleware)
+		{
+			rulesRoutes.GET("", rulesHandler.ListRules(s.db.Pool))
+			rulesRoutes.POST("", rulesHandler.CreateRule(s.db.Pool))
+			rulesRoutes.PUT("/:id", rulesHandler.UpdateRule(s.db.Pool))
+			rulesRoutes.DELETE("/:id", rulesHandler.DeleteRule(s.db.Pool))
+			rulesRoutes.GET("/:id/dry-run", rulesHandler.DryRunRule(s.db.Pool)) // Qué movimientos existentes cambiaría
+			rulesRoutes.POST("/:id/apply", rulesHandler.ApplyRule(s.db.Pool))   // Aplicar retroactivamente
+		}
+
+		// Búsqueda de movimientos (protegida - requiere auth + account)
+		// Texto completo en español + filtros de monto y fecha en la misma query: "super >5000 2026-03"
+		searchRoutes := api.Group("/search")
+		searchRoutes.Use(authMiddleware)
+		searchRoutes.Use(accountMiddleware)
+		{
+			searchRoutes.GET("", searchHandler.SearchTransactions(s.db.Pool))
+		}
+
+		// Vistas guardadas (protegidas - requieren auth + account)
+		// Filtros con nombre sobre gastos o ingresos; las fechas relativas (this_month, last_90_days) se resuelven al ejecutarlas
+		viewsRoutes := api.Group("/views")
+		viewsRoutes.Use(authMiddleware)
+		viewsRoutes.Use(accountMiddleware)
+		{
+			viewsRoutes.GET("", viewsHandler.ListViews(s.db.Pool))
+			viewsRoutes.POST("", viewsHandler.CreateView(s.db.Pool))
+			viewsRoutes.GET("/:id", viewsHandler.GetView(s.db.Pool))
+			viewsRoutes.PUT("/:id", viewsHandler.UpdateView(s.db.Pool))
+			viewsRoutes.DELETE("/:id", viewsHandler.DeleteView(s.db.Pool))
+			viewsRoutes.GET("/:id/results", viewsHandler.ViewResults(s.db.Pool)) // Ejecutar la vista
+			viewsRoutes.GET("/:id/summary", viewsHandler.ViewSummary(s.db.Pool)) // Totales para el dashboard
+			viewsRoutes.GET("/:id/export", viewsHandler.ExportView(s.db.Pool))   // CSV
 		}
 
 		// Rutas de dashboard (protegidas - requieren auth + account)
@@ -158,6 +283,15 @@ func (s *Server) setupRoutes() {
 			dashboardRoutes.GET("/summary", dashboardHandler.GetSummary(s.db.Pool))
 		}
 
+		// Rutas de reportes (protegidas - requieren auth + account)
+		reportsRoutes := api.Group("/reports")
+		reportsRoutes.Use(authMiddleware)
+		reportsRoutes.Use(accountMiddleware)
+		{
+			reportsRoutes.GET("/members", reportsHandler.GetMembersReport(s.db.Pool))
+			reportsRoutes.GET("/tags", reportsHandler.GetTagsReport(s.db.Pool))
+		}
+
 		// Rutas de savings goals (protegidas - requieren auth + account)
 		savingsGoalsRoutes := api.Group("/savings-goals")
 		savingsGoalsRoutes.Use(authMiddleware)
@@ -165,12 +299,22 @@ func (s *Server) setupRoutes() {
 		{
 		savingsGoalsRoutes.POST("", savingsGoalsHandler.CreateSavingsGoal(s.db.Pool))
 		savingsGoalsRoutes.GET("", savingsGoalsHandler.ListSavingsGoals(s.db.Pool))
+		savingsGoalsRoutes.GET("/reconciliation", savingsGoalsHandler.GetReconciliation(s.db.Pool))
 		savingsGoalsRoutes.GET("/:id", savingsGoalsHandler.GetSavingsGoal(s.db.Pool))
 		savingsGoalsRoutes.GET("/:id/transactions", savingsGoalsHandler.GetTransactions(s.db.Pool))
+		savingsGoalsRoutes.GET("/:id/projection", savingsGoalsHandler.GetSavingsGoalProjection(s.db.Pool))
 		savingsGoalsRoutes.PUT("/:id", savingsGoalsHandler.UpdateSavingsGoal(s.db.Pool))
 		savingsGoalsRoutes.DELETE("/:id", savingsGoalsHandler.DeleteSavingsGoal(s.db.Pool))
 		savingsGoalsRoutes.POST("/:id/add-funds", savingsGoalsHandler.AddFunds(s.db.Pool))
 		savingsGoalsRoutes.POST("/:id/withdraw-funds", savingsGoalsHandler.WithdrawFunds(s.db.Pool))
+		savingsGoalsRoutes.GET("/:id/contribution-rules", savingsGoalsHandler.ListContributionRules(s.db.Pool))
+		savingsGoalsRoutes.POST("/:id/contribution-rules", savingsGoalsHandler.CreateContributionRule(s.db.Pool))
+		savingsGoalsRoutes.PUT("/:id/contribution-rules/:rule_id", savingsGoalsHandler.UpdateContributionRule(s.db.Pool))
+		savingsGoalsRoutes.DELETE("/:id/contribution-rules/:rule_id", savingsGoalsHandler.DeleteContributionRule(s.db.Pool))
+		savingsGoalsRoutes.GET("/:id/milestones", savingsGoalsHandler.ListMilestones(s.db.Pool))
+		savingsGoalsRoutes.POST("/:id/milestones", savingsGoalsHandler.CreateMilestone(s.db.Pool))
+		savingsGoalsRoutes.DELETE("/:id/milestones/:milestone_id", savingsGoalsHandler.DeleteMilestone(s.db.Pool))
+		savingsGoalsRoutes.GET("/:id/events", savingsGoalsHandler.ListGoalEvents(s.db.Pool))
 		}
 
 		// Rutas de recurring expenses (protegidas - requieren auth + account)
@@ -183,6 +327,16 @@ func (s *Server) setupRoutes() {
 			recurringExpensesRoutes.GET("/:id", recurringExpensesHandler.GetRecurringExpense(s.db.Pool))
 			recurringExpensesRoutes.PUT("/:id", recurringExpensesHandler.UpdateRecurringExpense(s.db.Pool))
 			recurringExpensesRoutes.DELETE("/:id", recurringExpensesHandler.DeleteRecurringExpense(s.db.Pool))
+
+			// Excepciones por ocurrencia (skip / override) y pausa temporal
+			recurringExpensesRoutes.GET("/:id/occurrences", recurringExpensesHandler.ListRecurringExpenseOccurrences(s.db.Pool))
+			recurringExpensesRoutes.PUT("/:id/occurrences/:date", recurringExpensesHandler.UpsertRecurringExpenseOccurrence(s.db.Pool))
+			recurringExpensesRoutes.DELETE("/:id/occurrences/:date", recurringExpensesHandler.DeleteRecurringExpenseOccurrence(s.db.Pool))
+			recurringExpensesRoutes.POST("/:id/pause", recurringExpensesHandler.PauseRecurringExpense(s.db.Pool))
+			recurringExpensesRoutes.POST("/:id/resume", recurringExpensesHandler.ResumeRecurringExpense(s.db.Pool))
+
+			// Historial de versiones (cambios de monto con effective_from)
+			recurringExpensesRoutes.GET("/:id/history", recurringExpensesHandler.GetRecurringExpenseHistory(s.db.Pool))
 		}
 
 		// Rutas de recurring incomes (protegidas - requieren auth + account)
@@ -195,6 +349,88 @@ func (s *Server) setupRoutes() {
 			recurringIncomesRoutes.GET("/:id", recurringIncomesHandler.GetRecurringIncome(s.db.Pool))
 			recurringIncomesRoutes.PUT("/:id", recurringIncomesHandler.UpdateRecurringIncome(s.db.Pool))
 			recurringIncomesRoutes.DELETE("/:id", recurringIncomesHandler.DeleteRecurringIncome(s.db.Pool))
+
+			// Excepciones por ocurrencia (skip / override) y pausa temporal
+			recurringIncomesRoutes.GET("/:id/occurrences", recurringIncomesHandler.ListRecurringIncomeOccurrences(s.db.Pool))
+			recurringIncomesRoutes.PUT("/:id/occurrences/:date", recurringIncomesHandler.UpsertRecurringIncomeOccurrence(s.db.Pool))
+			recurringIncomesRoutes.DELETE("/:id/occurrences/:date", recurringIncomesHandler.DeleteRecurringIncomeOccurrence(s.db.Pool))
+			recurringIncomesRoutes.POST("/:id/pause", recurringIncomesHandler.PauseRecurringIncome(s.db.Pool))
+			recurringIncomesRoutes.POST("/:id/resume", recurringIncomesHandler.ResumeRecurringIncome(s.db.Pool))
+		}
+
+		// Rutas de compras en cuotas (protegidas - requieren auth + account)
+		installmentsRoutes := api.Group("/installments")
+		installmentsRoutes.Use(authMiddleware)
+		installmentsRoutes.Use(accountMiddleware)
+		{
+			installmentsRoutes.POST("", installmentsHandler.CreateInstallmentPurchase(s.db.Pool))
+			installmentsRoutes.GET("", installmentsHandler.ListInstallmentPurchases(s.db.Pool))
+			installmentsRoutes.GET("/commitments", installmentsHandler.GetInstallmentCommitments(s.db.Pool))
+			installmentsRoutes.GET("/:id", installmentsHandler.GetInstallmentPurchase(s.db.Pool))
+			installmentsRoutes.DELETE("/:id", installmentsHandler.DeleteInstallmentPurchase(s.db.Pool))
+			installmentsRoutes.POST("/:id/payoff", installmentsHandler.PayOffInstallmentPurchase(s.db.Pool))
+		}
+
+		// Rutas de medios de pago / tarjetas (protegidas - requieren auth + account)
+		paymentMethodsRoutes := api.Group("/payment-methods")
+		paymentMethodsRoutes.Use(authMiddleware)
+		paymentMethodsRoutes.Use(accountMiddleware)
+		{
+			paymentMethodsRoutes.GET("", paymentMethodsHandler.ListPaymentMethods(s.db.Pool))
+			paymentMethodsRoutes.POST("", paymentMethodsHandler.CreatePaymentMethod(s.db.Pool))
+			paymentMethodsRoutes.GET("/balances", paymentMethodsHandler.GetPaymentMethodBalances(s.db.Pool))
+			paymentMethodsRoutes.PUT("/:id", paymentMethodsHandler.UpdatePaymentMethod(s.db.Pool))
+			paymentMethodsRoutes.DELETE("/:id", paymentMethodsHandler.DeletePaymentMethod(s.db.Pool))
+			paymentMethodsRoutes.GET("/:id/statements", paymentMethodsHandler.GetPaymentMethodStatements(s.db.Pool))
+		}
+
+		// Rutas de inversiones (protegidas - requieren auth + account)
+		investmentsRoutes := api.Group("/investments")
+		investmentsRoutes.Use(authMiddleware)
+		investmentsRoutes.Use(accountMiddleware)
+		{
+			investmentsRoutes.GET("", investmentsHandler.ListHoldings(s.db.Pool))
+			investmentsRoutes.POST("", investmentsHandler.CreateHolding(s.db.Pool))
+			investmentsRoutes.POST("/prices/refresh", investmentsHandler.RefreshPrices(s.db.Pool, priceProvider))
+			investmentsRoutes.GET("/:id", investmentsHandler.GetHolding(s.db.Pool))
+			investmentsRoutes.PUT("/:id", investmentsHandler.UpdateHolding(s.db.Pool))
+			investmentsRoutes.DELETE("/:id", investmentsHandler.DeleteHolding(s.db.Pool))
+			investmentsRoutes.POST("/:id/transactions", investmentsHandler.CreateTransaction(s.db.Pool))
+			investmentsRoutes.POST("/:id/prices", investmentsHandler.AddPrice(s.db.Pool))
+		}
+
+		// Rutas de préstamos y deudas (protegidas - requieren auth + account)
+		debtsRoutes := api.Group("/debts")
+		debtsRoutes.Use(authMiddleware)
+		debtsRoutes.Use(accountMiddleware)
+		{
+			debtsRoutes.GET("", debtsHandler.ListDebts(s.db.Pool))
+			debtsRoutes.POST("", debtsHandler.CreateDebt(s.db.Pool))
+			debtsRoutes.GET("/:id", debtsHandler.GetDebt(s.db.Pool))
+			debtsRoutes.PUT("/:id", debtsHandler.UpdateDebt(s.db.Pool))
+			debtsRoutes.DELETE("/:id", debtsHandler.DeleteDebt(s.db.Pool))
+			debtsRoutes.POST("/:id/payments", debtsHandler.CreatePayment(s.db.Pool))
+			debtsRoutes.DELETE("/:id/payments/:payment_id", debtsHandler.DeletePayment(s.db.Pool))
+		}
+
+		// Rutas de patrimonio (el estado consolida todas las cuentas del usuario: solo requiere auth;
+		// los activos/pasivos manuales son por cuenta y requieren X-Account-ID)
+		netWorthRoutes := api.Group("/net-worth")
+		netWorthRoutes.Use(authMiddleware)
+		{
+			netWorthRoutes.GET("", netWorthHandler.GetNetWorth(s.db.Pool))
+
+			netWorthItemsRoutes := netWorthRoutes.Group("/items")
+			netWorthItemsRoutes.Use(accountMiddleware)
+			{
+				netWorthItemsRoutes.GET("", netWorthHandler.ListItems(s.db.Pool))
+				netWorthItemsRoutes.POST("", netWorthHandler.CreateItem(s.db.Pool))
+				netWorthItemsRoutes.GET("/:id", netWorthHandler.GetItem(s.db.Pool))
+				netWorthItemsRoutes.PUT("/:id", netWorthHandler.UpdateItem(s.db.Pool))
+				netWorthItemsRoutes.DELETE("/:id", netWorthHandler.DeleteItem(s.db.Pool))
+				netWorthItemsRoutes.POST("/:id/valuations", netWorthHandler.AddValuation(s.db.Pool))
+				netWorthItemsRoutes.DELETE("/:id/valuations/:valuation_id", netWorthHandler.DeleteValuation(s.db.Pool))
+			}
 		}
 	}
 }
@@ -317,40 +553,138 @@ func (s *Server) Start() error {
 	fmt.Printf("   - GET    http://localhost%s/api/expenses (Listar gastos con filtros)\n", addr)
 	fmt.Printf("   - GET    http://localhost%s/api/expenses/:id (Obtener detalle de gasto)\n", addr)
 	fmt.Printf("   - POST   http://localhost%s/api/expenses (Registrar gasto)\n", addr)
+	fmt.Printf("   - GET    http://localhost%s/api/expenses/suggest-category?description= (Sugerir categoría)\n", addr)
 	fmt.Printf("   - PUT    http://localhost%s/api/expenses/:id (Actualizar gasto)\n", addr)
 	fmt.Printf("   - DELETE http://localhost%s/api/expenses/:id (Eliminar gasto)\n", addr)
+	fmt.Printf("   - GET    http://localhost%s/api/expenses/:id/split (Ver división entre miembros)\n", addr)
+	fmt.Printf("   - PUT    http://localhost%s/api/expenses/:id/split (Dividir gasto entre miembros)\n", addr)
+	fmt.Printf("   - DELETE http://localhost%s/api/expenses/:id/split (Quitar división)\n", addr)
+	fmt.Printf("   - POST   http://localhost%s/api/expenses/:id/attachments (Adjuntar comprobante)\n", addr)
+	fmt.Printf("   - GET    http://localhost%s/api/expenses/:id/attachments (Listar comprobantes)\n", addr)
+	fmt.Printf("\n⚖️  Gastos compartidos (requiere autenticación + X-Account-ID, cuentas family):\n")
+	fmt.Printf("   - GET    http://localhost%s/api/splits/balances (Quién le debe a quién)\n", addr)
+	fmt.Printf("   - GET    http://localhost%s/api/splits/settlements (Listar reintegros)\n", addr)
+	fmt.Printf("   - POST   http://localhost%s/api/splits/settlements (Registrar reintegro)\n", addr)
+	fmt.Printf("   - DELETE http://localhost%s/api/splits/settlements/:id (Eliminar reintegro)\n", addr)
+	fmt.Printf("   - POST   http://localhost%s/api/splits/settle-up (Saldar todo)\n", addr)
 	fmt.Printf("\n💰 Ingresos (requiere autenticación + X-Account-ID):\n")
 	fmt.Printf("   - GET    http://localhost%s/api/incomes (Listar ingresos con filtros)\n", addr)
 	fmt.Printf("   - GET    http://localhost%s/api/incomes/:id (Obtener detalle de ingreso)\n", addr)
 	fmt.Printf("   - POST   http://localhost%s/api/incomes (Registrar ingreso)\n", addr)
 	fmt.Printf("   - PUT    http://localhost%s/api/incomes/:id (Actualizar ingreso)\n", addr)
 	fmt.Printf("   - DELETE http://localhost%s/api/incomes/:id (Eliminar ingreso)\n", addr)
+	fmt.Printf("   - POST   http://localhost%s/api/incomes/:id/attachments (Adjuntar comprobante)\n", addr)
+	fmt.Printf("   - GET    http://localhost%s/api/incomes/:id/attachments (Listar comprobantes)\n", addr)
+	fmt.Printf("   - GET    http://localhost%s/api/attachments/:id/download (Descargar comprobante)\n", addr)
+	fmt.Printf("   - DELETE http://localhost%s/api/attachments/:id (Eliminar comprobante)\n", addr)
+	fmt.Printf("   - POST   http://localhost%s/api/receipts/scan (Escanear comprobante → gasto borrador)\n", addr)
+	fmt.Printf("   - GET    http://localhost%s/api/receipts/drafts (Borradores pendientes)\n", addr)
+	fmt.Printf("   - POST   http://localhost%s/api/receipts/drafts/:id/confirm (Confirmar borrador)\n", addr)
+	fmt.Printf("   - DELETE http://localhost%s/api/receipts/drafts/:id (Descartar borrador)\n", addr)
 	fmt.Printf("\n🏷️  Categorías (requiere autenticación + X-Account-ID):\n")
 	fmt.Printf("   - GET    http://localhost%s/api/expense-categories (Listar categorías de gastos)\n", addr)
 	fmt.Printf("   - POST   http://localhost%s/api/expense-categories (Crear categoría custom)\n", addr)
 	fmt.Printf("   - PUT    http://localhost%s/api/expense-categories/:id (Actualizar)\n", addr)
-	fmt.Printf("   - DELETE http://localhost%s/api/expense-categories/:id (Eliminar)\n", addr)
+	fmt.Printf("   - DELETE http://localhost%s/api/expense-categories/:id (Eliminar, ?reassign_to= para reasignar)\n", addr)
+	fmt.Printf("   - POST   http://localhost%s/api/expense-categories/:id/merge (Fusionar en otra categoría)\n", addr)
 	fmt.Printf("   - GET    http://localhost%s/api/income-categories (Listar categorías de ingresos)\n", addr)
 	fmt.Printf("   - POST   http://localhost%s/api/income-categories (Crear categoría custom)\n", addr)
 	fmt.Printf("   - PUT    http://localhost%s/api/income-categories/:id (Actualizar)\n", addr)
-	fmt.Printf("   - DELETE http://localhost%s/api/income-categories/:id (Eliminar)\n", addr)
+	fmt.Printf("   - DELETE http://localhost%s/api/income-categories/:id (Eliminar, ?reassign_to= para reasignar)\n", addr)
+	fmt.Printf("   - POST   http://localhost%s/api/income-categories/:id/merge (Fusionar en otra categoría)\n", addr)
+	fmt.Printf("   - GET    http://localhost%s/api/tags?q= (Autocompletar tags)\n", addr)
+	fmt.Printf("   - PUT    http://localhost%s/api/tags/:id (Renombrar tag)\n", addr)
+	fmt.Printf("   - DELETE http://localhost%s/api/tags/:id (Eliminar tag)\n", addr)
+	fmt.Printf("   - GET    http://localhost%s/api/rules (Listar reglas de categorización)\n", addr)
+	fmt.Printf("   - POST   http://localhost%s/api/rules (Crear regla)\n", addr)
+	fmt.Printf("   - PUT    http://localhost%s/api/rules/:id (Actualizar regla)\n", addr)
+	fmt.Printf("   - DELETE http://localhost%s/api/rules/:id (Eliminar regla)\n", addr)
+	fmt.Printf("   - GET    http://localhost%s/api/rules/:id/dry-run (Simular sobre movimientos existentes)\n", addr)
+	fmt.Printf("   - POST   http://localhost%s/api/rules/:id/apply (Aplicar retroactivamente)\n", addr)
+	fmt.Printf("   - GET    http://localhost%s/api/search?q=&types= (Buscar gastos, ingresos, recurrentes y ahorros)\n", addr)
+	fmt.Printf("   - GET    http://localhost%s/api/views (Listar vistas guardadas)\n", addr)
+	fmt.Printf("   - POST   http://localhost%s/api/views (Crear vista guardada)\n", addr)
+	fmt.Printf("   - GET    http://localhost%s/api/views/:id (Obtener vista)\n", addr)
+	fmt.Printf("   - PUT    http://localhost%s/api/views/:id (Actualizar vista)\n", addr)
+	fmt.Printf("   - DELETE http://localhost%s/api/views/:id (Eliminar vista)\n", addr)
+	fmt.Printf("   - GET    http://localhost%s/api/views/:id/results (Ejecutar vista)\n", addr)
+	fmt.Printf("   - GET    http://localhost%s/api/views/:id/summary (Totales de la vista)\n", addr)
+	fmt.Printf("   - GET    http://localhost%s/api/views/:id/export (Exportar vista a CSV)\n", addr)
 	fmt.Printf("\n📊 Dashboard (requiere autenticación + X-Account-ID):\n")
-	fmt.Printf("   - GET    http://localhost%s/api/dashboard/summary?month=YYYY-MM (Resumen financiero del mes)\n", addr)
+	fmt.Printf("   - GET    http://localhost%s/api/dashboard/summary?month=YYYY-MM&basis=accrual|cash&family_member_id= (Resumen financiero del mes)\n", addr)
+	fmt.Printf("   - GET    http://localhost%s/api/reports/members?month=YYYY-MM (Ingresos, gastos y asignación por miembro)\n", addr)
+	fmt.Printf("   - GET    http://localhost%s/api/reports/tags?date_from=&date_to=&tags= (Totales por tag)\n", addr)
 	fmt.Printf("\n🎯 Metas de Ahorro (requiere autenticación + X-Account-ID):\n")
 	fmt.Printf("   - GET    http://localhost%s/api/savings-goals (Listar metas)\n", addr)
+	fmt.Printf("   - GET    http://localhost%s/api/savings-goals/reconciliation (Conciliar metas con el saldo de la cuenta)\n", addr)
 	fmt.Printf("   - GET    http://localhost%s/api/savings-goals/:id (Detalle con historial)\n", addr)
 	fmt.Printf("   - GET    http://localhost%s/api/savings-goals/:id/transactions (Solo historial de transacciones)\n", addr)
+	fmt.Printf("   - GET    http://localhost%s/api/savings-goals/:id/projection (Proyección y what-if)\n", addr)
 	fmt.Printf("   - POST   http://localhost%s/api/savings-goals (Crear meta)\n", addr)
 	fmt.Printf("   - PUT    http://localhost%s/api/savings-goals/:id (Actualizar meta)\n", addr)
 	fmt.Printf("   - DELETE http://localhost%s/api/savings-goals/:id (Eliminar meta)\n", addr)
 	fmt.Printf("   - POST   http://localhost%s/api/savings-goals/:id/add-funds (Agregar fondos)\n", addr)
 	fmt.Printf("   - POST   http://localhost%s/api/savings-goals/:id/withdraw-funds (Retirar fondos)\n", addr)
+	fmt.Printf("   - GET    http://localhost%s/api/savings-goals/:id/contribution-rules (Aportes automáticos)\n", addr)
+	fmt.Printf("   - POST   http://localhost%s/api/savings-goals/:id/contribution-rules (Crear aporte automático)\n", addr)
+	fmt.Printf("   - PUT    http://localhost%s/api/savings-goals/:id/contribution-rules/:rule_id (Actualizar aporte automático)\n", addr)
+	fmt.Printf("   - DELETE http://localhost%s/api/savings-goals/:id/contribution-rules/:rule_id (Eliminar aporte automático)\n", addr)
+	fmt.Printf("   - GET    http://localhost%s/api/savings-goals/:id/milestones (Hitos de la meta)\n", addr)
+	fmt.Printf("   - POST   http://localhost%s/api/savings-goals/:id/milestones (Crear hito)\n", addr)
+	fmt.Printf("   - DELETE http://localhost%s/api/savings-goals/:id/milestones/:milestone_id (Eliminar hito)\n", addr)
+	fmt.Printf("   - GET    http://localhost%s/api/savings-goals/:id/events (Historial de hitos y meta completada)\n", addr)
 	fmt.Printf("\n🔁 Gastos Recurrentes (requiere autenticación + X-Account-ID):\n")
 	fmt.Printf("   - GET    http://localhost%s/api/recurring-expenses (Listar templates)\n", addr)
 	fmt.Printf("   - GET    http://localhost%s/api/recurring-expenses/:id (Detalle de template)\n", addr)
 	fmt.Printf("   - POST   http://localhost%s/api/recurring-expenses (Crear template)\n", addr)
 	fmt.Printf("   - PUT    http://localhost%s/api/recurring-expenses/:id (Actualizar template)\n", addr)
 	fmt.Printf("   - DELETE http://localhost%s/api/recurring-expenses/:id (Desactivar template)\n", addr)
+	fmt.Printf("   - GET    http://localhost%s/api/recurring-expenses/:id/occurrences (Próximas ocurrencias)\n", addr)
+	fmt.Printf("   - PUT    http://localhost%s/api/recurring-expenses/:id/occurrences/:date (Saltear/modificar una ocurrencia)\n", addr)
+	fmt.Printf("   - DELETE http://localhost%s/api/recurring-expenses/:id/occurrences/:date (Quitar excepción)\n", addr)
+	fmt.Printf("   - POST   http://localhost%s/api/recurring-expenses/:id/pause (Pausar hasta una fecha)\n", addr)
+	fmt.Printf("   - POST   http://localhost%s/api/recurring-expenses/:id/resume (Reanudar)\n", addr)
+	fmt.Printf("   - GET    http://localhost%s/api/recurring-expenses/:id/history (Historial de precios)\n", addr)
+	fmt.Printf("\n💳 Compras en cuotas (requiere autenticación + X-Account-ID):\n")
+	fmt.Printf("   - GET    http://localhost%s/api/installments (Listar compras en cuotas)\n", addr)
+	fmt.Printf("   - GET    http://localhost%s/api/installments/commitments (Cuotas comprometidas por mes)\n", addr)
+	fmt.Printf("   - GET    http://localhost%s/api/installments/:id (Detalle y plan de cuotas)\n", addr)
+	fmt.Printf("   - POST   http://localhost%s/api/installments (Crear compra en cuotas)\n", addr)
+	fmt.Printf("   - POST   http://localhost%s/api/installments/:id/payoff (Cancelación anticipada)\n", addr)
+	fmt.Printf("   - DELETE http://localhost%s/api/installments/:id (Eliminar compra y sus cuotas)\n", addr)
+	fmt.Printf("\n💳 Medios de pago / tarjetas (requiere autenticación + X-Account-ID):\n")
+	fmt.Printf("   - GET    http://localhost%s/api/payment-methods (Listar medios de pago)\n", addr)
+	fmt.Printf("   - POST   http://localhost%s/api/payment-methods (Crear medio de pago)\n", addr)
+	fmt.Printf("   - GET    http://localhost%s/api/payment-methods/balances (Saldo por billetera)\n", addr)
+	fmt.Printf("   - PUT    http://localhost%s/api/payment-methods/:id (Actualizar)\n", addr)
+	fmt.Printf("   - DELETE http://localhost%s/api/payment-methods/:id (Eliminar)\n", addr)
+	fmt.Printf("   - GET    http://localhost%s/api/payment-methods/:id/statements (Resúmenes de tarjeta por ciclo)\n", addr)
+	fmt.Printf("\n📈 Inversiones (requiere autenticación + X-Account-ID):\n")
+	fmt.Printf("   - GET    http://localhost%s/api/investments (Listar inversiones con valuación)\n", addr)
+	fmt.Printf("   - POST   http://localhost%s/api/investments (Crear inversión / plazo fijo)\n", addr)
+	fmt.Printf("   - POST   http://localhost%s/api/investments/prices/refresh (Actualizar precios desde el proveedor)\n", addr)
+	fmt.Printf("   - GET    http://localhost%s/api/investments/:id (Detalle con operaciones y precios)\n", addr)
+	fmt.Printf("   - PUT    http://localhost%s/api/investments/:id (Actualizar)\n", addr)
+	fmt.Printf("   - DELETE http://localhost%s/api/investments/:id (Eliminar)\n", addr)
+	fmt.Printf("   - POST   http://localhost%s/api/investments/:id/transactions (Compra / venta)\n", addr)
+	fmt.Printf("   - POST   http://localhost%s/api/investments/:id/prices (Cargar precio manual)\n", addr)
+	fmt.Printf("\n🤝 Préstamos y deudas (requiere autenticación + X-Account-ID):\n")
+	fmt.Printf("   - GET    http://localhost%s/api/debts (Listar con saldo pendiente)\n", addr)
+	fmt.Printf("   - POST   http://localhost%s/api/debts (Crear préstamo tomado / otorgado)\n", addr)
+	fmt.Printf("   - GET    http://localhost%s/api/debts/:id (Detalle con plan de cuotas y pagos)\n", addr)
+	fmt.Printf("   - PUT    http://localhost%s/api/debts/:id (Actualizar / dar por perdonada)\n", addr)
+	fmt.Printf("   - DELETE http://localhost%s/api/debts/:id (Eliminar)\n", addr)
+	fmt.Printf("   - POST   http://localhost%s/api/debts/:id/payments (Registrar pago / cobro)\n", addr)
+	fmt.Printf("   - DELETE http://localhost%s/api/debts/:id/payments/:payment_id (Eliminar pago)\n", addr)
+	fmt.Printf("\n🧮 Patrimonio (requiere autenticación):\n")
+	fmt.Printf("   - GET    http://localhost%s/api/net-worth (Estado patrimonial consolidado + histórico mensual)\n", addr)
+	fmt.Printf("   - GET    http://localhost%s/api/net-worth/items (Listar activos/pasivos - requiere X-Account-ID)\n", addr)
+	fmt.Printf("   - POST   http://localhost%s/api/net-worth/items (Crear activo/pasivo - requiere X-Account-ID)\n", addr)
+	fmt.Printf("   - GET    http://localhost%s/api/net-worth/items/:id (Detalle con valuaciones)\n", addr)
+	fmt.Printf("   - PUT    http://localhost%s/api/net-worth/items/:id (Actualizar)\n", addr)
+	fmt.Printf("   - DELETE http://localhost%s/api/net-worth/items/:id (Eliminar)\n", addr)
+	fmt.Printf("   - POST   http://localhost%s/api/net-worth/items/:id/valuations (Cargar valuación)\n", addr)
+	fmt.Printf("   - DELETE http://localhost%s/api/net-worth/items/:id/valuations/:valuation_id (Eliminar valuación)\n", addr)
 	fmt.Println()
 
 	// Iniciar el servidor
@@ -0,0 +1,126 @@
+-- Migration 018: Skip, pause and override individual recurring occurrences
+-- Date: 2026-02-02
+-- Description: Adds per-occurrence exceptions for recurring templates (skip one occurrence or
+--              override its amount) and a paused_until date to pause a template temporarily.
+--              The CRON scheduler consults both before generating expenses/incomes.
+
+-- ====================
+-- 1. CREATE ENUM TYPE
+-- ====================
+
+CREATE TYPE recurring_exception_type AS ENUM ('skip', 'override');
+
+COMMENT ON TYPE recurring_exception_type IS 'skip = no se genera la ocurrencia, override = se genera con otro monto';
+
+-- ====================
+-- 2. PAUSE SUPPORT ON TEMPLATES
+-- ====================
+
+ALTER TABLE recurring_expenses
+ADD COLUMN paused_until DATE;
+
+ALTER TABLE recurring_incomes
+ADD COLUMN paused_until DATE;
+
+COMMENT ON COLUMN recurring_expenses.paused_until IS 'Si no es NULL, no se generan ocurrencias hasta esta fecha (inclusive). Se reanuda al día siguiente';
+COMMENT ON COLUMN recurring_incomes.paused_until IS 'Si no es NULL, no se generan ocurrencias hasta esta fecha (inclusive). Se reanuda al día siguiente';
+
+-- ====================
+-- 3. CREATE TABLES
+-- ====================
+
+CREATE TABLE recurring_expense_exceptions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    recurring_expense_id UUID NOT NULL REFERENCES recurring_expenses(id) ON DELETE CASCADE,
+
+    -- Fecha de la ocurrencia afectada (la fecha en la que el CRON la generaría)
+    occurrence_date DATE NOT NULL,
+    exception_type recurring_exception_type NOT NULL,
+
+    -- Solo para override
+    override_amount NUMERIC(15,2) CHECK (override_amount IS NULL OR override_amount > 0),
+    override_amount_in_primary_currency NUMERIC(15,2) CHECK (override_amount_in_primary_currency IS NULL OR override_amount_in_primary_currency > 0),
+
+    note TEXT,
+
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    -- Una sola excepción por ocurrencia
+    UNIQUE (recurring_expense_id, occurrence_date),
+
+    CONSTRAINT check_expense_override_requires_amount CHECK (
+        (exception_type = 'override' AND override_amount IS NOT NULL)
+        OR
+        (exception_type = 'skip' AND override_amount IS NULL AND override_amount_in_primary_currency IS NULL)
+    )
+);
+
+CREATE TABLE recurring_income_exceptions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    recurring_income_id UUID NOT NULL REFERENCES recurring_incomes(id) ON DELETE CASCADE,
+
+    occurrence_date DATE NOT NULL,
+    exception_type recurring_exception_type NOT NULL,
+
+    override_amount NUMERIC(15,2) CHECK (override_amount IS NULL OR override_amount > 0),
+    override_amount_in_primary_currency NUMERIC(15,2) CHECK (override_amount_in_primary_currency IS NULL OR override_amount_in_primary_currency > 0),
+
+    note TEXT,
+
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    UNIQUE (recurring_income_id, occurrence_date),
+
+    CONSTRAINT check_income_override_requires_amount CHECK (
+        (exception_type = 'override' AND override_amount IS NOT NULL)
+        OR
+        (exception_type = 'skip' AND override_amount IS NULL AND override_amount_in_primary_currency IS NULL)
+    )
+);
+
+-- ====================
+-- 4. COMMENTS (Documentation)
+-- ====================
+
+COMMENT ON TABLE recurring_expense_exceptions IS 'Excepciones puntuales sobre ocurrencias de un recurring_expense (saltear o cambiar monto) sin modificar el template';
+COMMENT ON COLUMN recurring_expense_exceptions.occurrence_date IS 'Fecha de la ocurrencia afectada';
+COMMENT ON COLUMN recurring_expense_exceptions.override_amount IS 'Monto a usar en lugar de recurring_expenses.amount (solo override)';
+COMMENT ON COLUMN recurring_expense_exceptions.override_amount_in_primary_currency IS 'Monto en moneda primaria (opcional). Si es NULL se calcula con el exchange_rate del template';
+
+COMMENT ON TABLE recurring_income_exceptions IS 'Excepciones puntuales sobre ocurrencias de un recurring_income (saltear o cambiar monto) sin modificar el template';
+COMMENT ON COLUMN recurring_income_exceptions.occurrence_date IS 'Fecha de la ocurrencia afectada';
+COMMENT ON COLUMN recurring_income_exceptions.override_amount IS 'Monto a usar en lugar de recurring_incomes.amount (solo override)';
+COMMENT ON COLUMN recurring_income_exceptions.override_amount_in_primary_currency IS 'Monto en moneda primaria (opcional). Si es NULL se calcula con el exchange_rate del template';
+
+-- ====================
+-- 5. INDEXES
+-- ====================
+
+CREATE INDEX idx_recurring_expense_exceptions_template_date ON recurring_expense_exceptions(recurring_expense_id, occurrence_date);
+CREATE INDEX idx_recurring_income_exceptions_template_date ON recurring_income_exceptions(recurring_income_id, occurrence_date);
+
+-- ====================
+-- 6. TRIGGERS (auto-update updated_at)
+-- ====================
+
+CREATE TRIGGER trigger_update_recurring_expense_exceptions_updated_at
+BEFORE UPDATE ON recurring_expense_exceptions
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER trigger_update_recurring_income_exceptions_updated_at
+BEFORE UPDATE ON recurring_income_exceptions
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+-- ====================
+-- MIGRATION COMPLETE
+-- ====================
+
+-- Summary of changes:
+-- ✅ Created recurring_exception_type ENUM (skip, override)
+-- ✅ Added paused_until to recurring_expenses and recurring_incomes
+-- ✅ Created recurring_expense_exceptions and recurring_income_exceptions
+-- ✅ One exception per (template, occurrence_date)
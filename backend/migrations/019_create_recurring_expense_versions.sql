@@ -0,0 +1,101 @@
+-- Migration 019: Version history for recurring expense templates ("this and future" edits)
+-- Date: 2026-02-03
+-- Description: Every change to the amount/currency/description/category/member of a recurring
+--              expense creates a version with an effective_from date. The CRON generates each
+--              occurrence with the version in effect on that date, and generated expenses keep a FK
+--              to the version used, so price history (e.g. Netflix raising its price) is preserved.
+
+-- ====================
+-- 1. CREATE TABLES
+-- ====================
+
+CREATE TABLE recurring_expense_versions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    recurring_expense_id UUID NOT NULL REFERENCES recurring_expenses(id) ON DELETE CASCADE,
+    version_number INT NOT NULL CHECK (version_number > 0),
+
+    -- Desde qué fecha aplica esta versión (hasta el effective_from de la siguiente)
+    effective_from DATE NOT NULL,
+
+    -- Snapshot de los campos versionados del template
+    description TEXT NOT NULL,
+    amount NUMERIC(15,2) NOT NULL CHECK (amount > 0),
+    currency currency NOT NULL,
+    category_id UUID REFERENCES expense_categories(id) ON DELETE SET NULL,
+    family_member_id UUID REFERENCES family_members(id) ON DELETE SET NULL,
+    exchange_rate NUMERIC(15,6) CHECK (exchange_rate IS NULL OR exchange_rate > 0),
+    amount_in_primary_currency NUMERIC(15,2) CHECK (amount_in_primary_currency IS NULL OR amount_in_primary_currency > 0),
+
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    -- Una sola versión por fecha (editar dos veces el mismo día reemplaza la versión)
+    UNIQUE (recurring_expense_id, effective_from),
+    UNIQUE (recurring_expense_id, version_number)
+);
+
+-- ====================
+-- 2. COMMENTS (Documentation)
+-- ====================
+
+COMMENT ON TABLE recurring_expense_versions IS 'Historial de versiones de un recurring_expense. La versión vigente en una fecha es la de mayor effective_from <= fecha';
+COMMENT ON COLUMN recurring_expense_versions.effective_from IS 'Fecha desde la cual aplica esta versión';
+COMMENT ON COLUMN recurring_expense_versions.version_number IS 'Número correlativo de versión (1 = valores originales del template)';
+
+-- ====================
+-- 3. INDEXES
+-- ====================
+
+CREATE INDEX idx_recurring_expense_versions_template_date ON recurring_expense_versions(recurring_expense_id, effective_from DESC);
+
+-- ====================
+-- 4. TRIGGERS (auto-update updated_at)
+-- ====================
+
+CREATE TRIGGER trigger_update_recurring_expense_versions_updated_at
+BEFORE UPDATE ON recurring_expense_versions
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+-- ====================
+-- 5. LINK GENERATED ROWS TO THE VERSION USED
+-- ====================
+
+ALTER TABLE expenses
+ADD COLUMN recurring_expense_version_id UUID REFERENCES recurring_expense_versions(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_expenses_recurring_expense_version_id ON expenses(recurring_expense_version_id);
+
+COMMENT ON COLUMN expenses.recurring_expense_version_id IS 'Versión del template usada para generar este gasto (NULL para gastos one-time)';
+
+-- ====================
+-- 6. MIGRATE EXISTING DATA
+-- ====================
+
+-- Versión 1 de cada template existente = sus valores actuales desde start_date
+INSERT INTO recurring_expense_versions (
+    recurring_expense_id, version_number, effective_from,
+    description, amount, currency, category_id, family_member_id,
+    exchange_rate, amount_in_primary_currency
+)
+SELECT
+    id, 1, start_date,
+    description, amount, currency, category_id, family_member_id,
+    exchange_rate, amount_in_primary_currency
+FROM recurring_expenses;
+
+-- Vincular los gastos ya generados con la versión 1
+UPDATE expenses e
+SET recurring_expense_version_id = v.id
+FROM recurring_expense_versions v
+WHERE v.recurring_expense_id = e.recurring_expense_id
+  AND v.version_number = 1;
+
+-- ====================
+-- MIGRATION COMPLETE
+-- ====================
+
+-- Summary of changes:
+-- ✅ Created recurring_expense_versions (one row per effective_from)
+-- ✅ Added recurring_expense_version_id to expenses
+-- ✅ Seeded version 1 for every existing template and linked already generated expenses
@@ -0,0 +1,104 @@
+-- Migration 020: Installment purchases ("compras en cuotas")
+-- Date: 2026-02-04
+-- Description: Card purchases paid in N cuotas become a first-class resource. Creating a purchase
+--              generates one expense per cuota (linked to the purchase) following the French
+--              amortization system when an interest/CFT rate is given. Early payoff replaces the
+--              pending cuotas with a single expense for the remaining principal.
+
+-- ====================
+-- 1. CREATE ENUM TYPE
+-- ====================
+
+CREATE TYPE installment_purchase_status AS ENUM ('active', 'paid_off');
+
+-- ====================
+-- 2. CREATE TABLE
+-- ====================
+
+CREATE TABLE installment_purchases (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    account_id UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+
+    description TEXT NOT NULL,
+    category_id UUID REFERENCES expense_categories(id) ON DELETE SET NULL,
+    family_member_id UUID REFERENCES family_members(id) ON DELETE SET NULL,
+
+    -- Monto financiado (precio de contado) y moneda
+    total_amount NUMERIC(15,2) NOT NULL CHECK (total_amount > 0),
+    currency currency NOT NULL,
+
+    -- Plan de cuotas
+    installments_count INT NOT NULL CHECK (installments_count BETWEEN 1 AND 120),
+    interest_rate NUMERIC(7,4) NOT NULL DEFAULT 0 CHECK (interest_rate >= 0), -- TNA / CFT anual en %
+    installment_amount NUMERIC(15,2) NOT NULL CHECK (installment_amount > 0),
+    total_with_interest NUMERIC(15,2) NOT NULL CHECK (total_with_interest > 0),
+    first_due_date DATE NOT NULL,
+
+    -- Multi-currency (snapshot aplicado a todas las cuotas)
+    exchange_rate NUMERIC(15,6) NOT NULL CHECK (exchange_rate > 0),
+
+    -- Estado
+    status installment_purchase_status NOT NULL DEFAULT 'active',
+    paid_off_at DATE,
+
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT check_installment_paid_off_at CHECK (
+        (status = 'paid_off' AND paid_off_at IS NOT NULL) OR
+        (status <> 'paid_off' AND paid_off_at IS NULL)
+    )
+);
+
+-- ====================
+-- 3. LINK EXPENSES (SCHEDULE) TO THE PURCHASE
+-- ====================
+
+ALTER TABLE expenses
+ADD COLUMN installment_purchase_id UUID REFERENCES installment_purchases(id) ON DELETE CASCADE,
+ADD COLUMN installment_number INT CHECK (installment_number IS NULL OR installment_number > 0);
+
+-- Una sola fila por número de cuota (el pago anticipado usa installment_number NULL)
+CREATE UNIQUE INDEX idx_expenses_installment_unique
+ON expenses(installment_purchase_id, installment_number)
+WHERE installment_purchase_id IS NOT NULL AND installment_number IS NOT NULL;
+
+-- ====================
+-- 4. COMMENTS (Documentation)
+-- ====================
+
+COMMENT ON TABLE installment_purchases IS 'Compras en cuotas. Cada cuota es un expense con installment_purchase_id';
+COMMENT ON COLUMN installment_purchases.total_amount IS 'Monto financiado (precio de contado)';
+COMMENT ON COLUMN installment_purchases.interest_rate IS 'Tasa anual (TNA/CFT) en porcentaje. 0 = cuotas sin interés';
+COMMENT ON COLUMN installment_purchases.installment_amount IS 'Valor de cada cuota (sistema francés). La última absorbe el redondeo';
+COMMENT ON COLUMN installment_purchases.total_with_interest IS 'Suma de todas las cuotas';
+COMMENT ON COLUMN installment_purchases.first_due_date IS 'Fecha de la primera cuota. Las siguientes vencen mensualmente el mismo día';
+COMMENT ON COLUMN installment_purchases.status IS 'active: plan vigente (completed se deriva cuando vencen todas las cuotas), paid_off: cancelada anticipadamente';
+COMMENT ON COLUMN expenses.installment_purchase_id IS 'Compra en cuotas que generó este gasto (NULL para gastos comunes)';
+COMMENT ON COLUMN expenses.installment_number IS 'Número de cuota (1..N). NULL en el gasto de pago anticipado';
+
+-- ====================
+-- 5. INDEXES
+-- ====================
+
+CREATE INDEX idx_installment_purchases_account_id ON installment_purchases(account_id);
+CREATE INDEX idx_installment_purchases_status ON installment_purchases(account_id, status);
+CREATE INDEX idx_expenses_installment_purchase_id ON expenses(installment_purchase_id);
+
+-- ====================
+-- 6. TRIGGERS (auto-update updated_at)
+-- ====================
+
+CREATE TRIGGER trigger_update_installment_purchases_updated_at
+BEFORE UPDATE ON installment_purchases
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+-- ====================
+-- MIGRATION COMPLETE
+-- ====================
+
+-- Summary of changes:
+-- ✅ Created installment_purchase_status ENUM
+-- ✅ Created installment_purchases table
+-- ✅ Added installment_purchase_id and installment_number to expenses
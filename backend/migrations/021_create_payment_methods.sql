@@ -0,0 +1,84 @@
+-- Migration 021: Payment methods (cards) with statement cycles
+-- Date: 2026-02-05
+-- Description: Adds payment_methods per account. Credit cards carry a closing day and a due day,
+--              and expenses paid with a card store the closing/due date of the statement they fall
+--              into, so the dashboard can report on a cash basis (due date) or accrual basis (date).
+
+-- ====================
+-- 1. CREATE ENUM TYPE
+-- ====================
+
+CREATE TYPE payment_method_type AS ENUM ('credit_card', 'debit_card', 'cash', 'bank_transfer', 'digital_wallet');
+
+-- ====================
+-- 2. CREATE TABLE
+-- ====================
+
+CREATE TABLE payment_methods (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    account_id UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    type payment_method_type NOT NULL,
+
+    -- Solo tarjetas de crédito: día de cierre y día de vencimiento del resumen
+    closing_day INT CHECK (closing_day BETWEEN 1 AND 31),
+    due_day INT CHECK (due_day BETWEEN 1 AND 31),
+
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT check_credit_card_cycle CHECK (
+        (type = 'credit_card' AND closing_day IS NOT NULL AND due_day IS NOT NULL) OR
+        (type <> 'credit_card' AND closing_day IS NULL AND due_day IS NULL)
+    )
+);
+
+-- Nombre único por cuenta (case-insensitive)
+CREATE UNIQUE INDEX idx_payment_methods_unique_name_per_account ON payment_methods(account_id, LOWER(name));
+
+-- ====================
+-- 3. LINK EXPENSES TO A PAYMENT METHOD
+-- ====================
+
+ALTER TABLE expenses
+ADD COLUMN payment_method_id UUID REFERENCES payment_methods(id) ON DELETE SET NULL,
+ADD COLUMN statement_closing_date DATE,
+ADD COLUMN statement_due_date DATE;
+
+-- ====================
+-- 4. COMMENTS (Documentation)
+-- ====================
+
+COMMENT ON TABLE payment_methods IS 'Medios de pago de la cuenta (tarjetas, efectivo, billeteras, etc.)';
+COMMENT ON COLUMN payment_methods.closing_day IS 'Día de cierre del resumen (solo credit_card). Meses cortos → último día del mes';
+COMMENT ON COLUMN payment_methods.due_day IS 'Día de vencimiento del resumen (solo credit_card). Si es <= closing_day vence el mes siguiente al cierre';
+COMMENT ON COLUMN expenses.payment_method_id IS 'Medio de pago usado (opcional)';
+COMMENT ON COLUMN expenses.statement_closing_date IS 'Cierre del resumen de tarjeta en el que cae el gasto (NULL si no es tarjeta de crédito)';
+COMMENT ON COLUMN expenses.statement_due_date IS 'Vencimiento del resumen: fecha en la que el gasto impacta en el flujo de caja';
+
+-- ====================
+-- 5. INDEXES
+-- ====================
+
+CREATE INDEX idx_payment_methods_account_id ON payment_methods(account_id);
+CREATE INDEX idx_expenses_payment_method_id ON expenses(payment_method_id);
+CREATE INDEX idx_expenses_statement_closing_date ON expenses(payment_method_id, statement_closing_date);
+CREATE INDEX idx_expenses_statement_due_date ON expenses(account_id, statement_due_date);
+
+-- ====================
+-- 6. TRIGGERS (auto-update updated_at)
+-- ====================
+
+CREATE TRIGGER trigger_update_payment_methods_updated_at
+BEFORE UPDATE ON payment_methods
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+-- ====================
+-- MIGRATION COMPLETE
+-- ====================
+
+-- Summary of changes:
+-- ✅ Created payment_method_type ENUM and payment_methods table
+-- ✅ Added payment_method_id, statement_closing_date and statement_due_date to expenses
@@ -0,0 +1,60 @@
+-- Migration 022: Payment methods as wallets (currency + opening balance) on every transaction
+-- Date: 2026-02-06
+-- Description: Extends payment_methods with a currency and an optional opening balance so cash,
+--              Mercado Pago, bank accounts, etc. can be tracked as wallets. Incomes and the
+--              recurring templates get an optional payment_method_id (expenses already have it).
+
+-- ====================
+-- 1. WALLET FIELDS
+-- ====================
+
+ALTER TABLE payment_methods
+ADD COLUMN currency currency,
+ADD COLUMN opening_balance DECIMAL(15, 2) NOT NULL DEFAULT 0;
+
+-- Los medios de pago existentes toman la moneda de la cuenta
+UPDATE payment_methods pm
+SET currency = a.currency
+FROM accounts a
+WHERE pm.account_id = a.id;
+
+ALTER TABLE payment_methods ALTER COLUMN currency SET NOT NULL;
+
+-- ====================
+-- 2. LINK INCOMES AND RECURRING TEMPLATES
+-- ====================
+
+ALTER TABLE incomes
+ADD COLUMN payment_method_id UUID REFERENCES payment_methods(id) ON DELETE SET NULL;
+
+ALTER TABLE recurring_expenses
+ADD COLUMN payment_method_id UUID REFERENCES payment_methods(id) ON DELETE SET NULL;
+
+ALTER TABLE recurring_incomes
+ADD COLUMN payment_method_id UUID REFERENCES payment_methods(id) ON DELETE SET NULL;
+
+-- ====================
+-- 3. COMMENTS (Documentation)
+-- ====================
+
+COMMENT ON COLUMN payment_methods.currency IS 'Moneda del medio de pago (el saldo se calcula en esta moneda)';
+COMMENT ON COLUMN payment_methods.opening_balance IS 'Saldo inicial al empezar a registrar movimientos (negativo = deuda)';
+COMMENT ON COLUMN incomes.payment_method_id IS 'Medio de pago / billetera donde ingresó el dinero (opcional)';
+COMMENT ON COLUMN recurring_expenses.payment_method_id IS 'Medio de pago que se copia a cada gasto generado (opcional)';
+COMMENT ON COLUMN recurring_incomes.payment_method_id IS 'Medio de pago que se copia a cada ingreso generado (opcional)';
+
+-- ====================
+-- 4. INDEXES
+-- ====================
+
+CREATE INDEX idx_incomes_payment_method_id ON incomes(payment_method_id);
+CREATE INDEX idx_recurring_expenses_payment_method_id ON recurring_expenses(payment_method_id);
+CREATE INDEX idx_recurring_incomes_payment_method_id ON recurring_incomes(payment_method_id);
+
+-- ====================
+-- MIGRATION COMPLETE
+-- ====================
+
+-- Summary of changes:
+-- ✅ Added currency and opening_balance to payment_methods
+-- ✅ Added payment_method_id to incomes, recurring_expenses and recurring_incomes
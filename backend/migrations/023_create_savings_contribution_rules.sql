@@ -0,0 +1,85 @@
+-- Migration 023: Automatic savings goal contributions
+-- Date: 2026-02-07
+-- Description: Contribution rules per savings goal (fixed amount or a percentage of the incomes
+--              received in the period) that the daily cron executes, writing the same
+--              savings_goal_transactions as POST /savings-goals/:id/add-funds.
+
+-- ====================
+-- 1. CREATE ENUM TYPES
+-- ====================
+
+CREATE TYPE contribution_rule_type AS ENUM ('fixed_amount', 'income_percentage');
+CREATE TYPE contribution_frequency AS ENUM ('daily', 'weekly', 'monthly');
+
+-- ====================
+-- 2. CREATE TABLE
+-- ====================
+
+CREATE TABLE savings_goal_contribution_rules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    savings_goal_id UUID NOT NULL REFERENCES savings_goals(id) ON DELETE CASCADE,
+    rule_type contribution_rule_type NOT NULL,
+
+    -- fixed_amount: monto en la moneda de la meta / income_percentage: % de los ingresos del período
+    amount DECIMAL(15, 2) CHECK (amount > 0),
+    percentage DECIMAL(5, 2) CHECK (percentage > 0 AND percentage <= 100),
+
+    frequency contribution_frequency NOT NULL,
+    start_date DATE NOT NULL,
+    next_run_date DATE NOT NULL,
+    last_run_date DATE,
+    last_run_status VARCHAR(30), -- applied, skipped_insufficient_balance, skipped_no_income, skipped_deadline_passed
+
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT check_contribution_rule_value CHECK (
+        (rule_type = 'fixed_amount' AND amount IS NOT NULL AND percentage IS NULL) OR
+        (rule_type = 'income_percentage' AND percentage IS NOT NULL AND amount IS NULL)
+    )
+);
+
+-- ====================
+-- 3. LINK GOAL TRANSACTIONS TO THE RULE THAT CREATED THEM
+-- ====================
+
+ALTER TABLE savings_goal_transactions
+ADD COLUMN contribution_rule_id UUID REFERENCES savings_goal_contribution_rules(id) ON DELETE SET NULL;
+
+-- ====================
+-- 4. COMMENTS (Documentation)
+-- ====================
+
+COMMENT ON TABLE savings_goal_contribution_rules IS 'Aportes automáticos a metas de ahorro, ejecutados por el CRON diario';
+COMMENT ON COLUMN savings_goal_contribution_rules.amount IS 'Monto fijo por ejecución, en la moneda de la meta (solo fixed_amount)';
+COMMENT ON COLUMN savings_goal_contribution_rules.percentage IS 'Porcentaje de los ingresos recibidos desde la ejecución anterior (solo income_percentage)';
+COMMENT ON COLUMN savings_goal_contribution_rules.next_run_date IS 'Próxima fecha en la que el CRON ejecuta la regla';
+COMMENT ON COLUMN savings_goal_contribution_rules.last_run_status IS 'Resultado de la última ejecución (applied o motivo por el que se salteó)';
+COMMENT ON COLUMN savings_goal_transactions.contribution_rule_id IS 'Regla que generó el depósito (NULL = manual)';
+
+-- ====================
+-- 5. INDEXES
+-- ====================
+
+CREATE INDEX idx_contribution_rules_goal_id ON savings_goal_contribution_rules(savings_goal_id);
+CREATE INDEX idx_contribution_rules_next_run ON savings_goal_contribution_rules(next_run_date) WHERE is_active = true;
+CREATE INDEX idx_savings_goal_transactions_rule_id ON savings_goal_transactions(contribution_rule_id);
+
+-- ====================
+-- 6. TRIGGERS (auto-update updated_at)
+-- ====================
+
+CREATE TRIGGER trigger_update_contribution_rules_updated_at
+BEFORE UPDATE ON savings_goal_contribution_rules
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+-- ====================
+-- MIGRATION COMPLETE
+-- ====================
+
+-- Summary of changes:
+-- ✅ Created contribution_rule_type and contribution_frequency ENUMs
+-- ✅ Created savings_goal_contribution_rules table
+-- ✅ Added contribution_rule_id to savings_goal_transactions
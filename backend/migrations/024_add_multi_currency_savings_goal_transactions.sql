@@ -0,0 +1,58 @@
+-- Migration 024: Multi-currency savings goal transactions
+-- Date: 2026-02-08
+-- Description: Goals can have their own currency (e.g. USD goal in an ARS account). Each deposit or
+--              withdrawal keeps the currency and amount actually moved, the exchange rate to the goal
+--              currency and the amount in the account's primary currency (same "Modo 3" as expenses).
+--              savings_goal_transactions.amount keeps being the amount in the goal currency.
+
+-- ====================
+-- 1. ADD COLUMNS
+-- ====================
+
+ALTER TABLE savings_goal_transactions
+ADD COLUMN source_currency currency,
+ADD COLUMN source_amount DECIMAL(15, 2),
+ADD COLUMN exchange_rate DECIMAL(15, 6),
+ADD COLUMN amount_in_primary_currency DECIMAL(15, 2);
+
+-- ====================
+-- 2. BACKFILL EXISTING TRANSACTIONS
+-- ====================
+
+-- Hasta ahora todo movimiento era en la moneda de la meta
+UPDATE savings_goal_transactions t
+SET source_currency = sg.currency,
+    source_amount = t.amount,
+    exchange_rate = 1,
+    amount_in_primary_currency = CASE WHEN sg.currency = a.currency THEN t.amount END
+FROM savings_goals sg
+JOIN accounts a ON a.id = sg.account_id
+WHERE sg.id = t.savings_goal_id;
+
+ALTER TABLE savings_goal_transactions
+ALTER COLUMN source_currency SET NOT NULL,
+ALTER COLUMN source_amount SET NOT NULL,
+ALTER COLUMN exchange_rate SET NOT NULL;
+
+ALTER TABLE savings_goal_transactions
+ADD CONSTRAINT check_source_amount_positive CHECK (source_amount > 0),
+ADD CONSTRAINT check_exchange_rate_positive CHECK (exchange_rate > 0);
+
+-- ====================
+-- 3. COMMENTS (Documentation)
+-- ====================
+
+COMMENT ON COLUMN savings_goal_transactions.amount IS 'Monto en la moneda de la meta (el que suma/resta a current_amount)';
+COMMENT ON COLUMN savings_goal_transactions.source_currency IS 'Moneda en la que se movió el dinero (ej: pesos para comprar dólares)';
+COMMENT ON COLUMN savings_goal_transactions.source_amount IS 'Monto en source_currency';
+COMMENT ON COLUMN savings_goal_transactions.exchange_rate IS 'Tasa source_currency → moneda de la meta (1 si son iguales)';
+COMMENT ON COLUMN savings_goal_transactions.amount_in_primary_currency IS 'Monto en la moneda principal de la cuenta (NULL si no había tasa disponible)';
+COMMENT ON COLUMN savings_goal_contribution_rules.last_run_status IS 'Resultado de la última ejecución: applied, skipped_insufficient_balance, skipped_no_income, skipped_deadline_passed, skipped_no_rate';
+
+-- ====================
+-- MIGRATION COMPLETE
+-- ====================
+
+-- Summary of changes:
+-- ✅ Added source_currency, source_amount, exchange_rate and amount_in_primary_currency to savings_goal_transactions
+-- ✅ Backfilled existing transactions (same currency as the goal)
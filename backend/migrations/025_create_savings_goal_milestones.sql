@@ -0,0 +1,94 @@
+-- Migration 025: Savings goal milestones, events and completion
+-- Date: 2026-02-09
+-- Description: Goals get milestones (percentage of the target or a fixed amount) and an event log
+--              written when a deposit crosses a milestone or completes the goal. Goals record
+--              achieved_at when current_amount >= target_amount (status achieved).
+
+-- ====================
+-- 1. ACHIEVED_AT ON GOALS
+-- ====================
+
+ALTER TABLE savings_goals
+ADD COLUMN achieved_at TIMESTAMP;
+
+-- Metas que ya alcanzaron el objetivo
+UPDATE savings_goals
+SET achieved_at = updated_at
+WHERE current_amount >= target_amount;
+
+-- ====================
+-- 2. CREATE MILESTONES TABLE
+-- ====================
+
+CREATE TABLE savings_goal_milestones (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    savings_goal_id UUID NOT NULL REFERENCES savings_goals(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+
+    -- Uno de los dos: % del target_amount (sigue al target si cambia) o monto fijo en la moneda de la meta
+    percentage DECIMAL(5, 2) CHECK (percentage > 0 AND percentage <= 100),
+    amount DECIMAL(15, 2) CHECK (amount > 0),
+
+    reached_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT check_milestone_value CHECK (
+        (percentage IS NOT NULL AND amount IS NULL) OR
+        (percentage IS NULL AND amount IS NOT NULL)
+    )
+);
+
+-- ====================
+-- 3. CREATE EVENTS TABLE
+-- ====================
+
+CREATE TYPE savings_goal_event_type AS ENUM ('milestone_reached', 'goal_achieved');
+
+CREATE TABLE savings_goal_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    savings_goal_id UUID NOT NULL REFERENCES savings_goals(id) ON DELETE CASCADE,
+    event_type savings_goal_event_type NOT NULL,
+    milestone_id UUID REFERENCES savings_goal_milestones(id) ON DELETE SET NULL,
+    milestone_name VARCHAR(100), -- Copia del nombre, por si el hito se elimina
+    transaction_id UUID REFERENCES savings_goal_transactions(id) ON DELETE SET NULL,
+    current_amount DECIMAL(15, 2) NOT NULL,
+    occurred_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- ====================
+-- 4. DEFAULT MILESTONES FOR EXISTING GOALS
+-- ====================
+
+-- 25/50/75/100%, marcando como alcanzados (sin evento) los que ya se superaron
+INSERT INTO savings_goal_milestones (savings_goal_id, name, percentage, reached_at)
+SELECT sg.id, p.pct || '%', p.pct,
+       CASE WHEN sg.current_amount >= sg.target_amount * p.pct / 100 THEN sg.updated_at END
+FROM savings_goals sg
+CROSS JOIN (VALUES (25), (50), (75), (100)) AS p(pct);
+
+-- ====================
+-- 5. COMMENTS (Documentation)
+-- ====================
+
+COMMENT ON COLUMN savings_goals.achieved_at IS 'Cuándo current_amount alcanzó target_amount (NULL si todavía no o si se retiró por debajo)';
+COMMENT ON TABLE savings_goal_milestones IS 'Hitos de una meta de ahorro (25/50/75/100% o montos custom)';
+COMMENT ON COLUMN savings_goal_milestones.reached_at IS 'Cuándo se cruzó el hito por primera vez (no se resetea con retiros)';
+COMMENT ON TABLE savings_goal_events IS 'Historial de hitos alcanzados y metas completadas';
+
+-- ====================
+-- 6. INDEXES
+-- ====================
+
+CREATE INDEX idx_savings_goal_milestones_goal_id ON savings_goal_milestones(savings_goal_id);
+CREATE INDEX idx_savings_goal_events_goal_id ON savings_goal_events(savings_goal_id, occurred_at DESC);
+CREATE INDEX idx_savings_goals_achieved_at ON savings_goals(achieved_at) WHERE achieved_at IS NOT NULL;
+
+-- ====================
+-- MIGRATION COMPLETE
+-- ====================
+
+-- Summary of changes:
+-- ✅ Added achieved_at to savings_goals
+-- ✅ Created savings_goal_milestones and savings_goal_events tables
+-- ✅ Created savings_goal_event_type ENUM
+-- ✅ Added default 25/50/75/100% milestones to existing goals
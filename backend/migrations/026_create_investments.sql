@@ -0,0 +1,132 @@
+-- Migration 026: Investment holdings (plazo fijo, FCI, crypto, stocks)
+-- Date: 2026-02-10
+-- Description: Adds investment holdings per account with quantity and cost basis, buy/sell
+--              transactions (weighted average cost) and price snapshots entered manually or fetched
+--              from a price provider. Fixed-term deposits store rate, start and maturity instead of
+--              trades. A holding can optionally back a savings goal.
+
+-- ====================
+-- 1. CREATE ENUM TYPES
+-- ====================
+
+CREATE TYPE investment_instrument_type AS ENUM ('fixed_term', 'mutual_fund', 'crypto', 'stock', 'bond', 'other');
+CREATE TYPE investment_transaction_type AS ENUM ('buy', 'sell');
+
+-- ====================
+-- 2. CREATE HOLDINGS TABLE
+-- ====================
+
+CREATE TABLE investment_holdings (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    account_id UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    instrument_type investment_instrument_type NOT NULL,
+    symbol VARCHAR(20),
+    currency currency NOT NULL,
+
+    -- Posición actual (mantenida por las compras/ventas)
+    quantity DECIMAL(20, 8) NOT NULL DEFAULT 0 CHECK (quantity >= 0),
+    cost_basis DECIMAL(15, 2) NOT NULL DEFAULT 0 CHECK (cost_basis >= 0),
+    realized_gain DECIMAL(15, 2) NOT NULL DEFAULT 0,
+
+    -- Solo plazo fijo: TNA (%), fecha de constitución y vencimiento. quantity = cost_basis = capital
+    annual_rate DECIMAL(7, 4) CHECK (annual_rate >= 0),
+    start_date DATE,
+    maturity_date DATE,
+
+    savings_goal_id UUID REFERENCES savings_goals(id) ON DELETE SET NULL,
+    notes TEXT,
+
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT check_fixed_term_fields CHECK (
+        (instrument_type = 'fixed_term' AND annual_rate IS NOT NULL AND start_date IS NOT NULL
+            AND maturity_date IS NOT NULL AND maturity_date > start_date) OR
+        (instrument_type <> 'fixed_term' AND annual_rate IS NULL AND start_date IS NULL AND maturity_date IS NULL)
+    )
+);
+
+-- Nombre único por cuenta (case-insensitive)
+CREATE UNIQUE INDEX idx_investment_holdings_unique_name_per_account ON investment_holdings(account_id, LOWER(name));
+
+-- ====================
+-- 3. CREATE TRANSACTIONS TABLE
+-- ====================
+
+CREATE TABLE investment_transactions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    holding_id UUID NOT NULL REFERENCES investment_holdings(id) ON DELETE CASCADE,
+    transaction_type investment_transaction_type NOT NULL,
+    quantity DECIMAL(20, 8) NOT NULL CHECK (quantity > 0),
+    price DECIMAL(20, 8) NOT NULL CHECK (price >= 0),
+    fees DECIMAL(15, 2) NOT NULL DEFAULT 0 CHECK (fees >= 0),
+
+    -- Compra: quantity × price + fees (lo que salió). Venta: quantity × price - fees (lo que entró)
+    amount DECIMAL(15, 2) NOT NULL,
+    -- Solo ventas: amount - costo promedio de las unidades vendidas
+    realized_gain DECIMAL(15, 2),
+
+    date DATE NOT NULL DEFAULT CURRENT_DATE,
+    description TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- ====================
+-- 4. CREATE PRICES TABLE
+-- ====================
+
+CREATE TABLE investment_prices (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    holding_id UUID NOT NULL REFERENCES investment_holdings(id) ON DELETE CASCADE,
+    price DECIMAL(20, 8) NOT NULL CHECK (price > 0),
+    price_date DATE NOT NULL,
+    source VARCHAR(50) NOT NULL DEFAULT 'manual',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    -- Un precio por día: el último cargado pisa al anterior
+    CONSTRAINT unique_investment_price_per_day UNIQUE (holding_id, price_date)
+);
+
+-- ====================
+-- 5. COMMENTS (Documentation)
+-- ====================
+
+COMMENT ON TABLE investment_holdings IS 'Inversiones de la cuenta: plazos fijos, FCI, cripto, acciones, bonos';
+COMMENT ON COLUMN investment_holdings.symbol IS 'Ticker para el proveedor de precios (ej: BTC, GGAL, AL30). NULL = solo precios manuales';
+COMMENT ON COLUMN investment_holdings.currency IS 'Moneda en la que cotiza (precios, costo y valuación)';
+COMMENT ON COLUMN investment_holdings.cost_basis IS 'Costo total de las unidades actuales (costo promedio ponderado)';
+COMMENT ON COLUMN investment_holdings.realized_gain IS 'Ganancia (o pérdida) realizada acumulada por ventas';
+COMMENT ON COLUMN investment_holdings.annual_rate IS 'Solo plazo fijo: TNA en %. Interés simple, base 365 días';
+COMMENT ON COLUMN investment_holdings.savings_goal_id IS 'Meta de ahorro que respalda esta inversión (opcional)';
+COMMENT ON COLUMN investment_transactions.amount IS 'Compra: costo total con comisiones. Venta: neto cobrado';
+COMMENT ON COLUMN investment_prices.source IS 'manual, trade (precio de una compra/venta) o el nombre del proveedor';
+
+-- ====================
+-- 6. INDEXES
+-- ====================
+
+CREATE INDEX idx_investment_holdings_account_id ON investment_holdings(account_id);
+CREATE INDEX idx_investment_holdings_savings_goal_id ON investment_holdings(savings_goal_id);
+CREATE INDEX idx_investment_transactions_holding_date ON investment_transactions(holding_id, date DESC);
+CREATE INDEX idx_investment_prices_holding_date ON investment_prices(holding_id, price_date DESC);
+
+-- ====================
+-- 7. TRIGGERS (auto-update updated_at)
+-- ====================
+
+CREATE TRIGGER trigger_update_investment_holdings_updated_at
+BEFORE UPDATE ON investment_holdings
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+-- ====================
+-- MIGRATION COMPLETE
+-- ====================
+
+-- Summary of changes:
+-- ✅ Created investment_instrument_type and investment_transaction_type ENUMs
+-- ✅ Created investment_holdings (position, fixed-term fields, optional savings goal)
+-- ✅ Created investment_transactions (buy/sell with realized gain)
+-- ✅ Created investment_prices (one snapshot per holding and day)
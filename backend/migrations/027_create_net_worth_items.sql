@@ -0,0 +1,81 @@
+-- Migration 027: Net worth assets and liabilities with dated valuations
+-- Date: 2026-02-11
+-- Description: Adds manual net worth items (property, vehicles, loans, card debt...) per account
+--              and their valuations over time. The net worth statement combines the value of each
+--              item at a date with cash balances, savings goal balances and investments.
+
+-- ====================
+-- 1. CREATE ENUM TYPE
+-- ====================
+
+CREATE TYPE net_worth_item_kind AS ENUM ('asset', 'liability');
+
+-- ====================
+-- 2. CREATE ITEMS TABLE
+-- ====================
+
+CREATE TABLE net_worth_items (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    account_id UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    kind net_worth_item_kind NOT NULL,
+    category VARCHAR(50),
+    currency currency NOT NULL,
+    notes TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Nombre único por cuenta (case-insensitive)
+CREATE UNIQUE INDEX idx_net_worth_items_unique_name_per_account ON net_worth_items(account_id, LOWER(name));
+
+-- ====================
+-- 3. CREATE VALUATIONS TABLE
+-- ====================
+
+CREATE TABLE net_worth_valuations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    item_id UUID NOT NULL REFERENCES net_worth_items(id) ON DELETE CASCADE,
+    value DECIMAL(15, 2) NOT NULL CHECK (value >= 0),
+    valuation_date DATE NOT NULL,
+    notes TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    -- Una valuación por día: la última cargada pisa a la anterior
+    CONSTRAINT unique_net_worth_valuation_per_day UNIQUE (item_id, valuation_date)
+);
+
+-- ====================
+-- 4. COMMENTS (Documentation)
+-- ====================
+
+COMMENT ON TABLE net_worth_items IS 'Activos y pasivos cargados a mano (inmuebles, autos, préstamos, deuda de tarjeta)';
+COMMENT ON COLUMN net_worth_items.kind IS 'asset suma al patrimonio, liability resta';
+COMMENT ON COLUMN net_worth_items.category IS 'Categoría libre para agrupar (ej: property, vehicle, loan, credit_card)';
+COMMENT ON COLUMN net_worth_items.currency IS 'Moneda de las valuaciones';
+COMMENT ON TABLE net_worth_valuations IS 'Valor del item a una fecha. Rige hasta la siguiente valuación; vendido/cancelado = valuación en 0';
+
+-- ====================
+-- 5. INDEXES
+-- ====================
+
+CREATE INDEX idx_net_worth_items_account_id ON net_worth_items(account_id);
+CREATE INDEX idx_net_worth_valuations_item_date ON net_worth_valuations(item_id, valuation_date DESC);
+
+-- ====================
+-- 6. TRIGGERS (auto-update updated_at)
+-- ====================
+
+CREATE TRIGGER trigger_update_net_worth_items_updated_at
+BEFORE UPDATE ON net_worth_items
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+-- ====================
+-- MIGRATION COMPLETE
+-- ====================
+
+-- Summary of changes:
+-- ✅ Created net_worth_item_kind ENUM
+-- ✅ Created net_worth_items (manual assets and liabilities per account)
+-- ✅ Created net_worth_valuations (one value per item and day)
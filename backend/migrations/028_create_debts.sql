@@ -0,0 +1,104 @@
+-- Migration 028: Loans and debts ledger
+-- Date: 2026-02-12
+-- Description: Money borrowed from or lent to a counterparty (family, friends, a bank) with a
+--              French, German or interest-free amortization schedule. Payments are the expenses
+--              (borrowed) or incomes (lent) linked to the debt through debt_id; the disbursement,
+--              when recorded, is the movement in the opposite table.
+
+-- ====================
+-- 1. CREATE ENUM TYPES
+-- ====================
+
+CREATE TYPE debt_direction AS ENUM ('borrowed', 'lent');
+CREATE TYPE debt_amortization_type AS ENUM ('french', 'german', 'interest_free');
+
+-- ====================
+-- 2. CREATE TABLE
+-- ====================
+
+CREATE TABLE debts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    account_id UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+
+    counterparty VARCHAR(100) NOT NULL,
+    description TEXT,
+    direction debt_direction NOT NULL,
+
+    -- Capital y moneda
+    principal NUMERIC(15,2) NOT NULL CHECK (principal > 0),
+    currency currency NOT NULL,
+
+    -- Plan de pagos (mensual)
+    amortization_type debt_amortization_type NOT NULL,
+    annual_rate NUMERIC(7,4) NOT NULL DEFAULT 0 CHECK (annual_rate >= 0), -- TNA en %
+    installments_count INT NOT NULL CHECK (installments_count BETWEEN 1 AND 360),
+    start_date DATE NOT NULL,
+    first_due_date DATE NOT NULL,
+
+    -- Categorías de los pagos generados (según direction se usa una u otra)
+    expense_category_id UUID REFERENCES expense_categories(id) ON DELETE SET NULL,
+    income_category_id UUID REFERENCES income_categories(id) ON DELETE SET NULL,
+
+    -- Deuda perdonada / incobrable: deja de contar como saldo pendiente
+    written_off_at DATE,
+
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT check_debt_interest_free_rate CHECK (
+        amortization_type <> 'interest_free' OR annual_rate = 0
+    ),
+    CONSTRAINT check_debt_first_due_date CHECK (first_due_date >= start_date)
+);
+
+-- ====================
+-- 3. LINK EXPENSES AND INCOMES (PAYMENTS / DISBURSEMENT) TO THE DEBT
+-- ====================
+
+-- SET NULL: borrar la deuda no borra la plata que efectivamente se movió
+ALTER TABLE expenses
+ADD COLUMN debt_id UUID REFERENCES debts(id) ON DELETE SET NULL;
+
+ALTER TABLE incomes
+ADD COLUMN debt_id UUID REFERENCES debts(id) ON DELETE SET NULL;
+
+-- ====================
+-- 4. COMMENTS (Documentation)
+-- ====================
+
+COMMENT ON TABLE debts IS 'Préstamos tomados (borrowed) u otorgados (lent) con su plan de pagos';
+COMMENT ON COLUMN debts.counterparty IS 'Con quién: familiar, amigo, banco';
+COMMENT ON COLUMN debts.direction IS 'borrowed: yo debo (los pagos son expenses), lent: me deben (los cobros son incomes)';
+COMMENT ON COLUMN debts.amortization_type IS 'french: cuota fija, german: amortización de capital fija, interest_free: cuotas iguales sin interés';
+COMMENT ON COLUMN debts.annual_rate IS 'Tasa nominal anual en porcentaje. 0 en interest_free';
+COMMENT ON COLUMN debts.start_date IS 'Fecha del desembolso';
+COMMENT ON COLUMN debts.first_due_date IS 'Vencimiento de la primera cuota. Las siguientes vencen mensualmente el mismo día';
+COMMENT ON COLUMN debts.written_off_at IS 'Fecha en que se perdonó o se dio por incobrable (NULL = vigente)';
+COMMENT ON COLUMN expenses.debt_id IS 'Deuda vinculada: pago de un préstamo tomado o desembolso de uno otorgado';
+COMMENT ON COLUMN incomes.debt_id IS 'Deuda vinculada: cobro de un préstamo otorgado o desembolso de uno tomado';
+
+-- ====================
+-- 5. INDEXES
+-- ====================
+
+CREATE INDEX idx_debts_account_id ON debts(account_id);
+CREATE INDEX idx_expenses_debt_id ON expenses(debt_id);
+CREATE INDEX idx_incomes_debt_id ON incomes(debt_id);
+
+-- ====================
+-- 6. TRIGGERS (auto-update updated_at)
+-- ====================
+
+CREATE TRIGGER trigger_update_debts_updated_at
+BEFORE UPDATE ON debts
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+-- ====================
+-- MIGRATION COMPLETE
+-- ====================
+
+-- Summary of changes:
+-- ✅ Created debt_direction and debt_amortization_type ENUMs
+-- ✅ Created debts table
+-- ✅ Added debt_id to expenses and incomes
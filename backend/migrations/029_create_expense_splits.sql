@@ -0,0 +1,80 @@
+-- Migration 029: Split expenses among family members
+-- Date: 2026-02-13
+-- Description: An expense of a family account can be split among several members (equal shares,
+--              percentages or exact amounts) and records which member paid it. Settlements record
+--              reimbursements between members; balances (who owes whom) are derived from both.
+
+-- ====================
+-- 1. CREATE ENUM TYPE
+-- ====================
+
+CREATE TYPE expense_split_type AS ENUM ('equal', 'percentage', 'exact');
+
+-- ====================
+-- 2. SPLIT COLUMNS ON EXPENSES
+-- ====================
+
+ALTER TABLE expenses
+ADD COLUMN split_type expense_split_type,
+ADD COLUMN paid_by_member_id UUID REFERENCES family_members(id) ON DELETE SET NULL;
+
+-- ====================
+-- 3. CREATE TABLES
+-- ====================
+
+CREATE TABLE expense_splits (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    expense_id UUID NOT NULL REFERENCES expenses(id) ON DELETE CASCADE,
+    family_member_id UUID NOT NULL REFERENCES family_members(id) ON DELETE CASCADE,
+
+    -- Solo uno según split_type: percentage en 'percentage', amount en 'exact', ninguno en 'equal'
+    percentage NUMERIC(7,4) CHECK (percentage IS NULL OR (percentage > 0 AND percentage <= 100)),
+    amount NUMERIC(15,2) CHECK (amount IS NULL OR amount > 0),
+
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT unique_expense_split_member UNIQUE (expense_id, family_member_id)
+);
+
+CREATE TABLE split_settlements (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    account_id UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    from_member_id UUID NOT NULL REFERENCES family_members(id) ON DELETE CASCADE,
+    to_member_id UUID NOT NULL REFERENCES family_members(id) ON DELETE CASCADE,
+    amount NUMERIC(15,2) NOT NULL CHECK (amount > 0), -- En la moneda de la cuenta
+    date DATE NOT NULL,
+    notes TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT check_split_settlement_members CHECK (from_member_id <> to_member_id)
+);
+
+-- ====================
+-- 4. COMMENTS (Documentation)
+-- ====================
+
+COMMENT ON COLUMN expenses.split_type IS 'Cómo se divide el gasto entre miembros (NULL = no dividido)';
+COMMENT ON COLUMN expenses.paid_by_member_id IS 'Miembro que pagó un gasto dividido';
+COMMENT ON TABLE expense_splits IS 'Parte de cada miembro en un gasto dividido. Los montos se calculan sobre el monto actual del gasto';
+COMMENT ON COLUMN expense_splits.percentage IS 'Porcentaje del gasto (split_type = percentage)';
+COMMENT ON COLUMN expense_splits.amount IS 'Monto exacto en la moneda del gasto (split_type = exact)';
+COMMENT ON TABLE split_settlements IS 'Reintegros entre miembros: from_member_id le pagó a to_member_id';
+
+-- ====================
+-- 5. INDEXES
+-- ====================
+
+CREATE INDEX idx_expenses_split_type ON expenses(account_id) WHERE split_type IS NOT NULL;
+CREATE INDEX idx_expense_splits_expense_id ON expense_splits(expense_id);
+CREATE INDEX idx_expense_splits_family_member_id ON expense_splits(family_member_id);
+CREATE INDEX idx_split_settlements_account_id ON split_settlements(account_id, date DESC);
+
+-- ====================
+-- MIGRATION COMPLETE
+-- ====================
+
+-- Summary of changes:
+-- ✅ Created expense_split_type ENUM
+-- ✅ Added split_type and paid_by_member_id to expenses
+-- ✅ Created expense_splits (share of each member)
+-- ✅ Created split_settlements (reimbursements between members)
@@ -0,0 +1,33 @@
+-- Migration 030: Monthly allowances per family member
+-- Date: 2026-02-14
+-- Description: Optional monthly allowance (in the account currency) per family member, compared
+--              against the expenses assigned to the member in the per-member report.
+
+-- ====================
+-- 1. ADD COLUMN
+-- ====================
+
+ALTER TABLE family_members
+ADD COLUMN monthly_allowance NUMERIC(15,2) CHECK (monthly_allowance IS NULL OR monthly_allowance > 0);
+
+-- ====================
+-- 2. COMMENTS (Documentation)
+-- ====================
+
+COMMENT ON COLUMN family_members.monthly_allowance IS 'Asignación mensual del miembro en la moneda de la cuenta (NULL = sin asignación)';
+
+-- ====================
+-- 3. INDEXES
+-- ====================
+
+-- Reporte por miembro: gastos e ingresos de la cuenta agrupados por miembro y mes
+CREATE INDEX idx_expenses_account_member_date ON expenses(account_id, family_member_id, date);
+CREATE INDEX idx_incomes_account_member_date ON incomes(account_id, family_member_id, date);
+
+-- ====================
+-- MIGRATION COMPLETE
+-- ====================
+
+-- Summary of changes:
+-- ✅ Added monthly_allowance to family_members
+-- ✅ Added indexes for the per-member report
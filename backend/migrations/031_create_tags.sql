@@ -0,0 +1,75 @@
+-- Migration 031: Free-form tags on transactions
+-- Date: 2026-02-15
+-- Description: Tags per account (e.g. "vacaciones-2026", "deducible", "reembolsable") linked
+--              many-to-many to expenses, incomes and recurring templates. Generated movements
+--              inherit the tags of their template.
+
+-- ====================
+-- 1. CREATE TABLES
+-- ====================
+
+CREATE TABLE tags (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    account_id UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    name VARCHAR(40) NOT NULL, -- Normalizado: minúsculas, sin espacios
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT unique_tag_name_per_account UNIQUE (account_id, name)
+);
+
+CREATE TABLE expense_tags (
+    expense_id UUID NOT NULL REFERENCES expenses(id) ON DELETE CASCADE,
+    tag_id UUID NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    PRIMARY KEY (expense_id, tag_id)
+);
+
+CREATE TABLE income_tags (
+    income_id UUID NOT NULL REFERENCES incomes(id) ON DELETE CASCADE,
+    tag_id UUID NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    PRIMARY KEY (income_id, tag_id)
+);
+
+CREATE TABLE recurring_expense_tags (
+    recurring_expense_id UUID NOT NULL REFERENCES recurring_expenses(id) ON DELETE CASCADE,
+    tag_id UUID NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    PRIMARY KEY (recurring_expense_id, tag_id)
+);
+
+CREATE TABLE recurring_income_tags (
+    recurring_income_id UUID NOT NULL REFERENCES recurring_incomes(id) ON DELETE CASCADE,
+    tag_id UUID NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    PRIMARY KEY (recurring_income_id, tag_id)
+);
+
+-- ====================
+-- 2. COMMENTS (Documentation)
+-- ====================
+
+COMMENT ON TABLE tags IS 'Etiquetas libres por cuenta, complementan a la categoría (un movimiento puede tener varias)';
+COMMENT ON COLUMN tags.name IS 'Nombre normalizado: minúsculas y guiones en lugar de espacios';
+COMMENT ON TABLE expense_tags IS 'Tags de cada gasto';
+COMMENT ON TABLE income_tags IS 'Tags de cada ingreso';
+COMMENT ON TABLE recurring_expense_tags IS 'Tags de cada template de gasto recurrente (se copian a los gastos generados)';
+COMMENT ON TABLE recurring_income_tags IS 'Tags de cada template de ingreso recurrente (se copian a los ingresos generados)';
+
+-- ====================
+-- 3. INDEXES
+-- ====================
+
+-- Autocompletado por prefijo
+CREATE INDEX idx_tags_account_name ON tags(account_id, name varchar_pattern_ops);
+
+-- Filtros y reportes por tag (la PK cubre la búsqueda por movimiento)
+CREATE INDEX idx_expense_tags_tag_id ON expense_tags(tag_id);
+CREATE INDEX idx_income_tags_tag_id ON income_tags(tag_id);
+CREATE INDEX idx_recurring_expense_tags_tag_id ON recurring_expense_tags(tag_id);
+CREATE INDEX idx_recurring_income_tags_tag_id ON recurring_income_tags(tag_id);
+
+-- ====================
+-- MIGRATION COMPLETE
+-- ====================
+
+-- Summary of changes:
+-- ✅ Created tags (per account, unique name)
+-- ✅ Created expense_tags, income_tags, recurring_expense_tags, recurring_income_tags
+-- ✅ Added indexes for autocomplete and tag filters
@@ -0,0 +1,50 @@
+-- Migration 032: Hierarchical categories (subcategories)
+-- Date: 2026-02-16
+-- Description: parent_id on expense_categories and income_categories so a category can live
+--              under another one (e.g. "Alimentación > Supermercado", "Alimentación > Delivery").
+--              The parent can be a system category or a custom category of the same account.
+--              Cycles are rejected by the API when creating/updating.
+
+-- ====================
+-- 1. ADD COLUMNS
+-- ====================
+
+ALTER TABLE expense_categories
+ADD COLUMN parent_id UUID REFERENCES expense_categories(id) ON DELETE RESTRICT;
+
+ALTER TABLE income_categories
+ADD COLUMN parent_id UUID REFERENCES income_categories(id) ON DELETE RESTRICT;
+
+-- ====================
+-- 2. CONSTRAINTS
+-- ====================
+
+-- A category can't be its own parent (longer cycles are checked by the API)
+ALTER TABLE expense_categories
+ADD CONSTRAINT check_expense_category_not_own_parent CHECK (parent_id IS NULL OR parent_id <> id);
+
+ALTER TABLE income_categories
+ADD CONSTRAINT check_income_category_not_own_parent CHECK (parent_id IS NULL OR parent_id <> id);
+
+-- ====================
+-- 3. COMMENTS (Documentation)
+-- ====================
+
+COMMENT ON COLUMN expense_categories.parent_id IS 'Categoría padre (NULL = categoría de primer nivel). No se puede borrar un padre con subcategorías';
+COMMENT ON COLUMN income_categories.parent_id IS 'Categoría padre (NULL = categoría de primer nivel). No se puede borrar un padre con subcategorías';
+
+-- ====================
+-- 4. INDEXES
+-- ====================
+
+CREATE INDEX idx_expense_categories_parent_id ON expense_categories(parent_id) WHERE parent_id IS NOT NULL;
+CREATE INDEX idx_income_categories_parent_id ON income_categories(parent_id) WHERE parent_id IS NOT NULL;
+
+-- ====================
+-- MIGRATION COMPLETE
+-- ====================
+
+-- Summary of changes:
+-- ✅ Added parent_id to expense_categories and income_categories
+-- ✅ Added check constraints against self-parenting
+-- ✅ Added indexes to find subcategories
@@ -0,0 +1,83 @@
+-- Migration 033: Auto-categorization rules
+-- Date: 2026-02-17
+-- Description: Per-account rules like "description contains 'UBER' → category Transporte + tag
+--              trabajo" or "amount >= 500 and currency USD → category Viajes". They run in priority
+--              order when a movement is created (API, scheduler) and only fill in what is empty:
+--              what the user typed wins. They can also be applied retroactively.
+
+-- ====================
+-- 1. CREATE TABLE
+-- ====================
+
+CREATE TABLE categorization_rules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    account_id UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+
+    name VARCHAR(100) NOT NULL,
+    movement_type VARCHAR(10) NOT NULL CHECK (movement_type IN ('expense', 'income')),
+    priority INT NOT NULL DEFAULT 100, -- Menor = se evalúa antes
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+
+    -- Condiciones (se tienen que cumplir todas las que no son NULL)
+    description_contains VARCHAR(100),
+    amount_min NUMERIC(15,2) CHECK (amount_min >= 0),
+    amount_max NUMERIC(15,2) CHECK (amount_max >= 0),
+    currency currency,
+    payment_method_id UUID REFERENCES payment_methods(id) ON DELETE CASCADE,
+
+    -- Acciones (según movement_type se usa una u otra categoría)
+    set_expense_category_id UUID REFERENCES expense_categories(id) ON DELETE SET NULL,
+    set_income_category_id UUID REFERENCES income_categories(id) ON DELETE SET NULL,
+    set_family_member_id UUID REFERENCES family_members(id) ON DELETE SET NULL,
+    add_tags TEXT[] NOT NULL DEFAULT '{}', -- Nombres normalizados, se crean al aplicarse
+
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT check_rule_has_condition CHECK (
+        description_contains IS NOT NULL OR amount_min IS NOT NULL OR amount_max IS NOT NULL
+        OR currency IS NOT NULL OR payment_method_id IS NOT NULL
+    ),
+    CONSTRAINT check_rule_amount_range CHECK (
+        amount_min IS NULL OR amount_max IS NULL OR amount_min <= amount_max
+    ),
+    CONSTRAINT check_rule_category_type CHECK (
+        (movement_type = 'expense' AND set_income_category_id IS NULL)
+        OR (movement_type = 'income' AND set_expense_category_id IS NULL)
+    )
+);
+
+-- ====================
+-- 2. COMMENTS (Documentation)
+-- ====================
+
+COMMENT ON TABLE categorization_rules IS 'Reglas de auto-categorización: si un movimiento cumple las condiciones se le completan categoría, miembro y tags';
+COMMENT ON COLUMN categorization_rules.priority IS 'Orden de evaluación (menor primero). Si dos reglas ponen la categoría, gana la de menor prioridad';
+COMMENT ON COLUMN categorization_rules.description_contains IS 'Texto que tiene que aparecer en la descripción (sin distinguir mayúsculas)';
+COMMENT ON COLUMN categorization_rules.amount_min IS 'Monto mínimo (inclusive) en la moneda del movimiento';
+COMMENT ON COLUMN categorization_rules.amount_max IS 'Monto máximo (inclusive) en la moneda del movimiento';
+COMMENT ON COLUMN categorization_rules.add_tags IS 'Tags que se suman al movimiento';
+
+-- ====================
+-- 3. INDEXES
+-- ====================
+
+CREATE INDEX idx_categorization_rules_account ON categorization_rules(account_id, movement_type, priority) WHERE is_active = TRUE;
+
+-- ====================
+-- 4. TRIGGERS (auto-update updated_at)
+-- ====================
+
+CREATE TRIGGER trigger_update_categorization_rules_updated_at
+BEFORE UPDATE ON categorization_rules
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+-- ====================
+-- MIGRATION COMPLETE
+-- ====================
+
+-- Summary of changes:
+-- ✅ Created categorization_rules (conditions, actions, priority)
+-- ✅ Added index to load the active rules of an account in order
+-- ✅ Added updated_at trigger
@@ -0,0 +1,47 @@
+-- Migration 034: Full-text search across transactions
+-- Date: 2026-02-18
+-- Description: Spanish text search configuration that ignores accents ("cafe" finds "Café",
+--              "supermercados" finds "Supermercado") and GIN indexes over the descriptions
+--              searched by GET /api/search. Savings goal transactions are few per account and are
+--              searched together with the goal name, so they don't get an index.
+
+-- ====================
+-- 1. EXTENSIONS
+-- ====================
+
+CREATE EXTENSION IF NOT EXISTS unaccent;
+
+-- ====================
+-- 2. TEXT SEARCH CONFIGURATION
+-- ====================
+
+-- Same as 'spanish' (stemming + stopwords) but removing accents before stemming
+CREATE TEXT SEARCH CONFIGURATION spanish_unaccent (COPY = spanish);
+
+ALTER TEXT SEARCH CONFIGURATION spanish_unaccent
+ALTER MAPPING FOR hword, hword_part, word WITH unaccent, spanish_stem;
+
+-- ====================
+-- 3. COMMENTS (Documentation)
+-- ====================
+
+COMMENT ON TEXT SEARCH CONFIGURATION spanish_unaccent IS 'Español sin acentos: usado por la búsqueda de movimientos (GET /api/search)';
+
+-- ====================
+-- 4. INDEXES
+-- ====================
+
+-- The expression has to match the one used by the search query exactly
+CREATE INDEX idx_expenses_description_fts ON expenses USING GIN (to_tsvector('spanish_unaccent', description));
+CREATE INDEX idx_incomes_description_fts ON incomes USING GIN (to_tsvector('spanish_unaccent', description));
+CREATE INDEX idx_recurring_expenses_description_fts ON recurring_expenses USING GIN (to_tsvector('spanish_unaccent', description));
+CREATE INDEX idx_recurring_incomes_description_fts ON recurring_incomes USING GIN (to_tsvector('spanish_unaccent', description));
+
+-- ====================
+-- MIGRATION COMPLETE
+-- ====================
+
+-- Summary of changes:
+-- ✅ Enabled unaccent extension
+-- ✅ Created spanish_unaccent text search configuration
+-- ✅ Added full-text GIN indexes on expense, income and recurring template descriptions
@@ -0,0 +1,63 @@
+-- Migration 035: Saved views (smart filters)
+-- Date: 2026-02-19
+-- Description: Named filter sets over expenses or incomes, like "Gastos con tarjeta este mes".
+--              filters keeps the same params as GET /expenses and GET /incomes; date_range is a
+--              relative expression ("this_month", "last_90_days") resolved every time the view runs.
+--              Private views are only visible to the member who created them.
+
+-- ====================
+-- 1. CREATE TABLE
+-- ====================
+
+CREATE TABLE saved_views (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    account_id UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+
+    name VARCHAR(100) NOT NULL,
+    movement_type VARCHAR(10) NOT NULL CHECK (movement_type IN ('expense', 'income')),
+    is_private BOOLEAN NOT NULL DEFAULT FALSE,
+
+    filters JSONB NOT NULL DEFAULT '{}', -- Params de GET /expenses o GET /incomes
+    date_range VARCHAR(30),              -- Fechas relativas: this_month, last_90_days, ...
+    sort_by VARCHAR(20) NOT NULL DEFAULT 'date',
+    sort_order VARCHAR(4) NOT NULL DEFAULT 'desc' CHECK (sort_order IN ('asc', 'desc')),
+
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT unique_saved_view_name_per_account UNIQUE (account_id, name)
+);
+
+-- ====================
+-- 2. COMMENTS (Documentation)
+-- ====================
+
+COMMENT ON TABLE saved_views IS 'Vistas guardadas: filtros con nombre sobre gastos o ingresos, reutilizables en resultados, resumen y exportación';
+COMMENT ON COLUMN saved_views.filters IS 'Mismos filtros que la query string de GET /expenses o GET /incomes (sin paginación)';
+COMMENT ON COLUMN saved_views.date_range IS 'Rango relativo que se resuelve al ejecutar la vista (today, this_month, last_90_days, ...). Excluye date_from/date_to en filters';
+COMMENT ON COLUMN saved_views.is_private IS 'Si es true solo la ve quien la creó; si no, todos los miembros de la cuenta';
+
+-- ====================
+-- 3. INDEXES
+-- ====================
+
+CREATE INDEX idx_saved_views_account ON saved_views(account_id, name);
+
+-- ====================
+-- 4. TRIGGERS (auto-update updated_at)
+-- ====================
+
+CREATE TRIGGER trigger_update_saved_views_updated_at
+BEFORE UPDATE ON saved_views
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+-- ====================
+-- MIGRATION COMPLETE
+-- ====================
+
+-- Summary of changes:
+-- ✅ Created saved_views table (per account, optionally private to its creator)
+-- ✅ Added account index
+-- ✅ Added updated_at trigger
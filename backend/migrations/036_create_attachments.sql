@@ -0,0 +1,87 @@
+-- Migration 036: Attachments (receipts) on expenses and incomes
+-- Date: 2026-02-20
+-- Description: Metadata of the files (receipts, invoices, warranties) attached to expenses and incomes.
+--              The bytes live in the configured storage (local filesystem or S3-compatible) under
+--              storage_key; this table only keeps what is needed to list and serve them.
+--              When an attachment row goes away (directly or because its movement/account was
+--              deleted) its key is queued in attachment_deletions so the file is removed from storage.
+
+-- ====================
+-- 1. CREATE TABLES
+-- ====================
+
+CREATE TABLE attachments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    account_id UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+
+    -- Exactly one of expense_id / income_id
+    expense_id UUID REFERENCES expenses(id) ON DELETE CASCADE,
+    income_id UUID REFERENCES incomes(id) ON DELETE CASCADE,
+
+    storage_backend VARCHAR(20) NOT NULL, -- local, s3
+    storage_key TEXT NOT NULL,
+    filename VARCHAR(255) NOT NULL,       -- Original name, only for display/download
+    content_type VARCHAR(100) NOT NULL,   -- Detected from the content, not from the client
+    size_bytes BIGINT NOT NULL CHECK (size_bytes > 0),
+    sha256 CHAR(64) NOT NULL,
+
+    uploaded_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT attachment_single_movement CHECK (num_nonnulls(expense_id, income_id) = 1),
+    CONSTRAINT unique_attachment_storage_key UNIQUE (storage_backend, storage_key)
+);
+
+CREATE TABLE attachment_deletions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    storage_backend VARCHAR(20) NOT NULL,
+    storage_key TEXT NOT NULL,
+    deleted_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- ====================
+-- 2. COMMENTS (Documentation)
+-- ====================
+
+COMMENT ON TABLE attachments IS 'Comprobantes adjuntos a gastos e ingresos; el archivo vive en el storage configurado';
+COMMENT ON COLUMN attachments.storage_key IS 'Clave del archivo en el storage: account_id/uuid.ext';
+COMMENT ON COLUMN attachments.content_type IS 'Tipo detectado a partir del contenido (image/jpeg, image/png, image/webp, image/heic, application/pdf)';
+COMMENT ON TABLE attachment_deletions IS 'Archivos a borrar del storage: los carga el trigger al borrar un adjunto y los procesa el scheduler';
+
+-- ====================
+-- 3. INDEXES
+-- ====================
+
+CREATE INDEX idx_attachments_expense ON attachments(expense_id) WHERE expense_id IS NOT NULL;
+CREATE INDEX idx_attachments_income ON attachments(income_id) WHERE income_id IS NOT NULL;
+CREATE INDEX idx_attachments_account ON attachments(account_id);
+CREATE INDEX idx_attachment_deletions_key ON attachment_deletions(storage_backend, storage_key);
+
+-- ====================
+-- 4. TRIGGERS (queue files of deleted attachments)
+-- ====================
+
+-- Fires for direct deletes and for the cascades from expenses, incomes and accounts
+CREATE OR REPLACE FUNCTION queue_attachment_deletion()
+RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO attachment_deletions (storage_backend, storage_key)
+    VALUES (OLD.storage_backend, OLD.storage_key);
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_queue_attachment_deletion
+AFTER DELETE ON attachments
+FOR EACH ROW
+EXECUTE FUNCTION queue_attachment_deletion();
+
+-- ====================
+-- MIGRATION COMPLETE
+-- ====================
+
+-- Summary of changes:
+-- ✅ Created attachments table (one expense or one income per attachment)
+-- ✅ Created attachment_deletions queue
+-- ✅ Added indexes by movement, account and storage key
+-- ✅ Added trigger that queues the file of every deleted attachment
@@ -0,0 +1,41 @@
+-- Migration 037: Draft expenses from scanned receipts
+-- Date: 2026-02-21
+-- Description: Scanning a receipt (OCR) creates an expense marked as draft, with the receipt attached.
+--              Drafts are left out of the dashboard summary until the user confirms them.
+--              The OCR output is kept on the attachment it came from.
+
+-- ====================
+-- 1. ADD COLUMNS
+-- ====================
+
+ALTER TABLE expenses
+ADD COLUMN is_draft BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE attachments
+ADD COLUMN ocr_provider VARCHAR(20),
+ADD COLUMN ocr_text TEXT,
+ADD COLUMN ocr_data JSONB; -- merchant, date, total, currency (null = not found)
+
+-- ====================
+-- 2. COMMENTS (Documentation)
+-- ====================
+
+COMMENT ON COLUMN expenses.is_draft IS 'Creado a partir de un comprobante escaneado y todavía no confirmado; no cuenta en el dashboard';
+COMMENT ON COLUMN attachments.ocr_text IS 'Texto leído por OCR (solo para adjuntos escaneados con POST /receipts/scan)';
+COMMENT ON COLUMN attachments.ocr_data IS 'Datos extraídos del texto: merchant, date, total, currency';
+
+-- ====================
+-- 3. INDEXES
+-- ====================
+
+-- Few drafts per account at any time: partial index for the pending list
+CREATE INDEX idx_expenses_drafts ON expenses(account_id, created_at) WHERE is_draft;
+
+-- ====================
+-- MIGRATION COMPLETE
+-- ====================
+
+-- Summary of changes:
+-- ✅ Added expenses.is_draft
+-- ✅ Added OCR columns to attachments
+-- ✅ Added partial index for pending drafts
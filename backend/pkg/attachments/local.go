@@ -0,0 +1,92 @@
+package attachments
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrInvalidKey se retorna si la clave escaparía de la carpeta raíz del storage local
+var ErrInvalidKey = errors.New("invalid storage key")
+
+// LocalStorage guarda los adjuntos en el filesystem, bajo root/<clave>
+// Sirve para desarrollo y para instalaciones de un solo servidor (montar root en un volumen)
+type LocalStorage struct {
+	root string
+}
+
+// NewLocalStorage crea el storage local. root vacío = ./uploads
+// La carpeta se crea en el primer Put
+func NewLocalStorage(root string) *LocalStorage {
+	if root == "" {
+		root = "uploads"
+	}
+	return &LocalStorage{root: filepath.Clean(root)}
+}
+
+func (s *LocalStorage) Name() string {
+	return "local"
+}
+
+// path resuelve la clave dentro de root. Se compara con filepath.Rel y no por prefijo, así
+// funciona también con raíces relativas como "." (Join limpia el "./" del principio)
+func (s *LocalStorage) path(key string) (string, error) {
+	path := filepath.Join(s.root, filepath.FromSlash(key))
+	rel, err := filepath.Rel(s.root, path)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(os.PathSeparator)) {
+		return "", ErrInvalidKey
+	}
+	return path, nil
+}
+
+// Put escribe a un archivo temporal y lo renombra, para no dejar nunca un adjunto a medio escribir
+func (s *LocalStorage) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // No-op después del rename
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (s *LocalStorage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return f, err
+}
+
+func (s *LocalStorage) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package attachments
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLocalStoragePath(t *testing.T) {
+	tests := []struct {
+		root string
+		key  string
+		want string // "" = ErrInvalidKey
+	}{
+		{".", "acc/file.pdf", "acc/file.pdf"},
+		{"./uploads", "acc/file.pdf", "uploads/acc/file.pdf"},
+		{"uploads/", "acc/file.pdf", "uploads/acc/file.pdf"},
+		{"/srv/uploads", "acc/file.pdf", "/srv/uploads/acc/file.pdf"},
+		{"/srv/uploads", "acc/../other/file.pdf", "/srv/uploads/other/file.pdf"},
+		{".", "../file.pdf", ""},
+		{".", "", ""},
+		{"uploads", "acc/../../file.pdf", ""},
+		{"/srv/uploads", "../uploads-old/file.pdf", ""},
+		{"/srv/uploads", "..", ""},
+	}
+
+	for _, tt := range tests {
+		got, err := NewLocalStorage(tt.root).path(tt.key)
+		if tt.want == "" {
+			if err != ErrInvalidKey {
+				t.Errorf("path(%q) with root %q = %q, %v; want ErrInvalidKey", tt.key, tt.root, got, err)
+			}
+			continue
+		}
+		if err != nil || got != filepath.FromSlash(tt.want) {
+			t.Errorf("path(%q) with root %q = %q, %v; want %q", tt.key, tt.root, got, err, tt.want)
+		}
+	}
+}
+
+// Put, Get y Delete con la raíz relativa al directorio de trabajo, como STORAGE_PATH=.
+func TestLocalStorageRelativeRoot(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	ctx := context.Background()
+	s := NewLocalStorage(".")
+
+	if err := s.Put(ctx, "acc/receipt.pdf", []byte("%PDF-1.4"), "application/pdf"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	r, err := s.Get(ctx, "acc/receipt.pdf")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	data, _ := io.ReadAll(r)
+	r.Close()
+	if string(data) != "%PDF-1.4" {
+		t.Fatalf("Get = %q", data)
+	}
+
+	if err := s.Delete(ctx, "acc/receipt.pdf"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join("acc", "receipt.pdf")); !os.IsNotExist(err) {
+		t.Fatalf("file still exists after Delete: %v", err)
+	}
+	if _, err := s.Get(ctx, "acc/receipt.pdf"); err != ErrNotFound {
+		t.Fatalf("Get after Delete = %v, want ErrNotFound", err)
+	}
+	if err := s.Delete(ctx, "acc/receipt.pdf"); err != nil {
+		t.Fatalf("Delete of a missing key = %v, want nil", err)
+	}
+}
@@ -0,0 +1,215 @@
+package attachments
+
+import (
+	"context"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const (
+	testAccessKey = "AKIDEXAMPLE"
+	testSecretKey = "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"
+	testRegion    = "us-east-1"
+	testBucket    = "receipts"
+)
+
+// fakeS3 es un bucket en memoria (path-style) que valida cada request como lo haría S3:
+// recalcula la firma Signature V4 sobre lo que llega por la red y responde 403 si no coincide
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string]fakeObject // Por path codificado, ej: /receipts/acc/file.pdf
+	paths   []string              // Paths recibidos, en orden
+}
+
+type fakeObject struct {
+	data        []byte
+	contentType string
+}
+
+func newFakeS3(t *testing.T) (*fakeS3, *httptest.Server) {
+	f := &fakeS3{objects: map[string]fakeObject{}}
+	server := httptest.NewServer(f)
+	t.Cleanup(server.Close)
+	return f, server
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	path := r.URL.EscapedPath()
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.paths = append(f.paths, path)
+
+	if msg := f.checkSignature(r, path, body); msg != "" {
+		w.WriteHeader(http.StatusForbidden)
+		io.WriteString(w, "<Error><Code>SignatureDoesNotMatch</Code><Message>"+msg+"</Message></Error>")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPut:
+		f.objects[path] = fakeObject{data: body, contentType: r.Header.Get("Content-Type")}
+		w.WriteHeader(http.StatusOK)
+	case http.MethodGet:
+		obj, ok := f.objects[path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, "<Error><Code>NoSuchKey</Code></Error>")
+			return
+		}
+		w.Header().Set("Content-Type", obj.contentType)
+		w.Write(obj.data)
+	case http.MethodDelete:
+		delete(f.objects, path)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// checkSignature arma el canonical request desde el request recibido y compara la firma
+func (f *fakeS3) checkSignature(r *http.Request, path string, body []byte) string {
+	if got := r.Header.Get("x-amz-content-sha256"); got != sha256Hex(body) {
+		return "x-amz-content-sha256 does not match the body"
+	}
+
+	amzDate := r.Header.Get("x-amz-date")
+	if len(amzDate) != len("20060102T150405Z") {
+		return "missing x-amz-date"
+	}
+	scope := amzDate[:8] + "/" + testRegion + "/s3/aws4_request"
+	prefix := "AWS4-HMAC-SHA256 Credential=" + testAccessKey + "/" + scope + ", SignedHeaders=host;x-amz-content-sha256;x-amz-date, Signature="
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, prefix) {
+		return "unexpected Authorization header: " + auth
+	}
+
+	canonicalRequest := r.Method + "\n" + path + "\n\n" +
+		"host:" + r.Host + "\n" +
+		"x-amz-content-sha256:" + r.Header.Get("x-amz-content-sha256") + "\n" +
+		"x-amz-date:" + amzDate + "\n\n" +
+		"host;x-amz-content-sha256;x-amz-date\n" +
+		r.Header.Get("x-amz-content-sha256")
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+testSecretKey), amzDate[:8])
+	for _, part := range []string{testRegion, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	if want := hex.EncodeToString(hmacSHA256(key, stringToSign)); strings.TrimPrefix(auth, prefix) != want {
+		return "signature does not match"
+	}
+	return ""
+}
+
+func newTestS3Storage(t *testing.T, endpoint string) *S3Storage {
+	s, err := NewS3Storage(Config{
+		Backend:           "s3",
+		S3Endpoint:        endpoint,
+		S3Region:          testRegion,
+		S3Bucket:          testBucket,
+		S3AccessKeyID:     testAccessKey,
+		S3SecretAccessKey: testSecretKey,
+		S3ForcePathStyle:  true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestS3StorageRoundTrip(t *testing.T) {
+	fake, server := newFakeS3(t)
+	s := newTestS3Storage(t, server.URL)
+	ctx := context.Background()
+
+	tests := []struct {
+		key  string
+		path string // Como tiene que llegar (y firmarse)
+	}{
+		{"acc-1/3f2a.pdf", "/receipts/acc-1/3f2a.pdf"},
+		{"acc-1/ticket del súper+1.jpg", "/receipts/acc-1/ticket%20del%20s%C3%BAper%2B1.jpg"},
+		{"acc-1/a~b_c.png", "/receipts/acc-1/a~b_c.png"},
+	}
+
+	for _, tt := range tests {
+		fake.paths = nil
+		data := []byte("contenido de " + tt.key)
+
+		if err := s.Put(ctx, tt.key, data, "image/jpeg"); err != nil {
+			t.Fatalf("Put(%q): %v", tt.key, err)
+		}
+		if obj, ok := fake.objects[tt.path]; !ok || obj.contentType != "image/jpeg" {
+			t.Fatalf("Put(%q) stored at %v, want %q with its content type", tt.key, fake.paths, tt.path)
+		}
+
+		r, err := s.Get(ctx, tt.key)
+		if err != nil {
+			t.Fatalf("Get(%q): %v", tt.key, err)
+		}
+		got, _ := io.ReadAll(r)
+		r.Close()
+		if string(got) != string(data) {
+			t.Fatalf("Get(%q) = %q, want %q", tt.key, got, data)
+		}
+
+		if err := s.Delete(ctx, tt.key); err != nil {
+			t.Fatalf("Delete(%q): %v", tt.key, err)
+		}
+		if _, err := s.Get(ctx, tt.key); err != ErrNotFound {
+			t.Fatalf("Get(%q) after Delete = %v, want ErrNotFound", tt.key, err)
+		}
+	}
+}
+
+// Endpoint con path (proxy o gateway delante del bucket): el prefijo se conserva y entra en la firma
+func TestS3StorageEndpointWithPath(t *testing.T) {
+	fake, server := newFakeS3(t)
+	s := newTestS3Storage(t, server.URL+"/storage/")
+
+	if err := s.Put(context.Background(), "acc/file.pdf", []byte("%PDF"), "application/pdf"); err != nil {
+		t.Fatal(err)
+	}
+	if len(fake.paths) != 1 || fake.paths[0] != "/storage/receipts/acc/file.pdf" {
+		t.Fatalf("paths = %v, want [/storage/receipts/acc/file.pdf]", fake.paths)
+	}
+}
+
+// Credenciales incorrectas: el error incluye lo que respondió S3
+func TestS3StorageWrongCredentials(t *testing.T) {
+	_, server := newFakeS3(t)
+	ctx := context.Background()
+
+	s := newTestS3Storage(t, server.URL)
+	s.secretKey = "wrong"
+	err := s.Put(ctx, "acc/file.pdf", []byte("x"), "application/pdf")
+	if err == nil || !strings.Contains(err.Error(), "403") || !strings.Contains(err.Error(), "SignatureDoesNotMatch") {
+		t.Fatalf("Put with a wrong secret = %v, want a 403 with the S3 error body", err)
+	}
+}
+
+func TestNewS3StorageConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+		ok   bool
+	}{
+		{"aws default endpoint", Config{S3Bucket: "b", S3AccessKeyID: "k", S3SecretAccessKey: "s"}, true},
+		{"missing bucket", Config{S3AccessKeyID: "k", S3SecretAccessKey: "s"}, false},
+		{"missing credentials", Config{S3Bucket: "b"}, false},
+		{"endpoint without scheme", Config{S3Endpoint: "localhost:9000", S3Bucket: "b", S3AccessKeyID: "k", S3SecretAccessKey: "s"}, false},
+	}
+
+	for _, tt := range tests {
+		_, err := NewS3Storage(tt.cfg)
+		if (err == nil) != tt.ok {
+			t.Errorf("%s: err = %v, want ok = %v", tt.name, err, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package attachments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrNotFound se retorna cuando la clave no existe en el storage
+var ErrNotFound = errors.New("file not found in storage")
+
+// Storage guarda los bytes de los adjuntos. La metadata (nombre, tipo, tamaño, a qué movimiento
+// pertenece) vive en la tabla attachments; el storage solo conoce claves
+// LocalStorage y S3Storage son las implementaciones incluidas
+type Storage interface {
+	Name() string // Se guarda en attachments.storage_backend
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error) // ErrNotFound si no existe
+	Delete(ctx context.Context, key string) error               // Borrar una clave inexistente no es error
+}
+
+// Config elige el storage y sus parámetros (ver internal/config)
+type Config struct {
+	Backend   string // local (default) o s3
+	LocalPath string // Carpeta raíz del storage local
+
+	S3Endpoint        string // Vacío = AWS (https://s3.<region>.amazonaws.com); MinIO: http://localhost:9000
+	S3Region          string
+	S3Bucket          string
+	S3AccessKeyID     string
+	S3SecretAccessKey string
+	S3ForcePathStyle  bool // endpoint/bucket/key en lugar de bucket.endpoint/key (MinIO y la mayoría de los compatibles)
+}
+
+// New crea el storage configurado
+func New(cfg Config) (Storage, error) {
+	switch cfg.Backend {
+	case "", "local":
+		return NewLocalStorage(cfg.LocalPath), nil
+	case "s3":
+		return NewS3Storage(cfg)
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q (use local or s3)", cfg.Backend)
+	}
+}
@@ -0,0 +1,149 @@
+package attachments
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// MaxSize es el tamaño máximo de un adjunto (10 MB)
+const MaxSize = 10 << 20
+
+// MaxPerMovement limita los adjuntos de un mismo gasto o ingreso
+const MaxPerMovement = 10
+
+// AllowedTypes son los tipos aceptados (detectados por contenido) y su extensión en el storage
+var AllowedTypes = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/webp":      ".webp",
+	"image/heic":      ".heic",
+	"application/pdf": ".pdf",
+}
+
+var (
+	ErrMissingFile     = errors.New(`file is required (multipart/form-data, field "file")`)
+	ErrEmpty           = errors.New("file is empty")
+	ErrTooLarge        = errors.New("file exceeds the 10 MB limit")
+	ErrUnsupportedType = errors.New("unsupported file type: use JPEG, PNG, WEBP, HEIC or PDF")
+)
+
+// File es un adjunto ya leído y validado
+type File struct {
+	Filename    string // Nombre original, ya limpio (CleanFilename)
+	Data        []byte
+	ContentType string
+	SHA256      string
+}
+
+// FromRequest lee y valida el archivo del campo "file" de un request multipart/form-data
+// Corta la lectura del body apenas supera el límite, sin esperar a recibirlo entero
+func FromRequest(w http.ResponseWriter, r *http.Request) (*File, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, MaxSize+1<<20) // 1 MB de margen para el envoltorio multipart
+	f, header, err := r.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return nil, ErrTooLarge
+		}
+		return nil, ErrMissingFile
+	}
+	defer f.Close()
+	if header.Size > MaxSize {
+		return nil, ErrTooLarge
+	}
+
+	file, err := Read(f)
+	if err != nil {
+		return nil, err
+	}
+	file.Filename = CleanFilename(header.Filename, file.ContentType)
+	return file, nil
+}
+
+// HTTPStatus es el status con el que responder a un error de FromRequest o Read
+func HTTPStatus(err error) int {
+	switch err {
+	case ErrTooLarge:
+		return http.StatusRequestEntityTooLarge
+	case ErrUnsupportedType:
+		return http.StatusUnsupportedMediaType
+	case ErrMissingFile, ErrEmpty:
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// Read lee el archivo subido validando tamaño y tipo
+// El tipo se detecta a partir del contenido: la extensión y el Content-Type del cliente no cuentan
+func Read(r io.Reader) (*File, error) {
+	data, err := io.ReadAll(io.LimitReader(r, MaxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, ErrEmpty
+	}
+	if len(data) > MaxSize {
+		return nil, ErrTooLarge
+	}
+
+	contentType := DetectType(data)
+	if _, ok := AllowedTypes[contentType]; !ok {
+		return nil, ErrUnsupportedType
+	}
+
+	sum := sha256.Sum256(data)
+	return &File{Data: data, ContentType: contentType, SHA256: hex.EncodeToString(sum[:])}, nil
+}
+
+// DetectType es http.DetectContentType más HEIC (las fotos de iPhone), que no reconoce
+func DetectType(data []byte) string {
+	// ISO BMFF: [tamaño][ftyp][marca]
+	if len(data) >= 12 && string(data[4:8]) == "ftyp" {
+		switch string(data[8:12]) {
+		case "heic", "heix", "heim", "heis", "mif1", "msf1":
+			return "image/heic"
+		}
+	}
+	contentType := http.DetectContentType(data)
+	if i := strings.IndexByte(contentType, ';'); i >= 0 {
+		contentType = contentType[:i]
+	}
+	return contentType
+}
+
+// Key arma la clave de un adjunto nuevo: account_id/uuid.ext
+func Key(accountID, contentType string) string {
+	return accountID + "/" + uuid.NewString() + AllowedTypes[contentType]
+}
+
+// CleanFilename deja solo el nombre base del archivo subido (máx. 255 caracteres)
+// Si no queda nada usable, lo arma con la extensión del tipo detectado
+func CleanFilename(name, contentType string) string {
+	name = filepath.Base(strings.ReplaceAll(name, `\`, "/"))
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f || r == '"' {
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.TrimSpace(name)
+
+	if name == "" || name == "." || name == "/" {
+		name = "receipt" + AllowedTypes[contentType]
+	}
+	for len(name) > 255 {
+		_, size := utf8.DecodeLastRuneInString(name)
+		name = name[:len(name)-size]
+	}
+	return name
+}
@@ -0,0 +1,130 @@
+package categories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/LorenzoCampos/bolsillo-claro/internal/database"
+)
+
+// Tablas de categorías (expense_categories o income_categories)
+const (
+	ExpenseTable = "expense_categories"
+	IncomeTable  = "income_categories"
+)
+
+var (
+	ErrParentNotFound = errors.New("parent_id does not exist or does not belong to this account")
+	ErrCycle          = errors.New("parent_id would create a cycle (the parent is the category itself or one of its subcategories)")
+)
+
+// Node es una categoría visible para la cuenta (de sistema o propia)
+type Node struct {
+	ID       string
+	ParentID *string
+	Name     string
+	Icon     *string
+	Color    *string
+	IsSystem bool
+}
+
+// Tree son las categorías de una cuenta indexadas por id
+type Tree map[string]*Node
+
+// LoadTree carga las categorías de sistema y las de la cuenta de la tabla indicada
+func LoadTree(ctx context.Context, q database.Querier, table string, accountID interface{}) (Tree, error) {
+	rows, err := q.Query(ctx, `
+		SELECT id, parent_id, name, icon, color, COALESCE(is_system, FALSE)
+		FROM `+table+`
+		WHERE account_id IS NULL OR account_id = $1
+	`, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tree := Tree{}
+	for rows.Next() {
+		var node Node
+		if err := rows.Scan(&node.ID, &node.ParentID, &node.Name, &node.Icon, &node.Color, &node.IsSystem); err != nil {
+			return nil, err
+		}
+		tree[node.ID] = &node
+	}
+	return tree, rows.Err()
+}
+
+// Ancestors devuelve los ancestros de la categoría, del padre directo a la raíz
+func (t Tree) Ancestors(id string) []string {
+	var result []string
+	seen := map[string]bool{id: true}
+	node := t[id]
+	for node != nil && node.ParentID != nil && !seen[*node.ParentID] {
+		seen[*node.ParentID] = true
+		result = append(result, *node.ParentID)
+		node = t[*node.ParentID]
+	}
+	return result
+}
+
+// HasChildren indica si alguna categoría cuelga de id
+func (t Tree) HasChildren(id string) bool {
+	for _, node := range t {
+		if node.ParentID != nil && *node.ParentID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateParent verifica que parentID pueda ser el padre de categoryID
+// categoryID vacío = categoría nueva (no puede haber ciclo)
+func (t Tree) ValidateParent(categoryID, parentID string) error {
+	if _, ok := t[parentID]; !ok {
+		return ErrParentNotFound
+	}
+	if categoryID == "" {
+		return nil
+	}
+	if parentID == categoryID {
+		return ErrCycle
+	}
+	for _, ancestor := range t.Ancestors(parentID) {
+		if ancestor == categoryID {
+			return ErrCycle
+		}
+	}
+	return nil
+}
+
+// RollupTo devuelve la categoría bajo la que se agrupa id:
+// - parentID nil: la raíz de id (la categoría de primer nivel)
+// - parentID no nil: el hijo directo de parentID que contiene a id (o parentID mismo si id == parentID)
+// ok = false si id no está dentro de parentID
+func (t Tree) RollupTo(id string, parentID *string) (string, bool) {
+	path := append([]string{id}, t.Ancestors(id)...)
+	if parentID == nil {
+		return path[len(path)-1], true
+	}
+	if id == *parentID {
+		return id, true
+	}
+	for i := 1; i < len(path); i++ {
+		if path[i] == *parentID {
+			return path[i-1], true
+		}
+	}
+	return "", false
+}
+
+// SubtreeSQL arma una subquery con el id de la categoría del parámetro arg (ej: $3)
+// y los de todas sus subcategorías, para usar en un filtro: category_id IN (...)
+func SubtreeSQL(table, arg string) string {
+	return `
+		WITH RECURSIVE subtree AS (
+			SELECT id FROM ` + table + ` WHERE id = ` + arg + `
+			UNION
+			SELECT child.id FROM ` + table + ` child JOIN subtree ON child.parent_id = subtree.id
+		)
+		SELECT id FROM subtree`
+}
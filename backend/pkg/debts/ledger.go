@@ -0,0 +1,284 @@
+package debts
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/LorenzoCampos/bolsillo-claro/internal/database"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/money"
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrPaymentExceedsBalance se retorna al pagar más de lo que falta del plan
+var ErrPaymentExceedsBalance = errors.New("payment exceeds outstanding balance")
+
+// Estados de una cuota
+const (
+	EntryPaid    = "paid"
+	EntryPartial = "partial" // Pagada en parte, todavía no vencida
+	EntryPending = "pending"
+	EntryOverdue = "overdue" // Vencida sin pagar (total o parcialmente)
+)
+
+// Estados derivados de una deuda
+const (
+	StatusActive     = "active"
+	StatusOverdue    = "overdue"
+	StatusPaidOff    = "paid_off"
+	StatusWrittenOff = "written_off"
+)
+
+// Debt es un préstamo tomado u otorgado
+type Debt struct {
+	ID                string
+	AccountID         string
+	Counterparty      string
+	Description       *string
+	Direction         string
+	Principal         float64
+	Currency          string
+	AmortizationType  string
+	AnnualRate        float64
+	InstallmentsCount int
+	StartDate         time.Time
+	FirstDueDate      time.Time
+	ExpenseCategoryID *string
+	IncomeCategoryID  *string
+	WrittenOffAt      *time.Time
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+// Schedule es el plan de cuotas de la deuda
+func (d *Debt) Schedule() []Entry {
+	return BuildSchedule(d.AmortizationType, d.Principal, d.InstallmentsCount, d.AnnualRate, d.FirstDueDate)
+}
+
+// PaymentsTable es donde viven los pagos: un préstamo tomado se paga con gastos, uno otorgado se cobra
+// con ingresos. El desembolso (si se registró) queda en la otra tabla
+func (d *Debt) PaymentsTable() string {
+	if d.Direction == DirectionLent {
+		return "incomes"
+	}
+	return "expenses"
+}
+
+// DisbursementTable es la tabla opuesta a PaymentsTable
+func (d *Debt) DisbursementTable() string {
+	if d.Direction == DirectionLent {
+		return "expenses"
+	}
+	return "incomes"
+}
+
+const debtColumns = `
+	id, account_id, counterparty, description, direction, principal, currency, amortization_type,
+	annual_rate, installments_count, start_date, first_due_date, expense_category_id, income_category_id,
+	written_off_at, created_at, updated_at
+`
+
+func scanDebt(row pgx.Row) (*Debt, error) {
+	var d Debt
+	err := row.Scan(
+		&d.ID, &d.AccountID, &d.Counterparty, &d.Description, &d.Direction, &d.Principal, &d.Currency,
+		&d.AmortizationType, &d.AnnualRate, &d.InstallmentsCount, &d.StartDate, &d.FirstDueDate,
+		&d.ExpenseCategoryID, &d.IncomeCategoryID, &d.WrittenOffAt, &d.CreatedAt, &d.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// Find busca una deuda de la cuenta. Retorna pgx.ErrNoRows si no existe
+// forUpdate bloquea la fila (solo dentro de una transacción)
+func Find(ctx context.Context, q database.Querier, debtID string, accountID interface{}, forUpdate bool) (*Debt, error) {
+	query := `SELECT ` + debtColumns + ` FROM debts WHERE id = $1 AND account_id = $2`
+	if forUpdate {
+		query += " FOR UPDATE"
+	}
+	return scanDebt(q.QueryRow(ctx, query, debtID, accountID))
+}
+
+// ListByAccount lista las deudas de la cuenta, más recientes primero
+// Con asOf solo incluye las desembolsadas hasta esa fecha
+func ListByAccount(ctx context.Context, q database.Querier, accountID interface{}, asOf *time.Time) ([]Debt, error) {
+	rows, err := q.Query(ctx, `
+		SELECT `+debtColumns+` FROM debts
+		WHERE account_id = $1 AND ($2::date IS NULL OR start_date <= $2)
+		ORDER BY start_date DESC, created_at DESC
+	`, accountID, asOf)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []Debt{}
+	for rows.Next() {
+		d, err := scanDebt(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *d)
+	}
+	return result, rows.Err()
+}
+
+// Payment es un pago (o cobro) vinculado a la deuda. Principal e Interest los asigna Allocate
+type Payment struct {
+	ID          string
+	Date        time.Time
+	Amount      float64
+	Description string
+	Principal   float64
+	Interest    float64
+}
+
+// Payments lista los pagos de la deuda con fecha <= asOf (nil = todos), en orden de imputación
+func Payments(ctx context.Context, q database.Querier, d *Debt, asOf *time.Time) ([]Payment, error) {
+	rows, err := q.Query(ctx, `
+		SELECT id, date, amount, description FROM `+d.PaymentsTable()+`
+		WHERE debt_id = $1 AND ($2::date IS NULL OR date <= $2)
+		ORDER BY date ASC, created_at ASC
+	`, d.ID, asOf)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []Payment{}
+	for rows.Next() {
+		var p Payment
+		if err := rows.Scan(&p.ID, &p.Date, &p.Amount, &p.Description); err != nil {
+			return nil, err
+		}
+		result = append(result, p)
+	}
+	return result, rows.Err()
+}
+
+// EntryStatus es una cuota del plan con lo pagado
+type EntryStatus struct {
+	Entry
+	Paid   float64
+	Status string // paid | partial | pending | overdue
+}
+
+// Ledger es el estado de la deuda a una fecha
+type Ledger struct {
+	Entries              []EntryStatus
+	Payments             []Payment
+	TotalPaid            float64
+	PaidPrincipal        float64
+	PaidInterest         float64
+	OutstandingPrincipal float64 // Capital adeudado
+	OutstandingTotal     float64 // Lo que falta pagar del plan (capital + intereses de las cuotas pendientes)
+	OverdueAmount        float64
+	OverdueCount         int
+	NextDue              *EntryStatus // Primera cuota no pagada (puede estar vencida)
+}
+
+// Allocate imputa los pagos a las cuotas en orden: cada pago cancela primero el interés y después el
+// capital de la cuota más vieja pendiente. Un pago mayor a la cuota adelanta las siguientes
+// Las cuotas no pagadas con vencimiento anterior a asOf quedan overdue
+func Allocate(schedule []Entry, principal float64, payments []Payment, asOf time.Time) *Ledger {
+	ledger := &Ledger{Entries: make([]EntryStatus, len(schedule))}
+	paidInterest := make([]float64, len(schedule))
+	paidPrincipal := make([]float64, len(schedule))
+
+	sort.SliceStable(payments, func(i, j int) bool { return payments[i].Date.Before(payments[j].Date) })
+
+	cursor := 0
+	for i := range payments {
+		p := &payments[i]
+		left := p.Amount
+
+		for left > 0.005 && cursor < len(schedule) {
+			e := schedule[cursor]
+
+			interest := math.Min(left, money.Round(e.Interest-paidInterest[cursor]))
+			paidInterest[cursor] += interest
+			p.Interest += interest
+			left = money.Round(left - interest)
+
+			amortization := math.Min(left, money.Round(e.Principal-paidPrincipal[cursor]))
+			paidPrincipal[cursor] += amortization
+			p.Principal += amortization
+			left = money.Round(left - amortization)
+
+			if interest+amortization == 0 || money.Round(paidInterest[cursor]+paidPrincipal[cursor]) >= e.Amount {
+				cursor++
+			}
+		}
+
+		// Sobrante después de la última cuota (no debería pasar: los pagos se validan contra el saldo)
+		p.Principal = money.Round(p.Principal + left)
+		p.Interest = money.Round(p.Interest)
+
+		ledger.TotalPaid += p.Amount
+		ledger.PaidPrincipal += p.Principal
+		ledger.PaidInterest += p.Interest
+	}
+	ledger.Payments = payments
+
+	for i, e := range schedule {
+		status := EntryStatus{Entry: e, Paid: money.Round(paidInterest[i] + paidPrincipal[i])}
+		owed := money.Round(e.Amount - status.Paid)
+
+		switch {
+		case owed <= 0:
+			status.Status = EntryPaid
+		case e.DueDate.Before(asOf):
+			status.Status = EntryOverdue
+			ledger.OverdueAmount += owed
+			ledger.OverdueCount++
+		case status.Paid > 0:
+			status.Status = EntryPartial
+		default:
+			status.Status = EntryPending
+		}
+		if owed > 0 {
+			ledger.OutstandingTotal += owed
+		}
+
+		ledger.Entries[i] = status
+		if status.Status != EntryPaid && ledger.NextDue == nil {
+			ledger.NextDue = &ledger.Entries[i]
+		}
+	}
+
+	ledger.TotalPaid = money.Round(ledger.TotalPaid)
+	ledger.PaidPrincipal = money.Round(ledger.PaidPrincipal)
+	ledger.PaidInterest = money.Round(ledger.PaidInterest)
+	ledger.OutstandingPrincipal = math.Max(0, money.Round(principal-ledger.PaidPrincipal))
+	ledger.OutstandingTotal = money.Round(ledger.OutstandingTotal)
+	ledger.OverdueAmount = money.Round(ledger.OverdueAmount)
+
+	return ledger
+}
+
+// Ledger carga los pagos hasta asOf y los imputa al plan
+func (d *Debt) Ledger(ctx context.Context, q database.Querier, asOf time.Time) (*Ledger, error) {
+	payments, err := Payments(ctx, q, d, &asOf)
+	if err != nil {
+		return nil, err
+	}
+	return Allocate(d.Schedule(), d.Principal, payments, asOf), nil
+}
+
+// Status deriva el estado de la deuda a partir de su ledger
+func (d *Debt) Status(ledger *Ledger, asOf time.Time) string {
+	switch {
+	case d.WrittenOffAt != nil && !d.WrittenOffAt.After(asOf):
+		return StatusWrittenOff
+	case ledger.OutstandingTotal <= 0:
+		return StatusPaidOff
+	case ledger.OverdueCount > 0:
+		return StatusOverdue
+	default:
+		return StatusActive
+	}
+}
@@ -0,0 +1,88 @@
+package debts
+
+import (
+	"math"
+	"time"
+
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/money"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/savings"
+)
+
+// Valores de los ENUMs debt_direction y debt_amortization_type
+const (
+	DirectionBorrowed = "borrowed"
+	DirectionLent     = "lent"
+
+	AmortizationFrench       = "french"
+	AmortizationGerman       = "german"
+	AmortizationInterestFree = "interest_free"
+)
+
+// Entry es una cuota del plan
+type Entry struct {
+	Number             int
+	DueDate            time.Time
+	Amount             float64
+	Principal          float64
+	Interest           float64
+	RemainingPrincipal float64 // Saldo de capital después de pagar esta cuota
+}
+
+// BuildSchedule calcula el plan de cuotas mensuales
+//   - french: cuota fija, el interés se calcula sobre el saldo y la amortización crece
+//   - german: amortización de capital fija, la cuota baja a medida que baja el saldo
+//   - interest_free: cuotas iguales de capital, sin interés
+//
+// annualRate es la TNA en porcentaje. La última cuota absorbe el redondeo para que el capital cierre en 0
+func BuildSchedule(amortizationType string, principal float64, count int, annualRate float64, firstDueDate time.Time) []Entry {
+	monthlyRate := annualRate / 100 / 12
+	if amortizationType == AmortizationInterestFree {
+		monthlyRate = 0
+	}
+
+	fixedPrincipal := money.Round(principal / float64(count))
+	fixedInstallment := fixedPrincipal
+	if amortizationType == AmortizationFrench && monthlyRate > 0 {
+		fixedInstallment = money.Round(principal * monthlyRate / (1 - math.Pow(1+monthlyRate, -float64(count))))
+	}
+
+	schedule := make([]Entry, 0, count)
+	remaining := principal
+
+	for i := 1; i <= count; i++ {
+		interest := money.Round(remaining * monthlyRate)
+
+		var amortization float64
+		switch amortizationType {
+		case AmortizationFrench:
+			amortization = money.Round(fixedInstallment - interest)
+		default:
+			amortization = fixedPrincipal
+		}
+		if i == count || amortization > remaining {
+			amortization = money.Round(remaining)
+		}
+
+		remaining = money.Round(remaining - amortization)
+
+		schedule = append(schedule, Entry{
+			Number:             i,
+			DueDate:            savings.AddPeriods(savings.FrequencyMonthly, firstDueDate, firstDueDate.Day(), i-1),
+			Amount:             money.Round(amortization + interest),
+			Principal:          amortization,
+			Interest:           interest,
+			RemainingPrincipal: remaining,
+		})
+	}
+
+	return schedule
+}
+
+// Total suma todas las cuotas del plan (capital + intereses)
+func Total(schedule []Entry) float64 {
+	total := 0.0
+	for _, e := range schedule {
+		total += e.Amount
+	}
+	return money.Round(total)
+}
@@ -0,0 +1,68 @@
+package investments
+
+import (
+	"time"
+
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/money"
+)
+
+// FixedTerm es un plazo fijo: interés simple sobre el capital con TNA, base 365 días
+type FixedTerm struct {
+	Principal    float64
+	AnnualRate   float64 // TNA en %
+	StartDate    time.Time
+	MaturityDate time.Time
+}
+
+// MaturityFromTerm calcula el vencimiento a partir del plazo en días (ej: 30, 60, 90)
+func MaturityFromTerm(startDate time.Time, termDays int) time.Time {
+	return truncateDay(startDate).AddDate(0, 0, termDays)
+}
+
+// TermDays es el plazo en días
+func (f FixedTerm) TermDays() int {
+	return daysBetween(f.StartDate, f.MaturityDate)
+}
+
+// InterestAtMaturity es el interés que se cobra al vencimiento
+func (f FixedTerm) InterestAtMaturity() float64 {
+	return f.interestFor(f.TermDays())
+}
+
+// AccruedInterest es el interés devengado a asOf (sin pasar del vencimiento)
+func (f FixedTerm) AccruedInterest(asOf time.Time) float64 {
+	days := daysBetween(f.StartDate, asOf)
+	if days < 0 {
+		days = 0
+	}
+	if term := f.TermDays(); days > term {
+		days = term
+	}
+	return f.interestFor(days)
+}
+
+// DaysRemaining son los días que faltan para el vencimiento (0 si ya venció)
+func (f FixedTerm) DaysRemaining(asOf time.Time) int {
+	days := daysBetween(asOf, f.MaturityDate)
+	if days < 0 {
+		return 0
+	}
+	return days
+}
+
+// IsMatured indica si el plazo fijo ya venció a asOf
+func (f FixedTerm) IsMatured(asOf time.Time) bool {
+	return !truncateDay(asOf).Before(truncateDay(f.MaturityDate))
+}
+
+func (f FixedTerm) interestFor(days int) float64 {
+	return money.Round(f.Principal * f.AnnualRate / 100 * float64(days) / 365)
+}
+
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func daysBetween(from, to time.Time) int {
+	return int(truncateDay(to).Sub(truncateDay(from)).Hours() / 24)
+}
@@ -0,0 +1,82 @@
+package investments
+
+import (
+	"errors"
+
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/money"
+)
+
+// Tipos de instrumento (ENUM investment_instrument_type)
+const (
+	InstrumentFixedTerm  = "fixed_term"
+	InstrumentMutualFund = "mutual_fund"
+	InstrumentCrypto     = "crypto"
+	InstrumentStock      = "stock"
+	InstrumentBond       = "bond"
+	InstrumentOther      = "other"
+)
+
+// Tipos de movimiento (ENUM investment_transaction_type)
+const (
+	TradeBuy  = "buy"
+	TradeSell = "sell"
+)
+
+// ErrInsufficientQuantity se retorna al vender más unidades de las que hay en la posición
+var ErrInsufficientQuantity = errors.New("insufficient quantity")
+
+// Position es la tenencia actual de un instrumento
+type Position struct {
+	Quantity  float64
+	CostBasis float64 // Costo total de las unidades actuales
+}
+
+// Trade es una compra o venta
+type Trade struct {
+	Type     string
+	Quantity float64
+	Price    float64 // Por unidad, en la moneda del instrumento
+	Fees     float64
+}
+
+// TradeResult es la posición resultante de aplicar un Trade
+type TradeResult struct {
+	Position     Position
+	Amount       float64  // Compra: costo total con comisiones. Venta: neto cobrado
+	RealizedGain *float64 // Solo ventas
+}
+
+// Apply aplica una compra o venta con costo promedio ponderado: las compras suman su costo
+// (comisiones incluidas) y las ventas descuentan el costo promedio de las unidades vendidas
+func (p Position) Apply(t Trade) (*TradeResult, error) {
+	gross := t.Quantity * t.Price
+
+	if t.Type == TradeBuy {
+		amount := money.Round(gross + t.Fees)
+		return &TradeResult{
+			Position: Position{Quantity: p.Quantity + t.Quantity, CostBasis: money.Round(p.CostBasis + amount)},
+			Amount:   amount,
+		}, nil
+	}
+
+	if t.Quantity > p.Quantity+1e-9 {
+		return nil, ErrInsufficientQuantity
+	}
+
+	amount := money.Round(gross - t.Fees)
+	result := &TradeResult{Amount: amount}
+
+	if p.Quantity-t.Quantity <= 1e-9 {
+		// Venta total: sale todo el costo (evita residuos por redondeo)
+		gain := money.Round(amount - p.CostBasis)
+		result.RealizedGain = &gain
+		return result, nil
+	}
+
+	soldCost := money.Round(p.CostBasis * t.Quantity / p.Quantity)
+	gain := money.Round(amount - soldCost)
+	result.RealizedGain = &gain
+	result.Position = Position{Quantity: p.Quantity - t.Quantity, CostBasis: money.Round(p.CostBasis - soldCost)}
+
+	return result, nil
+}
@@ -0,0 +1,124 @@
+package investments
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrQuoteNotFound se retorna cuando el proveedor no tiene cotización para el instrumento
+var ErrQuoteNotFound = errors.New("quote not found")
+
+// Quote es una cotización por unidad
+type Quote struct {
+	Price    float64
+	Currency string
+	Date     time.Time
+}
+
+// PriceProvider obtiene cotizaciones de mercado por símbolo
+// FixtureProvider es la implementación local; una fuente real (API de un broker, un exchange
+// cripto, etc.) solo necesita implementar esta interfaz y registrarse en el servidor
+type PriceProvider interface {
+	Name() string
+	Quote(ctx context.Context, instrumentType, symbol, currency string) (*Quote, error)
+}
+
+//go:embed fixtures/prices.json
+var defaultFixtures []byte
+
+// FixtureProvider lee cotizaciones de un archivo JSON (por defecto, fixtures/prices.json embebido)
+// Sirve para desarrollo y para cargar precios de cierre de forma manual en bloque
+type FixtureProvider struct {
+	path string
+
+	once   sync.Once
+	quotes map[string]Quote
+	err    error
+}
+
+type fixtureFile struct {
+	AsOf   string `json:"as_of"` // YYYY-MM-DD; vacío = hoy
+	Quotes []struct {
+		InstrumentType string  `json:"instrument_type"`
+		Symbol         string  `json:"symbol"`
+		Currency       string  `json:"currency"`
+		Price          float64 `json:"price"`
+		Date           string  `json:"date,omitempty"` // Pisa as_of para esta cotización
+	} `json:"quotes"`
+}
+
+// NewFixtureProvider crea el proveedor local. path vacío = fixtures embebidas en el binario
+// El archivo se lee en la primera cotización pedida
+func NewFixtureProvider(path string) *FixtureProvider {
+	return &FixtureProvider{path: path}
+}
+
+// Name identifica al proveedor en investment_prices.source
+func (p *FixtureProvider) Name() string {
+	return "fixture"
+}
+
+// Quote busca la cotización por tipo de instrumento, símbolo (sin distinguir mayúsculas) y moneda
+func (p *FixtureProvider) Quote(ctx context.Context, instrumentType, symbol, currency string) (*Quote, error) {
+	p.once.Do(p.load)
+	if p.err != nil {
+		return nil, p.err
+	}
+
+	quote, ok := p.quotes[fixtureKey(instrumentType, symbol, currency)]
+	if !ok {
+		return nil, ErrQuoteNotFound
+	}
+	return &quote, nil
+}
+
+func (p *FixtureProvider) load() {
+	data := defaultFixtures
+	if p.path != "" {
+		data, p.err = os.ReadFile(p.path)
+		if p.err != nil {
+			return
+		}
+	}
+
+	var file fixtureFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		p.err = fmt.Errorf("invalid price fixtures: %w", err)
+		return
+	}
+
+	asOf := truncateDay(time.Now().UTC())
+	if file.AsOf != "" {
+		asOf, p.err = time.Parse("2006-01-02", file.AsOf)
+		if p.err != nil {
+			return
+		}
+	}
+
+	p.quotes = make(map[string]Quote, len(file.Quotes))
+	for _, q := range file.Quotes {
+		date := asOf
+		if q.Date != "" {
+			date, p.err = time.Parse("2006-01-02", q.Date)
+			if p.err != nil {
+				return
+			}
+		}
+		p.quotes[fixtureKey(q.InstrumentType, q.Symbol, q.Currency)] = Quote{
+			Price:    q.Price,
+			Currency: q.Currency,
+			Date:     date,
+		}
+	}
+}
+
+func fixtureKey(instrumentType, symbol, currency string) string {
+	return instrumentType + "|" + strings.ToUpper(symbol) + "|" + currency
+}
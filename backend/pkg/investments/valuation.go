@@ -0,0 +1,106 @@
+package investments
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/LorenzoCampos/bolsillo-claro/internal/database"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/money"
+)
+
+// MarketValue valúa una tenencia a asOf: un plazo fijo vale capital + interés devengado;
+// el resto, cantidad × último precio. Retorna nil si no hay precio cargado
+func MarketValue(pos Position, price *float64, fixedTerm *FixedTerm, asOf time.Time) *float64 {
+	var value float64
+	switch {
+	case fixedTerm != nil:
+		value = money.Round(fixedTerm.Principal + fixedTerm.AccruedInterest(asOf))
+	case price != nil:
+		value = money.Round(pos.Quantity * *price)
+	default:
+		return nil
+	}
+	return &value
+}
+
+// Valuation es una tenencia valuada a una fecha
+type Valuation struct {
+	HoldingID      string
+	AccountID      string
+	Name           string
+	InstrumentType string
+	Currency       string
+	SavingsGoalID  *string
+	MarketValue    *float64 // nil si no hay precio a esa fecha
+}
+
+// valuationsQuery reconstruye cada tenencia a $2: la cantidad sale de las operaciones hasta esa
+// fecha y el precio del último snapshot <= $2. Los plazos fijos cuentan desde start_date y, si se
+// archivaron (cobrados), hasta el vencimiento
+const valuationsQuery = `
+	SELECT h.id, h.account_id, h.name, h.instrument_type, h.currency, h.savings_goal_id,
+	       COALESCE(t.quantity, 0), h.cost_basis, h.annual_rate, h.start_date, h.maturity_date, p.price
+	FROM investment_holdings h
+	LEFT JOIN LATERAL (
+		SELECT SUM(CASE WHEN transaction_type = 'buy' THEN quantity ELSE -quantity END) AS quantity
+		FROM investment_transactions
+		WHERE holding_id = h.id AND date <= $2
+	) t ON true
+	LEFT JOIN LATERAL (
+		SELECT price FROM investment_prices
+		WHERE holding_id = h.id AND price_date <= $2
+		ORDER BY price_date DESC
+		LIMIT 1
+	) p ON true
+	WHERE %s
+	  AND (
+		(h.instrument_type = 'fixed_term' AND h.start_date <= $2 AND (h.is_active OR h.maturity_date > $2)) OR
+		(h.instrument_type <> 'fixed_term' AND COALESCE(t.quantity, 0) > 0)
+	  )
+	ORDER BY h.created_at
+`
+
+// GoalBackings lista las inversiones activas que respaldan la meta, valuadas a asOf
+func GoalBackings(ctx context.Context, q database.Querier, goalID string, asOf time.Time) ([]Valuation, error) {
+	return valuations(ctx, q, "h.savings_goal_id = $1 AND h.is_active = true", goalID, asOf)
+}
+
+// AccountValuations valúa las tenencias que tenía la cuenta a asOf (para patrimonio e históricos)
+func AccountValuations(ctx context.Context, q database.Querier, accountID interface{}, asOf time.Time) ([]Valuation, error) {
+	return valuations(ctx, q, "h.account_id = $1", accountID, asOf)
+}
+
+func valuations(ctx context.Context, q database.Querier, where string, arg interface{}, asOf time.Time) ([]Valuation, error) {
+	rows, err := q.Query(ctx, fmt.Sprintf(valuationsQuery, where), arg, asOf)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []Valuation{}
+	for rows.Next() {
+		var v Valuation
+		var pos Position
+		var annualRate, price *float64
+		var startDate, maturityDate *time.Time
+
+		err := rows.Scan(
+			&v.HoldingID, &v.AccountID, &v.Name, &v.InstrumentType, &v.Currency, &v.SavingsGoalID,
+			&pos.Quantity, &pos.CostBasis, &annualRate, &startDate, &maturityDate, &price,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		var fixedTerm *FixedTerm
+		if annualRate != nil && startDate != nil && maturityDate != nil {
+			fixedTerm = &FixedTerm{Principal: pos.CostBasis, AnnualRate: *annualRate, StartDate: *startDate, MaturityDate: *maturityDate}
+		}
+		v.MarketValue = MarketValue(pos, price, fixedTerm, asOf)
+
+		result = append(result, v)
+	}
+
+	return result, rows.Err()
+}
@@ -0,0 +1,304 @@
+package listing
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/categories"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/search"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/tags"
+)
+
+// MaxCategoryIDs es la cantidad máxima de categorías en un filtro category_ids
+const MaxCategoryIDs = 50
+
+var (
+	ErrInvalidSortBy     = errors.New("sort_by must be one of: date, amount, created_at, category, member")
+	ErrInvalidCursor     = errors.New("invalid cursor")
+	ErrCursorMismatch    = errors.New("cursor was created with a different sort_by/order")
+	ErrInvalidAmounts    = errors.New("amount_min must be less than or equal to amount_max")
+	ErrTooManyCategories = errors.New("category_ids can have at most 50 categories")
+)
+
+// Table describe la tabla de movimientos que se lista y los alias que usa la query
+type Table struct {
+	Name            string // expenses, incomes
+	Alias           string // Alias de la tabla, ej: e
+	TypeColumn      string // expense_type, income_type (también es el nombre del parámetro)
+	CategoryTable   string // Tabla de categorías (para filtrar con subcategorías)
+	CategoryAlias   string // Alias del LEFT JOIN a la tabla de categorías
+	MemberAlias     string // Alias del LEFT JOIN a family_members
+	RecurringColumn string // FK al template recurrente que generó el movimiento
+	DraftColumn     string // Vacío si la tabla no tiene borradores
+	Tags            tags.Link
+}
+
+var (
+	Expenses = Table{
+		Name: "expenses", Alias: "e", TypeColumn: "expense_type",
+		CategoryTable: categories.ExpenseTable, CategoryAlias: "ec", MemberAlias: "fm",
+		RecurringColumn: "recurring_expense_id", DraftColumn: "is_draft", Tags: tags.Expenses,
+	}
+	Incomes = Table{
+		Name: "incomes", Alias: "i", TypeColumn: "income_type",
+		CategoryTable: categories.IncomeTable, CategoryAlias: "ic", MemberAlias: "fm",
+		RecurringColumn: "recurring_income_id", Tags: tags.Incomes,
+	}
+)
+
+// Params son los filtros tal como llegan en la query string de GET /expenses y GET /incomes,
+// y como se guardan en una vista (ver pkg/views)
+type Params struct {
+	DateFrom             string   `form:"date_from" json:"date_from,omitempty"`                                     // YYYY-MM-DD
+	DateTo               string   `form:"date_to" json:"date_to,omitempty"`                                         // YYYY-MM-DD
+	Type                 string   `form:"-" json:"type,omitempty"`                                                  // one-time, recurring (en la query: expense_type / income_type)
+	CategoryID           string   `form:"category_id" json:"category_id,omitempty"`                                 // Categoría exacta
+	CategoryIDs          string   `form:"category_ids" json:"category_ids,omitempty"`                               // Comma-separated: cualquiera de estas categorías
+	IncludeSubcategories bool     `form:"include_subcategories" json:"include_subcategories,omitempty"`             // category_id/category_ids + todas sus subcategorías
+	FamilyMemberID       string   `form:"family_member_id" json:"family_member_id,omitempty"`                       // UUID
+	PaymentMethodID      string   `form:"payment_method_id" json:"payment_method_id,omitempty"`                     // UUID
+	Tags                 string   `form:"tags" json:"tags,omitempty"`                                               // Comma-separated tag names
+	TagsMatch            string   `form:"tags_match" json:"tags_match,omitempty"`                                   // any (default), all
+	AmountMin            *float64 `form:"amount_min" json:"amount_min,omitempty" binding:"omitempty,gte=0"`         // Inclusive, en la moneda del movimiento
+	AmountMax            *float64 `form:"amount_max" json:"amount_max,omitempty" binding:"omitempty,gte=0"`         // Inclusive, en la moneda del movimiento
+	Currency             string   `form:"currency" json:"currency,omitempty" binding:"omitempty,oneof=ARS USD EUR"` // Moneda del movimiento
+	HasRecurringTemplate *bool    `form:"has_recurring_template" json:"has_recurring_template,omitempty"`           // true: generados por un template recurrente, false: cargados a mano
+	Search               string   `form:"search" json:"search,omitempty" binding:"max=200"`                         // Palabras en la descripción
+}
+
+// Filters valida los parámetros y los convierte en filtros para t
+func (p Params) Filters(t Table) (Filters, error) {
+	for _, date := range []struct{ name, value string }{{"date_from", p.DateFrom}, {"date_to", p.DateTo}} {
+		if date.value == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", date.value); err != nil {
+			return Filters{}, errors.New("invalid " + date.name + " format, use YYYY-MM-DD")
+		}
+	}
+	if p.Type != "" && p.Type != "one-time" && p.Type != "recurring" {
+		return Filters{}, errors.New(t.TypeColumn + " must be one-time or recurring")
+	}
+
+	tagNames, matchAllTags, err := tags.ParseFilter(p.Tags, p.TagsMatch)
+	if err != nil {
+		return Filters{}, err
+	}
+	categoryIDs, err := ParseCategoryIDs(p.CategoryID, p.CategoryIDs)
+	if err != nil {
+		return Filters{}, err
+	}
+
+	f := Filters{
+		DateFrom:             p.DateFrom,
+		DateTo:               p.DateTo,
+		Type:                 p.Type,
+		FamilyMemberID:       p.FamilyMemberID,
+		PaymentMethodID:      p.PaymentMethodID,
+		Tags:                 tagNames,
+		MatchAllTags:         matchAllTags,
+		AmountMin:            p.AmountMin,
+		AmountMax:            p.AmountMax,
+		CategoryIDs:          categoryIDs,
+		IncludeSubcategories: p.IncludeSubcategories,
+		Currency:             p.Currency,
+		HasRecurringTemplate: p.HasRecurringTemplate,
+		Search:               p.Search,
+	}
+	if err := f.Validate(); err != nil {
+		return Filters{}, err
+	}
+	return f, nil
+}
+
+// Filters son los filtros ya validados de un listado de gastos o ingresos
+type Filters struct {
+	DateFrom             string // YYYY-MM-DD, inclusive
+	DateTo               string // YYYY-MM-DD, inclusive
+	Type                 string // one-time, recurring
+	FamilyMemberID       string
+	PaymentMethodID      string
+	Tags                 []string // Normalizados
+	MatchAllTags         bool     // true: todos los tags, false: alcanza con uno
+	AmountMin            *float64 // Inclusive, en la moneda del movimiento
+	AmountMax            *float64 // Inclusive, en la moneda del movimiento
+	CategoryIDs          []string // Cualquiera de estas categorías
+	IncludeSubcategories bool     // CategoryIDs + todas sus subcategorías
+	Currency             string
+	HasRecurringTemplate *bool  // true: generados por un template recurrente, false: cargados a mano
+	Search               string // Texto en la descripción (búsqueda de texto completo, ver pkg/search)
+}
+
+// ParseCategoryIDs junta category_id (una) y category_ids (separadas por coma) sin repetidas
+func ParseCategoryIDs(categoryID, categoryIDs string) ([]string, error) {
+	ids := []string{}
+	seen := map[string]bool{}
+	for _, id := range append([]string{categoryID}, strings.Split(categoryIDs, ",")...) {
+		id = strings.TrimSpace(id)
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	if len(ids) > MaxCategoryIDs {
+		return nil, ErrTooManyCategories
+	}
+	return ids, nil
+}
+
+// Validate revisa lo que no se puede validar con los binding tags
+func (f Filters) Validate() error {
+	if f.AmountMin != nil && f.AmountMax != nil && *f.AmountMin > *f.AmountMax {
+		return ErrInvalidAmounts
+	}
+	return nil
+}
+
+// Where agrega las condiciones de los filtros a whereClauses y args, numerando los parámetros desde argIndex
+func (f Filters) Where(t Table, whereClauses []string, args []interface{}, argIndex int) ([]string, []interface{}, int) {
+	column := func(name string) string {
+		return t.Alias + "." + name
+	}
+	add := func(clause string, arg interface{}) {
+		whereClauses = append(whereClauses, strings.ReplaceAll(clause, "?", "$"+strconv.Itoa(argIndex)))
+		args = append(args, arg)
+		argIndex++
+	}
+
+	// Los borradores de comprobantes escaneados solo se ven en /receipts/drafts hasta confirmarlos
+	if t.DraftColumn != "" {
+		whereClauses = append(whereClauses, "NOT "+column(t.DraftColumn))
+	}
+
+	if f.DateFrom != "" {
+		add(column("date")+" >= ?", f.DateFrom)
+	}
+	if f.DateTo != "" {
+		add(column("date")+" <= ?", f.DateTo)
+	}
+	if f.Type != "" {
+		add(column(t.TypeColumn)+" = ?", f.Type)
+	}
+	if f.FamilyMemberID != "" {
+		add(column("family_member_id")+" = ?", f.FamilyMemberID)
+	}
+	if f.PaymentMethodID != "" {
+		add(column("payment_method_id")+" = ?", f.PaymentMethodID)
+	}
+	if len(f.Tags) > 0 {
+		whereClauses = append(whereClauses, tags.Filter(t.Tags, column("id"), f.Tags, f.MatchAllTags, argIndex))
+		args = append(args, f.Tags)
+		argIndex++
+	}
+
+	if f.AmountMin != nil {
+		add(column("amount")+" >= ?", *f.AmountMin)
+	}
+	if f.AmountMax != nil {
+		add(column("amount")+" <= ?", *f.AmountMax)
+	}
+
+	if len(f.CategoryIDs) > 0 && f.IncludeSubcategories {
+		add(column("category_id")+" IN ("+categories.SubtreeSQL(t.CategoryTable, "ANY(?::uuid[])")+")", f.CategoryIDs)
+	} else if len(f.CategoryIDs) > 0 {
+		add(column("category_id")+" = ANY(?::uuid[])", f.CategoryIDs)
+	}
+
+	if f.Currency != "" {
+		add(column("currency")+" = ?::currency", f.Currency)
+	}
+
+	if f.HasRecurringTemplate != nil && *f.HasRecurringTemplate {
+		whereClauses = append(whereClauses, column(t.RecurringColumn)+" IS NOT NULL")
+	} else if f.HasRecurringTemplate != nil {
+		whereClauses = append(whereClauses, column(t.RecurringColumn)+" IS NULL")
+	}
+
+	// Misma expresión que los índices de la migración 034
+	if query := search.Words(f.Search); query.HasText() {
+		add("to_tsvector('"+search.Config+"', "+column("description")+") @@ to_tsquery('"+search.Config+"', ?)", query.TSQuery())
+	}
+
+	return whereClauses, args, argIndex
+}
+
+// sortTypes son los órdenes válidos de sort_by, con el tipo para volver a leer el valor guardado en el cursor
+var sortTypes = map[string]string{
+	"date":       "date",
+	"amount":     "numeric",
+	"created_at": "timestamp",
+	"category":   "text",
+	"member":     "text",
+}
+
+// ValidSort indica si sort_by es un orden válido
+func ValidSort(sortBy string) bool {
+	_, ok := sortTypes[sortBy]
+	return ok
+}
+
+// SortExpr devuelve la expresión SQL del orden. Por categoría y miembro se ordena por nombre
+// (los movimientos sin categoría o sin miembro quedan como "")
+func (t Table) SortExpr(sortBy string) string {
+	switch sortBy {
+	case "category":
+		return "COALESCE(" + t.CategoryAlias + ".name, '')"
+	case "member":
+		return "COALESCE(" + t.MemberAlias + ".name, '')"
+	default:
+		return t.Alias + "." + sortBy
+	}
+}
+
+// OrderBy arma el ORDER BY, con el id como desempate para que el orden sea estable (lo necesita el cursor)
+func (t Table) OrderBy(sortBy, order string) string {
+	direction := strings.ToUpper(order)
+	return t.SortExpr(sortBy) + " " + direction + ", " + t.Alias + ".id " + direction
+}
+
+// Cursor es la posición de la última fila de una página (keyset pagination): el valor de la
+// columna de orden y el id. La página siguiente arranca después de esa fila, sin OFFSET
+type Cursor struct {
+	SortBy string `json:"s"`
+	Order  string `json:"o"`
+	Value  string `json:"v"`
+	ID     string `json:"id"`
+}
+
+// Encode serializa el cursor para devolverlo como next_cursor
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor lee un cursor y revisa que sea del mismo orden que el pedido
+func DecodeCursor(encoded, sortBy, order string) (Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	var c Cursor
+	if err := json.Unmarshal(data, &c); err != nil || c.ID == "" || !ValidSort(c.SortBy) {
+		return Cursor{}, ErrInvalidCursor
+	}
+	if c.SortBy != sortBy || c.Order != order {
+		return Cursor{}, ErrCursorMismatch
+	}
+	return c, nil
+}
+
+// After agrega la condición "después del cursor" a whereClauses y args
+func (t Table) After(c Cursor, whereClauses []string, args []interface{}, argIndex int) ([]string, []interface{}, int) {
+	op := "<"
+	if c.Order == "asc" {
+		op = ">"
+	}
+	clause := "(" + t.SortExpr(c.SortBy) + ", " + t.Alias + ".id) " + op +
+		" ($" + strconv.Itoa(argIndex) + "::" + sortTypes[c.SortBy] + ", $" + strconv.Itoa(argIndex+1) + "::uuid)"
+	return append(whereClauses, clause), append(args, c.Value, c.ID), argIndex + 2
+}
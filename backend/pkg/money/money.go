@@ -0,0 +1,8 @@
+package money
+
+import "math"
+
+// Round redondea un monto a centavos
+func Round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,215 @@
+package receipts
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Receipt son los datos que se pudieron leer del comprobante (nil = no encontrado)
+type Receipt struct {
+	Merchant *string  `json:"merchant"`
+	Date     *string  `json:"date"` // YYYY-MM-DD
+	Total    *float64 `json:"total"`
+	Currency *string  `json:"currency"` // ARS, USD, EUR
+}
+
+var (
+	isoDate    = regexp.MustCompile(`\b(\d{4})-(\d{1,2})-(\d{1,2})\b`)
+	dayFirst   = regexp.MustCompile(`\b(\d{1,2})[/\-.](\d{1,2})[/\-.](\d{4}|\d{2})\b`)
+	amountExpr = regexp.MustCompile(`\d[\d.,]*\d|\d`)
+
+	totalLine    = regexp.MustCompile(`(?i)\b(total|importe|a pagar|monto)\b`)
+	notTotalLine = regexp.MustCompile(`(?i)sub\s*-?\s*total|\b(iva|impuesto|descuento|items?|art[ií]culos|unidades|cant)\b`)
+
+	// Líneas del encabezado que no son el nombre del comercio
+	headerNoise = regexp.MustCompile(`(?i)\b(ticket|factura|comprobante|recibo|cuit|c\.u\.i\.t|iva|ingresos brutos|ing\.? ?brutos|fecha|hora|tel|tel[eé]fono|direcci[oó]n|domicilio|caja|cajero|nro|original|duplicado|consumidor final|responsable|inicio de actividades)\b`)
+
+	usdExpr = regexp.MustCompile(`(?i)U\$S|US\$|\bUSD\b|d[oó]lares`)
+	eurExpr = regexp.MustCompile(`(?i)€|\bEUR\b|\beuros?\b`)
+	arsExpr = regexp.MustCompile(`(?i)\$|\bARS\b|\bpesos\b`)
+)
+
+// Parse busca comercio, fecha, total y moneda en el texto de un comprobante
+// Pensado para tickets y facturas argentinas: fechas día/mes/año y montos como 1.234,56
+// now descarta fechas futuras (suelen ser vencimientos o errores de lectura)
+func Parse(text string, now time.Time) Receipt {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	var r Receipt
+	r.Merchant = parseMerchant(lines)
+	r.Date = parseDate(lines, now)
+	total, totalLineText := parseTotal(lines)
+	r.Total = total
+	r.Currency = parseCurrency(totalLineText)
+	if r.Currency == nil {
+		r.Currency = parseCurrency(text)
+	}
+	return r
+}
+
+// parseMerchant toma la primera línea del encabezado que parece un nombre
+func parseMerchant(lines []string) *string {
+	for i, line := range lines {
+		if i >= 6 {
+			break
+		}
+		letters := 0
+		for _, r := range line {
+			if unicode.IsLetter(r) {
+				letters++
+			}
+		}
+		if letters < 3 || letters*2 < len([]rune(line)) || headerNoise.MatchString(line) {
+			continue
+		}
+
+		merchant := strings.Join(strings.Fields(strings.Trim(line, " -*=.:_|")), " ")
+		if len([]rune(merchant)) > 100 {
+			merchant = string([]rune(merchant)[:100])
+		}
+		return &merchant
+	}
+	return nil
+}
+
+// parseDate prefiere una fecha en una línea que diga "fecha"; si no, la primera válida
+func parseDate(lines []string, now time.Time) *string {
+	var first *string
+	for _, line := range lines {
+		for _, date := range datesIn(line, now) {
+			if strings.Contains(strings.ToLower(line), "fecha") {
+				return &date
+			}
+			if first == nil {
+				d := date
+				first = &d
+			}
+		}
+	}
+	return first
+}
+
+func datesIn(line string, now time.Time) []string {
+	var dates []string
+	add := func(year, month, day int) {
+		if year < 100 {
+			year += 2000
+		}
+		t := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+		// time.Date normaliza 31/02 a marzo: si cambió, no era una fecha
+		if t.Day() != day || int(t.Month()) != month || year < 2000 || t.After(now.AddDate(0, 0, 1)) {
+			return
+		}
+		dates = append(dates, t.Format("2006-01-02"))
+	}
+
+	for _, m := range isoDate.FindAllStringSubmatch(line, -1) {
+		add(atoi(m[1]), atoi(m[2]), atoi(m[3]))
+	}
+	for _, m := range dayFirst.FindAllStringSubmatch(line, -1) {
+		add(atoi(m[3]), atoi(m[2]), atoi(m[1]))
+	}
+	return dates
+}
+
+// parseTotal toma el mayor monto de las líneas de total (el total es mayor que el IVA o el
+// redondeo que a veces aparecen en líneas parecidas). Si la línea dice TOTAL pero el monto
+// quedó en la siguiente (pasa con el OCR de tickets anchos), usa esa
+func parseTotal(lines []string) (*float64, string) {
+	var best *float64
+	var bestLine string
+	for i, line := range lines {
+		if !totalLine.MatchString(line) || notTotalLine.MatchString(line) {
+			continue
+		}
+		amount, ok := lastAmount(line)
+		source := line
+		if !ok && i+1 < len(lines) {
+			amount, ok = lastAmount(lines[i+1])
+			source = lines[i+1]
+		}
+		if ok && (best == nil || amount > *best) {
+			a := amount
+			best, bestLine = &a, source
+		}
+	}
+	return best, bestLine
+}
+
+func lastAmount(line string) (float64, bool) {
+	// Sin fechas ni CUITs, que también son dígitos con separadores
+	line = isoDate.ReplaceAllString(line, " ")
+	line = dayFirst.ReplaceAllString(line, " ")
+	tokens := amountExpr.FindAllString(line, -1)
+	for i := len(tokens) - 1; i >= 0; i-- {
+		if amount, ok := ParseAmount(tokens[i]); ok {
+			return amount, true
+		}
+	}
+	return 0, false
+}
+
+// ParseAmount lee un monto escrito como 1.234,56 / 1,234.56 / 1234,5 / 12.500
+// Con un solo tipo de separador, 3 dígitos después son miles y 1 o 2 son decimales
+func ParseAmount(s string) (float64, bool) {
+	s = strings.TrimSpace(s)
+	lastDot, lastComma := strings.LastIndexByte(s, '.'), strings.LastIndexByte(s, ',')
+
+	decimal := -1
+	switch {
+	case lastDot >= 0 && lastComma >= 0:
+		decimal = max(lastDot, lastComma)
+	case lastDot >= 0 || lastComma >= 0:
+		sep := max(lastDot, lastComma)
+		if digits := len(s) - sep - 1; digits == 1 || digits == 2 {
+			decimal = sep
+		}
+	}
+
+	var b strings.Builder
+	for i, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case i == decimal:
+			b.WriteByte('.')
+		}
+	}
+
+	amount, err := strconv.ParseFloat(b.String(), 64)
+	if err != nil || amount <= 0 || amount >= 1e12 {
+		return 0, false
+	}
+	return amount, true
+}
+
+// parseCurrency busca la moneda; USD y EUR primero porque "US$" también tiene "$"
+func parseCurrency(text string) *string {
+	var currency string
+	switch {
+	case text == "":
+		return nil
+	case usdExpr.MatchString(text):
+		currency = "USD"
+	case eurExpr.MatchString(text):
+		currency = "EUR"
+	case arsExpr.MatchString(text):
+		currency = "ARS"
+	default:
+		return nil
+	}
+	return &currency
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
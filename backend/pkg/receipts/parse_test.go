@@ -0,0 +1,133 @@
+package receipts
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		in   string
+		want float64
+		ok   bool
+	}{
+		{"1.234,56", 1234.56, true},
+		{"1,234.56", 1234.56, true},
+		{"1234,5", 1234.5, true},
+		{"1234.50", 1234.5, true},
+		{"12.500", 12500, true}, // Un solo separador con 3 dígitos: miles
+		{"12,500", 12500, true},
+		{"1.234.567", 1234567, true},
+		{"1.234.567,89", 1234567.89, true},
+		{"980", 980, true},
+		{"0,00", 0, false},
+		{"", 0, false},
+		{"abc", 0, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := ParseAmount(tt.in)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("ParseAmount(%q) = %v, %v; want %v, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		text     string
+		merchant string // "" = nil
+		date     string
+		total    float64 // 0 = nil
+		currency string
+	}{
+		{
+			name:     "sample receipt",
+			text:     SampleReceipt,
+			merchant: "SUPERMERCADO EL TREBOL",
+			date:     "2026-03-08",
+			total:    2230.50,
+			currency: "ARS",
+		},
+		{
+			name:     "total on the next line",
+			text:     "FARMACIA CENTRAL\n05/03/2026\nTOTAL\n$ 15.320,00\n",
+			merchant: "FARMACIA CENTRAL",
+			date:     "2026-03-05",
+			total:    15320,
+			currency: "ARS",
+		},
+		{
+			name:     "dollars with US format",
+			text:     "DUTY FREE SHOP\n2026-02-28\nIMPORTE TOTAL U$S 1,234.56\n",
+			merchant: "DUTY FREE SHOP",
+			date:     "2026-02-28",
+			total:    1234.56,
+			currency: "USD",
+		},
+		{
+			name:     "subtotal and IVA are not the total",
+			text:     "LIBRERIA SUR\nSUBTOTAL 9.000,00\nIVA 21% 1.890,00\nTOTAL 10.890,00\n",
+			merchant: "LIBRERIA SUR",
+			total:    10890,
+		},
+		{
+			name:     "fecha line wins over other dates",
+			text:     "BAR EL PUERTO\nVto. CAE 01/03/2026\nFecha: 02/03/26\nTotal 4.500\n",
+			merchant: "BAR EL PUERTO",
+			date:     "2026-03-02",
+			total:    4500,
+		},
+		{
+			name:     "future and invalid dates are discarded",
+			text:     "KIOSCO 24HS\n31/02/2026\n15/04/2026\nTOTAL € 12,50\n",
+			merchant: "KIOSCO 24HS",
+			total:    12.50,
+			currency: "EUR",
+		},
+		{
+			name:     "header noise is not the merchant",
+			text:     "CUIT 30-12345678-9\nTICKET NRO 0001-00001234\nPANADERIA LA ESPIGA\nTOTAL $ 3.200\n",
+			merchant: "PANADERIA LA ESPIGA",
+			total:    3200,
+			currency: "ARS",
+		},
+		{
+			name: "nothing found",
+			text: "12345\n-----\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := Parse(tt.text, now)
+
+			if got := deref(r.Merchant); got != tt.merchant {
+				t.Errorf("merchant = %q, want %q", got, tt.merchant)
+			}
+			if got := deref(r.Date); got != tt.date {
+				t.Errorf("date = %q, want %q", got, tt.date)
+			}
+			if got := deref(r.Currency); got != tt.currency {
+				t.Errorf("currency = %q, want %q", got, tt.currency)
+			}
+			var total float64
+			if r.Total != nil {
+				total = *r.Total
+			}
+			if total != tt.total {
+				t.Errorf("total = %v, want %v", total, tt.total)
+			}
+		})
+	}
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
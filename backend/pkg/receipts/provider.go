@@ -0,0 +1,141 @@
+package receipts
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+var (
+	ErrOCRUnavailable    = errors.New("OCR is not available on this server (tesseract is not installed)")
+	ErrUnsupportedFormat = errors.New("OCR can't read this file type: use JPEG, PNG, WEBP or a PDF with text")
+)
+
+// OCRProvider extrae el texto de un comprobante (imagen o PDF)
+// TesseractProvider es la implementación local y FakeProvider la de tests; un servicio externo
+// (Google Vision, Textract, ...) solo necesita implementar esta interfaz y registrarse en el servidor
+type OCRProvider interface {
+	Name() string // Se guarda en attachments.ocr_provider
+	Text(ctx context.Context, data []byte, contentType string) (string, error)
+}
+
+// ocrTimeout limita cuánto puede tardar el comando externo
+const ocrTimeout = 60 * time.Second
+
+// TesseractProvider ejecuta tesseract (imágenes) y pdftotext (PDFs con texto) si están instalados
+// En Debian/Ubuntu: apt install tesseract-ocr tesseract-ocr-spa poppler-utils
+type TesseractProvider struct {
+	tesseract string // Ruta del binario, vacío si no está instalado
+	pdftotext string
+	languages string
+}
+
+// NewTesseractProvider busca los binarios en el PATH. languages en formato de tesseract ("spa+eng")
+func NewTesseractProvider(languages string) *TesseractProvider {
+	if languages == "" {
+		languages = "spa+eng"
+	}
+	p := &TesseractProvider{languages: languages}
+	p.tesseract, _ = exec.LookPath("tesseract")
+	p.pdftotext, _ = exec.LookPath("pdftotext")
+	return p
+}
+
+func (p *TesseractProvider) Name() string {
+	return "tesseract"
+}
+
+// Available indica si tesseract está instalado
+func (p *TesseractProvider) Available() bool {
+	return p.tesseract != ""
+}
+
+func (p *TesseractProvider) Text(ctx context.Context, data []byte, contentType string) (string, error) {
+	switch contentType {
+	case "image/jpeg", "image/png", "image/webp":
+		if p.tesseract == "" {
+			return "", ErrOCRUnavailable
+		}
+		// --psm 4: una columna de texto de tamaños variables, como un ticket
+		return p.run(ctx, data, p.tesseract, "{file}", "stdout", "-l", p.languages, "--psm", "4")
+	case "application/pdf":
+		// Las facturas electrónicas son PDFs con texto: no hace falta OCR
+		if p.pdftotext == "" {
+			return "", ErrUnsupportedFormat
+		}
+		return p.run(ctx, data, p.pdftotext, "-layout", "{file}", "-")
+	default:
+		return "", ErrUnsupportedFormat
+	}
+}
+
+// run escribe data a un archivo temporal y ejecuta el comando reemplazando {file} por su ruta
+func (p *TesseractProvider) run(ctx context.Context, data []byte, name string, args ...string) (string, error) {
+	tmp, err := os.CreateTemp("", "receipt-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+
+	for i, arg := range args {
+		if arg == "{file}" {
+			args[i] = tmp.Name()
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, ocrTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("%s: %w: %s", name, err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+// FakeProvider devuelve siempre el mismo texto sin leer el archivo
+// Sirve para tests y para probar el flujo completo sin Tesseract (OCR_PROVIDER=fake)
+type FakeProvider struct {
+	Output string // Vacío = SampleReceipt
+	Err    error
+}
+
+// SampleReceipt es un ticket de ejemplo con todos los campos que reconoce Parse
+const SampleReceipt = `SUPERMERCADO EL TREBOL
+CUIT 30-12345678-9
+Av. Siempreviva 742
+Fecha: 08/03/2026 Hora: 18:42
+ARROZ 1KG            $ 1.250,00
+LECHE ENTERA         $ 980,50
+SUBTOTAL             $ 2.230,50
+TOTAL                $ 2.230,50
+`
+
+func (p *FakeProvider) Name() string {
+	return "fake"
+}
+
+func (p *FakeProvider) Text(ctx context.Context, data []byte, contentType string) (string, error) {
+	if p.Err != nil {
+		return "", p.Err
+	}
+	if p.Output == "" {
+		return SampleReceipt, nil
+	}
+	return p.Output, nil
+}
@@ -0,0 +1,39 @@
+package receipts
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestFakeProvider(t *testing.T) {
+	ctx := context.Background()
+
+	text, err := (&FakeProvider{}).Text(ctx, nil, "image/jpeg")
+	if err != nil || text != SampleReceipt {
+		t.Fatalf("empty Output should return SampleReceipt, got %q, %v", text, err)
+	}
+
+	text, err = (&FakeProvider{Output: "OTRO\nTOTAL 10"}).Text(ctx, nil, "application/pdf")
+	if err != nil || text != "OTRO\nTOTAL 10" {
+		t.Fatalf("Output should be returned as is, got %q, %v", text, err)
+	}
+
+	_, err = (&FakeProvider{Err: ErrOCRUnavailable}).Text(ctx, nil, "image/png")
+	if err != ErrOCRUnavailable {
+		t.Fatalf("Err should be returned, got %v", err)
+	}
+}
+
+// The fake output goes through Parse like a real OCR result: the draft gets every field
+func TestFakeProviderParse(t *testing.T) {
+	text, err := (&FakeProvider{}).Text(context.Background(), nil, "image/jpeg")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := Parse(text, time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC))
+	if r.Merchant == nil || r.Date == nil || r.Total == nil || r.Currency == nil {
+		t.Fatalf("sample receipt should have every field, got %+v", r)
+	}
+}
@@ -0,0 +1,174 @@
+package rules
+
+import (
+	"context"
+	"strings"
+
+	"github.com/LorenzoCampos/bolsillo-claro/internal/database"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/tags"
+	"github.com/jackc/pgx/v5"
+)
+
+// Tipos de movimiento a los que aplica una regla (columna movement_type)
+const (
+	Expense = "expense"
+	Income  = "income"
+)
+
+// Rule es una regla de auto-categorización: si el movimiento cumple todas las condiciones
+// (las que no son nil), se le aplican las acciones
+type Rule struct {
+	ID           string
+	Name         string
+	MovementType string
+	Priority     int
+
+	// Condiciones
+	DescriptionContains *string
+	AmountMin           *float64
+	AmountMax           *float64
+	Currency            *string
+	PaymentMethodID     *string
+
+	// Acciones
+	CategoryID     *string // De expense_categories o income_categories según MovementType
+	FamilyMemberID *string
+	Tags           []string
+}
+
+// Movement son los campos de un gasto o ingreso que las reglas leen y completan
+type Movement struct {
+	Description     string
+	Amount          float64
+	Currency        string
+	PaymentMethodID *string
+	CategoryID      *string
+	FamilyMemberID  *string
+	Tags            []string
+}
+
+// Columns son las columnas que lee Scan, para usar en un SELECT sobre categorization_rules
+const Columns = `
+	id, name, movement_type, priority,
+	description_contains, amount_min, amount_max, currency::TEXT, payment_method_id,
+	CASE WHEN movement_type = 'expense' THEN set_expense_category_id ELSE set_income_category_id END,
+	set_family_member_id, add_tags`
+
+// Scan lee una regla seleccionada con Columns
+func Scan(row pgx.Row) (*Rule, error) {
+	var r Rule
+	err := row.Scan(
+		&r.ID, &r.Name, &r.MovementType, &r.Priority,
+		&r.DescriptionContains, &r.AmountMin, &r.AmountMax, &r.Currency, &r.PaymentMethodID,
+		&r.CategoryID, &r.FamilyMemberID, &r.Tags,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if r.Tags == nil {
+		r.Tags = []string{}
+	}
+	return &r, nil
+}
+
+// Load devuelve las reglas activas de la cuenta para el tipo de movimiento, en el orden en que se evalúan
+func Load(ctx context.Context, q database.Querier, accountID interface{}, movementType string) ([]Rule, error) {
+	rows, err := q.Query(ctx, `
+		SELECT `+Columns+`
+		FROM categorization_rules
+		WHERE account_id = $1 AND movement_type = $2 AND is_active = TRUE
+		ORDER BY priority ASC, created_at ASC
+	`, accountID, movementType)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []Rule{}
+	for rows.Next() {
+		rule, err := Scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *rule)
+	}
+	return result, rows.Err()
+}
+
+// Matches indica si el movimiento cumple todas las condiciones de la regla
+func (r Rule) Matches(m Movement) bool {
+	if r.DescriptionContains != nil && !strings.Contains(strings.ToLower(m.Description), strings.ToLower(*r.DescriptionContains)) {
+		return false
+	}
+	if r.AmountMin != nil && m.Amount < *r.AmountMin {
+		return false
+	}
+	if r.AmountMax != nil && m.Amount > *r.AmountMax {
+		return false
+	}
+	if r.Currency != nil && m.Currency != *r.Currency {
+		return false
+	}
+	if r.PaymentMethodID != nil && (m.PaymentMethodID == nil || *m.PaymentMethodID != *r.PaymentMethodID) {
+		return false
+	}
+	return true
+}
+
+// Apply aplica las acciones de la regla al movimiento (sin mirar las condiciones)
+// Sin overwrite solo completa la categoría y el miembro si están vacíos: lo que cargó el usuario o
+// puso una regla de mayor prioridad se respeta. Los tags siempre se suman (hasta tags.MaxPerItem)
+// Devuelve si cambió algo
+func (r Rule) Apply(m *Movement, overwrite bool) bool {
+	changed := false
+	if r.CategoryID != nil && (m.CategoryID == nil || (overwrite && *m.CategoryID != *r.CategoryID)) {
+		id := *r.CategoryID
+		m.CategoryID = &id
+		changed = true
+	}
+	if r.FamilyMemberID != nil && (m.FamilyMemberID == nil || (overwrite && *m.FamilyMemberID != *r.FamilyMemberID)) {
+		id := *r.FamilyMemberID
+		m.FamilyMemberID = &id
+		changed = true
+	}
+	for _, tag := range r.Tags {
+		if len(m.Tags) >= tags.MaxPerItem {
+			break
+		}
+		if !contains(m.Tags, tag) {
+			m.Tags = append(m.Tags, tag)
+			changed = true
+		}
+	}
+	return changed
+}
+
+// Evaluate corre las reglas en orden sobre el movimiento y devuelve los ids de las que lo cambiaron
+func Evaluate(rules []Rule, m *Movement) []string {
+	applied := []string{}
+	for _, rule := range rules {
+		if rule.Matches(*m) && rule.Apply(m, false) {
+			applied = append(applied, rule.ID)
+		}
+	}
+	return applied
+}
+
+// Categorize carga las reglas activas de la cuenta y las aplica al movimiento antes de guardarlo
+// Es el punto de entrada para todo lo que crea movimientos (API, scheduler, importaciones)
+func Categorize(ctx context.Context, q database.Querier, accountID interface{}, movementType string, m *Movement) ([]string, error) {
+	rules, err := Load(ctx, q, accountID, movementType)
+	if err != nil {
+		return nil, err
+	}
+	return Evaluate(rules, m), nil
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,102 @@
+package savings
+
+import (
+	"context"
+	"errors"
+
+	"github.com/LorenzoCampos/bolsillo-claro/internal/database"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/money"
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrRateNotFound se retorna cuando no hay tasa en exchange_rates y no se informó una manual
+var ErrRateNotFound = errors.New("exchange rate not found")
+
+// Source es lo que informa el usuario (o el CRON) al mover dinero a/desde una meta
+// Sigue el "Modo 3" de expenses: amount_in_goal_currency > exchange_rate > tasa de exchange_rates
+type Source struct {
+	Amount               float64 // En Currency
+	Currency             string  // Vacío = moneda de la meta
+	AmountInGoalCurrency *float64
+	ExchangeRate         *float64 // Currency → moneda de la meta
+}
+
+// LookupRate busca la última tasa from → to con rate_date <= date en exchange_rates
+// Si solo está cargada la inversa (ej: USD → ARS para ARS → USD), usa 1/rate
+func LookupRate(ctx context.Context, q database.Querier, from, to, date string) (float64, error) {
+	if from == to {
+		return 1, nil
+	}
+
+	var rate float64
+	err := q.QueryRow(ctx, `
+		SELECT rate FROM (
+			SELECT rate, rate_date, 0 AS inverse FROM exchange_rates
+			WHERE from_currency = $1 AND to_currency = $2 AND rate_date <= $3
+			UNION ALL
+			SELECT 1 / rate, rate_date, 1 AS inverse FROM exchange_rates
+			WHERE from_currency = $2 AND to_currency = $1 AND rate_date <= $3
+		) rates
+		ORDER BY rate_date DESC, inverse ASC
+		LIMIT 1
+	`, from, to, date).Scan(&rate)
+	if err == pgx.ErrNoRows {
+		return 0, ErrRateNotFound
+	}
+	return rate, err
+}
+
+// ResolveMovement calcula el monto en la moneda de la meta y en la moneda principal de la cuenta
+// Retorna ErrRateNotFound si no se puede llegar a la moneda de la meta; si solo falta la tasa
+// hacia la moneda principal, AmountInPrimaryCurrency queda en nil
+func ResolveMovement(ctx context.Context, q database.Querier, transactionType, goalCurrency, primaryCurrency string, src Source, date string) (*Movement, error) {
+	m := &Movement{
+		TransactionType: transactionType,
+		SourceCurrency:  src.Currency,
+		SourceAmount:    src.Amount,
+		Date:            date,
+	}
+	if m.SourceCurrency == "" {
+		m.SourceCurrency = goalCurrency
+	}
+
+	switch {
+	case m.SourceCurrency == goalCurrency:
+		m.ExchangeRate = 1
+		m.Amount = src.Amount
+	case src.AmountInGoalCurrency != nil:
+		// Modo 1: el usuario informó cuánto entró/salió en la moneda de la meta
+		m.Amount = *src.AmountInGoalCurrency
+		m.ExchangeRate = m.Amount / src.Amount
+	case src.ExchangeRate != nil:
+		// Modo 2: el usuario informó la tasa
+		m.ExchangeRate = *src.ExchangeRate
+		m.Amount = money.Round(src.Amount * m.ExchangeRate)
+	default:
+		rate, err := LookupRate(ctx, q, m.SourceCurrency, goalCurrency, date)
+		if err != nil {
+			return nil, err
+		}
+		m.ExchangeRate = rate
+		m.Amount = money.Round(src.Amount * rate)
+	}
+
+	switch {
+	case goalCurrency == primaryCurrency:
+		m.AmountInPrimaryCurrency = &m.Amount
+	case m.SourceCurrency == primaryCurrency:
+		// Lo que realmente salió/entró de la cuenta (ej: los pesos gastados en dólares)
+		m.AmountInPrimaryCurrency = &m.SourceAmount
+	default:
+		rate, err := LookupRate(ctx, q, goalCurrency, primaryCurrency, date)
+		if err != nil && err != ErrRateNotFound {
+			return nil, err
+		}
+		if err == nil {
+			amount := money.Round(m.Amount * rate)
+			m.AmountInPrimaryCurrency = &amount
+		}
+	}
+
+	return m, nil
+}
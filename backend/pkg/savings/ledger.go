@@ -0,0 +1,166 @@
+package savings
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/LorenzoCampos/bolsillo-claro/internal/database"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrGoalNotFound se retorna cuando la meta no existe o no pertenece a la cuenta
+var ErrGoalNotFound = errors.New("savings goal not found")
+
+// ErrInsufficientFunds se retorna al retirar más que current_amount
+var ErrInsufficientFunds = errors.New("insufficient funds in savings goal")
+
+// Movement es un depósito o retiro listo para registrar (ver ResolveMovement)
+type Movement struct {
+	TransactionType         string  // deposit, withdrawal
+	Amount                  float64 // En la moneda de la meta
+	SourceCurrency          string
+	SourceAmount            float64
+	ExchangeRate            float64  // source_currency → moneda de la meta
+	AmountInPrimaryCurrency *float64 // nil si no hay tasa hacia la moneda principal
+	Description             *string
+	Date                    string
+	ContributionRuleID      *string // nil para los movimientos manuales
+}
+
+// Goal son los datos de la meta que devuelve Record, tomados dentro de la transacción
+type Goal struct {
+	Name          string
+	CurrentAmount float64
+	TargetAmount  float64
+	AchievedAt    *time.Time
+	UpdatedAt     time.Time
+}
+
+// RecordResult es el movimiento creado por Record
+type RecordResult struct {
+	TransactionID uuid.UUID
+	CreatedAt     time.Time
+	Goal          Goal
+	Events        []Event // Hitos cruzados / meta completada por este movimiento
+}
+
+// Record registra un movimiento en savings_goal_transactions, actualiza current_amount de la meta
+// y sincroniza hitos y achieved_at (SyncProgress)
+// Lo usan add-funds, withdraw-funds y el CRON de aportes automáticos, siempre dentro de tx
+func Record(ctx context.Context, tx pgx.Tx, goalID string, accountID interface{}, m Movement) (*RecordResult, error) {
+	var result RecordResult
+
+	// FOR UPDATE: evita que dos movimientos simultáneos pisen current_amount
+	err := tx.QueryRow(ctx,
+		`SELECT name, current_amount, target_amount FROM savings_goals WHERE id = $1 AND account_id = $2 FOR UPDATE`,
+		goalID, accountID,
+	).Scan(&result.Goal.Name, &result.Goal.CurrentAmount, &result.Goal.TargetAmount)
+	if err == pgx.ErrNoRows {
+		return nil, ErrGoalNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	newAmount := result.Goal.CurrentAmount + m.Amount
+	if m.TransactionType == "withdrawal" {
+		if m.Amount > result.Goal.CurrentAmount {
+			return &result, ErrInsufficientFunds
+		}
+		newAmount = result.Goal.CurrentAmount - m.Amount
+	}
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO savings_goal_transactions (
+			savings_goal_id, amount, transaction_type, description, date, contribution_rule_id,
+			source_currency, source_amount, exchange_rate, amount_in_primary_currency
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, created_at
+	`,
+		goalID, m.Amount, m.TransactionType, m.Description, m.Date, m.ContributionRuleID,
+		m.SourceCurrency, m.SourceAmount, m.ExchangeRate, m.AmountInPrimaryCurrency,
+	).Scan(&result.TransactionID, &result.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.QueryRow(ctx, `
+		UPDATE savings_goals
+		SET current_amount = $1, updated_at = NOW()
+		WHERE id = $2
+		RETURNING current_amount, updated_at
+	`, newAmount, goalID).Scan(&result.Goal.CurrentAmount, &result.Goal.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	progress, err := SyncProgress(ctx, tx, goalID, &result.TransactionID)
+	if err != nil {
+		return nil, err
+	}
+	result.Goal.AchievedAt = progress.AchievedAt
+	result.Events = progress.Events
+
+	return &result, nil
+}
+
+// AssignedToGoals es el neto movido a metas de ahorro (depósitos - retiros) en un rango de fechas,
+// convertido a la moneda principal de la cuenta
+type AssignedToGoals struct {
+	Total       float64
+	Unconverted int // Movimientos de metas en otra moneda sin tasa en exchange_rates (no incluidos en Total)
+}
+
+// NetAssigned suma el ledger de savings_goal_transactions de todas las metas de la cuenta (activas o no)
+// con fecha entre from (opcional, inclusive) y to (inclusive), usando amount_in_primary_currency
+// Los movimientos sin ese monto se convierten con la última tasa de exchange_rates a su fecha
+func NetAssigned(ctx context.Context, q database.Querier, accountID interface{}, from *time.Time, to time.Time) (*AssignedToGoals, error) {
+	var result AssignedToGoals
+	err := q.QueryRow(ctx, `
+		SELECT
+			COALESCE(SUM(
+				CASE WHEN t.transaction_type = 'deposit' THEN 1 ELSE -1 END
+				* COALESCE(t.amount_in_primary_currency, CASE WHEN sg.currency = a.currency THEN t.amount ELSE t.amount * r.rate END)
+			), 0),
+			COUNT(*) FILTER (WHERE t.amount_in_primary_currency IS NULL AND sg.currency <> a.currency AND r.rate IS NULL)
+		FROM savings_goal_transactions t
+		JOIN savings_goals sg ON sg.id = t.savings_goal_id
+		JOIN accounts a ON a.id = sg.account_id
+		LEFT JOIN LATERAL (
+			SELECT rate FROM exchange_rates
+			WHERE from_currency = sg.currency AND to_currency = a.currency AND rate_date <= t.date
+			ORDER BY rate_date DESC, created_at DESC
+			LIMIT 1
+		) r ON sg.currency <> a.currency AND t.amount_in_primary_currency IS NULL
+		WHERE sg.account_id = $1
+		  AND ($2::date IS NULL OR t.date >= $2)
+		  AND t.date <= $3
+	`, accountID, from, to).Scan(&result.Total, &result.Unconverted)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// AvailableBalance calcula el saldo disponible de la cuenta hasta asOf, en la moneda principal:
+// ingresos - gastos - neto asignado a metas (misma fórmula que el dashboard, pero acumulada)
+func AvailableBalance(ctx context.Context, q database.Querier, accountID interface{}, asOf time.Time) (float64, error) {
+	var flows float64
+	err := q.QueryRow(ctx, `
+		SELECT
+			(SELECT COALESCE(SUM(amount_in_primary_currency), 0) FROM incomes WHERE account_id = $1 AND date <= $2)
+			- (SELECT COALESCE(SUM(amount_in_primary_currency), 0) FROM expenses WHERE account_id = $1 AND NOT is_draft AND date <= $2)
+	`, accountID, asOf).Scan(&flows)
+	if err != nil {
+		return 0, err
+	}
+
+	assigned, err := NetAssigned(ctx, q, accountID, nil, asOf)
+	if err != nil {
+		return 0, err
+	}
+
+	return flows - assigned.Total, nil
+}
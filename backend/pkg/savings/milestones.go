@@ -0,0 +1,146 @@
+package savings
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Tipos de evento (ENUM savings_goal_event_type)
+const (
+	EventMilestoneReached = "milestone_reached"
+	EventGoalAchieved     = "goal_achieved"
+)
+
+// DefaultMilestonePercentages son los hitos que se crean si la meta no define otros
+var DefaultMilestonePercentages = []float64{25, 50, 75, 100}
+
+// Event es un hito cruzado o la meta completada, registrado en savings_goal_events
+type Event struct {
+	ID            string
+	EventType     string
+	MilestoneID   *string
+	MilestoneName *string
+	TransactionID *uuid.UUID
+	CurrentAmount float64
+	OccurredAt    time.Time
+}
+
+// Progress es el resultado de SyncProgress
+type Progress struct {
+	AchievedAt *time.Time
+	Events     []Event
+}
+
+// SyncProgress marca los hitos cruzados y completa (o reabre) la meta según current_amount,
+// registrando un evento por cada hito nuevo y al completarse. Se llama dentro de tx después de
+// cambiar current_amount o target_amount; transactionID es el movimiento que lo provocó (si hay)
+func SyncProgress(ctx context.Context, tx pgx.Tx, goalID string, transactionID *uuid.UUID) (*Progress, error) {
+	var currentAmount, targetAmount float64
+	var achievedAt *time.Time
+	err := tx.QueryRow(ctx,
+		`SELECT current_amount, target_amount, achieved_at FROM savings_goals WHERE id = $1`,
+		goalID,
+	).Scan(&currentAmount, &targetAmount, &achievedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	progress := &Progress{AchievedAt: achievedAt, Events: []Event{}}
+
+	// Hitos cruzados por primera vez (los porcentuales siguen al target_amount actual)
+	rows, err := tx.Query(ctx, `
+		UPDATE savings_goal_milestones
+		SET reached_at = NOW()
+		WHERE savings_goal_id = $1
+		  AND reached_at IS NULL
+		  AND COALESCE(amount, $2 * percentage / 100) <= $3
+		RETURNING id, name, COALESCE(amount, $2 * percentage / 100) AS threshold
+	`, goalID, targetAmount, currentAmount)
+	if err != nil {
+		return nil, err
+	}
+
+	type reached struct {
+		id, name  string
+		threshold float64
+	}
+	var milestones []reached
+	for rows.Next() {
+		var m reached
+		if err := rows.Scan(&m.id, &m.name, &m.threshold); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		milestones = append(milestones, m)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// En orden de monto, para que el historial quede en el orden en que se cruzaron
+	sort.Slice(milestones, func(i, j int) bool {
+		return milestones[i].threshold < milestones[j].threshold
+	})
+
+	for _, m := range milestones {
+		milestoneID, name := m.id, m.name
+		event, err := insertEvent(ctx, tx, goalID, EventMilestoneReached, &milestoneID, &name, transactionID, currentAmount)
+		if err != nil {
+			return nil, err
+		}
+		progress.Events = append(progress.Events, *event)
+	}
+
+	switch {
+	case currentAmount >= targetAmount && achievedAt == nil:
+		err = tx.QueryRow(ctx,
+			`UPDATE savings_goals SET achieved_at = NOW() WHERE id = $1 RETURNING achieved_at`,
+			goalID,
+		).Scan(&progress.AchievedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		event, err := insertEvent(ctx, tx, goalID, EventGoalAchieved, nil, nil, transactionID, currentAmount)
+		if err != nil {
+			return nil, err
+		}
+		progress.Events = append(progress.Events, *event)
+	case currentAmount < targetAmount && achievedAt != nil:
+		// Se retiró por debajo del objetivo (o se subió el target): la meta vuelve a estar en curso
+		_, err = tx.Exec(ctx, `UPDATE savings_goals SET achieved_at = NULL WHERE id = $1`, goalID)
+		if err != nil {
+			return nil, err
+		}
+		progress.AchievedAt = nil
+	}
+
+	return progress, nil
+}
+
+func insertEvent(ctx context.Context, tx pgx.Tx, goalID, eventType string, milestoneID, milestoneName *string, transactionID *uuid.UUID, currentAmount float64) (*Event, error) {
+	event := Event{
+		EventType:     eventType,
+		MilestoneID:   milestoneID,
+		MilestoneName: milestoneName,
+		TransactionID: transactionID,
+		CurrentAmount: currentAmount,
+	}
+
+	err := tx.QueryRow(ctx, `
+		INSERT INTO savings_goal_events (
+			savings_goal_id, event_type, milestone_id, milestone_name, transaction_id, current_amount
+		) VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, occurred_at
+	`, goalID, eventType, milestoneID, milestoneName, transactionID, currentAmount).Scan(&event.ID, &event.OccurredAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &event, nil
+}
@@ -0,0 +1,32 @@
+package savings
+
+import "time"
+
+// Frecuencias válidas de un aporte automático (ENUM contribution_frequency)
+const (
+	FrequencyDaily   = "daily"
+	FrequencyWeekly  = "weekly"
+	FrequencyMonthly = "monthly"
+)
+
+// AddPeriods mueve date n períodos de la frecuencia (n puede ser negativo)
+// En monthly se respeta anchorDay (el día de start_date) y se ajusta al último día del mes
+// si no existe: una regla que arranca el 31 corre el 28/29 en febrero y vuelve al 31 en marzo
+func AddPeriods(frequency string, date time.Time, anchorDay, n int) time.Time {
+	date = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+
+	switch frequency {
+	case FrequencyDaily:
+		return date.AddDate(0, 0, n)
+	case FrequencyWeekly:
+		return date.AddDate(0, 0, 7*n)
+	default:
+		firstOfMonth := time.Date(date.Year(), date.Month()+time.Month(n), 1, 0, 0, 0, 0, time.UTC)
+		lastDay := firstOfMonth.AddDate(0, 1, -1).Day()
+		day := anchorDay
+		if day > lastDay {
+			day = lastDay
+		}
+		return time.Date(firstOfMonth.Year(), firstOfMonth.Month(), day, 0, 0, 0, 0, time.UTC)
+	}
+}
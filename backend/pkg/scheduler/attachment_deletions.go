@@ -0,0 +1,76 @@
+package scheduler
+
+import (
+	"context"
+
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/attachments"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/logger"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// purgeBatchSize limita los archivos borrados por ejecución
+const purgeBatchSize = 500
+
+// PurgeDeletedAttachments borra del storage los archivos de los adjuntos eliminados
+// La cola (attachment_deletions) la llena un trigger, así que incluye los adjuntos que se fueron en
+// cascada al borrar un gasto, un ingreso o una cuenta. Los de otro backend quedan en la cola
+func PurgeDeletedAttachments(pool *pgxpool.Pool, store attachments.Storage) error {
+	ctx := context.Background()
+
+	rows, err := pool.Query(ctx, `
+		SELECT id, storage_key
+		FROM attachment_deletions
+		WHERE storage_backend = $1
+		ORDER BY deleted_at
+		LIMIT $2
+	`, store.Name(), purgeBatchSize)
+	if err != nil {
+		return err
+	}
+
+	type deletion struct{ id, key string }
+	var pending []deletion
+	for rows.Next() {
+		var d deletion
+		if err := rows.Scan(&d.id, &d.key); err != nil {
+			rows.Close()
+			return err
+		}
+		pending = append(pending, d)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	purged, failed := 0, 0
+	for _, d := range pending {
+		// Si se volvió a usar la misma clave (no debería pasar: son UUIDs) el archivo se conserva
+		var inUse bool
+		err := pool.QueryRow(ctx, `
+			SELECT EXISTS(SELECT 1 FROM attachments WHERE storage_backend = $1 AND storage_key = $2)
+		`, store.Name(), d.key).Scan(&inUse)
+		if err == nil && !inUse {
+			err = store.Delete(ctx, d.key)
+		}
+		if err != nil {
+			failed++
+			logger.Error("scheduler.attachments_purge.error", "Error borrando archivo del storage", map[string]interface{}{
+				"storage_key": d.key,
+				"error":       err.Error(),
+			})
+			continue // Queda en la cola para la próxima ejecución
+		}
+
+		if _, err := pool.Exec(ctx, `DELETE FROM attachment_deletions WHERE id = $1`, d.id); err != nil {
+			return err
+		}
+		purged++
+	}
+
+	logger.Info("scheduler.attachments_purge.done", "Archivos de adjuntos eliminados del storage", map[string]interface{}{
+		"purged": purged,
+		"failed": failed,
+	})
+	return nil
+}
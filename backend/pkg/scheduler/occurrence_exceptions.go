@@ -0,0 +1,69 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// OccurrenceException representa una excepción puntual sobre una ocurrencia de un template
+// ExceptionType: "skip" (no generar) u "override" (generar con otro monto)
+type OccurrenceException struct {
+	ExceptionType                   string
+	OverrideAmount                  *float64
+	OverrideAmountInPrimaryCurrency *float64
+}
+
+// getExpenseException busca la excepción de un recurring_expense para una fecha
+// Retorna nil si la ocurrencia no tiene excepción
+func getExpenseException(pool *pgxpool.Pool, ctx context.Context, templateID string, date time.Time) (*OccurrenceException, error) {
+	query := `
+		SELECT exception_type, override_amount, override_amount_in_primary_currency
+		FROM recurring_expense_exceptions
+		WHERE recurring_expense_id = $1 AND occurrence_date = $2
+	`
+	return scanOccurrenceException(pool.QueryRow(ctx, query, templateID, date))
+}
+
+// getIncomeException busca la excepción de un recurring_income para una fecha
+// Retorna nil si la ocurrencia no tiene excepción
+func getIncomeException(pool *pgxpool.Pool, ctx context.Context, templateID string, date time.Time) (*OccurrenceException, error) {
+	query := `
+		SELECT exception_type, override_amount, override_amount_in_primary_currency
+		FROM recurring_income_exceptions
+		WHERE recurring_income_id = $1 AND occurrence_date = $2
+	`
+	return scanOccurrenceException(pool.QueryRow(ctx, query, templateID, date))
+}
+
+func scanOccurrenceException(row pgx.Row) (*OccurrenceException, error) {
+	var e OccurrenceException
+	err := row.Scan(&e.ExceptionType, &e.OverrideAmount, &e.OverrideAmountInPrimaryCurrency)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// overrideAmounts calcula amount y amount_in_primary_currency para una ocurrencia con override
+// Si la excepción no trae el monto en moneda primaria, se usa el exchange_rate del template
+func (e *OccurrenceException) overrideAmounts(exchangeRate *float64) (float64, *float64) {
+	amount := *e.OverrideAmount
+
+	if e.OverrideAmountInPrimaryCurrency != nil {
+		amountInPrimaryCurrency := *e.OverrideAmountInPrimaryCurrency
+		return amount, &amountInPrimaryCurrency
+	}
+
+	rate := 1.0
+	if exchangeRate != nil {
+		rate = *exchangeRate
+	}
+	amountInPrimaryCurrency := amount * rate
+	return amount, &amountInPrimaryCurrency
+}
@@ -7,6 +7,9 @@ import (
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/LorenzoCampos/bolsillo-claro/pkg/logger"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/rules"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/statement"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/tags"
 )
 
 // RecurringExpenseTemplate representa un template activo que puede generar gastos
@@ -18,6 +21,7 @@ type RecurringExpenseTemplate struct {
 	Currency                  string
 	CategoryID                *string
 	FamilyMemberID            *string
+	PaymentMethodID           *string // Medio de pago que se copia al movimiento generado (migración 022)
 	RecurrenceFrequency       string
 	RecurrenceInterval        int
 	RecurrenceDayOfMonth      *int
@@ -28,6 +32,7 @@ type RecurringExpenseTemplate struct {
 	CurrentOccurrence         int
 	ExchangeRate              *float64
 	AmountInPrimaryCurrency   *float64
+	VersionID                 *string // Versión del template usada para generar (migración 019)
 }
 
 // GenerateDailyRecurringExpenses genera gastos recurrentes para el día de hoy
@@ -90,6 +95,55 @@ func GenerateDailyRecurringExpenses(pool *pgxpool.Pool) error {
 		// Calcular la fecha del gasto a generar
 		expenseDate := calculateExpenseDate(template, today)
 
+		// Usar la versión del template vigente en la fecha del gasto (historial de precios)
+		err = applyExpenseVersion(pool, ctx, &template, expenseDate)
+		if err != nil {
+			logger.Error("scheduler.recurring_expenses.version_error", "Error obteniendo versión del template", map[string]interface{}{
+				"template_id": template.ID,
+				"error":       err.Error(),
+			})
+			errorCount++
+			continue
+		}
+
+		if template.VersionID != nil {
+			err = syncExpenseTemplateWithVersion(pool, ctx, template)
+			if err != nil {
+				logger.Warning("scheduler.recurring_expenses.version_sync_error", "Error sincronizando template con su versión vigente", map[string]interface{}{
+					"template_id": template.ID,
+					"version_id":  *template.VersionID,
+					"error":       err.Error(),
+				})
+				// No marcamos como error: el gasto se genera igual con la versión correcta
+			}
+		}
+
+		// Consultar si hay una excepción para esta ocurrencia (skip u override)
+		exception, err := getExpenseException(pool, ctx, template.ID, expenseDate)
+		if err != nil {
+			logger.Error("scheduler.recurring_expenses.exception_error", "Error obteniendo excepción de ocurrencia", map[string]interface{}{
+				"template_id": template.ID,
+				"error":       err.Error(),
+			})
+			errorCount++
+			continue
+		}
+
+		if exception != nil && exception.ExceptionType == "skip" {
+			// La ocurrencia salteada NO cuenta para total_occurrences
+			logger.Info("scheduler.recurring_expenses.skip_exception", "Ocurrencia salteada por excepción", map[string]interface{}{
+				"template_id": template.ID,
+				"description": template.Description,
+				"date":        expenseDate.Format("2006-01-02"),
+			})
+			skipCount++
+			continue
+		}
+
+		if exception != nil && exception.ExceptionType == "override" {
+			template.Amount, template.AmountInPrimaryCurrency = exception.overrideAmounts(template.ExchangeRate)
+		}
+
 		// Generar el gasto
 		err = generateExpenseFromTemplate(pool, ctx, template, expenseDate)
 		if err != nil {
@@ -172,12 +226,14 @@ func getTemplatesForToday(pool *pgxpool.Pool, ctx context.Context, today time.Ti
 			recurrence_day_of_month, recurrence_day_of_week,
 			start_date, end_date,
 			total_occurrences, current_occurrence,
-			exchange_rate, amount_in_primary_currency
+			exchange_rate, amount_in_primary_currency,
+			payment_method_id
 		FROM recurring_expenses
 		WHERE is_active = true
 		  AND start_date <= $1
 		  AND (end_date IS NULL OR end_date >= $1)
 		  AND (total_occurrences IS NULL OR current_occurrence < total_occurrences)
+		  AND (paused_until IS NULL OR paused_until < $1)
 	`
 
 	rows, err := pool.Query(ctx, query, today)
@@ -200,6 +256,7 @@ func getTemplatesForToday(pool *pgxpool.Pool, ctx context.Context, today time.Ti
 			&startDate, &endDate,
 			&t.TotalOccurrences, &t.CurrentOccurrence,
 			&t.ExchangeRate, &t.AmountInPrimaryCurrency,
+			&t.PaymentMethodID,
 		)
 		if err != nil {
 			return nil, err
@@ -223,6 +280,29 @@ func getTemplatesForToday(pool *pgxpool.Pool, ctx context.Context, today time.Ti
 	return templates, nil
 }
 
+// ExpenseOccurrencesBetween devuelve las fechas (entre from y to, inclusive) en las que el template
+// generaría un gasto. No considera excepciones, pausas ni total_occurrences
+func ExpenseOccurrencesBetween(t RecurringExpenseTemplate, from, to time.Time) []time.Time {
+	from = from.UTC().Truncate(24 * time.Hour)
+	to = to.UTC().Truncate(24 * time.Hour)
+
+	if from.Before(t.StartDate) {
+		from = t.StartDate
+	}
+	if t.EndDate != nil && to.After(*t.EndDate) {
+		to = *t.EndDate
+	}
+
+	dates := []time.Time{}
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		if shouldGenerateToday(t, day) {
+			dates = append(dates, day)
+		}
+	}
+
+	return dates
+}
+
 // shouldGenerateToday determina si un template debe generar un gasto HOY
 func shouldGenerateToday(t RecurringExpenseTemplate, today time.Time) bool {
 	switch t.RecurrenceFrequency {
@@ -324,12 +404,45 @@ func generateExpenseFromTemplate(pool *pgxpool.Pool, ctx context.Context, t Recu
 			description, amount, currency,
 			exchange_rate, amount_in_primary_currency,
 			expense_type, date,
-			recurring_expense_id,
+			recurring_expense_id, recurring_expense_version_id,
+			payment_method_id, statement_closing_date, statement_due_date,
 			created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, NOW(), NOW())
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, NOW(), NOW())
 		RETURNING id
 	`
 
+	// Medio de pago del template: si es tarjeta de crédito, calcular el resumen en el que cae
+	paymentMethodID := t.PaymentMethodID
+	var statementClosingDate, statementDueDate *time.Time
+	if paymentMethodID != nil {
+		var err error
+		statementClosingDate, statementDueDate, err = statement.ResolveDates(ctx, pool, *paymentMethodID, t.AccountID, expenseDate)
+		if err == statement.ErrPaymentMethodNotFound {
+			// Medio de pago desactivado: se genera el gasto sin medio de pago
+			logger.Warning("scheduler.expense.payment_method_inactive", "Medio de pago inactivo, gasto generado sin medio de pago", map[string]interface{}{
+				"recurring_expense_id": t.ID,
+				"payment_method_id":    *paymentMethodID,
+			})
+			paymentMethodID = nil
+		} else if err != nil {
+			return err
+		}
+	}
+
+	// Reglas de auto-categorización: completan lo que el template deja vacío y suman tags
+	movement := rules.Movement{
+		Description:     t.Description,
+		Amount:          t.Amount,
+		Currency:        t.Currency,
+		PaymentMethodID: paymentMethodID,
+		CategoryID:      t.CategoryID,
+		FamilyMemberID:  t.FamilyMemberID,
+	}
+	appliedRules, err := rules.Categorize(ctx, pool, t.AccountID, rules.Expense, &movement)
+	if err != nil {
+		return err
+	}
+
 	// Exchange rate: usar del template o default 1.0
 	exchangeRate := 1.0
 	if t.ExchangeRate != nil {
@@ -343,12 +456,12 @@ func generateExpenseFromTemplate(pool *pgxpool.Pool, ctx context.Context, t Recu
 	}
 
 	var expenseID string
-	err := pool.QueryRow(
+	err = pool.QueryRow(
 		ctx,
 		insertQuery,
 		t.AccountID,
-		t.FamilyMemberID,
-		t.CategoryID,
+		movement.FamilyMemberID,
+		movement.CategoryID,
 		t.Description,
 		t.Amount,
 		t.Currency,
@@ -356,13 +469,25 @@ func generateExpenseFromTemplate(pool *pgxpool.Pool, ctx context.Context, t Recu
 		amountInPrimaryCurrency,
 		"recurring", // expense_type
 		expenseDate,
-		t.ID, // recurring_expense_id (FK al template)
+		t.ID,        // recurring_expense_id (FK al template)
+		t.VersionID, // recurring_expense_version_id (versión usada)
+		paymentMethodID,
+		statementClosingDate,
+		statementDueDate,
 	).Scan(&expenseID)
 
 	if err != nil {
 		return err
 	}
 
+	// Los tags del template se copian al gasto generado
+	if err := tags.Copy(ctx, pool, tags.RecurringExpenses, t.ID, tags.Expenses, expenseID); err != nil {
+		return err
+	}
+	if err := tags.Add(ctx, pool, tags.Expenses, expenseID, t.AccountID, movement.Tags); err != nil {
+		return err
+	}
+
 	logger.Info("scheduler.expense.generated", "Gasto generado desde template", map[string]interface{}{
 		"expense_id":           expenseID,
 		"recurring_expense_id": t.ID,
@@ -371,6 +496,7 @@ func generateExpenseFromTemplate(pool *pgxpool.Pool, ctx context.Context, t Recu
 		"amount":               t.Amount,
 		"currency":             t.Currency,
 		"date":                 expenseDate.Format("2006-01-02"),
+		"applied_rules":        appliedRules,
 	})
 
 	return nil
@@ -7,6 +7,9 @@ import (
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/LorenzoCampos/bolsillo-claro/pkg/logger"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/rules"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/statement"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/tags"
 )
 
 // RecurringIncomeTemplate representa un template activo que puede generar ingresos
@@ -18,6 +21,7 @@ type RecurringIncomeTemplate struct {
 	Currency                  string
 	CategoryID                *string
 	FamilyMemberID            *string
+	PaymentMethodID           *string // Medio de pago que se copia al movimiento generado (migración 022)
 	RecurrenceFrequency       string
 	RecurrenceInterval        int
 	RecurrenceDayOfMonth      *int
@@ -90,6 +94,32 @@ func GenerateDailyRecurringIncomes(pool *pgxpool.Pool) error {
 		// Calcular la fecha del ingreso a generar
 		incomeDate := calculateIncomeGenerationDate(template, today)
 
+		// Consultar si hay una excepción para esta ocurrencia (skip u override)
+		exception, err := getIncomeException(pool, ctx, template.ID, incomeDate)
+		if err != nil {
+			logger.Error("scheduler.recurring_incomes.exception_error", "Error obteniendo excepción de ocurrencia", map[string]interface{}{
+				"template_id": template.ID,
+				"error":       err.Error(),
+			})
+			errorCount++
+			continue
+		}
+
+		if exception != nil && exception.ExceptionType == "skip" {
+			// La ocurrencia salteada NO cuenta para total_occurrences
+			logger.Info("scheduler.recurring_incomes.skip_exception", "Ocurrencia salteada por excepción", map[string]interface{}{
+				"template_id": template.ID,
+				"description": template.Description,
+				"date":        incomeDate.Format("2006-01-02"),
+			})
+			skipCount++
+			continue
+		}
+
+		if exception != nil && exception.ExceptionType == "override" {
+			template.Amount, template.AmountInPrimaryCurrency = exception.overrideAmounts(template.ExchangeRate)
+		}
+
 		// Generar el ingreso
 		err = generateActualIncomeFromTemplate(pool, ctx, template, incomeDate)
 		if err != nil {
@@ -172,12 +202,14 @@ func getIncomeTemplatesForToday(pool *pgxpool.Pool, ctx context.Context, today t
 			recurrence_day_of_month, recurrence_day_of_week,
 			start_date, end_date,
 			total_occurrences, current_occurrence,
-			exchange_rate, amount_in_primary_currency
+			exchange_rate, amount_in_primary_currency,
+			payment_method_id
 		FROM recurring_incomes
 		WHERE is_active = true
 		  AND start_date <= $1
 		  AND (end_date IS NULL OR end_date >= $1)
 		  AND (total_occurrences IS NULL OR current_occurrence < total_occurrences)
+		  AND (paused_until IS NULL OR paused_until < $1)
 	`
 
 	rows, err := pool.Query(ctx, query, today)
@@ -200,6 +232,7 @@ func getIncomeTemplatesForToday(pool *pgxpool.Pool, ctx context.Context, today t
 			&startDate, &endDate,
 			&t.TotalOccurrences, &t.CurrentOccurrence,
 			&t.ExchangeRate, &t.AmountInPrimaryCurrency,
+			&t.PaymentMethodID,
 		)
 		if err != nil {
 			return nil, err
@@ -223,6 +256,29 @@ func getIncomeTemplatesForToday(pool *pgxpool.Pool, ctx context.Context, today t
 	return templates, nil
 }
 
+// IncomeOccurrencesBetween devuelve las fechas (entre from y to, inclusive) en las que el template
+// generaría un ingreso. No considera excepciones, pausas ni total_occurrences
+func IncomeOccurrencesBetween(t RecurringIncomeTemplate, from, to time.Time) []time.Time {
+	from = from.UTC().Truncate(24 * time.Hour)
+	to = to.UTC().Truncate(24 * time.Hour)
+
+	if from.Before(t.StartDate) {
+		from = t.StartDate
+	}
+	if t.EndDate != nil && to.After(*t.EndDate) {
+		to = *t.EndDate
+	}
+
+	dates := []time.Time{}
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		if shouldGenerateIncomeToday(t, day) {
+			dates = append(dates, day)
+		}
+	}
+
+	return dates
+}
+
 // shouldGenerateIncomeToday determina si un template debe generar un ingreso HOY
 func shouldGenerateIncomeToday(t RecurringIncomeTemplate, today time.Time) bool {
 	switch t.RecurrenceFrequency {
@@ -324,12 +380,42 @@ func generateActualIncomeFromTemplate(pool *pgxpool.Pool, ctx context.Context, t
 			description, amount, currency,
 			exchange_rate, amount_in_primary_currency,
 			income_type, date,
-			recurring_income_id,
+			recurring_income_id, payment_method_id,
 			created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, NOW(), NOW())
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, NOW(), NOW())
 		RETURNING id
 	`
 
+	// Medio de pago del template: solo se copia si sigue existiendo y activo
+	paymentMethodID := t.PaymentMethodID
+	if paymentMethodID != nil {
+		_, _, err := statement.ResolveDates(ctx, pool, *paymentMethodID, t.AccountID, incomeDate)
+		if err == statement.ErrPaymentMethodNotFound {
+			// Medio de pago desactivado: se genera el ingreso sin medio de pago
+			logger.Warning("scheduler.income.payment_method_inactive", "Medio de pago inactivo, ingreso generado sin medio de pago", map[string]interface{}{
+				"recurring_income_id": t.ID,
+				"payment_method_id":   *paymentMethodID,
+			})
+			paymentMethodID = nil
+		} else if err != nil {
+			return err
+		}
+	}
+
+	// Reglas de auto-categorización: completan lo que el template deja vacío y suman tags
+	movement := rules.Movement{
+		Description:     t.Description,
+		Amount:          t.Amount,
+		Currency:        t.Currency,
+		PaymentMethodID: paymentMethodID,
+		CategoryID:      t.CategoryID,
+		FamilyMemberID:  t.FamilyMemberID,
+	}
+	appliedRules, err := rules.Categorize(ctx, pool, t.AccountID, rules.Income, &movement)
+	if err != nil {
+		return err
+	}
+
 	// Exchange rate: usar del template o default 1.0
 	exchangeRate := 1.0
 	if t.ExchangeRate != nil {
@@ -343,12 +429,12 @@ func generateActualIncomeFromTemplate(pool *pgxpool.Pool, ctx context.Context, t
 	}
 
 	var incomeID string
-	err := pool.QueryRow(
+	err = pool.QueryRow(
 		ctx,
 		insertQuery,
 		t.AccountID,
-		t.FamilyMemberID,
-		t.CategoryID,
+		movement.FamilyMemberID,
+		movement.CategoryID,
 		t.Description,
 		t.Amount,
 		t.Currency,
@@ -356,13 +442,22 @@ func generateActualIncomeFromTemplate(pool *pgxpool.Pool, ctx context.Context, t
 		amountInPrimaryCurrency,
 		"recurring", // income_type
 		incomeDate,
-		t.ID, // recurring_income_id (FK al template)
+		t.ID,              // recurring_income_id (FK al template)
+		paymentMethodID,   // payment_method_id (copiado del template si sigue activo)
 	).Scan(&incomeID)
 
 	if err != nil {
 		return err
 	}
 
+	// Los tags del template se copian al ingreso generado
+	if err := tags.Copy(ctx, pool, tags.RecurringIncomes, t.ID, tags.Incomes, incomeID); err != nil {
+		return err
+	}
+	if err := tags.Add(ctx, pool, tags.Incomes, incomeID, t.AccountID, movement.Tags); err != nil {
+		return err
+	}
+
 	logger.Info("scheduler.income.generated", "Gasto generado desde template", map[string]interface{}{
 		"income_id":           incomeID,
 		"recurring_income_id": t.ID,
@@ -371,6 +466,7 @@ func generateActualIncomeFromTemplate(pool *pgxpool.Pool, ctx context.Context, t
 		"amount":               t.Amount,
 		"currency":             t.Currency,
 		"date":                 incomeDate.Format("2006-01-02"),
+		"applied_rules":        appliedRules,
 	})
 
 	return nil
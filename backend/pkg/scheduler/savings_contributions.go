@@ -0,0 +1,298 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/logger"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/savings"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Resultado de cada ejecución de una regla (savings_goal_contribution_rules.last_run_status)
+const (
+	contributionApplied             = "applied"
+	contributionInsufficientBalance = "skipped_insufficient_balance"
+	contributionNoIncome            = "skipped_no_income"
+	contributionDeadlinePassed      = "skipped_deadline_passed"
+	contributionNoRate              = "skipped_no_rate"
+)
+
+// contributionAlreadyRun indica que otra ejecución ya procesó esa fecha (no se guarda en last_run_status)
+const contributionAlreadyRun = "already_run"
+
+// ContributionRule representa una regla de aporte automático activa con ejecución pendiente
+type ContributionRule struct {
+	ID              string
+	SavingsGoalID   string
+	AccountID       string
+	GoalName        string
+	GoalDeadline    *time.Time
+	GoalCurrency    string
+	AccountCurrency string
+	RuleType        string
+	Amount          *float64
+	Percentage      *float64
+	Frequency       string
+	StartDate       time.Time
+	NextRunDate     time.Time
+	LastRunDate     *time.Time
+}
+
+// ExecuteSavingsContributionRules ejecuta los aportes automáticos a metas de ahorro pendientes
+// Cada ejecución registra el depósito con savings.Record (misma lógica que add-funds) en su propia transacción
+// Si el servidor estuvo apagado, se ejecutan los períodos atrasados uno por uno hasta hoy
+func ExecuteSavingsContributionRules(pool *pgxpool.Pool) error {
+	ctx := context.Background()
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+
+	logger.Info("scheduler.savings_contributions.start", "Iniciando aportes automáticos a metas de ahorro", map[string]interface{}{
+		"date": today.Format("2006-01-02"),
+	})
+
+	rules, err := getDueContributionRules(pool, ctx, today)
+	if err != nil {
+		logger.Error("scheduler.savings_contributions.error", "Error obteniendo reglas de aporte", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return err
+	}
+
+	if len(rules) == 0 {
+		logger.Info("scheduler.savings_contributions.complete", "No hay aportes para ejecutar hoy", map[string]interface{}{
+			"date": today.Format("2006-01-02"),
+		})
+		return nil
+	}
+
+	logger.Info("scheduler.savings_contributions.found", fmt.Sprintf("Encontradas %d reglas para procesar", len(rules)), map[string]interface{}{
+		"count": len(rules),
+	})
+
+	appliedCount := 0
+	skipCount := 0
+	errorCount := 0
+
+	for _, rule := range rules {
+		for !rule.NextRunDate.After(today) {
+			runDate := rule.NextRunDate
+
+			status, amount, err := executeContributionRule(pool, ctx, &rule, runDate)
+			if status == contributionAlreadyRun {
+				// Otra ejecución (catch-up de arranque vs CRON) ya avanzó la regla: seguimos desde su next_run_date
+				continue
+			}
+			if err != nil {
+				logger.Error("scheduler.savings_contributions.execute_error", "Error ejecutando aporte automático", map[string]interface{}{
+					"rule_id":  rule.ID,
+					"goal_id":  rule.SavingsGoalID,
+					"run_date": runDate.Format("2006-01-02"),
+					"error":    err.Error(),
+				})
+				errorCount++
+				// No avanzamos next_run_date: se reintenta en la próxima ejecución del CRON
+				break
+			}
+
+			if status == contributionApplied {
+				logger.Info("scheduler.savings_contributions.applied", "Aporte automático registrado", map[string]interface{}{
+					"rule_id":    rule.ID,
+					"goal_id":    rule.SavingsGoalID,
+					"goal_name":  rule.GoalName,
+					"account_id": rule.AccountID,
+					"amount":     amount,
+					"run_date":   runDate.Format("2006-01-02"),
+				})
+				appliedCount++
+			} else {
+				logger.Warning("scheduler.savings_contributions.skip", "Aporte automático salteado", map[string]interface{}{
+					"rule_id":    rule.ID,
+					"goal_id":    rule.SavingsGoalID,
+					"goal_name":  rule.GoalName,
+					"account_id": rule.AccountID,
+					"amount":     amount,
+					"reason":     status,
+					"run_date":   runDate.Format("2006-01-02"),
+				})
+				skipCount++
+			}
+		}
+	}
+
+	logger.Info("scheduler.savings_contributions.complete", "Aportes automáticos completados", map[string]interface{}{
+		"rules":   len(rules),
+		"applied": appliedCount,
+		"skipped": skipCount,
+		"errors":  errorCount,
+	})
+
+	return nil
+}
+
+// getDueContributionRules obtiene las reglas activas de metas activas con next_run_date <= hoy
+// Las metas completadas (achieved_at) no reciben aportes; si se retira por debajo del objetivo, se reanudan
+func getDueContributionRules(pool *pgxpool.Pool, ctx context.Context, today time.Time) ([]ContributionRule, error) {
+	query := `
+		SELECT
+			r.id, r.savings_goal_id, sg.account_id, sg.name, sg.deadline, sg.currency, a.currency,
+			r.rule_type, r.amount, r.percentage, r.frequency,
+			r.start_date, r.next_run_date, r.last_run_date
+		FROM savings_goal_contribution_rules r
+		JOIN savings_goals sg ON sg.id = r.savings_goal_id
+		JOIN accounts a ON a.id = sg.account_id
+		WHERE r.is_active = true
+		  AND sg.is_active = true
+		  AND sg.achieved_at IS NULL
+		  AND r.next_run_date <= $1
+		ORDER BY r.next_run_date, r.created_at
+	`
+
+	rows, err := pool.Query(ctx, query, today)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rules []ContributionRule
+	for rows.Next() {
+		var r ContributionRule
+		err := rows.Scan(
+			&r.ID, &r.SavingsGoalID, &r.AccountID, &r.GoalName, &r.GoalDeadline, &r.GoalCurrency, &r.AccountCurrency,
+			&r.RuleType, &r.Amount, &r.Percentage, &r.Frequency,
+			&r.StartDate, &r.NextRunDate, &r.LastRunDate,
+		)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, r)
+	}
+
+	return rules, rows.Err()
+}
+
+// executeContributionRule ejecuta una ocurrencia de la regla en una transacción:
+// calcula el monto, valida el saldo disponible, registra el depósito y avanza next_run_date
+// Los aportes salteados también avanzan la regla (no se acumulan para el período siguiente)
+func executeContributionRule(pool *pgxpool.Pool, ctx context.Context, rule *ContributionRule, runDate time.Time) (string, float64, error) {
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return "", 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	// Bloquear la regla y releer su estado: si otra ejecución ya procesó runDate, no se repite el aporte
+	var nextRunDate time.Time
+	err = tx.QueryRow(ctx, `
+		SELECT next_run_date, last_run_date
+		FROM savings_goal_contribution_rules
+		WHERE id = $1
+		FOR UPDATE
+	`, rule.ID).Scan(&nextRunDate, &rule.LastRunDate)
+	if err != nil {
+		return "", 0, err
+	}
+	if !nextRunDate.Equal(runDate) {
+		rule.NextRunDate = nextRunDate
+		return contributionAlreadyRun, 0, nil
+	}
+
+	status, movement, err := contributionStatus(ctx, tx, rule, runDate)
+	if err != nil {
+		return "", 0, err
+	}
+
+	var amount float64
+	if movement != nil {
+		amount = movement.Amount
+	}
+
+	if status == contributionApplied {
+		description := "Aporte automático"
+		movement.Description = &description
+		movement.ContributionRuleID = &rule.ID
+		_, err = savings.Record(ctx, tx, rule.SavingsGoalID, rule.AccountID, *movement)
+		if err != nil {
+			return "", 0, err
+		}
+	}
+
+	nextRunDate = savings.AddPeriods(rule.Frequency, runDate, rule.StartDate.Day(), 1)
+	_, err = tx.Exec(ctx, `
+		UPDATE savings_goal_contribution_rules
+		SET next_run_date = $1, last_run_date = $2, last_run_status = $3
+		WHERE id = $4
+	`, nextRunDate, runDate, status, rule.ID)
+	if err != nil {
+		return "", 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return "", 0, err
+	}
+
+	rule.NextRunDate = nextRunDate
+	rule.LastRunDate = &runDate
+
+	return status, amount, nil
+}
+
+// contributionStatus calcula el movimiento a registrar y si corresponde aplicarlo
+// fixed_amount está en la moneda de la meta; income_percentage sale de los ingresos en la moneda principal
+func contributionStatus(ctx context.Context, tx pgx.Tx, rule *ContributionRule, runDate time.Time) (string, *savings.Movement, error) {
+	if rule.GoalDeadline != nil && runDate.After(*rule.GoalDeadline) {
+		return contributionDeadlinePassed, nil, nil
+	}
+
+	var src savings.Source
+	if rule.RuleType == "fixed_amount" {
+		src = savings.Source{Amount: *rule.Amount, Currency: rule.GoalCurrency}
+	} else {
+		// Ingresos recibidos desde la ejecución anterior (o desde un período antes de la primera)
+		windowStart := savings.AddPeriods(rule.Frequency, runDate, rule.StartDate.Day(), -1)
+		if rule.LastRunDate != nil {
+			windowStart = *rule.LastRunDate
+		}
+
+		var incomes float64
+		err := tx.QueryRow(ctx, `
+			SELECT COALESCE(SUM(amount_in_primary_currency), 0)
+			FROM incomes
+			WHERE account_id = $1 AND date > $2 AND date <= $3
+		`, rule.AccountID, windowStart, runDate).Scan(&incomes)
+		if err != nil {
+			return "", nil, err
+		}
+
+		amount := math.Round(incomes*(*rule.Percentage)) / 100
+		if amount <= 0 {
+			return contributionNoIncome, nil, nil
+		}
+		src = savings.Source{Amount: amount, Currency: rule.AccountCurrency}
+	}
+
+	movement, err := savings.ResolveMovement(ctx, tx, "deposit", rule.GoalCurrency, rule.AccountCurrency, src, runDate.Format("2006-01-02"))
+	if err == savings.ErrRateNotFound {
+		return contributionNoRate, nil, nil
+	}
+	if err != nil {
+		return "", nil, err
+	}
+
+	// Sin el monto en la moneda principal no se puede validar el saldo disponible
+	if movement.AmountInPrimaryCurrency == nil {
+		return contributionNoRate, movement, nil
+	}
+
+	available, err := savings.AvailableBalance(ctx, tx, rule.AccountID, runDate)
+	if err != nil {
+		return "", nil, err
+	}
+	if available-*movement.AmountInPrimaryCurrency < 0 {
+		return contributionInsufficientBalance, movement, nil
+	}
+
+	return contributionApplied, movement, nil
+}
@@ -0,0 +1,56 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// applyExpenseVersion reemplaza los campos versionados del template por los de la versión
+// vigente en la fecha indicada (la de mayor effective_from <= date)
+// Si el template no tiene versiones (no debería pasar después de la migración 019), no hace nada
+func applyExpenseVersion(pool *pgxpool.Pool, ctx context.Context, t *RecurringExpenseTemplate, date time.Time) error {
+	query := `
+		SELECT id, description, amount, currency, category_id, family_member_id,
+		       exchange_rate, amount_in_primary_currency
+		FROM recurring_expense_versions
+		WHERE recurring_expense_id = $1 AND effective_from <= $2
+		ORDER BY effective_from DESC
+		LIMIT 1
+	`
+
+	var versionID string
+	err := pool.QueryRow(ctx, query, t.ID, date).Scan(
+		&versionID, &t.Description, &t.Amount, &t.Currency, &t.CategoryID, &t.FamilyMemberID,
+		&t.ExchangeRate, &t.AmountInPrimaryCurrency,
+	)
+	if err == pgx.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	t.VersionID = &versionID
+	return nil
+}
+
+// syncExpenseTemplateWithVersion copia al template los valores de la versión vigente, para que
+// GET /recurring-expenses/:id refleje el precio actual cuando entra en vigencia una versión futura
+func syncExpenseTemplateWithVersion(pool *pgxpool.Pool, ctx context.Context, t RecurringExpenseTemplate) error {
+	query := `
+		UPDATE recurring_expenses
+		SET description = $2, amount = $3, currency = $4, category_id = $5, family_member_id = $6,
+		    exchange_rate = $7, amount_in_primary_currency = $8
+		WHERE id = $1
+		  AND (description, amount, currency, category_id, family_member_id, exchange_rate, amount_in_primary_currency)
+		      IS DISTINCT FROM ($2, $3, $4::currency, $5::uuid, $6::uuid, $7::numeric, $8::numeric)
+	`
+	_, err := pool.Exec(ctx, query,
+		t.ID, t.Description, t.Amount, t.Currency, t.CategoryID, t.FamilyMemberID,
+		t.ExchangeRate, t.AmountInPrimaryCurrency,
+	)
+	return err
+}
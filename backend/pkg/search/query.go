@@ -0,0 +1,271 @@
+package search
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Config es la configuración de búsqueda de texto (migración 034): español sin acentos
+const Config = "spanish_unaccent"
+
+// Query es una búsqueda ya interpretada. Ej: `super -delivery >5000 2026-03`
+// → Terms [super], Excluded [delivery], AmountMin 5000.01, DateFrom 2026-03-01, DateTo 2026-03-31
+type Query struct {
+	Terms     []string   // Palabras que tienen que aparecer (también como prefijo: "super" → "supermercado")
+	Excluded  []string   // -palabra
+	Phrases   [][]string // "frase exacta"
+	AmountMin *float64   // Inclusive
+	AmountMax *float64   // Inclusive
+	DateFrom  *time.Time // Inclusive
+	DateTo    *time.Time // Inclusive
+}
+
+// Parse interpreta la sintaxis de búsqueda:
+//   - palabra, "frase exacta", -palabra (excluir)
+//   - montos: >5000, >=5000, <100, <=100, 1000..5000 (en la moneda del movimiento)
+//   - fechas: 2026-03 (mes), 2026-03-15 (día), 2026-01..2026-03, >=2026-03-01, <2026-03
+//
+// Si hay varios filtros de monto o de fecha se combinan (se quedan con el rango más chico)
+func Parse(input string) (Query, error) {
+	var q Query
+	for _, token := range splitTokens(input) {
+		if token.phrase {
+			if words := words(token.text); len(words) > 0 {
+				q.Phrases = append(q.Phrases, words)
+			}
+			continue
+		}
+
+		text := token.text
+		switch {
+		case strings.HasPrefix(text, ">=") || strings.HasPrefix(text, "<=") || strings.HasPrefix(text, ">") || strings.HasPrefix(text, "<"):
+			if err := q.parseComparison(text); err != nil {
+				return Query{}, err
+			}
+		case strings.Contains(text, ".."):
+			if err := q.parseRange(text); err != nil {
+				return Query{}, err
+			}
+		case isDate(text):
+			from, to, _ := parseDate(text)
+			q.narrowDates(&from, &to)
+		case strings.HasPrefix(text, "-") && len(text) > 1:
+			q.Excluded = append(q.Excluded, words(text[1:])...)
+		default:
+			q.Terms = append(q.Terms, words(text)...)
+		}
+	}
+	return q, nil
+}
+
+// Words arma una búsqueda solo de texto: todas las palabras tienen que aparecer, sin operadores
+// (para filtros de texto como el search de GET /expenses)
+func Words(text string) Query {
+	return Query{Terms: words(text)}
+}
+
+// HasText indica si la búsqueda tiene algo de texto (si no, es solo por monto y/o fecha)
+func (q Query) HasText() bool {
+	return len(q.Terms) > 0 || len(q.Excluded) > 0 || len(q.Phrases) > 0
+}
+
+// TSQuery arma la expresión para to_tsquery(Config, ...). Las palabras solo tienen letras y
+// números, así que no hay nada que escapar
+func (q Query) TSQuery() string {
+	var parts []string
+	for _, term := range q.Terms {
+		parts = append(parts, term+":*")
+	}
+	for _, phrase := range q.Phrases {
+		parts = append(parts, "("+strings.Join(phrase, " <-> ")+")")
+	}
+	for _, term := range q.Excluded {
+		parts = append(parts, "!"+term)
+	}
+	return strings.Join(parts, " & ")
+}
+
+// Text devuelve la parte de texto de la búsqueda, para mostrar cómo se interpretó
+func (q Query) Text() string {
+	parts := append([]string{}, q.Terms...)
+	for _, phrase := range q.Phrases {
+		parts = append(parts, `"`+strings.Join(phrase, " ")+`"`)
+	}
+	for _, term := range q.Excluded {
+		parts = append(parts, "-"+term)
+	}
+	return strings.Join(parts, " ")
+}
+
+func (q *Query) parseComparison(text string) error {
+	op := text[:1]
+	value := text[1:]
+	inclusive := strings.HasPrefix(value, "=")
+	if inclusive {
+		value = value[1:]
+	}
+
+	if isDate(value) {
+		from, to, _ := parseDate(value)
+		switch {
+		case op == ">" && inclusive:
+			q.narrowDates(&from, nil)
+		case op == ">":
+			next := to.AddDate(0, 0, 1)
+			q.narrowDates(&next, nil)
+		case op == "<" && inclusive:
+			q.narrowDates(nil, &to)
+		default:
+			previous := from.AddDate(0, 0, -1)
+			q.narrowDates(nil, &previous)
+		}
+		return nil
+	}
+
+	amount, err := parseAmount(value)
+	if err != nil {
+		return invalidFilter(text)
+	}
+	// Los montos tienen 2 decimales: > 5000 es lo mismo que >= 5000.01
+	switch {
+	case op == ">" && !inclusive:
+		amount += 0.01
+	case op == "<" && !inclusive:
+		amount -= 0.01
+	}
+	if op == ">" {
+		q.narrowAmounts(&amount, nil)
+	} else {
+		q.narrowAmounts(nil, &amount)
+	}
+	return nil
+}
+
+func (q *Query) parseRange(text string) error {
+	parts := strings.SplitN(text, "..", 2)
+	low, high := parts[0], parts[1]
+	if low == "" && high == "" {
+		return invalidFilter(text)
+	}
+
+	if (low == "" || isDate(low)) && (high == "" || isDate(high)) {
+		var from, to *time.Time
+		if low != "" {
+			start, _, _ := parseDate(low)
+			from = &start
+		}
+		if high != "" {
+			_, end, _ := parseDate(high)
+			to = &end
+		}
+		q.narrowDates(from, to)
+		return nil
+	}
+
+	var min, max *float64
+	if low != "" {
+		amount, err := parseAmount(low)
+		if err != nil {
+			return invalidFilter(text)
+		}
+		min = &amount
+	}
+	if high != "" {
+		amount, err := parseAmount(high)
+		if err != nil {
+			return invalidFilter(text)
+		}
+		max = &amount
+	}
+	q.narrowAmounts(min, max)
+	return nil
+}
+
+func (q *Query) narrowAmounts(min, max *float64) {
+	if min != nil && (q.AmountMin == nil || *min > *q.AmountMin) {
+		q.AmountMin = min
+	}
+	if max != nil && (q.AmountMax == nil || *max < *q.AmountMax) {
+		q.AmountMax = max
+	}
+}
+
+func (q *Query) narrowDates(from, to *time.Time) {
+	if from != nil && (q.DateFrom == nil || from.After(*q.DateFrom)) {
+		q.DateFrom = from
+	}
+	if to != nil && (q.DateTo == nil || to.Before(*q.DateTo)) {
+		q.DateTo = to
+	}
+}
+
+func invalidFilter(text string) error {
+	return errors.New(`invalid filter "` + text + `": use amounts like >5000, <=100, 1000..5000 or dates like 2026-03, 2026-03-15, 2026-01..2026-03`)
+}
+
+type token struct {
+	text   string
+	phrase bool
+}
+
+// splitTokens separa por espacios respetando las frases entre comillas
+func splitTokens(input string) []token {
+	var tokens []token
+	var current strings.Builder
+	inPhrase := false
+
+	flush := func(phrase bool) {
+		if current.Len() > 0 {
+			tokens = append(tokens, token{text: current.String(), phrase: phrase})
+			current.Reset()
+		}
+	}
+
+	for _, r := range input {
+		switch {
+		case r == '"':
+			flush(inPhrase)
+			inPhrase = !inPhrase
+		case unicode.IsSpace(r) && !inPhrase:
+			flush(false)
+		default:
+			current.WriteRune(r)
+		}
+	}
+	flush(inPhrase)
+	return tokens
+}
+
+// words parte un texto en palabras de letras y números, en minúsculas ("Coca-Cola" → [coca cola])
+func words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func isDate(text string) bool {
+	_, _, ok := parseDate(text)
+	return ok
+}
+
+// parseDate interpreta YYYY-MM-DD (ese día) o YYYY-MM (todo el mes)
+func parseDate(text string) (from, to time.Time, ok bool) {
+	if day, err := time.Parse("2006-01-02", text); err == nil {
+		return day, day, true
+	}
+	if month, err := time.Parse("2006-01", text); err == nil {
+		return month, month.AddDate(0, 1, -1), true
+	}
+	return time.Time{}, time.Time{}, false
+}
+
+func parseAmount(text string) (float64, error) {
+	amount, err := strconv.ParseFloat(strings.TrimPrefix(text, "$"), 64)
+	if err != nil || amount < 0 {
+		return 0, errors.New("invalid amount")
+	}
+	return amount, nil
+}
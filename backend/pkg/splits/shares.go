@@ -0,0 +1,129 @@
+package splits
+
+import (
+	"math"
+	"sort"
+)
+
+// Valores del ENUM expense_split_type
+const (
+	SplitEqual      = "equal"
+	SplitPercentage = "percentage"
+	SplitExact      = "exact"
+)
+
+// Share es la parte de un miembro tal como se cargó
+type Share struct {
+	MemberID   string
+	Percentage *float64 // split_type = percentage
+	Amount     *float64 // split_type = exact
+}
+
+// Amounts reparte total entre las partes según el tipo de división
+// Se trabaja en centavos: los que sobran del redondeo van a las primeras partes, así la suma cierra
+// exacto. En exact, si el gasto cambió de monto después de dividirlo, se reparte proporcionalmente
+func Amounts(splitType string, total float64, shares []Share) []float64 {
+	weights := make([]float64, len(shares))
+	for i, s := range shares {
+		switch {
+		case splitType == SplitPercentage && s.Percentage != nil:
+			weights[i] = *s.Percentage
+		case splitType == SplitExact && s.Amount != nil:
+			weights[i] = *s.Amount
+		default:
+			weights[i] = 1
+		}
+	}
+
+	return allocate(total, weights)
+}
+
+func allocate(total float64, weights []float64) []float64 {
+	result := make([]float64, len(weights))
+	sum := 0.0
+	for _, w := range weights {
+		sum += w
+	}
+	if sum <= 0 {
+		return result
+	}
+
+	totalCents := int64(math.Round(total * 100))
+	var assigned int64
+	cents := make([]int64, len(weights))
+	for i, w := range weights {
+		cents[i] = int64(math.Floor(float64(totalCents) * w / sum))
+		assigned += cents[i]
+	}
+	for i := 0; assigned < totalCents; i = (i + 1) % len(cents) {
+		cents[i]++
+		assigned++
+	}
+
+	for i, c := range cents {
+		result[i] = float64(c) / 100
+	}
+	return result
+}
+
+// Transfer es un pago sugerido para saldar cuentas
+type Transfer struct {
+	From   string
+	To     string
+	Amount float64
+}
+
+// Simplify arma las transferencias para dejar todos los saldos en 0 (estilo Splitwise):
+// el que más debe le paga al que más le deben, hasta que alguno de los dos queda saldado
+// balances: > 0 le deben, < 0 debe
+func Simplify(balances map[string]float64) []Transfer {
+	type member struct {
+		id    string
+		cents int64
+	}
+
+	var debtors, creditors []member
+	for id, balance := range balances {
+		cents := int64(math.Round(balance * 100))
+		switch {
+		case cents < 0:
+			debtors = append(debtors, member{id, -cents})
+		case cents > 0:
+			creditors = append(creditors, member{id, cents})
+		}
+	}
+
+	// Orden estable: mayor saldo primero, id para desempatar
+	byAmount := func(list []member) {
+		sort.Slice(list, func(i, j int) bool {
+			if list[i].cents != list[j].cents {
+				return list[i].cents > list[j].cents
+			}
+			return list[i].id < list[j].id
+		})
+	}
+	byAmount(debtors)
+	byAmount(creditors)
+
+	transfers := []Transfer{}
+	i, j := 0, 0
+	for i < len(debtors) && j < len(creditors) {
+		amount := debtors[i].cents
+		if creditors[j].cents < amount {
+			amount = creditors[j].cents
+		}
+
+		transfers = append(transfers, Transfer{From: debtors[i].id, To: creditors[j].id, Amount: float64(amount) / 100})
+
+		debtors[i].cents -= amount
+		creditors[j].cents -= amount
+		if debtors[i].cents == 0 {
+			i++
+		}
+		if creditors[j].cents == 0 {
+			j++
+		}
+	}
+
+	return transfers
+}
@@ -0,0 +1,81 @@
+package statement
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/LorenzoCampos/bolsillo-claro/internal/database"
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrPaymentMethodNotFound se retorna cuando el medio de pago no existe, no pertenece
+// a la cuenta o está inactivo
+var ErrPaymentMethodNotFound = errors.New("payment method not found")
+
+// Cycle representa un ciclo de resumen de tarjeta de crédito
+type Cycle struct {
+	Start   time.Time // Día siguiente al cierre anterior
+	Closing time.Time // Fecha de cierre (inclusive)
+	Due     time.Time // Fecha de vencimiento del resumen
+}
+
+// CycleFor calcula el ciclo del resumen en el que cae una compra hecha en date
+// Un gasto del mismo día del cierre entra en ese resumen
+// Si due_day <= closing_day, el vencimiento es el mes siguiente al cierre
+func CycleFor(closingDay, dueDay int, date time.Time) Cycle {
+	date = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+
+	closing := dayInMonth(date.Year(), date.Month(), closingDay)
+	if date.After(closing) {
+		closing = dayInMonth(date.Year(), date.Month()+1, closingDay)
+	}
+
+	previousClosing := dayInMonth(closing.Year(), closing.Month()-1, closingDay)
+
+	due := dayInMonth(closing.Year(), closing.Month(), dueDay)
+	if !due.After(closing) {
+		due = dayInMonth(closing.Year(), closing.Month()+1, dueDay)
+	}
+
+	return Cycle{
+		Start:   previousClosing.AddDate(0, 0, 1),
+		Closing: closing,
+		Due:     due,
+	}
+}
+
+// ResolveDates valida que el medio de pago pertenezca a la cuenta y esté activo, y devuelve
+// el cierre/vencimiento del resumen para un gasto en date (nil si no es tarjeta de crédito)
+func ResolveDates(ctx context.Context, q database.Querier, paymentMethodID string, accountID interface{}, date time.Time) (*time.Time, *time.Time, error) {
+	var closingDay, dueDay *int
+	query := `
+		SELECT closing_day, due_day
+		FROM payment_methods
+		WHERE id = $1 AND account_id = $2 AND is_active = true
+	`
+	err := q.QueryRow(ctx, query, paymentMethodID, accountID).Scan(&closingDay, &dueDay)
+	if err == pgx.ErrNoRows {
+		return nil, nil, ErrPaymentMethodNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if closingDay == nil || dueDay == nil {
+		return nil, nil, nil
+	}
+
+	cycle := CycleFor(*closingDay, *dueDay, date)
+	return &cycle.Closing, &cycle.Due, nil
+}
+
+// dayInMonth arma la fecha con ese día, usando el último día del mes si no existe (ej: 31 en abril)
+func dayInMonth(year int, month time.Month, day int) time.Time {
+	firstOfMonth := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	lastDay := firstOfMonth.AddDate(0, 1, -1).Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return time.Date(firstOfMonth.Year(), firstOfMonth.Month(), day, 0, 0, 0, 0, time.UTC)
+}
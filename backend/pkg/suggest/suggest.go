@@ -0,0 +1,182 @@
+package suggest
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/LorenzoCampos/bolsillo-claro/internal/database"
+)
+
+const (
+	// HistoryLimit es cuántos gastos categorizados recientes se miran
+	HistoryLimit = 2000
+
+	// HalfLifeDays: un gasto de hace 180 días pesa la mitad que uno de hoy
+	HalfLifeDays = 180.0
+
+	// MinSimilarity descarta coincidencias de una sola palabra en descripciones largas
+	MinSimilarity = 0.25
+
+	// HighConfidence y MinMatches son el umbral para autocompletar la categoría al crear un gasto
+	HighConfidence = 0.75
+	MinMatches     = 2
+)
+
+// stopwords son palabras que no dicen nada de la categoría
+var stopwords = map[string]bool{
+	"de": true, "del": true, "la": true, "las": true, "el": true, "los": true, "en": true,
+	"y": true, "a": true, "al": true, "por": true, "para": true, "con": true, "un": true, "una": true,
+}
+
+var accents = strings.NewReplacer("á", "a", "é", "e", "í", "i", "ó", "o", "ú", "u", "ü", "u", "ñ", "n")
+
+// Example es un gasto pasado con su categoría
+type Example struct {
+	Description string
+	CategoryID  string
+	Date        time.Time
+}
+
+// Suggestion es una categoría candidata para una descripción
+type Suggestion struct {
+	CategoryID string
+	Score      float64 // Suma de similitud × peso por antigüedad de los gastos parecidos
+	Confidence float64 // Score / suma de los scores de todas las candidatas (0-1)
+	Matches    int     // Cantidad de gastos parecidos con esta categoría
+}
+
+// IsConfident indica si la sugerencia es lo bastante segura para autocompletar la categoría
+func (s Suggestion) IsConfident() bool {
+	return s.Confidence >= HighConfidence && s.Matches >= MinMatches
+}
+
+// Tokenize normaliza una descripción en palabras: minúsculas, sin acentos, sin números sueltos
+// (montos, fechas, números de operación) ni stopwords, sin repetidas
+// Ej: "UBER *Trip 4821 a Palermo" → [uber trip palermo]
+func Tokenize(description string) []string {
+	normalized := accents.Replace(strings.ToLower(description))
+	fields := strings.FieldsFunc(normalized, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	result := []string{}
+	seen := map[string]bool{}
+	for _, field := range fields {
+		if utf8.RuneCountInString(field) < 2 || stopwords[field] || seen[field] || isNumber(field) {
+			continue
+		}
+		seen[field] = true
+		result = append(result, field)
+	}
+	return result
+}
+
+// Rank ordena las categorías del historial según qué tan parecidas son sus descripciones a description
+// Similitud: Jaccard entre las palabras. Peso: se divide a la mitad cada HalfLifeDays
+func Rank(history []Example, description string, now time.Time) []Suggestion {
+	query := Tokenize(description)
+	if len(query) == 0 {
+		return []Suggestion{}
+	}
+
+	byCategory := map[string]*Suggestion{}
+	total := 0.0
+	for _, example := range history {
+		similarity := jaccard(query, Tokenize(example.Description))
+		if similarity < MinSimilarity {
+			continue
+		}
+
+		ageDays := math.Max(now.Sub(example.Date).Hours()/24, 0)
+		weight := similarity * math.Pow(0.5, ageDays/HalfLifeDays)
+
+		s, ok := byCategory[example.CategoryID]
+		if !ok {
+			s = &Suggestion{CategoryID: example.CategoryID}
+			byCategory[example.CategoryID] = s
+		}
+		s.Score += weight
+		s.Matches++
+		total += weight
+	}
+
+	result := make([]Suggestion, 0, len(byCategory))
+	for _, s := range byCategory {
+		s.Confidence = s.Score / total
+		result = append(result, *s)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Score != result[j].Score {
+			return result[i].Score > result[j].Score
+		}
+		return result[i].CategoryID < result[j].CategoryID
+	})
+	return result
+}
+
+// LoadHistory trae los últimos HistoryLimit gastos categorizados de la cuenta
+// Solo los que tienen una categoría que la cuenta todavía ve (de sistema o propia)
+func LoadHistory(ctx context.Context, q database.Querier, accountID interface{}) ([]Example, error) {
+	rows, err := q.Query(ctx, `
+		SELECT e.description, e.category_id, e.date
+		FROM expenses e
+		JOIN expense_categories c ON c.id = e.category_id
+		WHERE e.account_id = $1 AND NOT e.is_draft AND (c.account_id IS NULL OR c.account_id = $1)
+		ORDER BY e.date DESC, e.created_at DESC
+		LIMIT $2
+	`, accountID, HistoryLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	history := []Example{}
+	for rows.Next() {
+		var example Example
+		if err := rows.Scan(&example.Description, &example.CategoryID, &example.Date); err != nil {
+			return nil, err
+		}
+		history = append(history, example)
+	}
+	return history, rows.Err()
+}
+
+// Suggest rankea las categorías de gastos para description con el historial de la cuenta
+func Suggest(ctx context.Context, q database.Querier, accountID interface{}, description string) ([]Suggestion, error) {
+	history, err := LoadHistory(ctx, q, accountID)
+	if err != nil {
+		return nil, err
+	}
+	return Rank(history, description, time.Now().UTC()), nil
+}
+
+func jaccard(a, b []string) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	inA := make(map[string]bool, len(a))
+	for _, token := range a {
+		inA[token] = true
+	}
+	common := 0
+	for _, token := range b {
+		if inA[token] {
+			common++
+		}
+	}
+	return float64(common) / float64(len(a)+len(b)-common)
+}
+
+func isNumber(s string) bool {
+	for _, r := range s {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,187 @@
+package tags
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/LorenzoCampos/bolsillo-claro/internal/database"
+)
+
+// MaxLength es el largo máximo de un tag (VARCHAR(40) en la tabla)
+const MaxLength = 40
+
+// MaxPerItem es la cantidad máxima de tags por movimiento
+const MaxPerItem = 20
+
+var (
+	ErrInvalidTag  = errors.New("tags can only contain letters, numbers, '-' and '_' (max 40 characters)")
+	ErrTooManyTags = errors.New("a movement can have at most 20 tags")
+)
+
+// Link es la tabla que relaciona los tags con un tipo de movimiento
+type Link struct {
+	Table  string // Ej: expense_tags
+	Column string // Columna con el id del movimiento, ej: expense_id
+}
+
+var (
+	Expenses          = Link{Table: "expense_tags", Column: "expense_id"}
+	Incomes           = Link{Table: "income_tags", Column: "income_id"}
+	RecurringExpenses = Link{Table: "recurring_expense_tags", Column: "recurring_expense_id"}
+	RecurringIncomes  = Link{Table: "recurring_income_tags", Column: "recurring_income_id"}
+)
+
+// Normalize limpia un tag: minúsculas, sin espacios en los extremos y con guiones en lugar de espacios
+// Ej: "  Vacaciones 2026 " → "vacaciones-2026"
+func Normalize(name string) (string, error) {
+	name = strings.Join(strings.Fields(strings.ToLower(name)), "-")
+	if name == "" || utf8.RuneCountInString(name) > MaxLength {
+		return "", ErrInvalidTag
+	}
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '_' {
+			return "", ErrInvalidTag
+		}
+	}
+	return name, nil
+}
+
+// NormalizeAll normaliza una lista de tags y saca los repetidos (conserva el orden)
+func NormalizeAll(names []string) ([]string, error) {
+	result := []string{}
+	seen := map[string]bool{}
+	for _, name := range names {
+		normalized, err := Normalize(name)
+		if err != nil {
+			return nil, err
+		}
+		if seen[normalized] {
+			continue
+		}
+		seen[normalized] = true
+		result = append(result, normalized)
+	}
+	if len(result) > MaxPerItem {
+		return nil, ErrTooManyTags
+	}
+	return result, nil
+}
+
+// Set reemplaza los tags de un movimiento. Los tags que no existen en la cuenta se crean
+// names tiene que venir normalizado (NormalizeAll)
+func Set(ctx context.Context, q database.Querier, link Link, itemID string, accountID interface{}, names []string) error {
+	if _, err := q.Exec(ctx, `DELETE FROM `+link.Table+` WHERE `+link.Column+` = $1`, itemID); err != nil {
+		return err
+	}
+	return Add(ctx, q, link, itemID, accountID, names)
+}
+
+// Add suma tags a un movimiento sin tocar los que ya tiene (ej: los que agrega una regla)
+// names tiene que venir normalizado (NormalizeAll)
+func Add(ctx context.Context, q database.Querier, link Link, itemID string, accountID interface{}, names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+
+	_, err := q.Exec(ctx, `
+		INSERT INTO tags (account_id, name)
+		SELECT $1, unnest($2::TEXT[])
+		ON CONFLICT (account_id, name) DO NOTHING
+	`, accountID, names)
+	if err != nil {
+		return err
+	}
+
+	_, err = q.Exec(ctx, `
+		INSERT INTO `+link.Table+` (`+link.Column+`, tag_id)
+		SELECT $1, id FROM tags WHERE account_id = $2 AND name = ANY($3)
+		ON CONFLICT DO NOTHING
+	`, itemID, accountID, names)
+	return err
+}
+
+// Copy copia los tags de un movimiento a otro (ej: de un template al gasto generado)
+func Copy(ctx context.Context, q database.Querier, from Link, fromID string, to Link, toID string) error {
+	_, err := q.Exec(ctx, `
+		INSERT INTO `+to.Table+` (`+to.Column+`, tag_id)
+		SELECT $1, tag_id FROM `+from.Table+` WHERE `+from.Column+` = $2
+		ON CONFLICT DO NOTHING
+	`, toID, fromID)
+	return err
+}
+
+// Load devuelve los tags (ordenados por nombre) de cada movimiento de la lista
+// Los movimientos sin tags tienen una lista vacía
+func Load(ctx context.Context, q database.Querier, link Link, itemIDs []string) (map[string][]string, error) {
+	result := make(map[string][]string, len(itemIDs))
+	for _, id := range itemIDs {
+		result[id] = []string{}
+	}
+	if len(itemIDs) == 0 {
+		return result, nil
+	}
+
+	rows, err := q.Query(ctx, `
+		SELECT l.`+link.Column+`::TEXT, t.name
+		FROM `+link.Table+` l
+		JOIN tags t ON t.id = l.tag_id
+		WHERE l.`+link.Column+` = ANY($1::UUID[])
+		ORDER BY t.name
+	`, itemIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id, name string
+		if err := rows.Scan(&id, &name); err != nil {
+			return nil, err
+		}
+		result[id] = append(result[id], name)
+	}
+	return result, rows.Err()
+}
+
+// Filter arma la condición WHERE para filtrar movimientos por tags
+// itemExpr es la columna id del movimiento en la query (ej: e.id) y argIndex el número del
+// parámetro que va a recibir names. matchAll = true exige todos los tags; si no, alcanza con uno
+func Filter(link Link, itemExpr string, names []string, matchAll bool, argIndex int) string {
+	arg := "$" + strconv.Itoa(argIndex)
+	matches := `
+		FROM ` + link.Table + ` tf
+		JOIN tags tn ON tn.id = tf.tag_id
+		WHERE tf.` + link.Column + ` = ` + itemExpr + ` AND tn.name = ANY(` + arg + `)`
+
+	if matchAll {
+		return "(SELECT COUNT(*)" + matches + ") = " + strconv.Itoa(len(names))
+	}
+	return "EXISTS (SELECT 1" + matches + ")"
+}
+
+// ParseFilter lee los parámetros tags (separados por coma) y tags_match (any | all) de un listado
+func ParseFilter(param, match string) ([]string, bool, error) {
+	if strings.TrimSpace(param) == "" {
+		return nil, false, nil
+	}
+	if match != "" && match != "any" && match != "all" {
+		return nil, false, errors.New("tags_match must be any or all")
+	}
+
+	var parts []string
+	for _, part := range strings.Split(param, ",") {
+		if strings.TrimSpace(part) != "" {
+			parts = append(parts, part)
+		}
+	}
+
+	names, err := NormalizeAll(parts)
+	if err != nil {
+		return nil, false, err
+	}
+	return names, match == "all", nil
+}
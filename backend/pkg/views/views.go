@@ -0,0 +1,136 @@
+package views
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/LorenzoCampos/bolsillo-claro/internal/database"
+	"github.com/LorenzoCampos/bolsillo-claro/pkg/listing"
+)
+
+const (
+	Expense = "expense"
+	Income  = "income"
+)
+
+// MaxRelativeDays / MaxRelativeMonths limitan last_N_days y last_N_months
+const (
+	MaxRelativeDays   = 3650
+	MaxRelativeMonths = 120
+)
+
+var ErrInvalidDateRange = errors.New("date_range must be one of: today, yesterday, this_week, last_week, this_month, last_month, this_year, last_year, last_N_days, last_N_months")
+
+// View es una vista guardada: filtros con nombre sobre gastos o ingresos
+type View struct {
+	ID           string
+	Name         string
+	MovementType string
+	Params       listing.Params // Como en la query string de GET /expenses o GET /incomes
+	DateRange    *string        // Rango relativo, se resuelve al ejecutar la vista
+	SortBy       string
+	Order        string
+}
+
+// Table devuelve la tabla sobre la que corre la vista
+func (v View) Table() listing.Table {
+	if v.MovementType == Income {
+		return listing.Incomes
+	}
+	return listing.Expenses
+}
+
+// Filters resuelve el rango relativo a la fecha now y valida los filtros
+// Es el punto de entrada para usar una vista desde otro lado (resultados, resumen, exportación, alertas)
+func (v View) Filters(now time.Time) (listing.Filters, error) {
+	params := v.Params
+	if v.DateRange != nil {
+		from, to, err := ResolveRange(*v.DateRange, now)
+		if err != nil {
+			return listing.Filters{}, err
+		}
+		params.DateFrom = from.Format("2006-01-02")
+		params.DateTo = to.Format("2006-01-02")
+	}
+	return params.Filters(v.Table())
+}
+
+// Load trae una vista que userID puede ver (las privadas solo las ve quien las creó)
+// Devuelve pgx.ErrNoRows si no existe
+func Load(ctx context.Context, q database.Querier, accountID interface{}, userID, viewID string) (*View, error) {
+	var v View
+	err := q.QueryRow(ctx, `
+		SELECT id, name, movement_type, filters, date_range, sort_by, sort_order
+		FROM saved_views
+		WHERE id = $1 AND account_id = $2 AND (NOT is_private OR created_by = $3)
+	`, viewID, accountID, userID).Scan(&v.ID, &v.Name, &v.MovementType, &v.Params, &v.DateRange, &v.SortBy, &v.Order)
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// ValidRange indica si expr es un rango relativo válido
+func ValidRange(expr string) bool {
+	_, _, err := ResolveRange(expr, time.Now())
+	return err == nil
+}
+
+// ResolveRange convierte un rango relativo en fechas (inclusive) a partir de now:
+//   - today, yesterday
+//   - this_week, last_week (de lunes a domingo)
+//   - this_month, last_month, this_year, last_year (los "this_" terminan a fin de período, no hoy)
+//   - last_N_days: los últimos N días contando hoy (last_90_days)
+//   - last_N_months: desde el primer día de hace N-1 meses hasta hoy (last_3_months en marzo → 1/1 a hoy)
+func ResolveRange(expr string, now time.Time) (from, to time.Time, err error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	yearStart := time.Date(now.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+	weekStart := today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7)) // Lunes
+
+	switch expr {
+	case "today":
+		return today, today, nil
+	case "yesterday":
+		yesterday := today.AddDate(0, 0, -1)
+		return yesterday, yesterday, nil
+	case "this_week":
+		return weekStart, weekStart.AddDate(0, 0, 6), nil
+	case "last_week":
+		return weekStart.AddDate(0, 0, -7), weekStart.AddDate(0, 0, -1), nil
+	case "this_month":
+		return monthStart, monthStart.AddDate(0, 1, -1), nil
+	case "last_month":
+		return monthStart.AddDate(0, -1, 0), monthStart.AddDate(0, 0, -1), nil
+	case "this_year":
+		return yearStart, yearStart.AddDate(1, 0, -1), nil
+	case "last_year":
+		return yearStart.AddDate(-1, 0, 0), yearStart.AddDate(0, 0, -1), nil
+	}
+
+	if n, unit, ok := parseLastN(expr); ok {
+		switch {
+		case unit == "days" && n <= MaxRelativeDays:
+			return today.AddDate(0, 0, -(n - 1)), today, nil
+		case unit == "months" && n <= MaxRelativeMonths:
+			return monthStart.AddDate(0, -(n - 1), 0), today, nil
+		}
+	}
+	return time.Time{}, time.Time{}, ErrInvalidDateRange
+}
+
+// parseLastN lee last_N_days / last_N_months
+func parseLastN(expr string) (int, string, bool) {
+	parts := strings.Split(expr, "_")
+	if len(parts) != 3 || parts[0] != "last" || (parts[2] != "days" && parts[2] != "months") {
+		return 0, "", false
+	}
+	n, err := strconv.Atoi(parts[1])
+	if err != nil || n < 1 {
+		return 0, "", false
+	}
+	return n, parts[2], true
+}